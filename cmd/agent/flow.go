@@ -28,6 +28,7 @@ func runFlow() {
 	cmd.SetVersionTemplate("{{ .Version }}\n")
 
 	cmd.AddCommand(
+		convertCommand(),
 		fmtCommand(),
 		runCommand(),
 	)
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/grafana/agent/pkg/converter"
+)
+
+func convertCommand() *cobra.Command {
+	f := &flowConvert{
+		output: "",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "convert [flags] file",
+		Short: "Convert a static mode configuration file to a River file",
+		Long: `The convert subcommand converts a static mode YAML configuration file into
+an equivalent River configuration file for Grafana Agent Flow.
+
+If the file argument is not supplied or if the file argument is "-", then convert will read from stdin.
+
+The converted file is written to stdout unless the -o flag is provided.
+
+Settings which can't be converted are reported on stderr. If any of them are
+errors, the converted file is still written but convert exits with a non-zero
+status, since the converted file doesn't behave the same as the original.`,
+		Args:         cobra.RangeArgs(0, 1),
+		SilenceUsage: true,
+
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				// Read from stdin when there are no args provided.
+				return f.Run("-")
+			}
+			return f.Run(args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.output, "output", "o", f.output, "write the converted file to this path instead of stdout")
+	return cmd
+}
+
+type flowConvert struct {
+	output string
+}
+
+func (fc *flowConvert) Run(configFile string) error {
+	var (
+		bb  []byte
+		err error
+	)
+	if configFile == "-" {
+		bb, err = io.ReadAll(os.Stdin)
+	} else {
+		bb, err = os.ReadFile(configFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	out, diags := converter.Convert(bb)
+	for _, diag := range diags {
+		fmt.Fprintln(os.Stderr, diag)
+	}
+
+	if out != nil {
+		if fc.output == "" {
+			_, err = os.Stdout.Write(out)
+		} else {
+			err = os.WriteFile(fc.output, out, 0644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if diags.HasErrors() {
+		return fmt.Errorf("encountered errors during conversion")
+	}
+	return nil
+}
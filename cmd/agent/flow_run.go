@@ -8,23 +8,34 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/grafana/agent/web/api"
 	"github.com/grafana/agent/web/ui"
 	"golang.org/x/exp/maps"
 
 	"github.com/fatih/color"
+	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/gorilla/mux"
+	"github.com/grafana/agent/pkg/cluster"
 	"github.com/grafana/agent/pkg/flow"
+	"github.com/grafana/agent/pkg/flow/configsource"
 	"github.com/grafana/agent/pkg/flow/logging"
+	"github.com/grafana/agent/pkg/flow/tracing"
 	"github.com/grafana/agent/pkg/river/diag"
 	"github.com/grafana/agent/pkg/usagestats"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	config_util "github.com/prometheus/common/config"
+	"github.com/rfratto/ckit/peer"
 	"github.com/spf13/cobra"
 	"go.uber.org/atomic"
+	"google.golang.org/grpc"
 
 	// Install Components
 	_ "github.com/grafana/agent/component/all"
@@ -33,21 +44,30 @@ import (
 func runCommand() *cobra.Command {
 	r := &flowRun{
 		httpListenAddr:   "127.0.0.1:12345",
+		grpcListenAddr:   "127.0.0.1:12346",
 		storagePath:      "data-agent/",
 		uiPrefix:         "/",
 		disableReporting: false,
+		pollFrequency:    time.Minute,
+		readinessPolicy:  readinessPolicyLoaded,
+		tracingSampling:  tracing.DefaultOptions.SamplingFraction,
 	}
 
 	cmd := &cobra.Command{
-		Use:   "run [flags] file",
+		Use:   "run [flags] path",
 		Short: "Run Grafana Agent Flow",
 		Long: `The run subcommand runs Grafana Agent Flow in the foreground until an interrupt
 is received.
 
-run must be provided an argument pointing at the River file to use. If the
-River file wasn't specified, can't be loaded, or contains errors, run will exit
+run must be provided an argument pointing at the River config to use. The
+config may be a River file, a directory of .river files which are merged
+together, an http:// or https:// URL, or an s3://bucket/key URL. If the config
+wasn't specified, can't be loaded, or contains errors, run will exit
 immediately.
 
+The config is checked for changes every --config.poll-frequency and reloaded
+when it changed. Sending SIGHUP also reloads the config.
+
 run starts an HTTP server which can be used to debug Grafana Agent Flow or
 force it to reload (by sending a GET or POST request to /-/reload). The listen
 address can be changed through the --server.http.listen-addr flag.
@@ -58,11 +78,33 @@ debugging UI can be changed by providing a different value to
 
 Additionally, the HTTP server exposes the following debug endpoints:
 
-  /debug/pprof   Go performance profiling tools
-
-If reloading the config file fails, Grafana Agent Flow will continue running in
-its last valid state. Components which failed may be be listed as unhealthy,
-depending on the nature of the reload error.
+  /debug/pprof             Go performance profiling tools
+  /debug/flow/evaluations  Slowest components and dependency chains of recent
+                           graph evaluations
+
+Graph evaluations are traced with OpenTelemetry. Spans are exported to the
+OTLP gRPC endpoint set by --tracing.otlp-endpoint, if any. The fraction of
+graph evaluations which are traced can be changed through
+--tracing.sampling-fraction.
+
+The /-/ready endpoint reports whether the agent is ready. With the default
+--readiness.policy of "loaded", the agent is ready once the config loaded
+successfully. With a policy of "healthy", the components listed in
+--readiness.components (or all components if none are listed) must also be
+healthy. The /-/healthy endpoint reports the health of the same components as
+JSON.
+
+When --cluster.enabled is set, run joins a cluster of agents which gossip with
+each other over gRPC. The gRPC listen address can be changed through the
+--server.grpc.listen-addr flag. Peers to join can be provided through
+--cluster.join-addresses or discovered through --cluster.discover-peers.
+Components which support clustering will distribute their work across all
+agents in the cluster.
+
+If reloading the config file fails, Grafana Agent Flow reverts to the last
+config file which loaded successfully and continues running in that state.
+The history of reloads is available in the UI, where previous config files can
+be rolled back to.
 `,
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
@@ -78,25 +120,89 @@ depending on the nature of the reload error.
 	cmd.Flags().StringVar(&r.uiPrefix, "server.http.ui-path-prefix", r.uiPrefix, "Prefix to serve the HTTP UI at")
 	cmd.Flags().
 		BoolVar(&r.disableReporting, "disable-reporting", r.disableReporting, "Disable reporting of enabled components to Grafana.")
+
+	// Config source flags
+	cmd.Flags().
+		DurationVar(&r.pollFrequency, "config.poll-frequency", r.pollFrequency, "How often to check the config for changes. 0 disables checking")
+	cmd.Flags().
+		StringVar(&r.basicAuthUser, "config.url.basic-auth-user", r.basicAuthUser, "User to use for basic auth when fetching the config from an http(s) URL")
+	cmd.Flags().
+		StringVar(&r.basicAuthPassFile, "config.url.basic-auth-password-file", r.basicAuthPassFile, "File containing the password to use for basic auth when fetching the config from an http(s) URL")
+
+	// Readiness flags
+	cmd.Flags().
+		StringVar(&r.readinessPolicy, "readiness.policy", r.readinessPolicy, fmt.Sprintf("Policy for /-/ready. %q: ready once the config is loaded. %q: ready once the config is loaded and components are healthy", readinessPolicyLoaded, readinessPolicyHealthy))
+	cmd.Flags().
+		StringSliceVar(&r.readinessComponents, "readiness.components", r.readinessComponents, "Comma-separated list of IDs of components which must be healthy for the agent to be healthy. Defaults to all components")
+
+	// Tracing flags
+	cmd.Flags().
+		StringVar(&r.tracingEndpoint, "tracing.otlp-endpoint", r.tracingEndpoint, "host:port of an OTLP gRPC endpoint to export controller spans to")
+	cmd.Flags().
+		BoolVar(&r.tracingInsecure, "tracing.otlp-insecure", r.tracingInsecure, "Disable TLS when connecting to --tracing.otlp-endpoint")
+	cmd.Flags().
+		Float64Var(&r.tracingSampling, "tracing.sampling-fraction", r.tracingSampling, "Fraction of graph evaluations to trace, between 0 and 1")
+
+	// Clustering flags
+	cmd.Flags().
+		StringVar(&r.grpcListenAddr, "server.grpc.listen-addr", r.grpcListenAddr, "address to listen for gRPC traffic on when clustering is enabled")
+	cmd.Flags().BoolVar(&r.clusterEnabled, "cluster.enabled", r.clusterEnabled, "Start in clustered mode")
+	cmd.Flags().
+		StringVar(&r.clusterNodeName, "cluster.node-name", r.clusterNodeName, "The name to use for this node (defaults to the hostname)")
+	cmd.Flags().
+		StringVar(&r.clusterAdvertiseAddr, "cluster.advertise-address", r.clusterAdvertiseAddr, "Address to advertise to other cluster peers (defaults to an address of the first network interface and the gRPC listen port)")
+	cmd.Flags().
+		StringSliceVar(&r.clusterJoinAddrs, "cluster.join-addresses", r.clusterJoinAddrs, "Comma-separated list of addresses of cluster peers to join")
+	cmd.Flags().
+		StringVar(&r.clusterDiscoverPeers, "cluster.discover-peers", r.clusterDiscoverPeers, "go-discover expression to find cluster peers to join. Mutually exclusive with --cluster.join-addresses")
 	return cmd
 }
 
+// Supported values of --readiness.policy.
+const (
+	readinessPolicyLoaded  = "loaded"
+	readinessPolicyHealthy = "healthy"
+)
+
 type flowRun struct {
 	httpListenAddr   string
 	storagePath      string
 	uiPrefix         string
 	disableReporting bool
+
+	pollFrequency     time.Duration
+	basicAuthUser     string
+	basicAuthPassFile string
+
+	readinessPolicy     string
+	readinessComponents []string
+
+	tracingEndpoint string
+	tracingInsecure bool
+	tracingSampling float64
+
+	grpcListenAddr       string
+	clusterEnabled       bool
+	clusterNodeName      string
+	clusterAdvertiseAddr string
+	clusterJoinAddrs     []string
+	clusterDiscoverPeers string
 }
 
-func (fr *flowRun) Run(configFile string) error {
+func (fr *flowRun) Run(configPath string) error {
 	var wg sync.WaitGroup
 	defer wg.Wait()
 
 	ctx, cancel := interruptContext()
 	defer cancel()
 
-	if configFile == "" {
-		return fmt.Errorf("file argument not provided")
+	if configPath == "" {
+		return fmt.Errorf("path argument not provided")
+	}
+	switch fr.readinessPolicy {
+	case readinessPolicyLoaded, readinessPolicyHealthy:
+	default:
+		return fmt.Errorf("unknown readiness policy %q, expected %q or %q", fr.readinessPolicy, readinessPolicyLoaded, readinessPolicyHealthy)
 	}
 
 	l, err := logging.New(os.Stderr, logging.DefaultOptions)
@@ -104,19 +210,56 @@ func (fr *flowRun) Run(configFile string) error {
 		return fmt.Errorf("building logger: %w", err)
 	}
 
+	var clusterer cluster.Node = cluster.NewLocalNode(fr.grpcListenAddr)
+	if fr.clusterEnabled {
+		node, err := fr.startClusterNode(ctx, l, &wg)
+		if err != nil {
+			return fmt.Errorf("starting cluster node: %w", err)
+		}
+		defer fr.stopClusterNode(l, node)
+		clusterer = node
+	}
+
+	tracingOpts := tracing.DefaultOptions
+	tracingOpts.OTLPEndpoint = fr.tracingEndpoint
+	tracingOpts.OTLPInsecure = fr.tracingInsecure
+	tracingOpts.SamplingFraction = fr.tracingSampling
+	tp, err := tracing.New(tracingOpts)
+	if err != nil {
+		return fmt.Errorf("building tracer provider: %w", err)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := tp.Shutdown(shutdownCtx); err != nil {
+			level.Warn(l).Log("msg", "failed to flush spans", "err", err)
+		}
+	}()
+
 	f := flow.New(flow.Options{
 		Logger:         l,
 		DataPath:       fr.storagePath,
 		Reg:            prometheus.DefaultRegisterer,
 		HTTPListenAddr: fr.httpListenAddr,
+		Clusterer:      clusterer,
+		TracerProvider: tp,
 	})
 
-	reload := func() error {
-		flowCfg, err := loadFlowFile(configFile)
-		if err != nil {
-			return fmt.Errorf("reading config file %q: %w", configFile, err)
+	src, err := configsource.New(configPath, fr.configSourceOptions())
+	if err != nil {
+		return fmt.Errorf("creating config source: %w", err)
+	}
+	watcher := configsource.NewWatcher(l, prometheus.DefaultRegisterer, src, func(ff *flow.File, force bool) error {
+		// Explicit reloads evaluate every component so that changes outside of
+		// the config file, such as to environment variables, are picked up.
+		if force {
+			return f.ReloadFile(ff, nil)
 		}
-		if err := f.LoadFile(flowCfg); err != nil {
+		return f.LoadFile(ff, nil)
+	})
+
+	reload := func() error {
+		if err := watcher.Reload(ctx); err != nil {
 			return fmt.Errorf("error during the initial gragent load: %w", err)
 		}
 		return nil
@@ -125,14 +268,12 @@ func (fr *flowRun) Run(configFile string) error {
 	if err := reload(); err != nil {
 		var diags diag.Diagnostics
 		if errors.As(err, &diags) {
-			bb, _ := os.ReadFile(configFile)
-
 			p := diag.NewPrinter(diag.PrinterConfig{
 				Color:              !color.NoColor,
 				ContextLinesBefore: 1,
 				ContextLinesAfter:  1,
 			})
-			_ = p.Fprint(os.Stderr, map[string][]byte{configFile: bb}, diags)
+			_ = p.Fprint(os.Stderr, watcher.Files(), diags)
 
 			// Print newline after the diagnostics.
 			fmt.Println()
@@ -144,6 +285,20 @@ func (fr *flowRun) Run(configFile string) error {
 		return err
 	}
 
+	if fr.pollFrequency > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			watcher.Run(ctx, fr.pollFrequency)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reloadOnSignal(ctx, l, reload)
+	}()
+
 	// HTTP server
 	{
 		lis, err := net.Listen("tcp", fr.httpListenAddr)
@@ -155,18 +310,27 @@ func (fr *flowRun) Run(configFile string) error {
 
 		r.Handle("/metrics", promhttp.Handler())
 		r.PathPrefix("/debug/pprof").Handler(http.DefaultServeMux)
+		r.Handle("/debug/flow/evaluations", tp.Recorder())
 		r.PathPrefix("/component/{id}/").Handler(f.ComponentHandler())
 
 		ready := atomic.NewBool(true)
 		r.HandleFunc("/-/ready", func(w http.ResponseWriter, r *http.Request) {
-			if ready.Load() {
-				w.WriteHeader(http.StatusOK)
-				fmt.Fprintf(w, "Agent is Ready.\n")
-			} else {
+			if !ready.Load() {
 				w.WriteHeader(http.StatusServiceUnavailable)
 				fmt.Fprint(w, "Config failed to load.\n")
+				return
+			}
+			if fr.readinessPolicy == readinessPolicyHealthy {
+				if err := f.HealthReport(fr.readinessComponents).Err(); err != nil {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					fmt.Fprintf(w, "%s\n", err)
+					return
+				}
 			}
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, "Agent is Ready.\n")
 		})
+		r.HandleFunc("/-/healthy", f.HealthHandler(fr.readinessComponents))
 
 		r.HandleFunc("/-/reload", func(w http.ResponseWriter, _ *http.Request) {
 			err := reload()
@@ -179,7 +343,7 @@ func (fr *flowRun) Run(configFile string) error {
 		}).Methods(http.MethodGet, http.MethodPost)
 
 		// Register Routes must be the last
-		fa := api.NewFlowAPI(f, r)
+		fa := api.NewFlowAPI(f, clusterer, r)
 		fa.RegisterRoutes(fr.uiPrefix, r)
 
 		// NOTE(rfratto): keep this at the bottom of all other routes, otherwise it
@@ -220,6 +384,84 @@ func (fr *flowRun) Run(configFile string) error {
 	return f.Close()
 }
 
+// startClusterNode starts a gRPC server on the gRPC listen address and joins
+// the cluster through it. The gRPC server stops when ctx is canceled.
+func (fr *flowRun) startClusterNode(ctx context.Context, l log.Logger, wg *sync.WaitGroup) (*cluster.GossipNode, error) {
+	_, portStr, err := net.SplitHostPort(fr.grpcListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gRPC listen address %q: %w", fr.grpcListenAddr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gRPC listen port %q: %w", portStr, err)
+	}
+
+	gossipConfig := cluster.DefaultGossipConfig
+	gossipConfig.NodeName = fr.clusterNodeName
+	gossipConfig.AdvertiseAddr = fr.clusterAdvertiseAddr
+	gossipConfig.JoinPeers = fr.clusterJoinAddrs
+	gossipConfig.DiscoverPeers = fr.clusterDiscoverPeers
+	if err := gossipConfig.ApplyDefaults(port); err != nil {
+		return nil, err
+	}
+
+	lis, err := net.Listen("tcp", fr.grpcListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", fr.grpcListenAddr, err)
+	}
+	srv := grpc.NewServer()
+
+	node, err := cluster.NewGossipNode(l, srv, &gossipConfig)
+	if err != nil {
+		_ = lis.Close()
+		return nil, err
+	}
+	if err := prometheus.DefaultRegisterer.Register(node.Metrics()); err != nil {
+		_ = lis.Close()
+		return nil, fmt.Errorf("registering cluster metrics: %w", err)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		level.Info(l).Log("msg", "now listening for grpc traffic", "addr", fr.grpcListenAddr)
+		if err := srv.Serve(lis); err != nil {
+			level.Info(l).Log("msg", "grpc server closed", "err", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+
+	// The gRPC server must be running before the node can be started.
+	if err := node.Start(); err != nil {
+		return nil, fmt.Errorf("joining cluster: %w", err)
+	}
+	level.Info(l).Log("msg", "joined cluster", "node", gossipConfig.NodeName, "advertise_addr", gossipConfig.AdvertiseAddr, "peers", strings.Join(gossipConfig.JoinPeers, ","))
+
+	// Nodes only own keys after moving to the participant state.
+	if err := node.ChangeState(ctx, peer.StateParticipant); err != nil {
+		return nil, fmt.Errorf("changing cluster node state: %w", err)
+	}
+	return node, nil
+}
+
+// stopClusterNode gracefully leaves the cluster, giving peers a chance to
+// take over work owned by the local node.
+func (fr *flowRun) stopClusterNode(l log.Logger, node *cluster.GossipNode) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := node.ChangeState(ctx, peer.StateTerminating); err != nil {
+		level.Warn(l).Log("msg", "failed to change cluster node state to terminating", "err", err)
+	}
+	if err := node.Stop(); err != nil {
+		level.Error(l).Log("msg", "failed to leave cluster", "err", err)
+	}
+}
+
 // getEnabledComponentsFunc returns a function that gets the current enabled components
 func getEnabledComponentsFunc(f *flow.Flow) func() map[string]interface{} {
 	return func() map[string]interface{} {
@@ -232,13 +474,37 @@ func getEnabledComponentsFunc(f *flow.Flow) func() map[string]interface{} {
 	}
 }
 
-func loadFlowFile(filename string) (*flow.File, error) {
-	bb, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
+// configSourceOptions returns the options used to retrieve the config.
+func (fr *flowRun) configSourceOptions() configsource.Options {
+	var opts configsource.Options
+	if fr.basicAuthUser != "" && fr.basicAuthPassFile != "" {
+		opts.HTTPClientConfig.BasicAuth = &config_util.BasicAuth{
+			Username:     fr.basicAuthUser,
+			PasswordFile: fr.basicAuthPassFile,
+		}
 	}
+	return opts
+}
+
+// reloadOnSignal calls reload whenever SIGHUP is received until ctx is
+// canceled.
+func reloadOnSignal(ctx context.Context, l log.Logger, reload func() error) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
 
-	return flow.ReadFile(filename, bb)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			if err := reload(); err != nil {
+				level.Error(l).Log("msg", "failed to reload config after SIGHUP", "err", err)
+			} else {
+				level.Info(l).Log("msg", "config reloaded after SIGHUP")
+			}
+		}
+	}
 }
 
 func interruptContext() (context.Context, context.CancelFunc) {
@@ -2,12 +2,61 @@
 package all
 
 import (
-	_ "github.com/grafana/agent/component/discovery/kubernetes"                 // Import discovery.kubernetes
-	_ "github.com/grafana/agent/component/discovery/relabel"                    // Import discovery.relabel
-	_ "github.com/grafana/agent/component/local/file"                           // Import local.file
-	_ "github.com/grafana/agent/component/prometheus/integration/node_exporter" // Import prometheus.integration.node_exporter
-	_ "github.com/grafana/agent/component/prometheus/relabel"                   // Import prometheus.relabel
-	_ "github.com/grafana/agent/component/prometheus/remotewrite"               // Import prometheus.remote_write
-	_ "github.com/grafana/agent/component/prometheus/scrape"                    // Import prometheus.scrape
-	_ "github.com/grafana/agent/component/remote/s3"                            // Import s3.file
+	_ "github.com/grafana/agent/component/discovery/consul"                              // Import discovery.consul
+	_ "github.com/grafana/agent/component/discovery/dns"                                 // Import discovery.dns
+	_ "github.com/grafana/agent/component/discovery/docker"                              // Import discovery.docker
+	_ "github.com/grafana/agent/component/discovery/ec2"                                 // Import discovery.ec2
+	_ "github.com/grafana/agent/component/discovery/file"                                // Import discovery.file
+	_ "github.com/grafana/agent/component/discovery/gce"                                 // Import discovery.gce
+	_ "github.com/grafana/agent/component/discovery/http"                                // Import discovery.http
+	_ "github.com/grafana/agent/component/discovery/kubernetes"                          // Import discovery.kubernetes
+	_ "github.com/grafana/agent/component/discovery/relabel"                             // Import discovery.relabel
+	_ "github.com/grafana/agent/component/faro/receiver"                                 // Import faro.receiver
+	_ "github.com/grafana/agent/component/local/envfile"                                 // Import local.env_file
+	_ "github.com/grafana/agent/component/local/file"                                    // Import local.file
+	_ "github.com/grafana/agent/component/loki/process"                                  // Import loki.process
+	_ "github.com/grafana/agent/component/loki/relabel"                                  // Import loki.relabel
+	_ "github.com/grafana/agent/component/loki/source/file"                              // Import loki.source.file
+	_ "github.com/grafana/agent/component/loki/write"                                    // Import loki.write
+	_ "github.com/grafana/agent/component/module/file"                                   // Import module.file
+	_ "github.com/grafana/agent/component/module/string"                                 // Import module.string
+	_ "github.com/grafana/agent/component/otelcol/auth/basic"                            // Import otelcol.auth.basic
+	_ "github.com/grafana/agent/component/otelcol/auth/bearer"                           // Import otelcol.auth.bearer
+	_ "github.com/grafana/agent/component/otelcol/auth/oauth2"                           // Import otelcol.auth.oauth2
+	_ "github.com/grafana/agent/component/otelcol/exporter/logging"                      // Import otelcol.exporter.logging
+	_ "github.com/grafana/agent/component/otelcol/exporter/otlp"                         // Import otelcol.exporter.otlp
+	_ "github.com/grafana/agent/component/otelcol/exporter/otlphttp"                     // Import otelcol.exporter.otlphttp
+	_ "github.com/grafana/agent/component/otelcol/processor/attributes"                  // Import otelcol.processor.attributes
+	_ "github.com/grafana/agent/component/otelcol/processor/automaticlogging"            // Import otelcol.processor.automatic_logging
+	_ "github.com/grafana/agent/component/otelcol/processor/batch"                       // Import otelcol.processor.batch
+	_ "github.com/grafana/agent/component/otelcol/processor/memorylimiter"               // Import otelcol.processor.memory_limiter
+	_ "github.com/grafana/agent/component/otelcol/processor/servicegraph"                // Import otelcol.processor.service_graph
+	_ "github.com/grafana/agent/component/otelcol/processor/spanmetrics"                 // Import otelcol.processor.span_metrics
+	_ "github.com/grafana/agent/component/otelcol/processor/tailsampling"                // Import otelcol.processor.tail_sampling
+	_ "github.com/grafana/agent/component/otelcol/receiver/jaeger"                       // Import otelcol.receiver.jaeger
+	_ "github.com/grafana/agent/component/otelcol/receiver/otlp"                         // Import otelcol.receiver.otlp
+	_ "github.com/grafana/agent/component/otelcol/receiver/zipkin"                       // Import otelcol.receiver.zipkin
+	_ "github.com/grafana/agent/component/prometheus/integration/blackbox_exporter"      // Import prometheus.integration.blackbox_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/cadvisor"               // Import prometheus.integration.cadvisor
+	_ "github.com/grafana/agent/component/prometheus/integration/consul_exporter"        // Import prometheus.integration.consul_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/dnsmasq_exporter"       // Import prometheus.integration.dnsmasq_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/elasticsearch_exporter" // Import prometheus.integration.elasticsearch_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/github_exporter"        // Import prometheus.integration.github_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/kafka_exporter"         // Import prometheus.integration.kafka_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/memcached_exporter"     // Import prometheus.integration.memcached_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/mongodb_exporter"       // Import prometheus.integration.mongodb_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/mysqld_exporter"        // Import prometheus.integration.mysqld_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/node_exporter"          // Import prometheus.integration.node_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/postgres_exporter"      // Import prometheus.integration.postgres_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/process_exporter"       // Import prometheus.integration.process_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/redis_exporter"         // Import prometheus.integration.redis_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/snmp_exporter"          // Import prometheus.integration.snmp_exporter
+	_ "github.com/grafana/agent/component/prometheus/integration/statsd_exporter"        // Import prometheus.integration.statsd_exporter
+	_ "github.com/grafana/agent/component/prometheus/receivehttp"                        // Import prometheus.receive_http
+	_ "github.com/grafana/agent/component/prometheus/relabel"                            // Import prometheus.relabel
+	_ "github.com/grafana/agent/component/prometheus/remotewrite"                        // Import prometheus.remote_write
+	_ "github.com/grafana/agent/component/prometheus/scrape"                             // Import prometheus.scrape
+	_ "github.com/grafana/agent/component/remote/http"                                   // Import remote.http
+	_ "github.com/grafana/agent/component/remote/s3"                                     // Import s3.file
+	_ "github.com/grafana/agent/component/remote/vault"                                  // Import remote.vault
 )
@@ -2,13 +2,17 @@ package appendable
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/grafana/agent/component/prometheus"
 	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/textparse"
 	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/scrape"
 	"github.com/prometheus/prometheus/storage"
 )
 
@@ -22,36 +26,109 @@ type FlowMetric struct {
 type FlowAppendable struct {
 	mut       sync.RWMutex
 	receivers []*prometheus.Receiver
+
+	// forwardedMut guards forwarded, which holds the metadata most recently
+	// forwarded for each series. Metadata is only forwarded with a sample when
+	// it differs from the metadata previously forwarded for its series.
+	forwardedMut sync.Mutex
+	forwarded    map[storage.SeriesRef]metadata.Metadata
 }
 
 // NewFlowAppendable initializes the appendable.
 func NewFlowAppendable(receivers ...*prometheus.Receiver) *FlowAppendable {
 	return &FlowAppendable{
 		receivers: receivers,
+		forwarded: make(map[storage.SeriesRef]metadata.Metadata),
 	}
 }
 
+// metadataChanged reports whether md differs from the metadata most recently
+// forwarded for the series.
+func (app *FlowAppendable) metadataChanged(ref storage.SeriesRef, md metadata.Metadata) bool {
+	app.forwardedMut.Lock()
+	defer app.forwardedMut.Unlock()
+	prev, ok := app.forwarded[ref]
+	return !ok || prev != md
+}
+
+// setForwarded records md as the metadata forwarded for the series.
+func (app *FlowAppendable) setForwarded(ref storage.SeriesRef, md metadata.Metadata) {
+	app.forwardedMut.Lock()
+	defer app.forwardedMut.Unlock()
+	app.forwarded[ref] = md
+}
+
+// forgetForwarded forgets the metadata forwarded for the series, so that it's
+// forwarded again the next time the series is appended.
+func (app *FlowAppendable) forgetForwarded(ref storage.SeriesRef) {
+	app.forwardedMut.Lock()
+	defer app.forwardedMut.Unlock()
+	delete(app.forwarded, ref)
+}
+
 type flowAppender struct {
-	buffer    map[int64][]*prometheus.FlowMetric // Though mostly a map of 1 item, this allows it to work if more than one TS gets added
+	parent    *FlowAppendable
+	buffer    map[int64][]*sample // Though mostly a map of 1 item, this allows it to work if more than one TS gets added
+	latest    map[storage.SeriesRef]*sample
 	receivers []*prometheus.Receiver
+
+	// metadataStore is set when the appender is used by a scrape loop which
+	// passes the metadata of the scraped target in the context. lookups
+	// caches the metadata found for each metric name, or nil if there is
+	// none.
+	metadataStore scrape.MetricMetadataStore
+	lookups       map[string]*metadata.Metadata
+}
+
+// sample is a buffered sample which is converted into a FlowMetric on
+// Commit.
+type sample struct {
+	ref       storage.SeriesRef
+	labels    labels.Labels
+	value     float64
+	exemplars []exemplar.Exemplar
+	metadata  *metadata.Metadata
+}
+
+func (s *sample) flowMetric() *prometheus.FlowMetric {
+	var opts []prometheus.FlowMetricOption
+	if len(s.exemplars) > 0 {
+		opts = append(opts, prometheus.WithExemplars(s.exemplars...))
+	}
+	if s.metadata != nil {
+		opts = append(opts, prometheus.WithMetadata(*s.metadata))
+	}
+	return prometheus.NewFlowMetric(uint64(s.ref), s.labels, s.value, opts...)
 }
 
 // Appender implements the Prometheus Appendable interface.
-func (app *FlowAppendable) Appender(_ context.Context) storage.Appender {
+func (app *FlowAppendable) Appender(ctx context.Context) storage.Appender {
 	app.mut.RLock()
 	defer app.mut.RUnlock()
 
+	metadataStore, _ := scrape.MetricMetadataStoreFromContext(ctx)
+
 	return &flowAppender{
-		buffer:    make(map[int64][]*prometheus.FlowMetric),
-		receivers: app.receivers,
+		parent:        app,
+		buffer:        make(map[int64][]*sample),
+		latest:        make(map[storage.SeriesRef]*sample),
+		receivers:     app.receivers,
+		metadataStore: metadataStore,
+		lookups:       make(map[string]*metadata.Metadata),
 	}
 }
 
-// SetReceivers defines the list of receivers for this appendable.
+// SetReceivers defines the list of receivers for this appendable. Metadata
+// is forwarded again for every series, since the new receivers haven't seen
+// it yet.
 func (app *FlowAppendable) SetReceivers(receivers []*prometheus.Receiver) {
 	app.mut.Lock()
 	app.receivers = receivers
 	app.mut.Unlock()
+
+	app.forwardedMut.Lock()
+	app.forwarded = make(map[storage.SeriesRef]metadata.Metadata)
+	app.forwardedMut.Unlock()
 }
 
 // ListReceivers is a test method for exposing the Appender's receivers.
@@ -65,11 +142,6 @@ func (app *flowAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64,
 	if len(app.receivers) == 0 {
 		return 0, nil
 	}
-	_, found := app.buffer[t]
-	if !found {
-		set := make([]*prometheus.FlowMetric, 0)
-		app.buffer[t] = set
-	}
 	// If ref is 0 then lets grab a global id
 	if ref == 0 {
 		ref = storage.SeriesRef(prometheus.GlobalRefMapping.GetOrAddGlobalRefID(l))
@@ -80,32 +152,130 @@ func (app *flowAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64,
 	} else {
 		prometheus.GlobalRefMapping.RemoveStaleMarker(uint64(ref))
 	}
-	app.buffer[t] = append(app.buffer[t], prometheus.NewFlowMetric(uint64(ref), l, v))
+
+	s := &sample{ref: ref, labels: l, value: v}
+	if md, found := app.lookupMetadata(l); found && app.parent.metadataChanged(ref, md) {
+		s.metadata = &md
+	}
+	app.buffer[t] = append(app.buffer[t], s)
+	app.latest[ref] = s
 	return ref, nil
 }
 
+// lookupMetadata finds the metadata for the series from the scraped target,
+// if any.
+func (app *flowAppender) lookupMetadata(l labels.Labels) (metadata.Metadata, bool) {
+	if app.metadataStore == nil {
+		return metadata.Metadata{}, false
+	}
+
+	name := l.Get(labels.MetricName)
+	md, cached := app.lookups[name]
+	if !cached {
+		if found, ok := app.findMetadata(name); ok {
+			md = &found
+		}
+		app.lookups[name] = md
+	}
+	if md == nil {
+		return metadata.Metadata{}, false
+	}
+	return *md, true
+}
+
+// findMetadata finds the metadata for the metric name in the metadata store
+// of the scraped target.
+func (app *flowAppender) findMetadata(name string) (metadata.Metadata, bool) {
+	if md, found := app.metadataStore.GetMetadata(name); found {
+		return toMetadata(md), true
+	}
+
+	// Series of histograms, summaries and counters are exposed with suffixes
+	// which aren't part of the metric family name the metadata is stored for.
+	for _, suffix := range []string{"_bucket", "_count", "_sum", "_total", "_created"} {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		md, found := app.metadataStore.GetMetadata(strings.TrimSuffix(name, suffix))
+		if found && md.Type != textparse.MetricTypeGauge && md.Type != textparse.MetricTypeUnknown {
+			return toMetadata(md), true
+		}
+	}
+	return metadata.Metadata{}, false
+}
+
+func toMetadata(md scrape.MetricMetadata) metadata.Metadata {
+	return metadata.Metadata{Type: md.Type, Help: md.Help, Unit: md.Unit}
+}
+
 func (app *flowAppender) AppendExemplar(ref storage.SeriesRef, l labels.Labels, e exemplar.Exemplar) (storage.SeriesRef, error) {
-	return 0, nil
+	if len(app.receivers) == 0 {
+		return 0, nil
+	}
+	// Exemplars are sent alongside the most recent sample of their series.
+	s, found := app.latest[ref]
+	if !found {
+		return 0, fmt.Errorf("no sample appended for series %s", l)
+	}
+	s.exemplars = append(s.exemplars, e)
+	return ref, nil
 }
 
 func (app *flowAppender) UpdateMetadata(ref storage.SeriesRef, l labels.Labels, m metadata.Metadata) (storage.SeriesRef, error) {
-	return 0, nil
+	if len(app.receivers) == 0 {
+		return 0, nil
+	}
+	s, found := app.latest[ref]
+	if !found {
+		return 0, fmt.Errorf("no sample appended for series %s", l)
+	}
+	if app.parent.metadataChanged(ref, m) {
+		s.metadata = &m
+	}
+	return ref, nil
 }
 
 func (app *flowAppender) Commit() error {
+	batches := make(map[int64][]*prometheus.FlowMetric, len(app.buffer))
+	for ts, samples := range app.buffer {
+		metrics := make([]*prometheus.FlowMetric, 0, len(samples))
+		for _, s := range samples {
+			metrics = append(metrics, s.flowMetric())
+		}
+		batches[ts] = metrics
+	}
+
 	for _, r := range app.receivers {
-		for ts, metrics := range app.buffer {
+		for ts, metrics := range batches {
 			if r == nil || r.Receive == nil {
 				continue
 			}
 			r.Receive(ts, metrics)
 		}
 	}
-	app.buffer = make(map[int64][]*prometheus.FlowMetric)
+
+	for _, samples := range app.buffer {
+		for _, s := range samples {
+			switch {
+			case value.IsStaleNaN(s.value):
+				// Receivers may drop stale series, so metadata must be forwarded
+				// again if the series comes back.
+				app.parent.forgetForwarded(s.ref)
+			case s.metadata != nil:
+				app.parent.setForwarded(s.ref, *s.metadata)
+			}
+		}
+	}
+	app.reset()
 	return nil
 }
 
 func (app *flowAppender) Rollback() error {
-	app.buffer = make(map[int64][]*prometheus.FlowMetric)
+	app.reset()
 	return nil
 }
+
+func (app *flowAppender) reset() {
+	app.buffer = make(map[int64][]*sample)
+	app.latest = make(map[storage.SeriesRef]*sample)
+}
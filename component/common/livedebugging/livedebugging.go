@@ -0,0 +1,65 @@
+// Package livedebugging provides utilities for components which implement
+// component.LiveDebuggingComponent.
+package livedebugging
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// Publisher publishes debugging data to a set of subscribers. The zero value
+// is ready for use.
+type Publisher struct {
+	active atomic.Int32
+
+	mut         sync.RWMutex
+	nextID      uint64
+	subscribers map[uint64]func(string)
+}
+
+// Subscribe registers fn to receive published data until the returned
+// function is called. Subscribe can be used to implement
+// component.LiveDebuggingComponent.
+func (p *Publisher) Subscribe(fn func(data string)) (unregister func()) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if p.subscribers == nil {
+		p.subscribers = make(map[uint64]func(string))
+	}
+	id := p.nextID
+	p.nextID++
+	p.subscribers[id] = fn
+	p.active.Store(int32(len(p.subscribers)))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mut.Lock()
+			defer p.mut.Unlock()
+			delete(p.subscribers, id)
+			p.active.Store(int32(len(p.subscribers)))
+		})
+	}
+}
+
+// Active returns true if there is at least one subscriber. Components should
+// check Active before building data to publish so that live debugging has no
+// cost when it isn't used.
+func (p *Publisher) Active() bool {
+	return p.active.Load() > 0
+}
+
+// Publish sends data to all subscribers.
+func (p *Publisher) Publish(data string) {
+	if !p.Active() {
+		return
+	}
+
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	for _, fn := range p.subscribers {
+		fn(data)
+	}
+}
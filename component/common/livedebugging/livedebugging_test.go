@@ -0,0 +1,31 @@
+package livedebugging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher(t *testing.T) {
+	var p Publisher
+	require.False(t, p.Active())
+
+	// Publishing without subscribers is a no-op.
+	p.Publish("ignored")
+
+	var a, b []string
+	unregisterA := p.Subscribe(func(data string) { a = append(a, data) })
+	unregisterB := p.Subscribe(func(data string) { b = append(b, data) })
+	require.True(t, p.Active())
+
+	p.Publish("first")
+	unregisterA()
+	unregisterA() // Unregistering twice must be safe.
+	p.Publish("second")
+
+	require.Equal(t, []string{"first"}, a)
+	require.Equal(t, []string{"first", "second"}, b)
+
+	unregisterB()
+	require.False(t, p.Active())
+}
@@ -30,6 +30,9 @@
 //   - health
 //   - debug
 //
+// for_each and enabled are meta-arguments which are evaluated by the Flow
+// controller and are never passed to components.
+//
 // Default values for Arguments may be provided by implementing
 // river.Unmarshaler.
 //
@@ -125,3 +128,18 @@ type HTTPComponent interface {
 	// will receive a request to just `/metrics`.
 	Handler() http.Handler
 }
+
+// LiveDebuggingComponent is an extension interface for components which can
+// stream the data passing through them, such as samples or targets, for
+// debugging.
+type LiveDebuggingComponent interface {
+	Component
+
+	// LiveDebugging registers fn to be invoked with a human-readable
+	// description of each piece of data which passes through the component.
+	// Calling the returned function unregisters fn.
+	//
+	// fn may be invoked concurrently and must not block. LiveDebugging must be
+	// safe for calling concurrently.
+	LiveDebugging(fn func(data string)) (unregister func())
+}
@@ -0,0 +1,107 @@
+// Package consul implements the discovery.consul component.
+package consul
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/config"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	promconfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	promconsul "github.com/prometheus/prometheus/discovery/consul"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.consul",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return discovery.New(opts, args, newConsul)
+		},
+	})
+}
+
+var newConsul discovery.Creator = func(args component.Arguments, opts component.Options) (discovery.Discoverer, error) {
+	newArgs := args.(Arguments)
+	return promconsul.NewDiscovery(newArgs.Convert(), opts.Logger)
+}
+
+// Arguments configures the discovery.consul component.
+type Arguments struct {
+	Server           string                  `river:"server,attr,optional"`
+	Token            rivertypes.Secret       `river:"token,attr,optional"`
+	Datacenter       string                  `river:"datacenter,attr,optional"`
+	Namespace        string                  `river:"namespace,attr,optional"`
+	TagSeparator     string                  `river:"tag_separator,attr,optional"`
+	Scheme           string                  `river:"scheme,attr,optional"`
+	Username         string                  `river:"username,attr,optional"`
+	Password         rivertypes.Secret       `river:"password,attr,optional"`
+	AllowStale       bool                    `river:"allow_stale,attr,optional"`
+	Services         []string                `river:"services,attr,optional"`
+	ServiceTags      []string                `river:"tags,attr,optional"`
+	NodeMeta         map[string]string       `river:"node_meta,attr,optional"`
+	RefreshInterval  time.Duration           `river:"refresh_interval,attr,optional"`
+	HTTPClientConfig config.HTTPClientConfig `river:"http_client_config,block,optional"`
+}
+
+// DefaultArguments holds defaults for Arguments. (copied from prometheus)
+var DefaultArguments = Arguments{
+	Server:           "localhost:8500",
+	TagSeparator:     ",",
+	Scheme:           "http",
+	AllowStale:       true,
+	RefreshInterval:  30 * time.Second,
+	HTTPClientConfig: config.DefaultHTTPClientConfig,
+}
+
+// UnmarshalRiver applies defaults and validates the unmarshaled arguments.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(args.Server) == "" {
+		return fmt.Errorf("server must not be empty")
+	}
+	if (args.Username != "" || args.Password != "") && args.HTTPClientConfig.BasicAuth != nil {
+		return fmt.Errorf("at most one of username/password and http_client_config basic_auth can be configured")
+	}
+	if args.Token != "" && (args.HTTPClientConfig.Authorization != nil || args.HTTPClientConfig.OAuth2 != nil) {
+		return fmt.Errorf("at most one of token, http_client_config authorization, or http_client_config oauth2 can be configured")
+	}
+	return nil
+}
+
+// Convert to prometheus config type
+func (args *Arguments) Convert() *promconsul.SDConfig {
+	httpClient := args.HTTPClientConfig.Convert()
+	if args.Username != "" || args.Password != "" {
+		httpClient.BasicAuth = &promconfig.BasicAuth{
+			Username: args.Username,
+			Password: promconfig.Secret(args.Password),
+		}
+	}
+
+	return &promconsul.SDConfig{
+		Server:           args.Server,
+		Token:            promconfig.Secret(args.Token),
+		Datacenter:       args.Datacenter,
+		Namespace:        args.Namespace,
+		TagSeparator:     args.TagSeparator,
+		Scheme:           args.Scheme,
+		AllowStale:       args.AllowStale,
+		Services:         args.Services,
+		ServiceTags:      args.ServiceTags,
+		NodeMeta:         args.NodeMeta,
+		RefreshInterval:  model.Duration(args.RefreshInterval),
+		HTTPClientConfig: *httpClient,
+	}
+}
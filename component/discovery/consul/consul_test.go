@@ -0,0 +1,107 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		server   = "consul.example.com:8500"
+		services = ["api", "web"]
+		tags     = ["prod"]
+		username = "user"
+		password = "pass"
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(exampleRiverConfig), &args))
+
+	promArgs := args.Convert()
+	require.Equal(t, "consul.example.com:8500", promArgs.Server)
+	require.Equal(t, []string{"api", "web"}, promArgs.Services)
+	require.Equal(t, []string{"prod"}, promArgs.ServiceTags)
+	require.Equal(t, ",", promArgs.TagSeparator)
+	require.True(t, promArgs.AllowStale)
+	require.Equal(t, "user", promArgs.HTTPClientConfig.BasicAuth.Username)
+	require.Equal(t, "pass", string(promArgs.HTTPClientConfig.BasicAuth.Password))
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		username = "user"
+
+		http_client_config {
+			basic_auth {
+				username = "other"
+			}
+		}
+	`
+	var args Arguments
+	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
+	require.EqualError(t, err, "at most one of username/password and http_client_config basic_auth can be configured")
+}
+
+func TestDiscovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Fake Consul server which knows about a single instance of the "test"
+	// service.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Consul-Index", "1")
+		switch r.URL.Path {
+		case "/v1/agent/self":
+			fmt.Fprint(w, `{"Config": {"Datacenter": "test-dc"}}`)
+		case "/v1/catalog/services":
+			fmt.Fprint(w, `{"test": ["tag1"]}`)
+		case "/v1/health/service/test":
+			fmt.Fprint(w, `[{
+				"Node": {"Node": "node1", "Address": "1.1.1.1", "Datacenter": "test-dc"},
+				"Service": {"ID": "test", "Service": "test", "Tags": ["tag1"], "Port": 3341},
+				"Checks": [{"CheckID": "serfHealth", "Status": "passing"}]
+			}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	args := DefaultArguments
+	args.Server = srvURL.Host
+	args.Services = []string{"test"}
+
+	d, err := newConsul(args, component.Options{Logger: util.TestLogger(t)})
+	require.NoError(t, err)
+
+	ch := make(chan []*targetgroup.Group)
+	go d.Run(ctx, ch)
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for targets")
+	case groups := <-ch:
+		require.Len(t, groups, 1)
+		require.Equal(t, "test", groups[0].Source)
+		require.Len(t, groups[0].Targets, 1)
+		target := groups[0].Targets[0]
+		require.Equal(t, model.LabelValue("1.1.1.1:3341"), target[model.AddressLabel])
+		require.Equal(t, model.LabelValue("test"), groups[0].Labels["__meta_consul_service"])
+		require.Equal(t, model.LabelValue("test-dc"), groups[0].Labels["__meta_consul_dc"])
+		require.Equal(t, model.LabelValue(",tag1,"), target["__meta_consul_tags"])
+	}
+}
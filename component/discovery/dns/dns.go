@@ -0,0 +1,77 @@
+// Package dns implements the discovery.dns component.
+package dns
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/prometheus/common/model"
+	promdns "github.com/prometheus/prometheus/discovery/dns"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.dns",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return discovery.New(opts, args, newDNS)
+		},
+	})
+}
+
+var newDNS discovery.Creator = func(args component.Arguments, opts component.Options) (discovery.Discoverer, error) {
+	newArgs := args.(Arguments)
+	return promdns.NewDiscovery(*newArgs.Convert(), opts.Logger), nil
+}
+
+// Arguments configures the discovery.dns component.
+type Arguments struct {
+	Names           []string      `river:"names,attr"`
+	RefreshInterval time.Duration `river:"refresh_interval,attr,optional"`
+	Type            string        `river:"type,attr,optional"`
+	Port            int           `river:"port,attr,optional"`
+}
+
+// DefaultArguments holds defaults for Arguments. (copied from prometheus)
+var DefaultArguments = Arguments{
+	RefreshInterval: 30 * time.Second,
+	Type:            "SRV",
+}
+
+// UnmarshalRiver applies defaults and validates the unmarshaled arguments.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if len(args.Names) == 0 {
+		return fmt.Errorf("at least one DNS name must be provided in names")
+	}
+	switch strings.ToUpper(args.Type) {
+	case "SRV":
+	case "A", "AAAA":
+		if args.Port == 0 {
+			return fmt.Errorf("a port is required for %s records", strings.ToUpper(args.Type))
+		}
+	default:
+		return fmt.Errorf("invalid DNS record type %q", args.Type)
+	}
+	return nil
+}
+
+// Convert to prometheus config type
+func (args *Arguments) Convert() *promdns.SDConfig {
+	return &promdns.SDConfig{
+		Names:           args.Names,
+		RefreshInterval: model.Duration(args.RefreshInterval),
+		Type:            args.Type,
+		Port:            args.Port,
+	}
+}
@@ -0,0 +1,57 @@
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		names = ["example.com", "example2.com"]
+		type  = "A"
+		port  = 8080
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(exampleRiverConfig), &args))
+
+	promArgs := args.Convert()
+	require.Equal(t, []string{"example.com", "example2.com"}, promArgs.Names)
+	require.Equal(t, "A", promArgs.Type)
+	require.Equal(t, 8080, promArgs.Port)
+	require.Equal(t, model.Duration(30*time.Second), promArgs.RefreshInterval)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "no names",
+			config: `names = []`,
+			err:    "at least one DNS name must be provided in names",
+		},
+		{
+			name:   "A record without port",
+			config: "names = [\"example.com\"]\ntype = \"A\"",
+			err:    "a port is required for A records",
+		},
+		{
+			name:   "unknown record type",
+			config: "names = [\"example.com\"]\ntype = \"MX\"",
+			err:    `invalid DNS record type "MX"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args Arguments
+			require.EqualError(t, river.Unmarshal([]byte(tc.config), &args), tc.err)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package docker implements the discovery.docker component.
+package docker
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/config"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/moby"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.docker",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return discovery.New(opts, args, newDocker)
+		},
+	})
+}
+
+var newDocker discovery.Creator = func(args component.Arguments, opts component.Options) (discovery.Discoverer, error) {
+	newArgs := args.(Arguments)
+	return moby.NewDockerDiscovery(newArgs.Convert(), opts.Logger)
+}
+
+// Arguments configures the discovery.docker component.
+type Arguments struct {
+	Host               string                  `river:"host,attr"`
+	Port               int                     `river:"port,attr,optional"`
+	HostNetworkingHost string                  `river:"host_networking_host,attr,optional"`
+	RefreshInterval    time.Duration           `river:"refresh_interval,attr,optional"`
+	Filters            []Filter                `river:"filter,block,optional"`
+	HTTPClientConfig   config.HTTPClientConfig `river:"http_client_config,block,optional"`
+}
+
+// Filter is used to limit the discovery process to a subset of available
+// resources.
+type Filter struct {
+	Name   string   `river:"name,attr"`
+	Values []string `river:"values,attr"`
+}
+
+// DefaultArguments holds defaults for Arguments. (copied from prometheus)
+var DefaultArguments = Arguments{
+	Port:               80,
+	HostNetworkingHost: "localhost",
+	RefreshInterval:    time.Minute,
+	HTTPClientConfig:   config.DefaultHTTPClientConfig,
+}
+
+// UnmarshalRiver applies defaults and validates the unmarshaled arguments.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Host == "" {
+		return fmt.Errorf("host must not be empty")
+	}
+	if _, err := url.Parse(args.Host); err != nil {
+		return fmt.Errorf("parsing host: %w", err)
+	}
+	if args.RefreshInterval <= 0 {
+		return fmt.Errorf("refresh_interval must be greater than 0")
+	}
+	return nil
+}
+
+// Convert to prometheus config type
+func (args *Arguments) Convert() *moby.DockerSDConfig {
+	filters := make([]moby.Filter, len(args.Filters))
+	for i, f := range args.Filters {
+		filters[i] = moby.Filter{Name: f.Name, Values: f.Values}
+	}
+
+	return &moby.DockerSDConfig{
+		HTTPClientConfig:   *args.HTTPClientConfig.Convert(),
+		Host:               args.Host,
+		Port:               args.Port,
+		Filters:            filters,
+		HostNetworkingHost: args.HostNetworkingHost,
+		RefreshInterval:    model.Duration(args.RefreshInterval),
+	}
+}
@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		host = "unix:///var/run/docker.sock"
+		port = 8080
+
+		filter {
+			name   = "label"
+			values = ["scrape=true"]
+		}
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(exampleRiverConfig), &args))
+
+	promArgs := args.Convert()
+	require.Equal(t, "unix:///var/run/docker.sock", promArgs.Host)
+	require.Equal(t, 8080, promArgs.Port)
+	require.Equal(t, "localhost", promArgs.HostNetworkingHost)
+	require.Equal(t, model.Duration(time.Minute), promArgs.RefreshInterval)
+	require.Len(t, promArgs.Filters, 1)
+	require.Equal(t, "label", promArgs.Filters[0].Name)
+	require.Equal(t, []string{"scrape=true"}, promArgs.Filters[0].Values)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`host = ""`), &args)
+	require.EqualError(t, err, "host must not be empty")
+}
+
+func TestDiscovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	socketPath := filepath.Join(t.TempDir(), "docker.sock")
+	lis, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	// Stub Docker daemon which reports a single container in a bridge network.
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", "1.41")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			fmt.Fprint(w, "OK")
+		case strings.HasSuffix(r.URL.Path, "/containers/json"):
+			fmt.Fprint(w, `[{
+				"Id": "c1",
+				"Names": ["/app"],
+				"Labels": {"com.example.team": "infra"},
+				"Ports": [{"PrivatePort": 8080, "Type": "tcp"}],
+				"HostConfig": {"NetworkMode": "bridge"},
+				"NetworkSettings": {"Networks": {"bridge": {"NetworkID": "n1", "IPAddress": "172.17.0.2"}}}
+			}]`)
+		case strings.HasSuffix(r.URL.Path, "/networks"):
+			fmt.Fprint(w, `[{"Id": "n1", "Name": "bridge", "Scope": "local"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	})}
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Close()
+
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(fmt.Sprintf(`host = "unix://%s"`, socketPath)), &args))
+
+	d, err := newDocker(args, component.Options{Logger: util.TestLogger(t)})
+	require.NoError(t, err)
+
+	ch := make(chan []*targetgroup.Group)
+	go d.Run(ctx, ch)
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for targets")
+	case groups := <-ch:
+		require.Len(t, groups, 1)
+		require.Len(t, groups[0].Targets, 1)
+		target := groups[0].Targets[0]
+		require.Equal(t, model.LabelValue("172.17.0.2:8080"), target[model.AddressLabel])
+		require.Equal(t, model.LabelValue("/app"), target["__meta_docker_container_name"])
+		require.Equal(t, model.LabelValue("infra"), target["__meta_docker_container_label_com_example_team"])
+		require.Equal(t, model.LabelValue("bridge"), target["__meta_docker_network_name"])
+	}
+}
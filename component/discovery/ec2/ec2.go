@@ -0,0 +1,109 @@
+// Package ec2 implements the discovery.ec2 component.
+package ec2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	promconfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	promaws "github.com/prometheus/prometheus/discovery/aws"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.ec2",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return discovery.New(opts, args, newEC2)
+		},
+	})
+}
+
+var newEC2 discovery.Creator = func(args component.Arguments, opts component.Options) (discovery.Discoverer, error) {
+	newArgs := args.(Arguments)
+	return promaws.NewEC2Discovery(newArgs.Convert(), opts.Logger), nil
+}
+
+// Arguments configures the discovery.ec2 component.
+type Arguments struct {
+	Endpoint        string            `river:"endpoint,attr,optional"`
+	Region          string            `river:"region,attr,optional"`
+	AccessKey       string            `river:"access_key,attr,optional"`
+	SecretKey       rivertypes.Secret `river:"secret_key,attr,optional"`
+	Profile         string            `river:"profile,attr,optional"`
+	RoleARN         string            `river:"role_arn,attr,optional"`
+	RefreshInterval time.Duration     `river:"refresh_interval,attr,optional"`
+	Port            int               `river:"port,attr,optional"`
+	Filters         []Filter          `river:"filter,block,optional"`
+}
+
+// Filter is used to limit the discovery process to a subset of available
+// instances. See the AWS documentation for DescribeInstances for the list of
+// supported filters.
+type Filter struct {
+	Name   string   `river:"name,attr"`
+	Values []string `river:"values,attr"`
+}
+
+// DefaultArguments holds defaults for Arguments. (copied from prometheus)
+var DefaultArguments = Arguments{
+	Port:            80,
+	RefreshInterval: 60 * time.Second,
+}
+
+// UnmarshalRiver applies defaults and validates the unmarshaled arguments.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	// Like Prometheus, fall back to the region of the instance the agent is
+	// running on when no region is provided.
+	if args.Region == "" {
+		sess, err := session.NewSession()
+		if err != nil {
+			return err
+		}
+		region, err := ec2metadata.New(sess).Region()
+		if err != nil {
+			return fmt.Errorf("region must be provided when not running on EC2")
+		}
+		args.Region = region
+	}
+	for _, f := range args.Filters {
+		if len(f.Values) == 0 {
+			return fmt.Errorf("filter %q must have at least one value", f.Name)
+		}
+	}
+	return nil
+}
+
+// Convert to prometheus config type
+func (args *Arguments) Convert() *promaws.EC2SDConfig {
+	filters := make([]*promaws.EC2Filter, len(args.Filters))
+	for i, f := range args.Filters {
+		filters[i] = &promaws.EC2Filter{Name: f.Name, Values: f.Values}
+	}
+
+	return &promaws.EC2SDConfig{
+		Endpoint:        args.Endpoint,
+		Region:          args.Region,
+		AccessKey:       args.AccessKey,
+		SecretKey:       promconfig.Secret(args.SecretKey),
+		Profile:         args.Profile,
+		RoleARN:         args.RoleARN,
+		RefreshInterval: model.Duration(args.RefreshInterval),
+		Port:            args.Port,
+		Filters:         filters,
+	}
+}
@@ -0,0 +1,49 @@
+package ec2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		region     = "us-east-1"
+		access_key = "AKIAEXAMPLE"
+		secret_key = "secret"
+
+		filter {
+			name   = "tag:Environment"
+			values = ["prod"]
+		}
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(exampleRiverConfig), &args))
+
+	promArgs := args.Convert()
+	require.Equal(t, "us-east-1", promArgs.Region)
+	require.Equal(t, "AKIAEXAMPLE", promArgs.AccessKey)
+	require.Equal(t, "secret", string(promArgs.SecretKey))
+	require.Equal(t, 80, promArgs.Port)
+	require.Equal(t, model.Duration(time.Minute), promArgs.RefreshInterval)
+	require.Len(t, promArgs.Filters, 1)
+	require.Equal(t, "tag:Environment", promArgs.Filters[0].Name)
+	require.Equal(t, []string{"prod"}, promArgs.Filters[0].Values)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		region = "us-east-1"
+
+		filter {
+			name   = "tag:Environment"
+			values = []
+		}
+	`
+	var args Arguments
+	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
+	require.EqualError(t, err, `filter "tag:Environment" must have at least one value`)
+}
@@ -0,0 +1,72 @@
+// Package file implements the discovery.file component.
+package file
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/prometheus/common/model"
+	promfile "github.com/prometheus/prometheus/discovery/file"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.file",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return discovery.New(opts, args, newFile)
+		},
+	})
+}
+
+var newFile discovery.Creator = func(args component.Arguments, opts component.Options) (discovery.Discoverer, error) {
+	newArgs := args.(Arguments)
+	return promfile.NewDiscovery(newArgs.Convert(), opts.Logger), nil
+}
+
+// patFileSDName matches the paths allowed for file discovery. It is copied
+// from Prometheus.
+var patFileSDName = regexp.MustCompile(`^[^*]*(\*[^/]*)?\.(json|yml|yaml|JSON|YML|YAML)$`)
+
+// Arguments configures the discovery.file component.
+type Arguments struct {
+	Files           []string      `river:"files,attr"`
+	RefreshInterval time.Duration `river:"refresh_interval,attr,optional"`
+}
+
+// DefaultArguments holds defaults for Arguments. (copied from prometheus)
+var DefaultArguments = Arguments{
+	RefreshInterval: 5 * time.Minute,
+}
+
+// UnmarshalRiver applies defaults and validates the unmarshaled arguments.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if len(args.Files) == 0 {
+		return fmt.Errorf("at least one path must be provided in files")
+	}
+	for _, name := range args.Files {
+		if !patFileSDName.MatchString(name) {
+			return fmt.Errorf("path name %q is not valid for file discovery", name)
+		}
+	}
+	return nil
+}
+
+// Convert to prometheus config type
+func (args *Arguments) Convert() *promfile.SDConfig {
+	return &promfile.SDConfig{
+		Files:           args.Files,
+		RefreshInterval: model.Duration(args.RefreshInterval),
+	}
+}
@@ -0,0 +1,69 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		files            = ["/etc/prometheus/targets/*.json"]
+		refresh_interval = "1m"
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(exampleRiverConfig), &args))
+	require.Equal(t, time.Minute, args.RefreshInterval)
+
+	var defaultArgs Arguments
+	require.NoError(t, river.Unmarshal([]byte(`files = ["targets.yml"]`), &defaultArgs))
+	require.Equal(t, 5*time.Minute, defaultArgs.RefreshInterval)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var args Arguments
+	require.EqualError(t, river.Unmarshal([]byte(`files = []`), &args), "at least one path must be provided in files")
+	require.EqualError(t, river.Unmarshal([]byte(`files = ["targets.txt"]`), &args), `path name "targets.txt" is not valid for file discovery`)
+}
+
+func TestDiscovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "targets.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{
+		"targets": ["localhost:9090", "localhost:9091"],
+		"labels": {"job": "prometheus"}
+	}]`), 0644))
+
+	args := DefaultArguments
+	args.Files = []string{path}
+
+	d, err := newFile(args, component.Options{Logger: util.TestLogger(t)})
+	require.NoError(t, err)
+
+	ch := make(chan []*targetgroup.Group)
+	go d.Run(ctx, ch)
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for targets")
+	case groups := <-ch:
+		require.Len(t, groups, 1)
+		require.Equal(t, []model.LabelSet{
+			{model.AddressLabel: "localhost:9090"},
+			{model.AddressLabel: "localhost:9091"},
+		}, groups[0].Targets)
+		require.Equal(t, model.LabelValue("prometheus"), groups[0].Labels["job"])
+		require.Equal(t, model.LabelValue(path), groups[0].Labels["__meta_filepath"])
+	}
+}
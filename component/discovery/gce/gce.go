@@ -0,0 +1,75 @@
+// Package gce implements the discovery.gce component.
+package gce
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/prometheus/common/model"
+	promgce "github.com/prometheus/prometheus/discovery/gce"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.gce",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return discovery.New(opts, args, newGCE)
+		},
+	})
+}
+
+var newGCE discovery.Creator = func(args component.Arguments, opts component.Options) (discovery.Discoverer, error) {
+	newArgs := args.(Arguments)
+	return promgce.NewDiscovery(*newArgs.Convert(), opts.Logger)
+}
+
+// Arguments configures the discovery.gce component.
+type Arguments struct {
+	Project         string        `river:"project,attr"`
+	Zone            string        `river:"zone,attr"`
+	Filter          string        `river:"filter,attr,optional"`
+	RefreshInterval time.Duration `river:"refresh_interval,attr,optional"`
+	Port            int           `river:"port,attr,optional"`
+	TagSeparator    string        `river:"tag_separator,attr,optional"`
+}
+
+// DefaultArguments holds defaults for Arguments. (copied from prometheus)
+var DefaultArguments = Arguments{
+	Port:            80,
+	TagSeparator:    ",",
+	RefreshInterval: 60 * time.Second,
+}
+
+// UnmarshalRiver applies defaults and validates the unmarshaled arguments.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Project == "" {
+		return fmt.Errorf("project must not be empty")
+	}
+	if args.Zone == "" {
+		return fmt.Errorf("zone must not be empty")
+	}
+	return nil
+}
+
+// Convert to prometheus config type
+func (args *Arguments) Convert() *promgce.SDConfig {
+	return &promgce.SDConfig{
+		Project:         args.Project,
+		Zone:            args.Zone,
+		Filter:          args.Filter,
+		RefreshInterval: model.Duration(args.RefreshInterval),
+		Port:            args.Port,
+		TagSeparator:    args.TagSeparator,
+	}
+}
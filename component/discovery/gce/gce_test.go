@@ -0,0 +1,35 @@
+package gce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		project = "my-project"
+		zone    = "us-central1-a"
+		filter  = "labels.env = prod"
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(exampleRiverConfig), &args))
+
+	promArgs := args.Convert()
+	require.Equal(t, "my-project", promArgs.Project)
+	require.Equal(t, "us-central1-a", promArgs.Zone)
+	require.Equal(t, "labels.env = prod", promArgs.Filter)
+	require.Equal(t, 80, promArgs.Port)
+	require.Equal(t, ",", promArgs.TagSeparator)
+	require.Equal(t, model.Duration(time.Minute), promArgs.RefreshInterval)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`project = "my-project"
+zone = ""`), &args)
+	require.EqualError(t, err, "zone must not be empty")
+}
@@ -0,0 +1,69 @@
+// Package http implements the discovery.http component.
+package http
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/config"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/prometheus/common/model"
+	promhttp "github.com/prometheus/prometheus/discovery/http"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.http",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return discovery.New(opts, args, newHTTP)
+		},
+	})
+}
+
+var newHTTP discovery.Creator = func(args component.Arguments, opts component.Options) (discovery.Discoverer, error) {
+	newArgs := args.(Arguments)
+	return promhttp.NewDiscovery(newArgs.Convert(), opts.Logger, nil)
+}
+
+// Arguments configures the discovery.http component.
+type Arguments struct {
+	URL              config.URL              `river:"url,attr"`
+	RefreshInterval  time.Duration           `river:"refresh_interval,attr,optional"`
+	HTTPClientConfig config.HTTPClientConfig `river:"http_client_config,block,optional"`
+}
+
+// DefaultArguments holds defaults for Arguments. (copied from prometheus)
+var DefaultArguments = Arguments{
+	RefreshInterval:  60 * time.Second,
+	HTTPClientConfig: config.DefaultHTTPClientConfig,
+}
+
+// UnmarshalRiver applies defaults and validates the unmarshaled arguments.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.URL.URL == nil || args.URL.Host == "" {
+		return fmt.Errorf("host is missing in url")
+	}
+	if args.URL.Scheme != "http" && args.URL.Scheme != "https" {
+		return fmt.Errorf("url scheme must be http or https")
+	}
+	return nil
+}
+
+// Convert to prometheus config type
+func (args *Arguments) Convert() *promhttp.SDConfig {
+	return &promhttp.SDConfig{
+		URL:              args.URL.String(),
+		RefreshInterval:  model.Duration(args.RefreshInterval),
+		HTTPClientConfig: *args.HTTPClientConfig.Convert(),
+	}
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+		url              = "https://www.example.com:12345/foo"
+		refresh_interval = "14s"
+
+		http_client_config {
+			basic_auth {
+				username = "123"
+				password = "456"
+			}
+		}
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(exampleRiverConfig), &args))
+	require.Equal(t, 14*time.Second, args.RefreshInterval)
+
+	promArgs := args.Convert()
+	require.Equal(t, "https://www.example.com:12345/foo", promArgs.URL)
+	require.Equal(t, model.Duration(14*time.Second), promArgs.RefreshInterval)
+	require.Equal(t, "123", promArgs.HTTPClientConfig.BasicAuth.Username)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var args Arguments
+	require.EqualError(t, river.Unmarshal([]byte(`url = "ftp://example.com"`), &args), "url scheme must be http or https")
+	require.EqualError(t, river.Unmarshal([]byte(`url = "http://"`), &args), "host is missing in url")
+}
+
+func TestDiscovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"labels": {"job": "node"}, "targets": ["127.0.0.1:9100"]}]`)
+	}))
+	defer srv.Close()
+
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(fmt.Sprintf(`url = "%s/sd"`, srv.URL)), &args))
+
+	d, err := newHTTP(args, component.Options{Logger: util.TestLogger(t)})
+	require.NoError(t, err)
+
+	ch := make(chan []*targetgroup.Group)
+	go d.Run(ctx, ch)
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for targets")
+	case groups := <-ch:
+		require.Len(t, groups, 1)
+		require.Equal(t, []model.LabelSet{{model.AddressLabel: "127.0.0.1:9100"}}, groups[0].Targets)
+		require.Equal(t, model.LabelValue("node"), groups[0].Labels["job"])
+		require.Equal(t, model.LabelValue(srv.URL+"/sd"), groups[0].Labels["__meta_url"])
+	}
+}
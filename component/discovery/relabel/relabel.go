@@ -2,8 +2,10 @@ package relabel
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/livedebugging"
 	flow_relabel "github.com/grafana/agent/component/common/relabel"
 	"github.com/grafana/agent/component/discovery"
 	"github.com/prometheus/prometheus/model/labels"
@@ -38,10 +40,14 @@ type Exports struct {
 
 // Component implements the discovery.relabel component.
 type Component struct {
-	opts component.Options
+	opts  component.Options
+	debug livedebugging.Publisher
 }
 
-var _ component.Component = (*Component)(nil)
+var (
+	_ component.Component              = (*Component)(nil)
+	_ component.LiveDebuggingComponent = (*Component)(nil)
+)
 
 // New creates a new discovery.relabel component.
 func New(o component.Options, args Arguments) (*Component, error) {
@@ -69,8 +75,11 @@ func (c *Component) Update(args component.Arguments) error {
 	relabelConfigs := flow_relabel.ComponentToPromRelabelConfigs(newArgs.RelabelConfigs)
 
 	for _, t := range newArgs.Targets {
-		lset := componentMapToPromLabels(t)
-		lset = relabel.Process(lset, relabelConfigs...)
+		in := componentMapToPromLabels(t)
+		lset := relabel.Process(in, relabelConfigs...)
+		if c.debug.Active() {
+			c.debug.Publish(relabelDebugString(in, lset))
+		}
 		if lset != nil {
 			targets = append(targets, promLabelsToComponent(lset))
 		}
@@ -100,3 +109,17 @@ func promLabelsToComponent(ls labels.Labels) discovery.Target {
 
 	return res
 }
+
+// LiveDebugging implements component.LiveDebuggingComponent. Each input
+// target is streamed along with its labels after relabeling whenever the
+// component is updated.
+func (c *Component) LiveDebugging(fn func(data string)) (unregister func()) {
+	return c.debug.Subscribe(fn)
+}
+
+func relabelDebugString(in, out labels.Labels) string {
+	if out == nil {
+		return fmt.Sprintf("%s => dropped", labels.New(in...))
+	}
+	return fmt.Sprintf("%s => %s", labels.New(in...), labels.New(out...))
+}
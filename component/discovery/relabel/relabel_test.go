@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/component/discovery"
 	"github.com/grafana/agent/component/discovery/relabel"
 	"github.com/grafana/agent/pkg/flow/componenttest"
@@ -73,3 +74,32 @@ rule {
 	require.NoError(t, tc.WaitExports(time.Second))
 	require.Equal(t, expectedExports, tc.Exports())
 }
+
+func TestRelabelLiveDebugging(t *testing.T) {
+	var args relabel.Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+targets = [
+	{ "__address__" = "localhost", "app" = "backend" },
+	{ "__address__" = "localhost", "app" = "frontend" },
+]
+
+rule {
+	source_labels = ["app"]
+	action        = "drop"
+	regex         = "frontend"
+}
+`), &args))
+
+	c, err := relabel.New(component.Options{OnStateChange: func(component.Exports) {}}, args)
+	require.NoError(t, err)
+
+	var data []string
+	unregister := c.LiveDebugging(func(d string) { data = append(data, d) })
+	defer unregister()
+
+	require.NoError(t, c.Update(args))
+	require.Equal(t, []string{
+		`{__address__="localhost", app="backend"} => {__address__="localhost", app="backend"}`,
+		`{__address__="localhost", app="frontend"} => dropped`,
+	}, data)
+}
@@ -0,0 +1,607 @@
+// Package receiver implements the faro.receiver component.
+package receiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations/v2/app_agent_receiver"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/grafana/loki/clients/pkg/promtail/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/weaveworks/common/instrument"
+	"github.com/weaveworks/common/middleware"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "faro.receiver",
+		Args:    Arguments{},
+		Exports: nil,
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the faro.receiver component.
+type Arguments struct {
+	LogLabels      map[string]string `river:"log_labels,attr,optional"`
+	LogSendTimeout time.Duration     `river:"log_send_timeout,attr,optional"`
+
+	Server     ServerArguments     `river:"server,block,optional"`
+	SourceMaps SourceMapArguments  `river:"sourcemaps,block,optional"`
+	Validation ValidationArguments `river:"validation,block,optional"`
+	Sampling   SamplingArguments   `river:"sampling,block,optional"`
+	Scrubbing  ScrubbingArguments  `river:"scrubbing,block,optional"`
+	Output     OutputArguments     `river:"output,block"`
+}
+
+// ServerArguments configures the HTTP server which receives payloads.
+type ServerArguments struct {
+	ListenAddress         string            `river:"listen_address,attr,optional"`
+	ListenPort            int               `river:"listen_port,attr,optional"`
+	CORSAllowedOrigins    []string          `river:"cors_allowed_origins,attr,optional"`
+	APIKey                rivertypes.Secret `river:"api_key,attr,optional"`
+	UploadAPIKey          rivertypes.Secret `river:"upload_api_key,attr,optional"`
+	MaxAllowedPayloadSize units.Base2Bytes  `river:"max_allowed_payload_size,attr,optional"`
+
+	RateLimiting RateLimitingArguments `river:"rate_limiting,block,optional"`
+}
+
+// RateLimitingArguments configures rate limiting of incoming payloads.
+type RateLimitingArguments struct {
+	Enabled   bool    `river:"enabled,attr,optional"`
+	Rate      float64 `river:"rate,attr,optional"`
+	BurstSize int     `river:"burst_size,attr,optional"`
+}
+
+// SourceMapArguments configures how source maps are retrieved to resolve the
+// original location of exception stack frames.
+type SourceMapArguments struct {
+	Download            bool                `river:"download,attr,optional"`
+	DownloadFromOrigins []string            `river:"download_from_origins,attr,optional"`
+	DownloadTimeout     time.Duration       `river:"download_timeout,attr,optional"`
+	Locations           []LocationArguments `river:"location,block,optional"`
+	Cache               CacheArguments      `river:"cache,block,optional"`
+}
+
+// CacheArguments configures caching of source maps.
+type CacheArguments struct {
+	MaxSize     units.Base2Bytes `river:"max_size,attr,optional"`
+	TTL         time.Duration    `river:"ttl,attr,optional"`
+	NotFoundTTL time.Duration    `river:"not_found_ttl,attr,optional"`
+}
+
+// LocationArguments is a location on the file system to read source maps
+// from.
+type LocationArguments struct {
+	Path               string `river:"path,attr"`
+	MinifiedPathPrefix string `river:"minified_path_prefix,attr,optional"`
+}
+
+// ValidationArguments configures validation of payloads.
+type ValidationArguments struct {
+	Schema string `river:"schema,attr,optional"`
+}
+
+// SamplingArguments configures sampling of the items of payloads.
+type SamplingArguments struct {
+	Rules []SamplingRuleArguments `river:"rule,block,optional"`
+}
+
+// SamplingRuleArguments configures the ratio of items of an app and kind to
+// keep.
+type SamplingRuleArguments struct {
+	App   string  `river:"app,attr,optional"`
+	Kind  string  `river:"kind,attr,optional"`
+	Ratio float64 `river:"ratio,attr"`
+}
+
+// ScrubbingArguments configures scrubbing of personal data from payloads.
+type ScrubbingArguments struct {
+	AllowedUserAttributes []string                 `river:"allowed_user_attributes,attr,optional"`
+	AllowedQueryParams    []string                 `river:"allowed_query_params,attr,optional"`
+	AllowedLogContext     []string                 `river:"allowed_log_context,attr,optional"`
+	HashSalt              rivertypes.Secret        `river:"hash_salt,attr,optional"`
+	Rules                 []ScrubbingRuleArguments `river:"rule,block,optional"`
+}
+
+// ScrubbingRuleArguments configures how to scrub the values of payload
+// fields.
+type ScrubbingRuleArguments struct {
+	Fields      []string `river:"fields,attr"`
+	Keys        string   `river:"keys,attr,optional"`
+	Pattern     string   `river:"pattern,attr,optional"`
+	Action      string   `river:"action,attr"`
+	Replacement string   `river:"replacement,attr,optional"`
+}
+
+// OutputArguments configures where to send received data.
+type OutputArguments struct {
+	Logs   []loki.LogsReceiver `river:"logs,attr,optional"`
+	Traces []otelcol.Consumer  `river:"traces,attr,optional"`
+}
+
+// DefaultArguments holds default settings for faro.receiver. The defaults
+// match those of the app_agent_receiver integration.
+var DefaultArguments = Arguments{
+	LogSendTimeout: app_agent_receiver.DefaultConfig.LogsSendTimeout,
+
+	Server: ServerArguments{
+		ListenAddress:         app_agent_receiver.DefaultConfig.Server.Host,
+		ListenPort:            app_agent_receiver.DefaultConfig.Server.Port,
+		MaxAllowedPayloadSize: 5 * units.MiB,
+		RateLimiting: RateLimitingArguments{
+			Enabled:   true,
+			Rate:      app_agent_receiver.DefaultRateLimitingRPS,
+			BurstSize: app_agent_receiver.DefaultRateLimitingBurstiness,
+		},
+	},
+	SourceMaps: SourceMapArguments{
+		DownloadFromOrigins: []string{"*"},
+		DownloadTimeout:     app_agent_receiver.DefaultConfig.SourceMaps.DownloadTimeout,
+		Cache: CacheArguments{
+			MaxSize:     100 * units.MiB,
+			TTL:         app_agent_receiver.DefaultConfig.SourceMaps.Cache.TTL,
+			NotFoundTTL: app_agent_receiver.DefaultConfig.SourceMaps.Cache.NotFoundTTL,
+		},
+	},
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Server.ListenPort < 0 || args.Server.ListenPort > 65535 {
+		return fmt.Errorf("listen_port must be between 0 and 65535")
+	}
+	if args.Server.RateLimiting.Enabled && args.Server.RateLimiting.Rate <= 0 {
+		return fmt.Errorf("rate_limiting rate must be greater than 0")
+	}
+	for _, l := range args.SourceMaps.Locations {
+		// Paths are templates which may refer to the release of the app.
+		if _, err := template.New(l.Path).Parse(l.Path); err != nil {
+			return fmt.Errorf("invalid source map location path %q: %w", l.Path, err)
+		}
+	}
+	if args.SourceMaps.Cache.MaxSize < 0 {
+		return fmt.Errorf("cache max_size must not be negative")
+	}
+	return args.config("").Processing.Validate()
+}
+
+// listenAddr returns the address the HTTP server listens on.
+func (args *Arguments) listenAddr() string {
+	return net.JoinHostPort(args.Server.ListenAddress, strconv.Itoa(args.Server.ListenPort))
+}
+
+// config converts args into the config of the app_agent_receiver
+// integration, which implements handling of payloads. Source maps are cached
+// on disk in dataPath.
+func (args *Arguments) config(dataPath string) *app_agent_receiver.Config {
+	locations := make([]app_agent_receiver.SourceMapFileLocation, 0, len(args.SourceMaps.Locations))
+	for _, l := range args.SourceMaps.Locations {
+		locations = append(locations, app_agent_receiver.SourceMapFileLocation{
+			Path:               l.Path,
+			MinifiedPathPrefix: l.MinifiedPathPrefix,
+		})
+	}
+
+	samplingRules := make([]app_agent_receiver.SamplingRule, 0, len(args.Sampling.Rules))
+	for _, r := range args.Sampling.Rules {
+		samplingRules = append(samplingRules, app_agent_receiver.SamplingRule(r))
+	}
+	scrubbingRules := make([]app_agent_receiver.ScrubbingRule, 0, len(args.Scrubbing.Rules))
+	for _, r := range args.Scrubbing.Rules {
+		scrubbingRules = append(scrubbingRules, app_agent_receiver.ScrubbingRule(r))
+	}
+
+	return &app_agent_receiver.Config{
+		Server: app_agent_receiver.ServerConfig{
+			Host:               args.Server.ListenAddress,
+			Port:               args.Server.ListenPort,
+			CORSAllowedOrigins: args.Server.CORSAllowedOrigins,
+			RateLimiting: app_agent_receiver.RateLimitingConfig{
+				Enabled:    args.Server.RateLimiting.Enabled,
+				RPS:        args.Server.RateLimiting.Rate,
+				Burstiness: args.Server.RateLimiting.BurstSize,
+			},
+			APIKey:                string(args.Server.APIKey),
+			UploadAPIKey:          string(args.Server.UploadAPIKey),
+			MaxAllowedPayloadSize: int64(args.Server.MaxAllowedPayloadSize),
+		},
+		LogsLabels:      args.LogLabels,
+		LogsSendTimeout: args.LogSendTimeout,
+		SourceMaps: app_agent_receiver.SourceMapConfig{
+			Download:            args.SourceMaps.Download,
+			DownloadFromOrigins: args.SourceMaps.DownloadFromOrigins,
+			DownloadTimeout:     args.SourceMaps.DownloadTimeout,
+			FileSystem:          locations,
+			Cache: app_agent_receiver.SourceMapCacheConfig{
+				MaxSize:     int64(args.SourceMaps.Cache.MaxSize),
+				TTL:         args.SourceMaps.Cache.TTL,
+				NotFoundTTL: args.SourceMaps.Cache.NotFoundTTL,
+				Directory:   filepath.Join(dataPath, "sourcemaps"),
+			},
+		},
+		Processing: app_agent_receiver.ProcessingConfig{
+			Validation: app_agent_receiver.ValidationConfig{
+				Schema: args.Validation.Schema,
+			},
+			Sampling: app_agent_receiver.SamplingConfig{
+				Rules: samplingRules,
+			},
+			Scrubbing: app_agent_receiver.ScrubbingConfig{
+				AllowedUserAttributes: args.Scrubbing.AllowedUserAttributes,
+				AllowedQueryParams:    args.Scrubbing.AllowedQueryParams,
+				AllowedLogContext:     args.Scrubbing.AllowedLogContext,
+				HashSalt:              string(args.Scrubbing.HashSalt),
+				Rules:                 scrubbingRules,
+			},
+		},
+	}
+}
+
+// Component implements the faro.receiver component.
+type Component struct {
+	opts    component.Options
+	metrics *serverMetrics
+
+	mut        sync.RWMutex
+	args       Arguments
+	handler    http.Handler
+	handlerReg *util.Unregisterer // Registerer of the metrics of handler.
+
+	// The source map store is only recreated when the sourcemaps block
+	// changes so that cached source maps are kept across updates.
+	sourceMaps        *app_agent_receiver.RealSourceMapStore
+	sourceMapsReg     *util.Unregisterer
+	sourceMapsHandler http.Handler
+
+	healthMut sync.RWMutex
+	health    component.Health
+
+	// serverUpdated is written to when the listen address changes so that Run
+	// restarts the server.
+	serverUpdated chan struct{}
+}
+
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.HealthComponent = (*Component)(nil)
+)
+
+// New creates a new faro.receiver component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	metrics, err := newServerMetrics(o.Registerer)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		opts:          o,
+		metrics:       metrics,
+		serverUpdated: make(chan struct{}, 1),
+	}
+
+	// Call to Update() to build the handler once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component. Run serves the HTTP server which
+// receives payloads, restarting it when the listen address changes.
+func (c *Component) Run(ctx context.Context) error {
+	r := mux.NewRouter()
+	r.Handle("/collect", http.HandlerFunc(c.serveCollect)).Methods(http.MethodPost, http.MethodOptions)
+	r.PathPrefix("/sourcemaps/").Handler(http.HandlerFunc(c.serveSourceMaps))
+
+	mw := middleware.Instrument{
+		RouteMatcher:     r,
+		Duration:         c.metrics.requestDuration,
+		RequestBodySize:  c.metrics.receivedMessageSize,
+		ResponseBodySize: c.metrics.sentMessageSize,
+		InflightRequests: c.metrics.inflightRequests,
+	}
+	handler := mw.Wrap(r)
+
+	for {
+		c.mut.RLock()
+		addr := c.args.listenAddr()
+		c.mut.RUnlock()
+
+		srv, done, err := c.startServer(addr, handler)
+		if err != nil {
+			// Wait for the listen address to be fixed.
+			level.Error(c.opts.Logger).Log("msg", "failed to start server", "addr", addr, "err", err)
+			c.setHealth(component.Health{
+				Health:     component.HealthTypeUnhealthy,
+				Message:    err.Error(),
+				UpdateTime: time.Now(),
+			})
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-c.serverUpdated:
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-c.serverUpdated:
+		case err := <-done:
+			// The server stopped on its own. Report it and wait for the listen
+			// address to change before starting it again.
+			level.Error(c.opts.Logger).Log("msg", "server stopped", "err", err)
+			c.setHealth(component.Health{
+				Health:     component.HealthTypeUnhealthy,
+				Message:    err.Error(),
+				UpdateTime: time.Now(),
+			})
+
+			select {
+			case <-ctx.Done():
+			case <-c.serverUpdated:
+			}
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = srv.Shutdown(shutdownCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// startServer starts an HTTP server listening on addr. done receives an
+// error if the server stops for any other reason than being shut down.
+func (c *Component) startServer(addr string, handler http.Handler) (srv *http.Server, done <-chan error, err error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	srv = &http.Server{Handler: handler}
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	level.Info(c.opts.Logger).Log("msg", "starting server", "addr", lis.Addr())
+	c.setHealth(component.Health{
+		Health:     component.HealthTypeHealthy,
+		Message:    "server listening on " + lis.Addr().String(),
+		UpdateTime: time.Now(),
+	})
+	return srv, errCh, nil
+}
+
+// serveCollect passes requests to the handler built from the most recent
+// arguments.
+func (c *Component) serveCollect(w http.ResponseWriter, r *http.Request) {
+	c.mut.RLock()
+	handler := c.handler
+	c.mut.RUnlock()
+
+	handler.ServeHTTP(w, r)
+}
+
+// serveSourceMaps passes requests to upload or preload source maps to the
+// handler built from the most recent arguments.
+func (c *Component) serveSourceMaps(w http.ResponseWriter, r *http.Request) {
+	c.mut.RLock()
+	handler := c.sourceMapsHandler
+	c.mut.RUnlock()
+
+	handler.ServeHTTP(w, r)
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+	cfg := newArgs.config(c.opts.DataPath)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	// The source map store and exporters register metrics when created, so the
+	// metrics of the previous ones must be unregistered first.
+	if c.handlerReg != nil {
+		c.handlerReg.UnregisterAll()
+	}
+	reg := util.WrapWithUnregisterer(c.opts.Registerer)
+
+	sourceMaps := c.sourceMaps
+	if sourceMaps == nil || !reflect.DeepEqual(c.args.SourceMaps, newArgs.SourceMaps) {
+		if c.sourceMapsReg != nil {
+			c.sourceMapsReg.UnregisterAll()
+		}
+		c.sourceMapsReg = util.WrapWithUnregisterer(c.opts.Registerer)
+
+		sourceMaps = app_agent_receiver.NewSourceMapStore(
+			log.With(c.opts.Logger, "subcomponent", "sourcemaps"),
+			cfg.SourceMaps, c.sourceMapsReg, nil, nil,
+		)
+	}
+
+	exporters := []app_agent_receiver.AppAgentReceiverExporter{
+		app_agent_receiver.NewReceiverMetricsExporter(reg),
+	}
+	if len(newArgs.Output.Logs) > 0 {
+		sender := &logsSender{receivers: newArgs.Output.Logs}
+		exporters = append(exporters, app_agent_receiver.NewLogsExporter(
+			c.opts.Logger,
+			app_agent_receiver.LogsExporterConfig{
+				GetLogsInstance:  func() (app_agent_receiver.LogsInstance, error) { return sender, nil },
+				Labels:           cfg.LogsLabels,
+				SendEntryTimeout: cfg.LogsSendTimeout,
+			},
+			sourceMaps,
+		))
+	}
+	if len(newArgs.Output.Traces) > 0 {
+		consumer := &tracesFanout{consumers: newArgs.Output.Traces}
+		exporters = append(exporters, app_agent_receiver.NewTracesExporter(
+			func() (otelconsumer.Traces, error) { return consumer, nil },
+		))
+	}
+
+	h, err := app_agent_receiver.NewAppAgentReceiverHandler(cfg, exporters, reg)
+	if err != nil {
+		reg.UnregisterAll()
+		return err
+	}
+
+	restartServer := c.handler != nil && c.args.listenAddr() != newArgs.listenAddr()
+
+	c.args = newArgs
+	c.handler = h.HTTPHandler(c.opts.Logger)
+	c.handlerReg = reg
+	c.sourceMaps = sourceMaps
+	c.sourceMapsHandler = app_agent_receiver.NewSourceMapHandler(c.opts.Logger, sourceMaps, cfg.Server.UploadAPIKey)
+
+	if restartServer {
+		select {
+		case c.serverUpdated <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// CurrentHealth implements component.HealthComponent.
+func (c *Component) CurrentHealth() component.Health {
+	c.healthMut.RLock()
+	defer c.healthMut.RUnlock()
+	return c.health
+}
+
+func (c *Component) setHealth(h component.Health) {
+	c.healthMut.Lock()
+	defer c.healthMut.Unlock()
+	c.health = h
+}
+
+// logsSender sends log entries created from payloads to Flow log receivers.
+type logsSender struct {
+	receivers []loki.LogsReceiver
+}
+
+var _ app_agent_receiver.LogsInstance = (*logsSender)(nil)
+
+// SendEntry sends entry to every receiver, returning false if any receiver
+// didn't accept the entry before timeout.
+func (s *logsSender) SendEntry(entry api.Entry, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for _, receiver := range s.receivers {
+		select {
+		case receiver <- loki.Entry(entry).Clone():
+		case <-timer.C:
+			return false
+		}
+	}
+	return true
+}
+
+// tracesFanout sends traces created from payloads to Flow otelcol consumers.
+type tracesFanout struct {
+	consumers []otelcol.Consumer
+}
+
+var _ otelconsumer.Traces = (*tracesFanout)(nil)
+
+// Capabilities implements otelconsumer.Traces.
+func (f *tracesFanout) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeTraces implements otelconsumer.Traces. Consumers which mutate data
+// are given a copy of td.
+func (f *tracesFanout) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	var errs error
+	for _, consumer := range f.consumers {
+		if consumer.Capabilities().MutatesData {
+			clone := ptrace.NewTraces()
+			td.CopyTo(clone)
+			errs = multierr.Append(errs, consumer.ConsumeTraces(ctx, clone))
+			continue
+		}
+		errs = multierr.Append(errs, consumer.ConsumeTraces(ctx, td))
+	}
+	return errs
+}
+
+// serverMetrics instruments requests to the HTTP server.
+type serverMetrics struct {
+	requestDuration     *prometheus.HistogramVec
+	receivedMessageSize *prometheus.HistogramVec
+	sentMessageSize     *prometheus.HistogramVec
+	inflightRequests    *prometheus.GaugeVec
+}
+
+func newServerMetrics(reg prometheus.Registerer) (*serverMetrics, error) {
+	m := &serverMetrics{
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "app_agent_receiver_request_duration_seconds",
+			Help:    "Time (in seconds) spent serving HTTP requests.",
+			Buckets: instrument.DefBuckets,
+		}, []string{"method", "route", "status_code", "ws"}),
+		receivedMessageSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "app_agent_receiver_request_message_bytes",
+			Help:    "Size (in bytes) of messages received in the request.",
+			Buckets: middleware.BodySizeBuckets,
+		}, []string{"method", "route"}),
+		sentMessageSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "app_agent_receiver_response_message_bytes",
+			Help:    "Size (in bytes) of messages sent in response.",
+			Buckets: middleware.BodySizeBuckets,
+		}, []string{"method", "route"}),
+		inflightRequests: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "app_agent_receiver_inflight_requests",
+			Help: "Current number of inflight requests.",
+		}, []string{"method", "route"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requestDuration, m.receivedMessageSize, m.sentMessageSize, m.inflightRequests} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
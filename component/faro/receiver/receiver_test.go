@@ -0,0 +1,301 @@
+package receiver_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/component/faro/receiver"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestReceiver(t *testing.T) {
+	payload, err := os.ReadFile("../../../pkg/integrations/v2/app_agent_receiver/testdata/payload.json")
+	require.NoError(t, err)
+
+	var (
+		logs   = make(loki.LogsReceiver, 10)
+		traces = &fakeConsumer{}
+	)
+
+	args := receiver.DefaultArguments
+	args.Server.ListenPort = freePort(t)
+	args.Server.APIKey = "secret"
+	args.LogLabels = map[string]string{
+		"app":  "frontend",
+		"kind": "",
+	}
+	args.Output = receiver.OutputArguments{
+		Logs:   []loki.LogsReceiver{logs},
+		Traces: []otelcol.Consumer{traces},
+	}
+
+	tc, err := componenttest.NewControllerFromID(nil, "faro.receiver")
+	require.NoError(t, err)
+	go func() {
+		err := tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, tc.WaitRunning(time.Second))
+
+	// Requests without the API key are rejected.
+	resp := postPayload(t, args.Server.ListenPort, "", payload)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = postPayload(t, args.Server.ListenPort, "secret", payload)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	// The payload has 2 logs, 1 exception, 1 measurement and 2 events, which
+	// are each sent as a log line.
+	kinds := map[model.LabelValue]int{}
+	for i := 0; i < 6; i++ {
+		select {
+		case entry := <-logs:
+			require.Equal(t, model.LabelValue("frontend"), entry.Labels["app"])
+			kinds[entry.Labels["kind"]]++
+		case <-time.After(time.Second):
+			require.FailNow(t, "timed out waiting for log entries")
+		}
+	}
+	require.Equal(t, map[model.LabelValue]int{
+		"log":         2,
+		"exception":   1,
+		"measurement": 1,
+		"event":       2,
+	}, kinds)
+	require.Equal(t, 1, traces.Calls())
+
+	// Changing the listen port restarts the server.
+	oldPort := args.Server.ListenPort
+	args.Server.ListenPort = freePort(t)
+	require.NoError(t, tc.Update(args))
+	require.Eventually(t, func() bool {
+		resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/collect", args.Server.ListenPort), "application/json", bytes.NewReader(payload))
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusUnauthorized
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = http.Post(fmt.Sprintf("http://127.0.0.1:%d/collect", oldPort), "application/json", bytes.NewReader(payload))
+	require.Error(t, err)
+}
+
+func TestReceiver_PayloadTooLarge(t *testing.T) {
+	args := receiver.DefaultArguments
+	args.Server.ListenPort = freePort(t)
+	args.Server.MaxAllowedPayloadSize = 10
+
+	tc, err := componenttest.NewControllerFromID(nil, "faro.receiver")
+	require.NoError(t, err)
+	go func() {
+		err := tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, tc.WaitRunning(time.Second))
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = http.Post(fmt.Sprintf("http://127.0.0.1:%d/collect", args.Server.ListenPort), "application/json", bytes.NewReader([]byte(`{"logs": []}`)))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestReceiver_SourceMapUpload(t *testing.T) {
+	mapFile, err := os.ReadFile("../../../pkg/integrations/v2/app_agent_receiver/testdata/foo.js.map")
+	require.NoError(t, err)
+
+	args := receiver.DefaultArguments
+	args.Server.ListenPort = freePort(t)
+	args.Server.APIKey = "public"
+	args.Server.UploadAPIKey = "secret"
+
+	tc, err := componenttest.NewControllerFromID(nil, "faro.receiver")
+	require.NoError(t, err)
+	go func() {
+		err := tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, tc.WaitRunning(time.Second))
+
+	upload := func(apiKey string) int {
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			url := fmt.Sprintf("http://127.0.0.1:%d/sourcemaps/upload?release=1.0.0&url=http://app.example.com/main.js", args.Server.ListenPort)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(mapFile))
+			require.NoError(t, err)
+			req.Header.Set("x-api-key", apiKey)
+			resp, err = http.DefaultClient.Do(req)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusUnauthorized, upload("wrong"))
+	// The API key sent by browsers can't be used to upload source maps.
+	require.Equal(t, http.StatusUnauthorized, upload("public"))
+	require.Equal(t, http.StatusOK, upload("secret"))
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	cfg := `
+		server {
+			listen_port = 8080
+
+			rate_limiting {
+				burst_size = 10
+			}
+		}
+
+		sourcemaps {
+			location {
+				path = "/var/www/{{ .Release }}/"
+			}
+		}
+
+		sampling {
+			rule {
+				kind  = "log"
+				ratio = 0.1
+			}
+		}
+
+		scrubbing {
+			allowed_query_params = ["page"]
+
+			rule {
+				fields = ["user_email", "log_context"]
+				action = "hash"
+			}
+		}
+
+		output {}
+	`
+
+	var args receiver.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	require.Equal(t, "127.0.0.1", args.Server.ListenAddress)
+	require.Equal(t, 8080, args.Server.ListenPort)
+	require.Equal(t, 5*units.MiB, args.Server.MaxAllowedPayloadSize)
+	require.Equal(t, receiver.RateLimitingArguments{Enabled: true, Rate: 100, BurstSize: 10}, args.Server.RateLimiting)
+	require.Equal(t, []string{"*"}, args.SourceMaps.DownloadFromOrigins)
+	require.Equal(t, time.Second, args.SourceMaps.DownloadTimeout)
+	require.Equal(t, []receiver.SamplingRuleArguments{{Kind: "log", Ratio: 0.1}}, args.Sampling.Rules)
+	require.Equal(t, []string{"page"}, args.Scrubbing.AllowedQueryParams)
+	require.Equal(t, []receiver.ScrubbingRuleArguments{{Fields: []string{"user_email", "log_context"}, Action: "hash"}}, args.Scrubbing.Rules)
+	require.Equal(t, receiver.CacheArguments{MaxSize: 100 * units.MiB, TTL: 24 * time.Hour, NotFoundTTL: 5 * time.Minute}, args.SourceMaps.Cache)
+
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name:   "invalid port",
+			cfg:    "server {\nlisten_port = 70000\n}\noutput {}",
+			expect: "listen_port must be between 0 and 65535",
+		},
+		{
+			name:   "invalid rate",
+			cfg:    "server {\nrate_limiting {\nrate = 0\n}\n}\noutput {}",
+			expect: "rate_limiting rate must be greater than 0",
+		},
+		{
+			name:   "invalid sampling ratio",
+			cfg:    "sampling {\nrule {\nratio = 1.5\n}\n}\noutput {}",
+			expect: "sampling ratio must be between 0 and 1, got 1.5",
+		},
+		{
+			name:   "invalid scrubbing action",
+			cfg:    "scrubbing {\nrule {\nfields = [\"user_email\"]\naction = \"encrypt\"\n}\n}\noutput {}",
+			expect: `invalid scrubbing action "encrypt", must be one of replace, hash, drop`,
+		},
+		{
+			name:   "invalid location",
+			cfg:    "sourcemaps {\nlocation {\npath = \"{{ .Release \"\n}\n}\noutput {}",
+			expect: `invalid source map location path "{{ .Release ": template: {{ .Release :1: unclosed action`,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args receiver.Arguments
+			require.EqualError(t, river.Unmarshal([]byte(tc.cfg), &args), tc.expect)
+		})
+	}
+}
+
+func postPayload(t *testing.T, port int, apiKey string, payload []byte) *http.Response {
+	t.Helper()
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://127.0.0.1:%d/collect", port), bytes.NewReader(payload))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set("x-api-key", apiKey)
+		}
+		resp, err = http.DefaultClient.Do(req)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	resp.Body.Close()
+	return resp
+}
+
+// freePort returns a port which is free to listen on.
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+// fakeConsumer is an otelcol.Consumer which counts how many times traces
+// were sent to it.
+type fakeConsumer struct {
+	mut   sync.Mutex
+	calls int
+}
+
+func (c *fakeConsumer) Calls() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.calls
+}
+
+func (c *fakeConsumer) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{}
+}
+
+func (c *fakeConsumer) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.calls++
+	return nil
+}
+
+func (c *fakeConsumer) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error { return nil }
+
+func (c *fakeConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error { return nil }
@@ -0,0 +1,206 @@
+// Package envfile implements the local.env_file component.
+package envfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "local.env_file",
+		Args:    Arguments{},
+		Exports: Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the local.env_file
+// component.
+type Arguments struct {
+	// Filename indicates the file to read.
+	Filename string `river:"filename,attr"`
+	// PollFrequency determines how often the file is read again to detect
+	// changes.
+	PollFrequency time.Duration `river:"poll_frequency,attr,optional"`
+}
+
+// DefaultArguments provides the default arguments for the local.env_file
+// component.
+var DefaultArguments = Arguments{
+	PollFrequency: time.Minute,
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(a)); err != nil {
+		return err
+	}
+
+	if a.PollFrequency <= 0 {
+		return fmt.Errorf("poll_frequency must be greater than 0")
+	}
+	return nil
+}
+
+// Exports holds values which are exported by the local.env_file component.
+type Exports struct {
+	// Values holds the variables defined in the file as secrets.
+	Values map[string]rivertypes.Secret `river:"values,attr"`
+}
+
+// Component implements the local.env_file component.
+type Component struct {
+	opts component.Options
+
+	mut    sync.Mutex
+	args   Arguments
+	values map[string]string // Most recently exported values.
+
+	healthMut sync.RWMutex
+	health    component.Health
+
+	// updated is written to when the arguments change so the poll timer is
+	// reset.
+	updated chan struct{}
+}
+
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.HealthComponent = (*Component)(nil)
+)
+
+// New creates a new local.env_file component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:    o,
+		updated: make(chan struct{}, 1),
+	}
+
+	// Perform an update which will immediately set our exports to the initial
+	// contents of the file.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	for {
+		c.mut.Lock()
+		pollFrequency := c.args.PollFrequency
+		c.mut.Unlock()
+
+		timer := time.NewTimer(pollFrequency)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-c.updated:
+			timer.Stop()
+		case <-timer.C:
+			// We ignore the error here from readFile since readFile will log errors
+			// and also report the error as the health of the component.
+			c.mut.Lock()
+			_ = c.readFile()
+			c.mut.Unlock()
+		}
+	}
+}
+
+// readFile reads and parses the file, exporting its values if they changed
+// since the last read. mut must be held when calling readFile.
+func (c *Component) readFile() error {
+	values, err := parseFile(c.args.Filename)
+	if err != nil {
+		c.setHealth(component.Health{
+			Health:     component.HealthTypeUnhealthy,
+			Message:    err.Error(),
+			UpdateTime: time.Now(),
+		})
+		level.Error(c.opts.Logger).Log("msg", "failed to read env file", "path", c.args.Filename, "err", err)
+		return err
+	}
+
+	if c.values == nil || !reflect.DeepEqual(c.values, values) {
+		c.values = values
+
+		secrets := make(map[string]rivertypes.Secret, len(values))
+		for k, v := range values {
+			secrets[k] = rivertypes.Secret(v)
+		}
+		c.opts.OnStateChange(Exports{Values: secrets})
+	}
+
+	c.setHealth(component.Health{
+		Health:     component.HealthTypeHealthy,
+		Message:    "read env file",
+		UpdateTime: time.Now(),
+	})
+	return nil
+}
+
+// parseFile parses the variables defined in the env file at filename.
+func parseFile(filename string) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	values, err := godotenv.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+	return values, nil
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.args = newArgs
+
+	select {
+	case c.updated <- struct{}{}:
+	default:
+	}
+
+	// Force an immediate read of the file to report any potential errors early.
+	return c.readFile()
+}
+
+// CurrentHealth implements component.HealthComponent.
+func (c *Component) CurrentHealth() component.Health {
+	c.healthMut.RLock()
+	defer c.healthMut.RUnlock()
+	return c.health
+}
+
+func (c *Component) setHealth(h component.Health) {
+	c.healthMut.Lock()
+	defer c.healthMut.Unlock()
+	c.health = h
+}
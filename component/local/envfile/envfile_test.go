@@ -0,0 +1,76 @@
+package envfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/local/envfile"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+# Comments and blank lines are ignored.
+USERNAME=admin
+export PASSWORD="hunter2"
+QUOTED='single # not a comment'
+`), 0600))
+
+	tc, err := componenttest.NewControllerFromID(nil, "local.env_file")
+	require.NoError(t, err)
+	go func() {
+		err := tc.Run(componenttest.TestContext(t), envfile.Arguments{
+			Filename:      filename,
+			PollFrequency: 50 * time.Millisecond,
+		})
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, tc.WaitExports(time.Second))
+	require.Equal(t, envfile.Exports{
+		Values: map[string]rivertypes.Secret{
+			"USERNAME": "admin",
+			"PASSWORD": "hunter2",
+			"QUOTED":   "single # not a comment",
+		},
+	}, tc.Exports())
+
+	// Rotated values are exported again.
+	require.NoError(t, os.WriteFile(filename, []byte("USERNAME=admin\nPASSWORD=rotated\n"), 0600))
+	require.NoError(t, tc.WaitExports(time.Second))
+	require.Equal(t, envfile.Exports{
+		Values: map[string]rivertypes.Secret{
+			"USERNAME": "admin",
+			"PASSWORD": "rotated",
+		},
+	}, tc.Exports())
+}
+
+func TestEnvFile_MissingFile(t *testing.T) {
+	tc, err := componenttest.NewControllerFromID(nil, "local.env_file")
+	require.NoError(t, err)
+
+	err = tc.Run(componenttest.TestContext(t), envfile.Arguments{
+		Filename:      filepath.Join(t.TempDir(), "missing.env"),
+		PollFrequency: time.Minute,
+	})
+	require.ErrorContains(t, err, "failed to open file")
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	var args envfile.Arguments
+	require.NoError(t, river.Unmarshal([]byte(`filename = "/etc/agent/.env"`), &args))
+	require.Equal(t, time.Minute, args.PollFrequency)
+
+	err := river.Unmarshal([]byte(`
+		filename       = "/etc/agent/.env"
+		poll_frequency = "0s"
+	`), &args)
+	require.EqualError(t, err, "poll_frequency must be greater than 0")
+}
@@ -0,0 +1,53 @@
+package loki
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/loki/clients/pkg/promtail/api"
+)
+
+// GuardedHandler guards sending entries to a Promtail api.EntryHandler which
+// may be stopped from another goroutine. Stopping an api.EntryHandler closes
+// its channel, so entries must not be sent to it once it's stopped.
+//
+// GuardedHandler allows components to send entries without holding the lock
+// which guards replacing the handler, so that replacing the handler doesn't
+// have to wait for a blocked send.
+type GuardedHandler struct {
+	handler api.EntryHandler
+
+	mut    sync.RWMutex
+	closed bool
+}
+
+// NewGuardedHandler returns a new GuardedHandler for h.
+func NewGuardedHandler(h api.EntryHandler) *GuardedHandler {
+	return &GuardedHandler{handler: h}
+}
+
+// Send sends entry to the handler. Send returns false if the entry wasn't
+// sent because Close was called or ctx was canceled.
+func (g *GuardedHandler) Send(ctx context.Context, entry api.Entry) bool {
+	g.mut.RLock()
+	defer g.mut.RUnlock()
+
+	if g.closed {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case g.handler.Chan() <- entry:
+		return true
+	}
+}
+
+// Close prevents further entries from being sent to the handler, waiting for
+// calls to Send which are in progress to return. The handler can be stopped
+// once Close returns.
+func (g *GuardedHandler) Close() {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	g.closed = true
+}
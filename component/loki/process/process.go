@@ -0,0 +1,202 @@
+// Package process implements the loki.process component.
+package process
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/loki/clients/pkg/logentry/stages"
+	"github.com/grafana/loki/clients/pkg/promtail/api"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "loki.process",
+		Args:    Arguments{},
+		Exports: Exports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the loki.process
+// component.
+type Arguments struct {
+	ForwardTo []loki.LogsReceiver `river:"forward_to,attr"`
+	Stages    []StageConfig       `river:"stage,block,optional"`
+}
+
+// Exports holds values which are exported by the loki.process component.
+type Exports struct {
+	Receiver loki.LogsReceiver `river:"receiver,attr"`
+}
+
+// Component implements the loki.process component.
+type Component struct {
+	opts component.Options
+
+	receiver   loki.LogsReceiver
+	processOut chan api.Entry
+
+	// mut guards the processing pipeline. Entries are sent into the pipeline
+	// through sender without holding mut, so replacing the pipeline doesn't
+	// wait for a blocked send.
+	mut          sync.RWMutex
+	stages       []StageConfig
+	entryHandler api.EntryHandler
+	sender       *loki.GuardedHandler
+
+	fanoutMut sync.RWMutex
+	fanout    []loki.LogsReceiver
+}
+
+var (
+	_ component.Component = (*Component)(nil)
+)
+
+// New creates a new loki.process component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts: o,
+
+		receiver:   make(loki.LogsReceiver),
+		processOut: make(chan api.Entry),
+	}
+
+	// Call to Update() to build the pipeline once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	o.OnStateChange(Exports{Receiver: c.receiver})
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	defer c.stopPipeline()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.handleIn(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		c.handleOut(ctx)
+	}()
+	wg.Wait()
+	return nil
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	c.fanoutMut.Lock()
+	c.fanout = newArgs.ForwardTo
+	c.fanoutMut.Unlock()
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	// Only rebuild the pipeline if the stages changed, since rebuilding
+	// discards any state held by stages (such as partially assembled multiline
+	// entries).
+	if c.entryHandler != nil && reflect.DeepEqual(c.stages, newArgs.Stages) {
+		return nil
+	}
+
+	pipeline, err := stages.NewPipeline(c.opts.Logger, toPipelineStages(newArgs.Stages), &c.opts.ID, c.opts.Registerer)
+	if err != nil {
+		return err
+	}
+
+	// Stop the old pipeline before replacing it, which flushes all of its
+	// in-flight entries to processOut.
+	if c.entryHandler != nil {
+		c.sender.Close()
+		c.entryHandler.Stop()
+	}
+	c.entryHandler = pipeline.Wrap(api.NewEntryHandler(c.processOut, func() {}))
+	c.sender = loki.NewGuardedHandler(c.entryHandler)
+	c.stages = newArgs.Stages
+	return nil
+}
+
+// handleIn sends entries written to the exported receiver into the
+// processing pipeline.
+func (c *Component) handleIn(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry := <-c.receiver:
+			// The pipeline may be replaced while the entry is being sent, in
+			// which case the entry is sent to the new pipeline.
+			for {
+				c.mut.RLock()
+				sender := c.sender
+				c.mut.RUnlock()
+
+				if sender.Send(ctx, api.Entry(entry)) {
+					break
+				}
+				if ctx.Err() != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// handleOut forwards processed entries to the receivers in forward_to.
+func (c *Component) handleOut(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry := <-c.processOut:
+			c.fanoutMut.RLock()
+			fanout := c.fanout
+			c.fanoutMut.RUnlock()
+
+			for _, receiver := range fanout {
+				select {
+				case <-ctx.Done():
+					return
+				case receiver <- loki.Entry(entry).Clone():
+				}
+			}
+		}
+	}
+}
+
+// stopPipeline stops the processing pipeline, discarding any entries which
+// were still being processed.
+func (c *Component) stopPipeline() {
+	done := make(chan struct{})
+	defer close(done)
+
+	// The pipeline can only finish stopping once its remaining entries have
+	// been read, so drain processOut until it has stopped.
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-c.processOut:
+			}
+		}
+	}()
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.sender.Close()
+	c.entryHandler.Stop()
+}
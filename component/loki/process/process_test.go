@@ -0,0 +1,185 @@
+package process_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/agent/component/loki/process"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+// Test performs a basic integration test which runs the loki.process
+// component and ensures that it can process and forward log entries.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "loki.process")
+	require.NoError(t, err)
+
+	cfg := `
+		forward_to = []
+
+		stage {
+			json {
+				expressions = { level = "", msg = "message" }
+			}
+		}
+
+		stage {
+			labels {
+				values = { level = "" }
+			}
+		}
+
+		stage {
+			match {
+				selector = "{level=\"debug\"}"
+				action   = "drop"
+			}
+		}
+
+		stage {
+			output {
+				source = "msg"
+			}
+		}
+	`
+	var args process.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	// Override our settings so entries get forwarded to ch.
+	ch := make(loki.LogsReceiver)
+	args.ForwardTo = []loki.LogsReceiver{ch}
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	input := ctrl.Exports().(process.Exports).Receiver
+
+	ts := time.Now()
+	input <- loki.NewEntry(model.LabelSet{"job": "test"}, logproto.Entry{
+		Timestamp: ts,
+		Line:      `{"level": "debug", "message": "dropped"}`,
+	})
+	input <- loki.NewEntry(model.LabelSet{"job": "test"}, logproto.Entry{
+		Timestamp: ts,
+		Line:      `{"level": "info", "message": "hello, world"}`,
+	})
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for log entry")
+	case e := <-ch:
+		require.Equal(t, model.LabelSet{"job": "test", "level": "info"}, e.Labels)
+		require.Equal(t, "hello, world", e.Line)
+		require.True(t, ts.Equal(e.Timestamp))
+	}
+}
+
+func TestArguments_MultipleStageTypes(t *testing.T) {
+	cfg := `
+		forward_to = []
+
+		stage {
+			regex {
+				expression = "(?P<level>\\w+)"
+			}
+			output {
+				source = "level"
+			}
+		}
+	`
+	var args process.Arguments
+	err := river.Unmarshal([]byte(cfg), &args)
+	require.EqualError(t, err, "each stage block must contain exactly one stage type, found 2")
+}
+
+func TestArguments_NestedMatchStages(t *testing.T) {
+	cfg := `
+		forward_to = []
+
+		stage {
+			match {
+				selector = "{app=\"nginx\"}"
+
+				stage {
+					regex {
+						expression = "^(?P<method>\\w+) "
+					}
+				}
+
+				stage {
+					labels {
+						values = { method = "" }
+					}
+				}
+			}
+		}
+	`
+	var args process.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	require.Len(t, args.Stages, 1)
+	require.Len(t, args.Stages[0].Match.Stages, 2)
+	require.Equal(t, "^(?P<method>\\w+) ", args.Stages[0].Match.Stages[0].Regex.Expression)
+}
+
+// TestUpdate_BlockedSend ensures that the component can be updated while
+// sending an entry into the pipeline is blocked.
+func TestUpdate_BlockedSend(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "loki.process")
+	require.NoError(t, err)
+
+	// Entries forwarded to blocked are never read, so the pipeline fills up.
+	blocked := make(loki.LogsReceiver)
+	go func() {
+		err := ctrl.Run(ctx, process.Arguments{ForwardTo: []loki.LogsReceiver{blocked}})
+		require.NoError(t, err)
+	}()
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	input := ctrl.Exports().(process.Exports).Receiver
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case input <- loki.NewEntry(model.LabelSet{"job": "test"}, logproto.Entry{Line: "hello"}):
+			}
+		}
+	}()
+
+	// Wait for the receiver to stop accepting entries.
+	require.Eventually(t, func() bool {
+		select {
+		case input <- loki.NewEntry(model.LabelSet{"job": "test"}, logproto.Entry{Line: "hello"}):
+			return false
+		case <-time.After(100 * time.Millisecond):
+			return true
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	updated := make(chan error, 1)
+	go func() {
+		updated <- ctrl.Update(process.Arguments{ForwardTo: []loki.LogsReceiver{make(loki.LogsReceiver)}})
+	}()
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "update blocked on sending entries")
+	case err := <-updated:
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,340 @@
+package process
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/loki/clients/pkg/logentry/stages"
+)
+
+// StageConfig defines a single stage of a processing pipeline. Exactly one of
+// the inner blocks must be set.
+type StageConfig struct {
+	CRI          *CRIConfig          `river:"cri,block,optional"`
+	Docker       *DockerConfig       `river:"docker,block,optional"`
+	Drop         *DropConfig         `river:"drop,block,optional"`
+	JSON         *JSONConfig         `river:"json,block,optional"`
+	LabelAllow   *LabelAllowConfig   `river:"label_keep,block,optional"`
+	LabelDrop    *LabelDropConfig    `river:"label_drop,block,optional"`
+	Labels       *LabelsConfig       `river:"labels,block,optional"`
+	Logfmt       *LogfmtConfig       `river:"logfmt,block,optional"`
+	Match        *MatchConfig        `river:"match,block,optional"`
+	Multiline    *MultilineConfig    `river:"multiline,block,optional"`
+	Output       *OutputConfig       `river:"output,block,optional"`
+	Pack         *PackConfig         `river:"pack,block,optional"`
+	Regex        *RegexConfig        `river:"regex,block,optional"`
+	Replace      *ReplaceConfig      `river:"replace,block,optional"`
+	StaticLabels *StaticLabelsConfig `river:"static_labels,block,optional"`
+	Template     *TemplateConfig     `river:"template,block,optional"`
+	Tenant       *TenantConfig       `river:"tenant,block,optional"`
+	Timestamp    *TimestampConfig    `river:"timestamp,block,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler. It validates that exactly one
+// stage type is set.
+func (sc *StageConfig) UnmarshalRiver(f func(interface{}) error) error {
+	type stageConfig StageConfig
+	if err := f((*stageConfig)(sc)); err != nil {
+		return err
+	}
+
+	var set int
+	for _, isSet := range []bool{
+		sc.CRI != nil, sc.Docker != nil, sc.Drop != nil, sc.JSON != nil,
+		sc.LabelAllow != nil, sc.LabelDrop != nil, sc.Labels != nil,
+		sc.Logfmt != nil, sc.Match != nil, sc.Multiline != nil, sc.Output != nil,
+		sc.Pack != nil, sc.Regex != nil, sc.Replace != nil,
+		sc.StaticLabels != nil, sc.Template != nil, sc.Tenant != nil,
+		sc.Timestamp != nil,
+	} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("each stage block must contain exactly one stage type, found %d", set)
+	}
+	return nil
+}
+
+// CRIConfig configures the cri stage, which parses log lines written in the
+// CRI log format. It has no settings.
+type CRIConfig struct{}
+
+// DockerConfig configures the docker stage, which parses log lines written
+// by the Docker json-file logging driver. It has no settings.
+type DockerConfig struct{}
+
+// DropConfig configures the drop stage.
+type DropConfig struct {
+	Source            string        `river:"source,attr,optional"`
+	Value             string        `river:"value,attr,optional"`
+	Expression        string        `river:"expression,attr,optional"`
+	OlderThan         time.Duration `river:"older_than,attr,optional"`
+	LongerThan        string        `river:"longer_than,attr,optional"`
+	DropCounterReason string        `river:"drop_counter_reason,attr,optional"`
+}
+
+// JSONConfig configures the json stage.
+type JSONConfig struct {
+	Expressions   map[string]string `river:"expressions,attr"`
+	Source        string            `river:"source,attr,optional"`
+	DropMalformed bool              `river:"drop_malformed,attr,optional"`
+}
+
+// LabelAllowConfig configures the label_keep stage.
+type LabelAllowConfig struct {
+	Values []string `river:"values,attr"`
+}
+
+// LabelDropConfig configures the label_drop stage.
+type LabelDropConfig struct {
+	Values []string `river:"values,attr"`
+}
+
+// LabelsConfig configures the labels stage. Keys of Values are label names;
+// values are the name of the extracted field to use as the label value. An
+// empty value uses the field of the same name as the label.
+type LabelsConfig struct {
+	Values map[string]string `river:"values,attr"`
+}
+
+// LogfmtConfig configures the logfmt stage.
+type LogfmtConfig struct {
+	Mapping map[string]string `river:"mapping,attr"`
+	Source  string            `river:"source,attr,optional"`
+}
+
+// MatchConfig configures the match stage. Stages nested in a match block are
+// only run for entries matching the selector.
+type MatchConfig struct {
+	Selector          string        `river:"selector,attr"`
+	Action            string        `river:"action,attr,optional"`
+	PipelineName      string        `river:"pipeline_name,attr,optional"`
+	DropCounterReason string        `river:"drop_counter_reason,attr,optional"`
+	Stages            []StageConfig `river:"stage,block,optional"`
+}
+
+// MultilineConfig configures the multiline stage.
+type MultilineConfig struct {
+	Expression  string        `river:"firstline,attr"`
+	MaxLines    uint64        `river:"max_lines,attr,optional"`
+	MaxWaitTime time.Duration `river:"max_wait_time,attr,optional"`
+}
+
+// OutputConfig configures the output stage.
+type OutputConfig struct {
+	Source string `river:"source,attr"`
+}
+
+// PackConfig configures the pack stage.
+type PackConfig struct {
+	Labels          []string `river:"labels,attr"`
+	IngestTimestamp bool     `river:"ingest_timestamp,attr,optional"`
+}
+
+// DefaultPackConfig holds default settings for the pack stage.
+var DefaultPackConfig = PackConfig{
+	IngestTimestamp: true,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (pc *PackConfig) UnmarshalRiver(f func(interface{}) error) error {
+	*pc = DefaultPackConfig
+
+	type packConfig PackConfig
+	return f((*packConfig)(pc))
+}
+
+// RegexConfig configures the regex stage.
+type RegexConfig struct {
+	Expression string `river:"expression,attr"`
+	Source     string `river:"source,attr,optional"`
+}
+
+// ReplaceConfig configures the replace stage.
+type ReplaceConfig struct {
+	Expression string `river:"expression,attr"`
+	Source     string `river:"source,attr,optional"`
+	Replace    string `river:"replace,attr,optional"`
+}
+
+// StaticLabelsConfig configures the static_labels stage.
+type StaticLabelsConfig struct {
+	Values map[string]string `river:"values,attr"`
+}
+
+// TemplateConfig configures the template stage.
+type TemplateConfig struct {
+	Source   string `river:"source,attr"`
+	Template string `river:"template,attr"`
+}
+
+// TenantConfig configures the tenant stage.
+type TenantConfig struct {
+	Label  string `river:"label,attr,optional"`
+	Source string `river:"source,attr,optional"`
+	Value  string `river:"value,attr,optional"`
+}
+
+// TimestampConfig configures the timestamp stage.
+type TimestampConfig struct {
+	Source          string   `river:"source,attr"`
+	Format          string   `river:"format,attr"`
+	FallbackFormats []string `river:"fallback_formats,attr,optional"`
+	Location        string   `river:"location,attr,optional"`
+	ActionOnFailure string   `river:"action_on_failure,attr,optional"`
+}
+
+// toPipelineStages converts a list of stage configs into the format used by
+// Promtail pipelines.
+func toPipelineStages(scs []StageConfig) stages.PipelineStages {
+	res := make(stages.PipelineStages, 0, len(scs))
+	for _, sc := range scs {
+		name, cfg := sc.convert()
+		res = append(res, stages.PipelineStage{name: cfg})
+	}
+	return res
+}
+
+// convert returns the Promtail stage type and configuration for sc.
+func (sc StageConfig) convert() (string, interface{}) {
+	switch {
+	case sc.CRI != nil:
+		return stages.StageTypeCRI, nil
+
+	case sc.Docker != nil:
+		return stages.StageTypeDocker, nil
+
+	case sc.Drop != nil:
+		cfg := map[string]interface{}{}
+		setOptional(cfg, "source", sc.Drop.Source)
+		setOptional(cfg, "value", sc.Drop.Value)
+		setOptional(cfg, "expression", sc.Drop.Expression)
+		if sc.Drop.OlderThan > 0 {
+			cfg["older_than"] = sc.Drop.OlderThan.String()
+		}
+		setOptional(cfg, "longer_than", sc.Drop.LongerThan)
+		setOptional(cfg, "drop_counter_reason", sc.Drop.DropCounterReason)
+		return stages.StageTypeDrop, cfg
+
+	case sc.JSON != nil:
+		cfg := map[string]interface{}{
+			"expressions":    sc.JSON.Expressions,
+			"drop_malformed": sc.JSON.DropMalformed,
+		}
+		setOptional(cfg, "source", sc.JSON.Source)
+		return stages.StageTypeJSON, cfg
+
+	case sc.LabelAllow != nil:
+		return stages.StageTypeLabelAllow, sc.LabelAllow.Values
+
+	case sc.LabelDrop != nil:
+		return stages.StageTypeLabelDrop, sc.LabelDrop.Values
+
+	case sc.Labels != nil:
+		return stages.StageTypeLabel, labelMapping(sc.Labels.Values)
+
+	case sc.Logfmt != nil:
+		cfg := map[string]interface{}{"mapping": sc.Logfmt.Mapping}
+		setOptional(cfg, "source", sc.Logfmt.Source)
+		return stages.StageTypeLogfmt, cfg
+
+	case sc.Match != nil:
+		cfg := map[string]interface{}{"selector": sc.Match.Selector}
+		setOptional(cfg, "action", sc.Match.Action)
+		setOptional(cfg, "pipeline_name", sc.Match.PipelineName)
+		setOptional(cfg, "drop_counter_reason", sc.Match.DropCounterReason)
+		if len(sc.Match.Stages) > 0 {
+			cfg["stages"] = toPipelineStages(sc.Match.Stages)
+		}
+		return stages.StageTypeMatch, cfg
+
+	case sc.Multiline != nil:
+		cfg := map[string]interface{}{"firstline": sc.Multiline.Expression}
+		if sc.Multiline.MaxLines > 0 {
+			cfg["max_lines"] = sc.Multiline.MaxLines
+		}
+		if sc.Multiline.MaxWaitTime > 0 {
+			cfg["max_wait_time"] = sc.Multiline.MaxWaitTime.String()
+		}
+		return stages.StageTypeMultiline, cfg
+
+	case sc.Output != nil:
+		return stages.StageTypeOutput, map[string]interface{}{"source": sc.Output.Source}
+
+	case sc.Pack != nil:
+		return stages.StageTypePack, map[string]interface{}{
+			"labels":           sc.Pack.Labels,
+			"ingest_timestamp": sc.Pack.IngestTimestamp,
+		}
+
+	case sc.Regex != nil:
+		cfg := map[string]interface{}{"expression": sc.Regex.Expression}
+		setOptional(cfg, "source", sc.Regex.Source)
+		return stages.StageTypeRegex, cfg
+
+	case sc.Replace != nil:
+		cfg := map[string]interface{}{
+			"expression": sc.Replace.Expression,
+			"replace":    sc.Replace.Replace,
+		}
+		setOptional(cfg, "source", sc.Replace.Source)
+		return stages.StageTypeReplace, cfg
+
+	case sc.StaticLabels != nil:
+		return stages.StageTypeStaticLabels, labelMapping(sc.StaticLabels.Values)
+
+	case sc.Template != nil:
+		return stages.StageTypeTemplate, map[string]interface{}{
+			"source":   sc.Template.Source,
+			"template": sc.Template.Template,
+		}
+
+	case sc.Tenant != nil:
+		return stages.StageTypeTenant, map[string]interface{}{
+			"label":  sc.Tenant.Label,
+			"source": sc.Tenant.Source,
+			"value":  sc.Tenant.Value,
+		}
+
+	case sc.Timestamp != nil:
+		cfg := map[string]interface{}{
+			"source": sc.Timestamp.Source,
+			"format": sc.Timestamp.Format,
+		}
+		if len(sc.Timestamp.FallbackFormats) > 0 {
+			cfg["fallback_formats"] = sc.Timestamp.FallbackFormats
+		}
+		setOptional(cfg, "location", sc.Timestamp.Location)
+		setOptional(cfg, "action_on_failure", sc.Timestamp.ActionOnFailure)
+		return stages.StageTypeTimestamp, cfg
+	}
+
+	// Unreachable: StageConfig.UnmarshalRiver guarantees that one stage type
+	// is set.
+	panic("stage config does not contain any stage type")
+}
+
+// setOptional sets key in cfg to value if value is non-empty. Promtail
+// distinguishes between unset and empty values for optional fields.
+func setOptional(cfg map[string]interface{}, key, value string) {
+	if value != "" {
+		cfg[key] = value
+	}
+}
+
+// labelMapping converts a map of label names into the format used by Promtail,
+// where a nil value indicates that the value should be taken from the
+// extracted field of the same name.
+func labelMapping(in map[string]string) map[string]interface{} {
+	res := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		if v == "" {
+			res[k] = nil
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
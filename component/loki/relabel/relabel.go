@@ -0,0 +1,150 @@
+// Package relabel implements the loki.relabel component.
+package relabel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	flow_relabel "github.com/grafana/agent/component/common/relabel"
+	"github.com/grafana/agent/component/loki"
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "loki.relabel",
+		Args:    Arguments{},
+		Exports: Exports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the loki.relabel
+// component.
+type Arguments struct {
+	// Where the relabelled log entries should be forwarded to.
+	ForwardTo []loki.LogsReceiver `river:"forward_to,attr"`
+
+	// The relabelling rules to apply to each log entry before it's forwarded.
+	RelabelConfigs []*flow_relabel.Config `river:"rule,block,optional"`
+}
+
+// Exports holds values which are exported by the loki.relabel component.
+type Exports struct {
+	Receiver loki.LogsReceiver `river:"receiver,attr"`
+}
+
+// Component implements the loki.relabel component.
+type Component struct {
+	opts     component.Options
+	receiver loki.LogsReceiver
+
+	mut       sync.RWMutex
+	rcs       []*relabel.Config
+	forwardto []loki.LogsReceiver
+
+	entriesProcessed prometheus_client.Counter
+	entriesDropped   prometheus_client.Counter
+}
+
+var (
+	_ component.Component = (*Component)(nil)
+)
+
+// New creates a new loki.relabel component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:     o,
+		receiver: make(loki.LogsReceiver),
+
+		entriesProcessed: prometheus_client.NewCounter(prometheus_client.CounterOpts{
+			Name: "agent_loki_relabel_entries_processed",
+			Help: "Total number of log entries processed",
+		}),
+		entriesDropped: prometheus_client.NewCounter(prometheus_client.CounterOpts{
+			Name: "agent_loki_relabel_entries_dropped",
+			Help: "Total number of log entries dropped because all of their labels were removed",
+		}),
+	}
+	for _, m := range []prometheus_client.Collector{c.entriesProcessed, c.entriesDropped} {
+		if err := o.Registerer.Register(m); err != nil {
+			return nil, err
+		}
+	}
+
+	// Call to Update() to set the relabelling rules once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.receiver:
+			c.mut.RLock()
+			rcs, forwardto := c.rcs, c.forwardto
+			c.mut.RUnlock()
+
+			c.entriesProcessed.Inc()
+			lset := relabelLabels(entry.Labels, rcs)
+			if len(lset) == 0 {
+				c.entriesDropped.Inc()
+				continue
+			}
+			entry.Labels = lset
+
+			for _, receiver := range forwardto {
+				select {
+				case <-ctx.Done():
+					return nil
+				case receiver <- entry.Clone():
+				}
+			}
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	newArgs := args.(Arguments)
+
+	c.rcs = flow_relabel.ComponentToPromRelabelConfigs(newArgs.RelabelConfigs)
+	c.forwardto = newArgs.ForwardTo
+	c.opts.OnStateChange(Exports{Receiver: c.receiver})
+
+	return nil
+}
+
+// relabelLabels applies relabelling rules to a set of log labels. An empty
+// set of labels is returned if the rules dropped the entry.
+func relabelLabels(ls model.LabelSet, rcs []*relabel.Config) model.LabelSet {
+	if len(rcs) == 0 {
+		return ls
+	}
+
+	lb := labels.NewBuilder(nil)
+	for k, v := range ls {
+		lb.Set(string(k), string(v))
+	}
+
+	processed := relabel.Process(lb.Labels(), rcs...)
+	res := make(model.LabelSet, len(processed))
+	for _, l := range processed {
+		res[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+	}
+	return res
+}
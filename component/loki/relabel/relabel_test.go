@@ -0,0 +1,60 @@
+package relabel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelabel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := `
+		forward_to = []
+
+		rule {
+			source_labels = ["level"]
+			regex         = "debug"
+			action        = "drop"
+		}
+
+		rule {
+			source_labels = ["app"]
+			target_label  = "service"
+		}
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	ch := make(loki.LogsReceiver)
+	args.ForwardTo = []loki.LogsReceiver{ch}
+
+	c, err := New(component.Options{
+		Logger:        util.TestLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+	}, args)
+	require.NoError(t, err)
+	go func() { require.NoError(t, c.Run(ctx)) }()
+
+	c.receiver <- loki.NewEntry(model.LabelSet{"app": "frontend", "level": "debug"}, logproto.Entry{Line: "dropped"})
+	c.receiver <- loki.NewEntry(model.LabelSet{"app": "frontend", "level": "info"}, logproto.Entry{Line: "kept"})
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for log entry")
+	case e := <-ch:
+		require.Equal(t, "kept", e.Line)
+		require.Equal(t, model.LabelSet{"app": "frontend", "level": "info", "service": "frontend"}, e.Labels)
+	}
+}
@@ -0,0 +1,203 @@
+// Package file implements the loki.source.file component.
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/loki/clients/pkg/promtail/positions"
+	"github.com/prometheus/common/model"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "loki.source.file",
+		Args:    Arguments{},
+		Exports: nil,
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+const (
+	// pathLabel is the label of a target which holds the path of the file to
+	// tail.
+	pathLabel = "__path__"
+
+	// filenameLabel is the label added to every entry read from a file,
+	// holding the path of that file.
+	filenameLabel = "filename"
+
+	// positionsSyncPeriod is how often positions are flushed to disk.
+	positionsSyncPeriod = 10 * time.Second
+
+	// resyncPeriod is how often targets are checked for files which could not
+	// be tailed previously (for example, because they didn't exist yet).
+	resyncPeriod = 10 * time.Second
+)
+
+// Arguments holds values which are used to configure the loki.source.file
+// component.
+type Arguments struct {
+	Targets   []discovery.Target  `river:"targets,attr"`
+	ForwardTo []loki.LogsReceiver `river:"forward_to,attr"`
+}
+
+// Component implements the loki.source.file component.
+type Component struct {
+	opts component.Options
+
+	mut       sync.RWMutex
+	args      Arguments
+	handler   loki.LogsReceiver
+	positions positions.Positions
+	tailers   map[string]*tailer
+}
+
+var (
+	_ component.Component = (*Component)(nil)
+)
+
+// New creates a new loki.source.file component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	if err := os.MkdirAll(o.DataPath, 0750); err != nil {
+		return nil, err
+	}
+	positionsFile, err := positions.New(o.Logger, positions.Config{
+		SyncPeriod:    positionsSyncPeriod,
+		PositionsFile: filepath.Join(o.DataPath, "positions.yml"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		opts: o,
+
+		handler:   make(loki.LogsReceiver),
+		positions: positionsFile,
+		tailers:   make(map[string]*tailer),
+	}
+
+	// Call to Update() to start tailing the initial set of targets.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component. Run forwards entries read from files to
+// the receivers configured in forward_to.
+func (c *Component) Run(ctx context.Context) error {
+	defer func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		for path, t := range c.tailers {
+			t.stop()
+			delete(c.tailers, path)
+		}
+		c.positions.Stop()
+	}()
+
+	resync := time.NewTicker(resyncPeriod)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-resync.C:
+			c.mut.Lock()
+			c.syncTailers()
+			c.mut.Unlock()
+
+		case entry := <-c.handler:
+			c.mut.RLock()
+			receivers := c.args.ForwardTo
+			c.mut.RUnlock()
+
+			for _, receiver := range receivers {
+				select {
+				case <-ctx.Done():
+					return nil
+				case receiver <- entry.Clone():
+				}
+			}
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.args = newArgs
+	c.syncTailers()
+	return nil
+}
+
+// syncTailers starts tailing files for targets which aren't being tailed yet
+// and stops tailing files which are no longer part of the set of targets.
+// syncTailers must be called while holding the write lock.
+func (c *Component) syncTailers() {
+	wanted := make(map[string]model.LabelSet, len(c.args.Targets))
+	for _, target := range c.args.Targets {
+		path, ok := target[pathLabel]
+		if !ok || path == "" {
+			level.Warn(c.opts.Logger).Log("msg", "ignoring target without a path", "target", fmt.Sprintf("%v", target))
+			continue
+		}
+		wanted[path] = targetLabels(target, path)
+	}
+
+	for path, t := range c.tailers {
+		// Tailers whose labels changed are restarted so new entries use the new
+		// set of labels. Tailers which stopped on their own (for example, due to
+		// an error reading the file) are restarted as well.
+		if labels, ok := wanted[path]; ok && labels.Equal(t.labels) && t.isRunning() {
+			continue
+		}
+		t.stop()
+		delete(c.tailers, path)
+	}
+
+	for path, labels := range wanted {
+		if _, ok := c.tailers[path]; ok {
+			continue
+		}
+
+		t, err := newTailer(c.opts.Logger, c.handler, c.positions, path, labels)
+		if err != nil {
+			level.Error(c.opts.Logger).Log("msg", "failed to tail file", "path", path, "err", err)
+			continue
+		}
+		c.tailers[path] = t
+	}
+}
+
+// targetLabels returns the set of labels to attach to entries read from the
+// file of a target. Labels starting with a double underscore are removed.
+func targetLabels(target discovery.Target, path string) model.LabelSet {
+	labels := make(model.LabelSet, len(target)+1)
+	for k, v := range target {
+		if len(k) >= 2 && k[:2] == "__" {
+			continue
+		}
+		labels[model.LabelName(k)] = model.LabelValue(v)
+	}
+	labels[filenameLabel] = model.LabelValue(path)
+	return labels
+}
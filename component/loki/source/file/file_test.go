@@ -0,0 +1,130 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/grafana/loki/clients/pkg/promtail/positions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func Test(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dataPath := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "test.log")
+	require.NoError(t, os.WriteFile(logPath, []byte("first line\n"), 0644))
+
+	opts := component.Options{
+		Logger:        util.TestLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      dataPath,
+	}
+
+	ch := make(loki.LogsReceiver)
+	args := Arguments{
+		Targets: []discovery.Target{{
+			"__path__":   logPath,
+			"__meta_foo": "bar",
+			"job":        "test",
+		}},
+		ForwardTo: []loki.LogsReceiver{ch},
+	}
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		require.NoError(t, c.Run(ctx))
+	}()
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("second line\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	expectLabels := model.LabelSet{"job": "test", "filename": model.LabelValue(logPath)}
+	for _, expectLine := range []string{"first line", "second line"} {
+		select {
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for log line")
+		case e := <-ch:
+			require.Equal(t, expectLabels, e.Labels)
+			require.Equal(t, expectLine, e.Line)
+		}
+	}
+
+	// Stopping the component should record how far the file was read.
+	cancel()
+	<-runDone
+
+	positionsFile, err := os.ReadFile(filepath.Join(dataPath, "positions.yml"))
+	require.NoError(t, err)
+	require.Contains(t, string(positionsFile), logPath)
+	require.Contains(t, string(positionsFile), `"23"`)
+}
+
+func TestTargetLabels(t *testing.T) {
+	labels := targetLabels(discovery.Target{
+		"__path__":        "/var/log/app.log",
+		"__address__":     "localhost",
+		"app":             "frontend",
+		"_not_meta_label": "kept",
+	}, "/var/log/app.log")
+
+	require.Equal(t, model.LabelSet{
+		"app":             "frontend",
+		"_not_meta_label": "kept",
+		"filename":        "/var/log/app.log",
+	}, labels)
+}
+
+func TestTailer_StopRecordsDeliveredPosition(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "test.log")
+	require.NoError(t, os.WriteFile(logPath, []byte("first line\nsecond line\nthird line\n"), 0644))
+
+	logger := util.TestLogger(t)
+	pos, err := positions.New(logger, positions.Config{
+		SyncPeriod:    time.Hour,
+		PositionsFile: filepath.Join(t.TempDir(), "positions.yml"),
+	})
+	require.NoError(t, err)
+	defer pos.Stop()
+
+	handler := make(loki.LogsReceiver)
+	tailer, err := newTailer(logger, handler, pos, logPath, model.LabelSet{})
+	require.NoError(t, err)
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for log line")
+	case e := <-handler:
+		require.Equal(t, "first line", e.Line)
+	}
+
+	// The remaining lines are read from the file but never received from the
+	// handler, so only the first line counts as read.
+	require.Eventually(t, func() bool {
+		p, err := tailer.tail.Tell()
+		return err == nil && p > int64(len("first line\n"))
+	}, 5*time.Second, 10*time.Millisecond)
+	tailer.stop()
+
+	recorded, err := pos.Get(logPath)
+	require.NoError(t, err)
+	require.Equal(t, int64(len("first line\n")), recorded)
+}
@@ -0,0 +1,213 @@
+package file
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/loki/clients/pkg/promtail/positions"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/hpcloud/tail"
+	"github.com/prometheus/common/model"
+	"go.uber.org/atomic"
+)
+
+// tailer tails a single file, sending each line as an entry to a handler and
+// periodically recording how far the file has been read.
+type tailer struct {
+	logger    log.Logger
+	handler   loki.LogsReceiver
+	positions positions.Positions
+	path      string
+	labels    model.LabelSet
+
+	tail      *tail.Tail
+	running   *atomic.Bool
+	delivered *atomic.Int64 // Offset just past the last line sent to the handler.
+
+	posAndSizeMtx sync.Mutex
+	stopOnce      sync.Once
+
+	quit    chan struct{} // Closed to stop sending entries to the handler.
+	posquit chan struct{} // Closed to stop the position marker goroutine.
+	posdone chan struct{} // Closed once the position marker goroutine exits.
+	done    chan struct{} // Closed once the reader goroutine exits.
+}
+
+func newTailer(logger log.Logger, handler loki.LogsReceiver, positions positions.Positions, path string, labels model.LabelSet) (*tailer, error) {
+	// Make sure the file we are tailing doesn't have a position already saved
+	// which is past the end of the file; this happens when a file gets
+	// truncated while we weren't watching it.
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := positions.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() < pos {
+		positions.Remove(path)
+		pos = 0
+	}
+
+	tail, err := tail.TailFile(path, tail.Config{
+		Follow:    true,
+		Poll:      true,
+		ReOpen:    true,
+		MustExist: true,
+		Location: &tail.SeekInfo{
+			Offset: pos,
+			Whence: 0,
+		},
+		Logger: tail.DiscardingLogger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tailer{
+		logger:    log.With(logger, "path", path),
+		handler:   handler,
+		positions: positions,
+		path:      path,
+		labels:    labels,
+
+		tail:      tail,
+		running:   atomic.NewBool(true),
+		delivered: atomic.NewInt64(pos),
+
+		quit:    make(chan struct{}),
+		posquit: make(chan struct{}),
+		posdone: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go t.readLines()
+	go t.updatePosition()
+	level.Info(t.logger).Log("msg", "started tailing file")
+	return t, nil
+}
+
+// updatePosition periodically records the current position of the tailer.
+// If the position can't be determined, the tailer is stopped; it will be
+// restarted from the last recorded position the next time targets are
+// synced.
+func (t *tailer) updatePosition() {
+	ticker := time.NewTicker(t.positions.SyncPeriod())
+	defer func() {
+		ticker.Stop()
+		close(t.posdone)
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.markPosition(); err != nil {
+				level.Error(t.logger).Log("msg", "failed to get tail position, stopping tailer", "err", err)
+				if err := t.tail.Stop(); err != nil {
+					level.Error(t.logger).Log("msg", "failed to stop tailer", "err", err)
+				}
+				return
+			}
+		case <-t.posquit:
+			return
+		}
+	}
+}
+
+// readLines consumes lines from the underlying tailer until its channel is
+// closed. The channel must always be drained, otherwise stopping the
+// underlying tailer would block forever.
+func (t *tailer) readLines() {
+	defer func() {
+		t.running.Store(false)
+		close(t.done)
+	}()
+
+	for line := range t.tail.Lines {
+		if line.Err != nil {
+			level.Error(t.logger).Log("msg", "failed to read line", "err", line.Err)
+			continue
+		}
+
+		entry := loki.NewEntry(t.labels, logproto.Entry{
+			Timestamp: line.Time,
+			Line:      line.Text,
+		})
+
+		select {
+		case <-t.quit:
+			// The tailer is stopping; drop the line and keep draining. Dropped
+			// lines are after the recorded position, so they're read again the
+			// next time the file is tailed.
+		case t.handler <- entry:
+			// Lines are read up to and including the newline, which is trimmed
+			// from the text.
+			t.delivered.Add(int64(len(line.Text)) + 1)
+		}
+	}
+}
+
+// markPosition records the offset just past the last line sent to the
+// handler. Lines which were read from the file but not sent yet aren't
+// included, so they aren't lost if the tailer stops before sending them.
+func (t *tailer) markPosition() error {
+	t.posAndSizeMtx.Lock()
+	defer t.posAndSizeMtx.Unlock()
+
+	if _, err := t.tail.Size(); err != nil {
+		// There's no position to record for a file which no longer exists.
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	pos, err := t.tail.Tell()
+	if err != nil {
+		return err
+	}
+
+	// The reader is always at or past the last line which was sent, unless
+	// the file was reopened after being truncated or rotated. The count of
+	// sent bytes no longer matches the file in that case, so the position of
+	// the reader is used instead.
+	if delivered := t.delivered.Load(); delivered <= pos {
+		pos = delivered
+	}
+	t.positions.Put(t.path, pos)
+	return nil
+}
+
+// isRunning returns true if the tailer is still reading lines.
+func (t *tailer) isRunning() bool {
+	return t.running.Load()
+}
+
+// stop stops the tailer, recording its final position. stop is safe to call
+// multiple times.
+func (t *tailer) stop() {
+	t.stopOnce.Do(func() {
+		close(t.posquit)
+		<-t.posdone
+
+		// Stop sending lines before recording the final position so that lines
+		// which are dropped while stopping are read again the next time the file
+		// is tailed. stop can't wait for the remaining lines to be sent since the
+		// handler may not be read from while stopping.
+		close(t.quit)
+		if err := t.markPosition(); err != nil {
+			level.Error(t.logger).Log("msg", "failed to record position when stopping tailer", "err", err)
+		}
+
+		if err := t.tail.Stop(); err != nil {
+			level.Error(t.logger).Log("msg", "failed to stop tailer", "err", err)
+		}
+		<-t.done
+		level.Info(t.logger).Log("msg", "stopped tailing file")
+	})
+}
@@ -0,0 +1,33 @@
+// Package loki contains shared types used by the loki.* family of Flow
+// components.
+package loki
+
+import (
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/prometheus/common/model"
+)
+
+// LogsReceiver is used to pass log entries between components. Components
+// which send log entries write to the channel; the component which exported
+// the LogsReceiver is responsible for reading from it.
+type LogsReceiver chan Entry
+
+// RiverCapsule marks LogsReceiver as a capsule.
+func (LogsReceiver) RiverCapsule() {}
+
+// Entry is a log entry with labels. Its layout matches Promtail's api.Entry so
+// that the two types can be converted between each other.
+type Entry struct {
+	Labels model.LabelSet
+	logproto.Entry
+}
+
+// NewEntry creates a new Entry from a set of labels and a log line.
+func NewEntry(labels model.LabelSet, entry logproto.Entry) Entry {
+	return Entry{Labels: labels, Entry: entry}
+}
+
+// Clone returns a copy of the entry whose labels can be safely modified.
+func (e Entry) Clone() Entry {
+	return Entry{Labels: e.Labels.Clone(), Entry: e.Entry}
+}
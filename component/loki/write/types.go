@@ -0,0 +1,94 @@
+package write
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	types "github.com/grafana/agent/component/common/config"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/loki/clients/pkg/promtail/client"
+	lokiflag "github.com/grafana/loki/pkg/util/flagext"
+	"github.com/prometheus/common/model"
+)
+
+// Defaults for config blocks.
+var (
+	DefaultEndpointOptions = EndpointOptions{
+		BatchWait:         client.BatchWait,
+		BatchSize:         client.BatchSize,
+		RemoteTimeout:     10 * time.Second,
+		MinBackoff:        client.MinBackoff,
+		MaxBackoff:        client.MaxBackoff,
+		MaxBackoffRetries: client.MaxRetries,
+	}
+)
+
+// EndpointOptions describes an individual location to send logs to.
+type EndpointOptions struct {
+	Name              string                  `river:"name,attr,optional"`
+	URL               string                  `river:"url,attr"`
+	BatchWait         time.Duration           `river:"batch_wait,attr,optional"`
+	BatchSize         int                     `river:"batch_size,attr,optional"`
+	HTTPClientConfig  *types.HTTPClientConfig `river:"http_client_config,block,optional"`
+	RemoteTimeout     time.Duration           `river:"remote_timeout,attr,optional"`
+	MinBackoff        time.Duration           `river:"min_backoff_period,attr,optional"`
+	MaxBackoff        time.Duration           `river:"max_backoff_period,attr,optional"`
+	MaxBackoffRetries int                     `river:"max_backoff_retries,attr,optional"`
+	TenantID          string                  `river:"tenant_id,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (r *EndpointOptions) UnmarshalRiver(f func(v interface{}) error) error {
+	*r = DefaultEndpointOptions
+
+	type arguments EndpointOptions
+	if err := f((*arguments)(r)); err != nil {
+		return err
+	}
+
+	switch {
+	case r.BatchSize <= 0:
+		return fmt.Errorf("batch_size must be greater than zero")
+	case r.BatchWait <= 0:
+		return fmt.Errorf("batch_wait must be greater than zero")
+	case r.MaxBackoff < r.MinBackoff:
+		return fmt.Errorf("min_backoff_period must not be greater than max_backoff_period")
+	}
+	return nil
+}
+
+// convertClientConfigs converts the endpoints of the component into configs
+// for Promtail clients.
+func convertClientConfigs(args Arguments) ([]client.Config, error) {
+	externalLabels := make(model.LabelSet, len(args.ExternalLabels))
+	for k, v := range args.ExternalLabels {
+		externalLabels[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	cfgs := make([]client.Config, 0, len(args.Endpoints))
+	for _, ep := range args.Endpoints {
+		parsedURL, err := url.Parse(ep.URL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse endpoint url %q: %w", ep.URL, err)
+		}
+
+		cfgs = append(cfgs, client.Config{
+			Name:      ep.Name,
+			URL:       flagext.URLValue{URL: parsedURL},
+			BatchWait: ep.BatchWait,
+			BatchSize: ep.BatchSize,
+			Client:    *ep.HTTPClientConfig.Convert(),
+			BackoffConfig: backoff.Config{
+				MinBackoff: ep.MinBackoff,
+				MaxBackoff: ep.MaxBackoff,
+				MaxRetries: ep.MaxBackoffRetries,
+			},
+			ExternalLabels: lokiflag.LabelSet{LabelSet: externalLabels},
+			Timeout:        ep.RemoteTimeout,
+			TenantID:       ep.TenantID,
+		})
+	}
+	return cfgs, nil
+}
@@ -0,0 +1,180 @@
+// Package write implements the loki.write component.
+package write
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/loki/clients/pkg/promtail/api"
+	"github.com/grafana/loki/clients/pkg/promtail/client"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "loki.write",
+		Args:    Arguments{},
+		Exports: Exports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the loki.write
+// component.
+type Arguments struct {
+	Endpoints      []EndpointOptions `river:"endpoint,block,optional"`
+	ExternalLabels map[string]string `river:"external_labels,attr,optional"`
+}
+
+// Exports holds the receiver that is used to send log entries to the
+// loki.write component.
+type Exports struct {
+	Receiver loki.LogsReceiver `river:"receiver,attr"`
+}
+
+// Component implements the loki.write component.
+type Component struct {
+	opts     component.Options
+	metrics  *client.Metrics
+	receiver loki.LogsReceiver
+
+	// mut guards endpoints. Entries are sent to a snapshot of endpoints
+	// without holding mut, so updating doesn't wait for a blocked send.
+	mut       sync.RWMutex
+	endpoints []*endpoint
+
+	// stopping tracks clients removed by Update which are still draining.
+	stopping sync.WaitGroup
+}
+
+// endpoint is a client along with the config it was created from.
+type endpoint struct {
+	cfg    client.Config
+	client client.Client
+	sender *loki.GuardedHandler
+}
+
+// stop stops sending entries to the client and stops the client, waiting for
+// it to send its pending batches.
+func (e *endpoint) stop() {
+	e.sender.Close()
+	e.client.Stop()
+}
+
+var (
+	_ component.Component = (*Component)(nil)
+)
+
+// New creates a new loki.write component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:     o,
+		metrics:  client.NewMetrics(o.Registerer, nil),
+		receiver: make(loki.LogsReceiver),
+	}
+
+	// Call to Update() to start the clients once at the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	o.OnStateChange(Exports{Receiver: c.receiver})
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	defer func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		// Give clients a chance to flush any buffered entries before exiting.
+		for _, e := range c.endpoints {
+			e.stop()
+		}
+		c.endpoints = nil
+		c.stopping.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.receiver:
+			c.mut.RLock()
+			endpoints := c.endpoints
+			c.mut.RUnlock()
+
+			for _, e := range endpoints {
+				// Entries for endpoints which were removed in the meantime are
+				// dropped.
+				if !e.sender.Send(ctx, api.Entry(entry)) && ctx.Err() != nil {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	cfgs, err := convertClientConfigs(newArgs)
+	if err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	// Clients of endpoints which didn't change are kept, so that their
+	// pending batches aren't interrupted.
+	var (
+		endpoints = make([]*endpoint, len(cfgs))
+		kept      = make([]bool, len(c.endpoints))
+		started   []client.Client
+	)
+	for i, cfg := range cfgs {
+		for j, old := range c.endpoints {
+			if !kept[j] && reflect.DeepEqual(cfg, old.cfg) {
+				endpoints[i], kept[j] = old, true
+				break
+			}
+		}
+		if endpoints[i] != nil {
+			continue
+		}
+
+		cl, err := client.New(c.metrics, cfg, nil, log.With(c.opts.Logger, "endpoint", cfg.URL.String()))
+		if err != nil {
+			for _, cl := range started {
+				cl.StopNow()
+			}
+			return err
+		}
+		endpoints[i] = &endpoint{cfg: cfg, client: cl, sender: loki.NewGuardedHandler(cl)}
+		started = append(started, cl)
+	}
+
+	// Removed clients are stopped in the background so they can drain
+	// batches which haven't been sent yet without blocking the update.
+	for j, e := range c.endpoints {
+		if kept[j] {
+			continue
+		}
+		c.stopping.Add(1)
+		go func(e *endpoint) {
+			defer c.stopping.Done()
+			e.stop()
+		}(e)
+	}
+
+	c.endpoints = endpoints
+	return nil
+}
@@ -0,0 +1,175 @@
+package write_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/agent/component/loki"
+	"github.com/grafana/agent/component/loki/write"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+// Test performs a basic integration test which runs the loki.write component
+// and ensures that it can push log entries to a Loki endpoint.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	type pushed struct {
+		tenant string
+		req    logproto.PushRequest
+	}
+	pushCh := make(chan pushed, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		buf, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+
+		var req logproto.PushRequest
+		require.NoError(t, req.Unmarshal(buf))
+
+		pushCh <- pushed{tenant: r.Header.Get("X-Scope-OrgID"), req: req}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctrl, err := componenttest.NewControllerFromID(l, "loki.write")
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+		endpoint {
+			url        = "%s/loki/api/v1/push"
+			batch_wait = "10ms"
+			tenant_id  = "tenant-1"
+		}
+
+		external_labels = { cluster = "local" }
+	`, srv.URL)
+	var args write.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	input := ctrl.Exports().(write.Exports).Receiver
+
+	ts := time.Now().Truncate(time.Millisecond)
+	input <- loki.NewEntry(model.LabelSet{"job": "test"}, logproto.Entry{
+		Timestamp: ts,
+		Line:      "hello, world",
+	})
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for push request")
+	case p := <-pushCh:
+		require.Equal(t, "tenant-1", p.tenant)
+		require.Len(t, p.req.Streams, 1)
+		require.Equal(t, `{cluster="local", job="test"}`, p.req.Streams[0].Labels)
+		require.Len(t, p.req.Streams[0].Entries, 1)
+		require.Equal(t, "hello, world", p.req.Streams[0].Entries[0].Line)
+		require.True(t, ts.Equal(p.req.Streams[0].Entries[0].Timestamp))
+	}
+}
+
+func TestEndpointOptions_Defaults(t *testing.T) {
+	cfg := `
+		endpoint {
+			url = "http://localhost:3100/loki/api/v1/push"
+		}
+	`
+	var args write.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	require.Len(t, args.Endpoints, 1)
+	require.Equal(t, write.DefaultEndpointOptions.BatchSize, args.Endpoints[0].BatchSize)
+	require.Equal(t, time.Second, args.Endpoints[0].BatchWait)
+	require.Equal(t, 10, args.Endpoints[0].MaxBackoffRetries)
+}
+
+func TestUpdate_DrainsClients(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	// The first push fails, so that batches are only delivered if they're
+	// retried while draining.
+	var (
+		tenants  = make(chan string, 10)
+		requests atomic.Int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Inc() == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		tenants <- r.Header.Get("X-Scope-OrgID")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctrl, err := componenttest.NewControllerFromID(l, "loki.write")
+	require.NoError(t, err)
+
+	// Batches are only sent when clients are stopped.
+	argsForTenant := func(tenant string) write.Arguments {
+		cfg := fmt.Sprintf(`
+			endpoint {
+				url        = "%s/loki/api/v1/push"
+				batch_wait         = "1h"
+				min_backoff_period = "10ms"
+				tenant_id          = "%s"
+			}
+		`, srv.URL, tenant)
+		var args write.Arguments
+		require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+		return args
+	}
+
+	go func() {
+		err := ctrl.Run(ctx, argsForTenant("tenant-1"))
+		require.NoError(t, err)
+	}()
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	input := ctrl.Exports().(write.Exports).Receiver
+
+	// The receiver is unbuffered, so once the second entry is received the
+	// first one has been handed to the client.
+	for i := 0; i < 2; i++ {
+		input <- loki.NewEntry(model.LabelSet{"job": "test"}, logproto.Entry{
+			Timestamp: time.Now(),
+			Line:      "hello, world",
+		})
+	}
+
+	// Clients of unchanged endpoints are kept with their pending batches.
+	require.NoError(t, ctrl.Update(argsForTenant("tenant-1")))
+	select {
+	case tenant := <-tenants:
+		require.FailNow(t, "unexpected push", "tenant %s", tenant)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Clients of changed endpoints drain their pending batches.
+	require.NoError(t, ctrl.Update(argsForTenant("tenant-2")))
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for push request")
+	case tenant := <-tenants:
+		require.Equal(t, "tenant-1", tenant)
+	}
+}
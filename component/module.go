@@ -0,0 +1,39 @@
+package component
+
+import (
+	"context"
+	"net/http"
+)
+
+// ModuleController is a mechanism which allows components to create and run
+// nested Flow controllers, called modules. A ModuleController is provided to
+// components through Options.
+type ModuleController interface {
+	// NewModule creates a new, un-started Module. The id must be unique across
+	// all modules created by the same ModuleController; an empty id may be used
+	// by components which only ever create a single module.
+	//
+	// export is invoked whenever the set of exports of the module changes.
+	NewModule(id string, export ExportFunc) (Module, error)
+}
+
+// Module is a nested Flow controller created by a ModuleController.
+type Module interface {
+	// LoadConfig parses River config and loads it into the Module. args holds
+	// the values for the argument blocks declared by the config. LoadConfig may
+	// be called multiple times throughout the lifetime of the Module.
+	LoadConfig(config []byte, args map[string]any) error
+
+	// Run starts the Module, blocking until ctx is canceled. All components
+	// running in the Module are stopped before Run returns.
+	Run(ctx context.Context)
+
+	// ComponentHandler returns an HTTP handler which routes requests to
+	// components running within the Module. Requests must have their path
+	// trimmed such that the path starts with the ID of the target component.
+	ComponentHandler() http.Handler
+}
+
+// ExportFunc is used for a Module to export values to the component which
+// created it.
+type ExportFunc func(exports map[string]any)
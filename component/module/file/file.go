@@ -0,0 +1,170 @@
+// Package file implements the module.file component, which runs a Flow module
+// loaded from a file on disk.
+package file
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/local/file"
+	"github.com/grafana/agent/component/module"
+	"github.com/grafana/agent/pkg/river"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "module.file",
+		Args:    Arguments{},
+		Exports: module.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the module.file
+// component.
+type Arguments struct {
+	// Filename indicates the file to load the module from.
+	Filename string `river:"filename,attr"`
+	// Type indicates how to detect changes to the file.
+	Type file.Detector `river:"detector,attr,optional"`
+	// PollFrequency determines the frequency to check for changes when Type is
+	// poll.
+	PollFrequency time.Duration `river:"poll_frequency,attr,optional"`
+	// IsSecret marks the file as holding a secret value which should not be
+	// displayed to the user.
+	IsSecret bool `river:"is_secret,attr,optional"`
+
+	// Arguments to pass into the module.
+	Arguments map[string]any `river:"arguments,attr,optional"`
+}
+
+// DefaultArguments provides the default arguments for the module.file
+// component.
+var DefaultArguments = Arguments{
+	Type:          file.DefaultArguments.Type,
+	PollFrequency: file.DefaultArguments.PollFrequency,
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type arguments Arguments
+	return f((*arguments)(a))
+}
+
+// fileArguments returns the arguments for the inner local.file component.
+func (a Arguments) fileArguments() file.Arguments {
+	return file.Arguments{
+		Filename:      a.Filename,
+		Type:          a.Type,
+		PollFrequency: a.PollFrequency,
+		IsSecret:      a.IsSecret,
+	}
+}
+
+// Component implements the module.file component.
+type Component struct {
+	*module.Component
+
+	file *file.Component
+
+	mut     sync.Mutex
+	args    Arguments
+	content string
+}
+
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.HealthComponent = (*Component)(nil)
+	_ component.HTTPComponent   = (*Component)(nil)
+)
+
+// New creates a new module.file component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	mc, err := module.NewComponent(o)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		Component: mc,
+		args:      args,
+	}
+
+	// The inner local.file component reports its content through its own
+	// OnStateChange, which is used to reload the module.
+	fileOpts := o
+	fileOpts.OnStateChange = c.onContentUpdate
+	c.file, err = file.New(fileOpts, args.fileArguments())
+	if err != nil {
+		return nil, err
+	}
+
+	// Loading the module content is done on every update of the file; the
+	// initial load must have been successful.
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if err := c.LoadFlowContent(c.content, c.args.Arguments); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// onContentUpdate is invoked by the inner local.file component whenever the
+// file changes.
+func (c *Component) onContentUpdate(e component.Exports) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.content = e.(file.Exports).Content.Value
+
+	// Only reload the module once it has been built; the initial load is
+	// performed by New.
+	if c.file == nil {
+		return
+	}
+	// Errors are reported through the health of the component.
+	_ = c.LoadFlowContent(c.content, c.args.Arguments)
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.file.Run(ctx)
+	}()
+
+	_ = c.Component.Run(ctx)
+	cancel()
+	return <-errCh
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	c.mut.Lock()
+	c.args = newArgs
+	c.mut.Unlock()
+
+	// Updating the inner local.file component will re-read the file and cause
+	// the module to be reloaded with the new arguments.
+	if err := c.file.Update(newArgs.fileArguments()); err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.LoadFlowContent(c.content, c.args.Arguments)
+}
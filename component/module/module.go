@@ -0,0 +1,129 @@
+// Package module holds common functionality for components which load and run
+// Flow modules.
+//
+// A module is a River file which is run by a nested Flow controller. Modules
+// may declare argument blocks, which receive values from the component that
+// loaded the module:
+//
+//	argument "NAME" {
+//	  optional = false
+//	  default  = "VALUE"
+//	}
+//
+// Arguments are referenced inside of the module as argument.NAME.value.
+//
+// Modules may also declare export blocks, whose values are exposed to the
+// component that loaded the module:
+//
+//	export "NAME" {
+//	  value = EXPRESSION
+//	}
+package module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/grafana/agent/component"
+)
+
+// Exports holds the exports of module components.
+type Exports struct {
+	// Exports exported from the running module.
+	Exports map[string]any `river:"exports,attr"`
+}
+
+// Component holds the common functionality for module components. Module
+// components embed Component, implementing Update to load River content into
+// the module.
+type Component struct {
+	opts component.Options
+	mod  component.Module
+
+	mut           sync.Mutex
+	health        component.Health
+	loaded        bool // Whether content was loaded successfully at least once.
+	latestContent string
+	latestArgs    map[string]any
+}
+
+// NewComponent creates a new module Component. The module must be loaded by
+// calling LoadFlowContent before the Component is run.
+func NewComponent(o component.Options) (*Component, error) {
+	if o.ModuleController == nil {
+		return nil, fmt.Errorf("modules are not supported by this controller")
+	}
+
+	c := &Component{opts: o}
+
+	mod, err := o.ModuleController.NewModule("", func(exports map[string]any) {
+		c.opts.OnStateChange(Exports{Exports: exports})
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.mod = mod
+	return c, nil
+}
+
+// LoadFlowContent loads River content with the provided arguments into the
+// module. The module is only reloaded if content or args changed since the
+// last successful load.
+func (c *Component) LoadFlowContent(content string, args map[string]any) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.loaded && content == c.latestContent && reflect.DeepEqual(args, c.latestArgs) {
+		return nil
+	}
+
+	if err := c.mod.LoadConfig([]byte(content), args); err != nil {
+		c.setHealth(component.Health{
+			Health:     component.HealthTypeUnhealthy,
+			Message:    fmt.Sprintf("failed to load module content: %s", err),
+			UpdateTime: time.Now(),
+		})
+		return err
+	}
+
+	c.loaded = true
+	c.latestContent = content
+	c.latestArgs = args
+	c.setHealth(component.Health{
+		Health:     component.HealthTypeHealthy,
+		Message:    "module content loaded",
+		UpdateTime: time.Now(),
+	})
+	return nil
+}
+
+// Run implements component.Component, running the module until ctx is
+// canceled.
+func (c *Component) Run(ctx context.Context) error {
+	c.mod.Run(ctx)
+	return nil
+}
+
+// CurrentHealth implements component.HealthComponent and reports whether the
+// most recent content was loaded successfully.
+func (c *Component) CurrentHealth() component.Health {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.health
+}
+
+// setHealth sets the health of the component. mut must be held when calling
+// setHealth.
+func (c *Component) setHealth(h component.Health) {
+	c.health = h
+}
+
+// Handler implements component.HTTPComponent. Requests are routed to the
+// components running inside of the module.
+func (c *Component) Handler() http.Handler {
+	return c.mod.ComponentHandler()
+}
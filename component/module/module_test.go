@@ -0,0 +1,50 @@
+package module
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/agent/component"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFlowContent_EmptyContent(t *testing.T) {
+	mod := &fakeModule{}
+	c, err := NewComponent(component.Options{
+		ModuleController: fakeModuleController{mod: mod},
+		OnStateChange:    func(e component.Exports) {},
+	})
+	require.NoError(t, err)
+
+	// Empty content must still be loaded the first time, even though it
+	// matches the zero value of the latest content.
+	require.NoError(t, c.LoadFlowContent("", nil))
+	require.Equal(t, 1, mod.loads)
+	require.Equal(t, component.HealthTypeHealthy, c.CurrentHealth().Health)
+
+	// Loading the same content again is a no-op.
+	require.NoError(t, c.LoadFlowContent("", nil))
+	require.Equal(t, 1, mod.loads)
+}
+
+type fakeModuleController struct {
+	mod *fakeModule
+}
+
+func (mc fakeModuleController) NewModule(id string, export component.ExportFunc) (component.Module, error) {
+	return mc.mod, nil
+}
+
+type fakeModule struct {
+	loads int
+}
+
+func (m *fakeModule) LoadConfig(config []byte, args map[string]any) error {
+	m.loads++
+	return nil
+}
+
+func (m *fakeModule) Run(ctx context.Context) { <-ctx.Done() }
+
+func (m *fakeModule) ComponentHandler() http.Handler { return http.NotFoundHandler() }
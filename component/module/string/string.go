@@ -0,0 +1,62 @@
+// Package string implements the module.string component, which runs a Flow
+// module from River content provided as a string.
+package string
+
+import (
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/module"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "module.string",
+		Args:    Arguments{},
+		Exports: module.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the module.string
+// component.
+type Arguments struct {
+	// Content to load for the module.
+	Content rivertypes.OptionalSecret `river:"content,attr"`
+
+	// Arguments to pass into the module.
+	Arguments map[string]any `river:"arguments,attr,optional"`
+}
+
+// Component implements the module.string component.
+type Component struct {
+	*module.Component
+}
+
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.HealthComponent = (*Component)(nil)
+	_ component.HTTPComponent   = (*Component)(nil)
+)
+
+// New creates a new module.string component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	mc, err := module.NewComponent(o)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{Component: mc}
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+	return c.LoadFlowContent(newArgs.Content.Value, newArgs.Arguments)
+}
@@ -0,0 +1,82 @@
+// Package basic provides an otelcol.auth.basic component.
+package basic
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol/auth"
+	"github.com/grafana/agent/component/otelcol/auth/internal/headerauth"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.auth.basic",
+		Args:    Arguments{},
+		Exports: auth.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return auth.New(opts, newFactory(), args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.auth.basic component.
+type Arguments struct {
+	Username string            `river:"username,attr"`
+	Password rivertypes.Secret `river:"password,attr"`
+}
+
+var _ auth.Arguments = Arguments{}
+
+// Convert implements auth.Arguments.
+func (args Arguments) Convert() otelconfig.Extension {
+	return &extensionConfig{
+		ExtensionSettings: otelconfig.NewExtensionSettings(otelconfig.NewComponentID(typeStr)),
+		Username:          args.Username,
+		Password:          string(args.Password),
+	}
+}
+
+// Extensions implements auth.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements auth.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+const typeStr = "basicauth"
+
+// extensionConfig is the OpenTelemetry Collector configuration for the basic
+// authentication extension.
+type extensionConfig struct {
+	otelconfig.ExtensionSettings
+
+	Username string
+	Password string
+}
+
+// newFactory returns a factory for the basic authentication extension, which
+// sets the Authorization header of outgoing requests using the HTTP Basic
+// authentication scheme.
+func newFactory() otelcomponent.ExtensionFactory {
+	return otelcomponent.NewExtensionFactory(
+		typeStr,
+		func() otelconfig.Extension {
+			return &extensionConfig{ExtensionSettings: otelconfig.NewExtensionSettings(otelconfig.NewComponentID(typeStr))}
+		},
+		func(_ context.Context, _ otelcomponent.ExtensionCreateSettings, cfg otelconfig.Extension) (otelcomponent.Extension, error) {
+			c := cfg.(*extensionConfig)
+			creds := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+			return headerauth.New("Basic " + creds), nil
+		},
+		otelcomponent.StabilityLevelBeta,
+	)
+}
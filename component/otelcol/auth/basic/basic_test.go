@@ -0,0 +1,71 @@
+package basic_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol/auth"
+	"github.com/grafana/agent/component/otelcol/auth/basic"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	otelconfigauth "go.opentelemetry.io/collector/config/configauth"
+)
+
+// Test performs a basic integration test which runs the otelcol.auth.basic
+// component and ensures that it can be used for authentication.
+func Test(t *testing.T) {
+	type request struct {
+		user, pass string
+		ok         bool
+	}
+	reqCh := make(chan request, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		reqCh <- request{user: user, pass: pass, ok: ok}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.auth.basic")
+	require.NoError(t, err)
+
+	cfg := `
+		username = "foo"
+		password = "bar"
+	`
+	var args basic.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	handler := ctrl.Exports().(auth.Exports).Handler
+	authenticator, ok := handler.Extension.(otelconfigauth.ClientAuthenticator)
+	require.True(t, ok, "handler does not implement configauth.ClientAuthenticator")
+
+	rt, err := authenticator.RoundTripper(http.DefaultTransport)
+	require.NoError(t, err)
+	cli := &http.Client{Transport: rt}
+
+	resp, err := cli.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	req := <-reqCh
+	require.True(t, req.ok, "missing basic auth header")
+	require.Equal(t, "foo", req.user)
+	require.Equal(t, "bar", req.pass)
+}
@@ -0,0 +1,99 @@
+// Package bearer provides an otelcol.auth.bearer component.
+package bearer
+
+import (
+	"context"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol/auth"
+	"github.com/grafana/agent/component/otelcol/auth/internal/headerauth"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.auth.bearer",
+		Args:    Arguments{},
+		Exports: auth.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return auth.New(opts, newFactory(), args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.auth.bearer component.
+type Arguments struct {
+	Token  rivertypes.Secret `river:"token,attr"`
+	Scheme string            `river:"scheme,attr,optional"`
+}
+
+var (
+	_ auth.Arguments    = Arguments{}
+	_ river.Unmarshaler = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Scheme: "Bearer",
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	return f((*arguments)(args))
+}
+
+// Convert implements auth.Arguments.
+func (args Arguments) Convert() otelconfig.Extension {
+	return &extensionConfig{
+		ExtensionSettings: otelconfig.NewExtensionSettings(otelconfig.NewComponentID(typeStr)),
+		Scheme:            args.Scheme,
+		Token:             string(args.Token),
+	}
+}
+
+// Extensions implements auth.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements auth.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+const typeStr = "bearertokenauth"
+
+// extensionConfig is the OpenTelemetry Collector configuration for the bearer
+// token authentication extension.
+type extensionConfig struct {
+	otelconfig.ExtensionSettings
+
+	Scheme string
+	Token  string
+}
+
+// newFactory returns a factory for the bearer token authentication
+// extension, which sets the Authorization header of outgoing requests to the
+// configured token.
+func newFactory() otelcomponent.ExtensionFactory {
+	return otelcomponent.NewExtensionFactory(
+		typeStr,
+		func() otelconfig.Extension {
+			return &extensionConfig{ExtensionSettings: otelconfig.NewExtensionSettings(otelconfig.NewComponentID(typeStr))}
+		},
+		func(_ context.Context, _ otelcomponent.ExtensionCreateSettings, cfg otelconfig.Extension) (otelcomponent.Extension, error) {
+			c := cfg.(*extensionConfig)
+			if c.Scheme == "" {
+				return headerauth.New(c.Token), nil
+			}
+			return headerauth.New(c.Scheme + " " + c.Token), nil
+		},
+		otelcomponent.StabilityLevelBeta,
+	)
+}
@@ -0,0 +1,54 @@
+// Package headerauth implements an OpenTelemetry Collector client
+// authenticator which injects a static Authorization header into outgoing
+// HTTP requests and gRPC calls.
+package headerauth
+
+import (
+	"context"
+	"net/http"
+
+	otelconfigauth "go.opentelemetry.io/collector/config/configauth"
+	"google.golang.org/grpc/credentials"
+)
+
+// New returns a client authenticator which sets the Authorization header of
+// all outgoing requests to the value returned by value.
+func New(value string) otelconfigauth.ClientAuthenticator {
+	return otelconfigauth.NewClientAuthenticator(
+		otelconfigauth.WithClientRoundTripper(func(base http.RoundTripper) (http.RoundTripper, error) {
+			return &roundTripper{base: base, value: value}, nil
+		}),
+		otelconfigauth.WithPerRPCCredentials(func() (credentials.PerRPCCredentials, error) {
+			return &perRPCCredentials{value: value}, nil
+		}),
+	)
+}
+
+// roundTripper is an http.RoundTripper which injects the Authorization header
+// into requests.
+type roundTripper struct {
+	base  http.RoundTripper
+	value string
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the original request.
+	newReq := req.Clone(req.Context())
+	newReq.Header.Set("Authorization", rt.value)
+	return rt.base.RoundTrip(newReq)
+}
+
+// perRPCCredentials injects the authorization metadata into gRPC calls.
+type perRPCCredentials struct {
+	value string
+}
+
+func (c *perRPCCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": c.value}, nil
+}
+
+// RequireTransportSecurity always returns true so that credentials are never
+// sent over an insecure connection.
+func (c *perRPCCredentials) RequireTransportSecurity() bool {
+	return true
+}
@@ -0,0 +1,45 @@
+package otelcol
+
+import (
+	"fmt"
+
+	otelconfigcompression "go.opentelemetry.io/collector/config/configcompression"
+)
+
+// CompressionType represents a mechanism used to compress data.
+type CompressionType string
+
+// Supported values for compression
+const (
+	CompressionTypeGzip    CompressionType = "gzip"
+	CompressionTypeZlib    CompressionType = "zlib"
+	CompressionTypeDeflate CompressionType = "deflate"
+	CompressionTypeSnappy  CompressionType = "snappy"
+	CompressionTypeZstd    CompressionType = "zstd"
+	CompressionTypeNone    CompressionType = "none"
+	CompressionTypeEmpty   CompressionType = "" // Same as CompressionTypeNone.
+)
+
+// UnmarshalText converts a string into a CompressionType. Returns an error if
+// the string is invalid.
+func (ct *CompressionType) UnmarshalText(in []byte) error {
+	switch typ := CompressionType(in); typ {
+	case CompressionTypeGzip,
+		CompressionTypeZlib,
+		CompressionTypeDeflate,
+		CompressionTypeSnappy,
+		CompressionTypeZstd,
+		CompressionTypeNone,
+		CompressionTypeEmpty:
+
+		*ct = typ
+		return nil
+	default:
+		return fmt.Errorf("unrecognized compression type %q", typ)
+	}
+}
+
+// Convert converts ct into the upstream type.
+func (ct CompressionType) Convert() otelconfigcompression.CompressionType {
+	return otelconfigcompression.CompressionType(ct)
+}
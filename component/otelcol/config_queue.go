@@ -0,0 +1,63 @@
+package otelcol
+
+import (
+	"fmt"
+
+	"github.com/grafana/agent/pkg/river"
+	otelexporterhelper "go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// QueueArguments holds shared settings for components which can queue
+// requests.
+type QueueArguments struct {
+	Enabled      bool `river:"enabled,attr,optional"`
+	NumConsumers int  `river:"num_consumers,attr,optional"`
+	QueueSize    int  `river:"queue_size,attr,optional"`
+
+	// TODO(rfratto): queues can send to persistent storage through an extension.
+}
+
+var _ river.Unmarshaler = (*QueueArguments)(nil)
+
+// DefaultQueueArguments holds default settings for QueueArguments.
+var DefaultQueueArguments = QueueArguments{
+	Enabled:      true,
+	NumConsumers: 10,
+
+	// Copied from upstream:
+	//
+	// 5000 queue elements at 100 requests/sec gives about 50 seconds of survival
+	// of destination outage. This is a pretty decent value for production. Users
+	// should calculate this from the perspective of how many seconds to buffer
+	// in case of a backend outage and multiply that by the number of requests
+	// per second.
+	QueueSize: 5000,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *QueueArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultQueueArguments
+
+	type arguments QueueArguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Enabled && args.QueueSize <= 0 {
+		return fmt.Errorf("queue_size must be greater than zero")
+	}
+	return nil
+}
+
+// Convert converts args into the upstream type.
+func (args *QueueArguments) Convert() *otelexporterhelper.QueueSettings {
+	if args == nil {
+		return nil
+	}
+
+	return &otelexporterhelper.QueueSettings{
+		Enabled:      args.Enabled,
+		NumConsumers: args.NumConsumers,
+		QueueSize:    args.QueueSize,
+	}
+}
@@ -11,12 +11,12 @@ import (
 	"github.com/grafana/agent/component/otelcol"
 	"github.com/grafana/agent/component/otelcol/internal/lazyconsumer"
 	"github.com/grafana/agent/component/otelcol/internal/scheduler"
+	"github.com/grafana/agent/component/otelcol/internal/zapadapter"
 	"github.com/grafana/agent/pkg/build"
 	otelcomponent "go.opentelemetry.io/collector/component"
 	otelconfig "go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
-	"go.uber.org/zap"
 )
 
 // Arguments is an extension of component.Arguments which contains necessary
@@ -51,8 +51,9 @@ type Exporter struct {
 }
 
 var (
-	_ component.Component       = (*Exporter)(nil)
-	_ component.HealthComponent = (*Exporter)(nil)
+	_ component.Component              = (*Exporter)(nil)
+	_ component.HealthComponent        = (*Exporter)(nil)
+	_ component.LiveDebuggingComponent = (*Exporter)(nil)
 )
 
 // New creates a new Flow component which encapsulates an OpenTelemetry
@@ -109,8 +110,7 @@ func (e *Exporter) Update(args component.Arguments) error {
 
 	settings := otelcomponent.ExporterCreateSettings{
 		TelemetrySettings: otelcomponent.TelemetrySettings{
-			// TODO(rfratto): create an adapter from zap -> go-kit/log
-			Logger: zap.NewNop(),
+			Logger: zapadapter.New(e.opts.Logger),
 
 			// TODO(rfratto): expose tracing and logging statistics.
 			//
@@ -165,3 +165,9 @@ func (e *Exporter) Update(args component.Arguments) error {
 func (e *Exporter) CurrentHealth() component.Health {
 	return e.sched.CurrentHealth()
 }
+
+// LiveDebugging implements component.LiveDebuggingComponent. Each span,
+// metric, and log record sent to the exporter is streamed.
+func (e *Exporter) LiveDebugging(fn func(data string)) (unregister func()) {
+	return e.consumer.LiveDebugging(fn)
+}
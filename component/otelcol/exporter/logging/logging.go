@@ -0,0 +1,102 @@
+// Package logging provides an otelcol.exporter.logging component.
+package logging
+
+import (
+	"fmt"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/exporter"
+	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/exporter/loggingexporter"
+	"go.uber.org/zap/zapcore"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.exporter.logging",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := loggingexporter.NewFactory()
+			return exporter.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Supported values for the verbosity argument.
+const (
+	VerbosityNormal   = "normal"
+	VerbosityDetailed = "detailed"
+)
+
+// Arguments configures the otelcol.exporter.logging component.
+type Arguments struct {
+	Verbosity          string `river:"verbosity,attr,optional"`
+	SamplingInitial    int    `river:"sampling_initial,attr,optional"`
+	SamplingThereafter int    `river:"sampling_thereafter,attr,optional"`
+}
+
+var (
+	_ exporter.Arguments = Arguments{}
+	_ river.Unmarshaler  = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default values for Arguments.
+var DefaultArguments = Arguments{
+	Verbosity:          VerbosityNormal,
+	SamplingInitial:    2,
+	SamplingThereafter: 500,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	switch args.Verbosity {
+	case VerbosityNormal, VerbosityDetailed:
+		// no-op
+	default:
+		return fmt.Errorf("verbosity must be one of %q or %q", VerbosityNormal, VerbosityDetailed)
+	}
+
+	if args.SamplingInitial < 0 || args.SamplingThereafter < 0 {
+		return fmt.Errorf("sampling_initial and sampling_thereafter must not be negative")
+	}
+	return nil
+}
+
+// Convert implements exporter.Arguments.
+func (args Arguments) Convert() otelconfig.Exporter {
+	// The upstream exporter always logs a summary of each batch, and only
+	// logs the full contents of each batch when its level is set to debug.
+	logLevel := zapcore.InfoLevel
+	if args.Verbosity == VerbosityDetailed {
+		logLevel = zapcore.DebugLevel
+	}
+
+	return &loggingexporter.Config{
+		ExporterSettings:   otelconfig.NewExporterSettings(otelconfig.NewComponentID("logging")),
+		LogLevel:           logLevel,
+		SamplingInitial:    args.SamplingInitial,
+		SamplingThereafter: args.SamplingThereafter,
+	}
+}
+
+// Extensions implements exporter.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements exporter.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
@@ -0,0 +1,60 @@
+package logging_test
+
+import (
+	"testing"
+
+	"github.com/grafana/agent/component/otelcol/exporter/logging"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/exporter/loggingexporter"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestArguments_Convert(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect loggingexporter.Config
+	}{
+		{
+			name: "defaults",
+			cfg:  ``,
+			expect: loggingexporter.Config{
+				LogLevel:           zapcore.InfoLevel,
+				SamplingInitial:    2,
+				SamplingThereafter: 500,
+			},
+		},
+		{
+			name: "detailed",
+			cfg: `
+				verbosity           = "detailed"
+				sampling_initial    = 5
+				sampling_thereafter = 1
+			`,
+			expect: loggingexporter.Config{
+				LogLevel:           zapcore.DebugLevel,
+				SamplingInitial:    5,
+				SamplingThereafter: 1,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args logging.Arguments
+			require.NoError(t, river.Unmarshal([]byte(tc.cfg), &args))
+
+			actual := args.Convert().(*loggingexporter.Config)
+			require.Equal(t, tc.expect.LogLevel, actual.LogLevel)
+			require.Equal(t, tc.expect.SamplingInitial, actual.SamplingInitial)
+			require.Equal(t, tc.expect.SamplingThereafter, actual.SamplingThereafter)
+		})
+	}
+}
+
+func TestArguments_InvalidVerbosity(t *testing.T) {
+	var args logging.Arguments
+	err := river.Unmarshal([]byte(`verbosity = "verbose"`), &args)
+	require.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package otlp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/exporter/otlp"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+)
+
+// Test performs a basic integration test which runs the otelcol.exporter.otlp
+// component and ensures that it can pass data to an OTLP gRPC server.
+func Test(t *testing.T) {
+	traceCh := make(chan ptrace.Traces)
+	tracesServer := makeTracesServer(t, traceCh)
+
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.exporter.otlp")
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+		timeout = "250ms"
+
+		client {
+			endpoint = "%s"
+
+			compression = "none"
+
+			tls {
+				insecure             = true
+				insecure_skip_verify = true
+			}
+		}
+
+		sending_queue {
+			enabled = false
+		}
+
+		retry_on_failure {
+			max_elapsed_time = "500ms"
+		}
+	`, tracesServer)
+	var args otlp.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	// Send traces in the background to our exporter, retrying while the
+	// exporter isn't fully initialized yet.
+	go func() {
+		exports := ctrl.Exports().(otelcol.ConsumerExports)
+
+		bo := time.NewTicker(100 * time.Millisecond)
+		defer bo.Stop()
+
+		for {
+			err := exports.Input.ConsumeTraces(ctx, createTestTraces())
+			if err == nil {
+				return
+			} else if !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+				t.Logf("failed sending traces: %s", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-bo.C:
+			}
+		}
+	}()
+
+	// Wait for our exporter to finish and pass data to our gRPC server.
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for traces")
+	case tr := <-traceCh:
+		require.Equal(t, 1, tr.SpanCount())
+	}
+}
+
+func TestArguments_Defaults(t *testing.T) {
+	var args otlp.Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		client {
+			endpoint = "localhost:4317"
+		}
+	`), &args))
+
+	otelCfg := args.Convert().(*otlpexporter.Config)
+
+	require.Equal(t, "localhost:4317", otelCfg.Endpoint)
+	require.Equal(t, "gzip", string(otelCfg.Compression))
+	require.Equal(t, 512*1024, otelCfg.WriteBufferSize)
+	require.Equal(t, 5*time.Second, otelCfg.Timeout)
+	require.True(t, otelCfg.QueueSettings.Enabled)
+	require.Equal(t, 5000, otelCfg.QueueSettings.QueueSize)
+	require.True(t, otelCfg.RetrySettings.Enabled)
+	require.Equal(t, time.Minute, otelCfg.RetrySettings.MaxElapsedTime)
+	require.Nil(t, otelCfg.Auth)
+}
+
+func TestArguments_InvalidCompression(t *testing.T) {
+	var args otlp.Arguments
+	err := river.Unmarshal([]byte(`
+		client {
+			endpoint    = "localhost:4317"
+			compression = "brotli"
+		}
+	`), &args)
+	require.Error(t, err)
+}
+
+// makeTracesServer returns a host:port which will accept traces over insecure
+// gRPC.
+func makeTracesServer(t *testing.T, ch chan ptrace.Traces) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	ptraceotlp.RegisterServer(srv, &mockTracesReceiver{ch: ch})
+
+	go func() {
+		err := srv.Serve(lis)
+		require.NoError(t, err)
+	}()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+type mockTracesReceiver struct {
+	ch chan ptrace.Traces
+}
+
+var _ ptraceotlp.GRPCServer = (*mockTracesReceiver)(nil)
+
+func (ms *mockTracesReceiver) Export(_ context.Context, req ptraceotlp.Request) (ptraceotlp.Response, error) {
+	ms.ch <- req.Traces()
+	return ptraceotlp.NewResponse(), nil
+}
+
+func createTestTraces() ptrace.Traces {
+	// Matches format from the protobuf definition:
+	// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+	var bb = `{
+		"resource_spans": [{
+			"scope_spans": [{
+				"spans": [{
+					"name": "TestSpan"
+				}]
+			}]
+		}]
+	}`
+
+	data, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces([]byte(bb))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,106 @@
+// Package otlphttp provides an otelcol.exporter.otlphttp component.
+package otlphttp
+
+import (
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/exporter"
+	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/exporter/otlphttpexporter"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.exporter.otlphttp",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := otlphttpexporter.NewFactory()
+			return exporter.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.exporter.otlphttp component.
+type Arguments struct {
+	Client HTTPClientArguments    `river:"client,block"`
+	Queue  otelcol.QueueArguments `river:"sending_queue,block,optional"`
+	Retry  otelcol.RetryArguments `river:"retry_on_failure,block,optional"`
+
+	// The URLs to send metrics/logs/traces to. If omitted the exporter will
+	// use Client.Endpoint by appending "/v1/metrics", "/v1/logs" or
+	// "/v1/traces", respectively. If set, these settings override
+	// Client.Endpoint for the corresponding signal.
+	TracesEndpoint  string `river:"traces_endpoint,attr,optional"`
+	MetricsEndpoint string `river:"metrics_endpoint,attr,optional"`
+	LogsEndpoint    string `river:"logs_endpoint,attr,optional"`
+}
+
+var (
+	_ exporter.Arguments = Arguments{}
+	_ river.Unmarshaler  = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default values for Arguments.
+var DefaultArguments = Arguments{
+	Queue:  otelcol.DefaultQueueArguments,
+	Retry:  otelcol.DefaultRetryArguments,
+	Client: DefaultHTTPClientArguments,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	return f((*arguments)(args))
+}
+
+// Convert implements exporter.Arguments.
+func (args Arguments) Convert() otelconfig.Exporter {
+	return &otlphttpexporter.Config{
+		ExporterSettings:   otelconfig.NewExporterSettings(otelconfig.NewComponentID("otlphttp")),
+		HTTPClientSettings: *(*otelcol.HTTPClientArguments)(&args.Client).Convert(),
+		QueueSettings:      *args.Queue.Convert(),
+		RetrySettings:      *args.Retry.Convert(),
+		TracesEndpoint:     args.TracesEndpoint,
+		MetricsEndpoint:    args.MetricsEndpoint,
+		LogsEndpoint:       args.LogsEndpoint,
+	}
+}
+
+// Extensions implements exporter.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return (*otelcol.HTTPClientArguments)(&args.Client).Extensions()
+}
+
+// Exporters implements exporter.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// HTTPClientArguments is used to configure otelcol.exporter.otlphttp with
+// component-specific defaults.
+type HTTPClientArguments otelcol.HTTPClientArguments
+
+var _ river.Unmarshaler = (*HTTPClientArguments)(nil)
+
+// DefaultHTTPClientArguments holds component-specific default settings for
+// HTTPClientArguments.
+var DefaultHTTPClientArguments = HTTPClientArguments{
+	Timeout:         30 * time.Second,
+	Compression:     otelcol.CompressionTypeGzip,
+	WriteBufferSize: 512 * units.Kibibyte,
+}
+
+// UnmarshalRiver implements river.Unmarshaler and supplies defaults.
+func (args *HTTPClientArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultHTTPClientArguments
+	type arguments HTTPClientArguments
+	return f((*arguments)(args))
+}
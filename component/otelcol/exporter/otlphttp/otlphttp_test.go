@@ -0,0 +1,148 @@
+package otlphttp_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/auth"
+	"github.com/grafana/agent/component/otelcol/auth/bearer"
+	"github.com/grafana/agent/component/otelcol/exporter/otlphttp"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+)
+
+// Test performs a basic integration test which runs the
+// otelcol.exporter.otlphttp component with an otelcol.auth.bearer handler and
+// ensures that it can pass authenticated data to an OTLP HTTP server.
+func Test(t *testing.T) {
+	type request struct {
+		authHeader string
+		traces     ptrace.Traces
+	}
+	reqCh := make(chan request, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/traces", r.URL.Path)
+
+		bb, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		req := ptraceotlp.NewRequest()
+		require.NoError(t, req.UnmarshalProto(bb))
+
+		select {
+		case reqCh <- request{authHeader: r.Header.Get("Authorization"), traces: req.Traces()}:
+		default:
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	// Run an otelcol.auth.bearer component to authenticate requests.
+	authCtrl, err := componenttest.NewControllerFromID(l, "otelcol.auth.bearer")
+	require.NoError(t, err)
+
+	var authArgs bearer.Arguments
+	require.NoError(t, river.Unmarshal([]byte(`token = "example-token"`), &authArgs))
+
+	go func() {
+		err := authCtrl.Run(ctx, authArgs)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, authCtrl.WaitExports(time.Second), "auth component never exported anything")
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.exporter.otlphttp")
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+		client {
+			endpoint    = "%s"
+			compression = "none"
+		}
+
+		sending_queue {
+			enabled = false
+		}
+
+		retry_on_failure {
+			max_elapsed_time = "500ms"
+		}
+	`, srv.URL)
+	var args otlphttp.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	args.Client.Auth = authCtrl.Exports().(auth.Exports).Handler
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	// Send traces in the background to our exporter, retrying while the
+	// exporter isn't fully initialized yet.
+	go func() {
+		exports := ctrl.Exports().(otelcol.ConsumerExports)
+
+		bo := time.NewTicker(100 * time.Millisecond)
+		defer bo.Stop()
+
+		for {
+			err := exports.Input.ConsumeTraces(ctx, createTestTraces())
+			if err == nil {
+				return
+			} else if !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+				t.Logf("failed sending traces: %s", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-bo.C:
+			}
+		}
+	}()
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for traces")
+	case req := <-reqCh:
+		require.Equal(t, "Bearer example-token", req.authHeader)
+		require.Equal(t, 1, req.traces.SpanCount())
+	}
+}
+
+func createTestTraces() ptrace.Traces {
+	// Matches format from the protobuf definition:
+	// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+	var bb = `{
+		"resource_spans": [{
+			"scope_spans": [{
+				"spans": [{
+					"name": "TestSpan"
+				}]
+			}]
+		}]
+	}`
+
+	data, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces([]byte(bb))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
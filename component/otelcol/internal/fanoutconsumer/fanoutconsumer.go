@@ -0,0 +1,212 @@
+// Package fanoutconsumer implements OpenTelemetry Collector consumers which
+// fan out incoming data to a list of Flow consumers.
+package fanoutconsumer
+
+import (
+	"context"
+
+	"github.com/grafana/agent/component/otelcol"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+)
+
+// Traces creates a new fanout traces consumer.
+func Traces(in []otelcol.Consumer) otelconsumer.Traces {
+	if len(in) == 0 {
+		return &tracesFanout{}
+	} else if len(in) == 1 {
+		return in[0]
+	}
+
+	var passthrough, clone []otelconsumer.Traces
+
+	// Iterate through all the consumers besides the last.
+	for i := 0; i < len(in)-1; i++ {
+		consumer := in[i]
+
+		if consumer.Capabilities().MutatesData {
+			clone = append(clone, consumer)
+		} else {
+			passthrough = append(passthrough, consumer)
+		}
+	}
+
+	last := in[len(in)-1]
+
+	// The final consumer can be given to the passthrough list regardless of
+	// whether it mutates as long as there's no other read-only consumers.
+	if len(passthrough) == 0 || !last.Capabilities().MutatesData {
+		passthrough = append(passthrough, last)
+	} else {
+		clone = append(clone, last)
+	}
+
+	return &tracesFanout{
+		passthrough: passthrough,
+		clone:       clone,
+	}
+}
+
+type tracesFanout struct {
+	passthrough []otelconsumer.Traces // Consumers where data can be passed through directly
+	clone       []otelconsumer.Traces // Consumes which require cloning data
+}
+
+func (f *tracesFanout) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeTraces exports the ptrace.Traces to all consumers wrapped by the
+// current one.
+func (f *tracesFanout) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	var errs error
+
+	// Initially pass to clone exporter to avoid the case where the optimization
+	// of sending the incoming data to a mutating consumer is used that may
+	// change the incoming data before cloning.
+	for _, f := range f.clone {
+		newTraces := ptrace.NewTraces()
+		td.CopyTo(newTraces)
+		errs = multierr.Append(errs, f.ConsumeTraces(ctx, newTraces))
+	}
+	for _, f := range f.passthrough {
+		errs = multierr.Append(errs, f.ConsumeTraces(ctx, td))
+	}
+
+	return errs
+}
+
+// Metrics creates a new fanout metrics consumer.
+func Metrics(in []otelcol.Consumer) otelconsumer.Metrics {
+	if len(in) == 0 {
+		return &metricsFanout{}
+	} else if len(in) == 1 {
+		return in[0]
+	}
+
+	var passthrough, clone []otelconsumer.Metrics
+
+	// Iterate through all the consumers besides the last.
+	for i := 0; i < len(in)-1; i++ {
+		consumer := in[i]
+
+		if consumer.Capabilities().MutatesData {
+			clone = append(clone, consumer)
+		} else {
+			passthrough = append(passthrough, consumer)
+		}
+	}
+
+	last := in[len(in)-1]
+
+	// The final consumer can be given to the passthrough list regardless of
+	// whether it mutates as long as there's no other read-only consumers.
+	if len(passthrough) == 0 || !last.Capabilities().MutatesData {
+		passthrough = append(passthrough, last)
+	} else {
+		clone = append(clone, last)
+	}
+
+	return &metricsFanout{
+		passthrough: passthrough,
+		clone:       clone,
+	}
+}
+
+type metricsFanout struct {
+	passthrough []otelconsumer.Metrics // Consumers where data can be passed through directly
+	clone       []otelconsumer.Metrics // Consumes which require cloning data
+}
+
+func (f *metricsFanout) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeMetrics exports the pmetric.Metrics to all consumers wrapped by the
+// current one.
+func (f *metricsFanout) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	var errs error
+
+	// Initially pass to clone exporter to avoid the case where the optimization
+	// of sending the incoming data to a mutating consumer is used that may
+	// change the incoming data before cloning.
+	for _, f := range f.clone {
+		newMetrics := pmetric.NewMetrics()
+		md.CopyTo(newMetrics)
+		errs = multierr.Append(errs, f.ConsumeMetrics(ctx, newMetrics))
+	}
+	for _, f := range f.passthrough {
+		errs = multierr.Append(errs, f.ConsumeMetrics(ctx, md))
+	}
+
+	return errs
+}
+
+// Logs creates a new fanout logs consumer.
+func Logs(in []otelcol.Consumer) otelconsumer.Logs {
+	if len(in) == 0 {
+		return &logsFanout{}
+	} else if len(in) == 1 {
+		return in[0]
+	}
+
+	var passthrough, clone []otelconsumer.Logs
+
+	// Iterate through all the consumers besides the last.
+	for i := 0; i < len(in)-1; i++ {
+		consumer := in[i]
+
+		if consumer.Capabilities().MutatesData {
+			clone = append(clone, consumer)
+		} else {
+			passthrough = append(passthrough, consumer)
+		}
+	}
+
+	last := in[len(in)-1]
+
+	// The final consumer can be given to the passthrough list regardless of
+	// whether it mutates as long as there's no other read-only consumers.
+	if len(passthrough) == 0 || !last.Capabilities().MutatesData {
+		passthrough = append(passthrough, last)
+	} else {
+		clone = append(clone, last)
+	}
+
+	return &logsFanout{
+		passthrough: passthrough,
+		clone:       clone,
+	}
+}
+
+type logsFanout struct {
+	passthrough []otelconsumer.Logs // Consumers where data can be passed through directly
+	clone       []otelconsumer.Logs // Consumes which require cloning data
+}
+
+func (f *logsFanout) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeLogs exports the plog.Logs to all consumers wrapped by the current
+// one.
+func (f *logsFanout) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	var errs error
+
+	// Initially pass to clone exporter to avoid the case where the optimization
+	// of sending the incoming data to a mutating consumer is used that may
+	// change the incoming data before cloning.
+	for _, f := range f.clone {
+		newLogs := plog.NewLogs()
+		ld.CopyTo(newLogs)
+		errs = multierr.Append(errs, f.ConsumeLogs(ctx, newLogs))
+	}
+	for _, f := range f.passthrough {
+		errs = multierr.Append(errs, f.ConsumeLogs(ctx, ld))
+	}
+
+	return errs
+}
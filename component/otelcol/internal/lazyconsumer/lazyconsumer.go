@@ -6,6 +6,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/grafana/agent/component/common/livedebugging"
 	otelcomponent "go.opentelemetry.io/collector/component"
 	otelconsumer "go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/plog"
@@ -15,7 +16,8 @@ import (
 
 // Consumer is a lazily-loaded consumer.
 type Consumer struct {
-	ctx context.Context
+	ctx   context.Context
+	debug livedebugging.Publisher
 
 	mut             sync.RWMutex
 	metricsConsumer otelconsumer.Metrics
@@ -59,6 +61,10 @@ func (c *Consumer) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
 		return otelcomponent.ErrDataTypeIsNotSupported
 	}
 
+	if c.debug.Active() {
+		c.publishTraces(td)
+	}
+
 	if c.tracesConsumer.Capabilities().MutatesData {
 		td = td.Clone()
 	}
@@ -78,6 +84,10 @@ func (c *Consumer) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error
 		return otelcomponent.ErrDataTypeIsNotSupported
 	}
 
+	if c.debug.Active() {
+		c.publishMetrics(md)
+	}
+
 	if c.metricsConsumer.Capabilities().MutatesData {
 		md = md.Clone()
 	}
@@ -97,6 +107,10 @@ func (c *Consumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
 		return otelcomponent.ErrDataTypeIsNotSupported
 	}
 
+	if c.debug.Active() {
+		c.publishLogs(ld)
+	}
+
 	if c.logsConsumer.Capabilities().MutatesData {
 		ld = ld.Clone()
 	}
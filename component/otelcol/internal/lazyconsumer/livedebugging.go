@@ -0,0 +1,84 @@
+package lazyconsumer
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// LiveDebugging registers fn to receive a description of each span, metric,
+// and log record sent to the Consumer. It can be used by components to
+// implement component.LiveDebuggingComponent.
+func (c *Consumer) LiveDebugging(fn func(data string)) (unregister func()) {
+	return c.debug.Subscribe(fn)
+}
+
+func (c *Consumer) publishTraces(td ptrace.Traces) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		service := serviceName(rs.Resource())
+
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				c.debug.Publish(fmt.Sprintf(
+					"span service=%q name=%q trace_id=%s span_id=%s",
+					service, span.Name(), span.TraceID().HexString(), span.SpanID().HexString(),
+				))
+			}
+		}
+	}
+}
+
+func (c *Consumer) publishMetrics(md pmetric.Metrics) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		service := serviceName(rm.Resource())
+
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				c.debug.Publish(fmt.Sprintf(
+					"metric service=%q name=%q type=%s",
+					service, metric.Name(), metric.Type(),
+				))
+			}
+		}
+	}
+}
+
+func (c *Consumer) publishLogs(ld plog.Logs) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		service := serviceName(rl.Resource())
+
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				c.debug.Publish(fmt.Sprintf(
+					"log service=%q severity=%q body=%q",
+					service, record.SeverityText(), record.Body().AsString(),
+				))
+			}
+		}
+	}
+}
+
+func serviceName(res pcommon.Resource) string {
+	if v, ok := res.Attributes().Get("service.name"); ok {
+		return v.AsString()
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+// Package zapadapter provides a zap.Logger implementation which writes to a
+// go-kit logger.
+package zapadapter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// New returns a new zap.Logger instance which will forward logs to the
+// provided go-kit logger. The go-kit logger is expected to perform its own
+// level filtering; all zap levels are enabled.
+func New(l log.Logger) *zap.Logger {
+	return zap.New(&loggerCore{inner: l})
+}
+
+// loggerCore is a zap.Core implementation which forwards logs to a go-kit
+// logger.
+type loggerCore struct {
+	inner log.Logger
+}
+
+var _ zapcore.Core = (*loggerCore)(nil)
+
+// Enabled implements zapcore.Core and returns whether logs at a specific
+// level should be reported.
+func (lc *loggerCore) Enabled(zapcore.Level) bool {
+	// An instance of log.Logger has no way of knowing if logs will be filtered
+	// out, so we always return true.
+	return true
+}
+
+// With implements zapcore.Core, returning a new logger core with fields added.
+func (lc *loggerCore) With(ff []zapcore.Field) zapcore.Core {
+	return &loggerCore{inner: log.With(lc.inner, fieldsToKeyvals(ff)...)}
+}
+
+// Check implements zapcore.Core. lc will always add itself along with the
+// provided entry to the CheckedEntry.
+func (lc *loggerCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(e, lc)
+}
+
+// Write implements zapcore.Core, immediately converting the provided Entry
+// and fields into a go-kit log line.
+func (lc *loggerCore) Write(e zapcore.Entry, ff []zapcore.Field) error {
+	logger := lc.inner
+	if e.LoggerName != "" {
+		logger = log.With(logger, "component", e.LoggerName)
+	}
+
+	switch e.Level {
+	case zapcore.DebugLevel:
+		logger = level.Debug(logger)
+	case zapcore.InfoLevel:
+		logger = level.Info(logger)
+	case zapcore.WarnLevel:
+		logger = level.Warn(logger)
+	default:
+		// go-kit doesn't have levels higher than error, so everything else is
+		// logged at the error level.
+		logger = level.Error(logger)
+	}
+
+	keyvals := append([]interface{}{"msg", e.Message}, fieldsToKeyvals(ff)...)
+	return logger.Log(keyvals...)
+}
+
+// Sync implements zapcore.Core. It is a no-op.
+func (lc *loggerCore) Sync() error {
+	return nil
+}
+
+// fieldsToKeyvals converts a set of zap fields into go-kit key/value pairs,
+// sorted by key.
+func fieldsToKeyvals(ff []zapcore.Field) []interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range ff {
+		f.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	keyvals := make([]interface{}, 0, len(keys)*2)
+	for _, k := range keys {
+		keyvals = append(keyvals, k, formatValue(enc.Fields[k]))
+	}
+	return keyvals
+}
+
+// formatValue converts values which don't have a useful go-kit
+// representation (such as nested objects) into strings.
+func formatValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}, []interface{}:
+		return fmt.Sprintf("%v", v)
+	default:
+		return v
+	}
+}
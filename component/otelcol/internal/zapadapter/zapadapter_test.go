@@ -0,0 +1,26 @@
+package zapadapter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/agent/component/otelcol/internal/zapadapter"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zapadapter.New(log.NewLogfmtLogger(&buf))
+
+	logger.
+		Named("exporter").
+		With(zap.String("kind", "traces")).
+		Warn("failed to send", zap.Int("count", 5), zap.Bool("retry", true))
+
+	require.Equal(t,
+		`level=warn kind=traces component=exporter msg="failed to send" count=5 retry=true`+"\n",
+		buf.String(),
+	)
+}
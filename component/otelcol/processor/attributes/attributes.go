@@ -0,0 +1,198 @@
+// Package attributes provides an otelcol.processor.attributes component.
+package attributes
+
+import (
+	"fmt"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/processor"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/mitchellh/mapstructure"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/attributesprocessor"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.attributes",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := attributesprocessor.NewFactory()
+			return processor.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.attributes component.
+type Arguments struct {
+	// Actions to perform against attributes. At least one is required.
+	Actions []ActionKeyValue `river:"action,block"`
+
+	// Include and Exclude filter which telemetry data the actions are applied
+	// to. All data is processed when neither is set.
+	Include *MatchProperties `river:"include,block,optional"`
+	Exclude *MatchProperties `river:"exclude,block,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+// ActionKeyValue configures a single action to perform against an
+// attribute.
+type ActionKeyValue struct {
+	Key           string      `river:"key,attr"`
+	Action        string      `river:"action,attr"`
+	Value         interface{} `river:"value,attr,optional"`
+	RegexPattern  string      `river:"pattern,attr,optional"`
+	FromAttribute string      `river:"from_attribute,attr,optional"`
+	FromContext   string      `river:"from_context,attr,optional"`
+	ConvertedType string      `river:"converted_type,attr,optional"`
+}
+
+// MatchProperties configures which telemetry data an action applies to.
+type MatchProperties struct {
+	MatchType        string   `river:"match_type,attr"`
+	Services         []string `river:"services,attr,optional"`
+	SpanNames        []string `river:"span_names,attr,optional"`
+	SpanKinds        []string `river:"span_kinds,attr,optional"`
+	LogBodies        []string `river:"log_bodies,attr,optional"`
+	LogSeverityTexts []string `river:"log_severity_texts,attr,optional"`
+	MetricNames      []string `river:"metric_names,attr,optional"`
+
+	Attributes []Attribute              `river:"attribute,block,optional"`
+	Resources  []Attribute              `river:"resource,block,optional"`
+	Libraries  []InstrumentationLibrary `river:"library,block,optional"`
+}
+
+// Attribute matches an attribute key against an optional value.
+type Attribute struct {
+	Key   string      `river:"key,attr"`
+	Value interface{} `river:"value,attr,optional"`
+}
+
+// InstrumentationLibrary matches the instrumentation library of telemetry
+// data.
+type InstrumentationLibrary struct {
+	Name    string  `river:"name,attr"`
+	Version *string `river:"version,attr,optional"`
+}
+
+var (
+	_ processor.Arguments = Arguments{}
+	_ river.Unmarshaler   = (*Arguments)(nil)
+)
+
+// UnmarshalRiver implements river.Unmarshaler. It validates settings provided
+// by the user.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = Arguments{}
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if len(args.Actions) == 0 {
+		return fmt.Errorf("at least one action block must be provided")
+	}
+	return nil
+}
+
+// Convert implements processor.Arguments.
+func (args Arguments) Convert() otelconfig.Processor {
+	cfg := &attributesprocessor.Config{
+		ProcessorSettings: otelconfig.NewProcessorSettings(otelconfig.NewComponentID("attributes")),
+	}
+
+	// The types for the attributes processor configuration live in internal
+	// packages of the collector-contrib module, so the configuration is
+	// decoded the same way the collector itself would.
+	input := map[string]interface{}{
+		"actions": args.convertActions(),
+	}
+	if args.Include != nil {
+		input["include"] = args.Include.convert()
+	}
+	if args.Exclude != nil {
+		input["exclude"] = args.Exclude.convert()
+	}
+
+	if err := mapstructure.Decode(input, cfg); err != nil {
+		// Every field being decoded has a matching type, so this should never
+		// happen.
+		panic(fmt.Sprintf("failed to decode attributes processor config: %s", err))
+	}
+	return cfg
+}
+
+func (args Arguments) convertActions() []interface{} {
+	res := make([]interface{}, 0, len(args.Actions))
+	for _, a := range args.Actions {
+		res = append(res, map[string]interface{}{
+			"key":            a.Key,
+			"action":         a.Action,
+			"value":          a.Value,
+			"pattern":        a.RegexPattern,
+			"from_attribute": a.FromAttribute,
+			"from_context":   a.FromContext,
+			"converted_type": a.ConvertedType,
+		})
+	}
+	return res
+}
+
+func (mp *MatchProperties) convert() map[string]interface{} {
+	return map[string]interface{}{
+		"match_type":         mp.MatchType,
+		"services":           mp.Services,
+		"span_names":         mp.SpanNames,
+		"span_kinds":         mp.SpanKinds,
+		"log_bodies":         mp.LogBodies,
+		"log_severity_texts": mp.LogSeverityTexts,
+		"metric_names":       mp.MetricNames,
+		"attributes":         convertAttributes(mp.Attributes),
+		"resources":          convertAttributes(mp.Resources),
+		"libraries":          convertLibraries(mp.Libraries),
+	}
+}
+
+func convertAttributes(in []Attribute) []interface{} {
+	res := make([]interface{}, 0, len(in))
+	for _, a := range in {
+		res = append(res, map[string]interface{}{
+			"key":   a.Key,
+			"value": a.Value,
+		})
+	}
+	return res
+}
+
+func convertLibraries(in []InstrumentationLibrary) []interface{} {
+	res := make([]interface{}, 0, len(in))
+	for _, l := range in {
+		res = append(res, map[string]interface{}{
+			"name":    l.Name,
+			"version": l.Version,
+		})
+	}
+	return res
+}
+
+// Extensions implements processor.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements processor.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements processor.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
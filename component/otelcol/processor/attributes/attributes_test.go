@@ -0,0 +1,171 @@
+package attributes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/processor/attributes"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/attributesprocessor"
+	"github.com/stretchr/testify/require"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Test performs a basic integration test which runs the
+// otelcol.processor.attributes component and ensures that it can modify and
+// forward data.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.processor.attributes")
+	require.NoError(t, err)
+
+	cfg := `
+		action {
+			key    = "cluster"
+			value  = "us-east-1"
+			action = "insert"
+		}
+
+		output { /* no-op */ }
+	`
+	var args attributes.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	// Override our settings so traces get forwarded to tracesCh.
+	tracesCh := make(chan ptrace.Traces, 1)
+	args.Output = &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{&tracesConsumer{ch: tracesCh}},
+	}
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	input := ctrl.Exports().(otelcol.ConsumerExports).Input
+
+	// Send traces in the background to our processor, retrying while it isn't
+	// fully initialized yet.
+	go func() {
+		for {
+			err := input.ConsumeTraces(ctx, createTestTraces())
+			if errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			require.NoError(t, err)
+			return
+		}
+	}()
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for traces")
+	case tr := <-tracesCh:
+		span := tr.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+		v, ok := span.Attributes().Get("cluster")
+		require.True(t, ok, "attribute was not inserted")
+		require.Equal(t, "us-east-1", v.Str())
+	}
+}
+
+func TestArguments_Convert(t *testing.T) {
+	cfg := `
+		action {
+			key    = "user.email"
+			action = "hash"
+		}
+
+		include {
+			match_type = "strict"
+			services   = ["auth-service"]
+
+			attribute {
+				key   = "env"
+				value = "prod"
+			}
+		}
+
+		output {}
+	`
+	var args attributes.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	otelCfg := args.Convert().(*attributesprocessor.Config)
+
+	require.Len(t, otelCfg.Actions, 1)
+	require.Equal(t, "user.email", otelCfg.Actions[0].Key)
+	require.Equal(t, "hash", string(otelCfg.Actions[0].Action))
+
+	require.NotNil(t, otelCfg.Include)
+	require.Nil(t, otelCfg.Exclude)
+	require.Equal(t, "strict", string(otelCfg.Include.MatchType))
+	require.Equal(t, []string{"auth-service"}, otelCfg.Include.Services)
+	require.Len(t, otelCfg.Include.Attributes, 1)
+	require.Equal(t, "env", otelCfg.Include.Attributes[0].Key)
+	require.Equal(t, "prod", otelCfg.Include.Attributes[0].Value)
+}
+
+func TestArguments_NoActions(t *testing.T) {
+	var args attributes.Arguments
+	err := river.Unmarshal([]byte(`output {}`), &args)
+	require.Error(t, err)
+}
+
+// tracesConsumer is an otelcol.Consumer which writes received traces to a
+// channel.
+type tracesConsumer struct {
+	ch chan<- ptrace.Traces
+}
+
+func (tc *tracesConsumer) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{}
+}
+
+func (tc *tracesConsumer) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	select {
+	case tc.ch <- td:
+	default:
+	}
+	return nil
+}
+
+func (tc *tracesConsumer) ConsumeMetrics(_ context.Context, _ pmetric.Metrics) error {
+	return nil
+}
+
+func (tc *tracesConsumer) ConsumeLogs(_ context.Context, _ plog.Logs) error {
+	return nil
+}
+
+func createTestTraces() ptrace.Traces {
+	// Matches format from the protobuf definition:
+	// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+	var bb = `{
+		"resource_spans": [{
+			"scope_spans": [{
+				"spans": [{
+					"name": "TestSpan"
+				}]
+			}]
+		}]
+	}`
+
+	data, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces([]byte(bb))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
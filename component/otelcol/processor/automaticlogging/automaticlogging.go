@@ -0,0 +1,123 @@
+// Package automaticlogging provides an otelcol.processor.automatic_logging
+// component.
+package automaticlogging
+
+import (
+	"fmt"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/processor"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/traces/automaticloggingprocessor"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.automatic_logging",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := automaticloggingprocessor.NewFactory()
+			return processor.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.automatic_logging component.
+//
+// Log lines are written to stdout through the logger of the agent; the
+// logs_instance backend of static mode has no Flow equivalent.
+type Arguments struct {
+	Spans             bool     `river:"spans,attr,optional"`
+	Roots             bool     `river:"roots,attr,optional"`
+	Processes         bool     `river:"processes,attr,optional"`
+	SpanAttributes    []string `river:"span_attributes,attr,optional"`
+	ProcessAttributes []string `river:"process_attributes,attr,optional"`
+
+	Overrides OverrideArguments `river:"overrides,block,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+// OverrideArguments overrides the keys used in generated log lines.
+type OverrideArguments struct {
+	ServiceKey  string `river:"service_key,attr,optional"`
+	SpanNameKey string `river:"span_name_key,attr,optional"`
+	StatusKey   string `river:"status_key,attr,optional"`
+	DurationKey string `river:"duration_key,attr,optional"`
+	TraceIDKey  string `river:"trace_id_key,attr,optional"`
+}
+
+var (
+	_ processor.Arguments = Arguments{}
+	_ river.Unmarshaler   = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Overrides: OverrideArguments{
+		ServiceKey:  "svc",
+		SpanNameKey: "span",
+		StatusKey:   "status",
+		DurationKey: "dur",
+		TraceIDKey:  "tid",
+	},
+}
+
+// UnmarshalRiver implements river.Unmarshaler. It applies defaults to args and
+// validates settings provided by the user.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if !args.Spans && !args.Roots && !args.Processes {
+		return fmt.Errorf("at least one of spans, roots, or processes must be enabled")
+	}
+	return nil
+}
+
+// Convert implements processor.Arguments.
+func (args Arguments) Convert() otelconfig.Processor {
+	return &automaticloggingprocessor.Config{
+		ProcessorSettings: otelconfig.NewProcessorSettings(otelconfig.NewComponentID(automaticloggingprocessor.TypeStr)),
+		LoggingConfig: &automaticloggingprocessor.AutomaticLoggingConfig{
+			Backend:           automaticloggingprocessor.BackendStdout,
+			Spans:             args.Spans,
+			Roots:             args.Roots,
+			Processes:         args.Processes,
+			SpanAttributes:    args.SpanAttributes,
+			ProcessAttributes: args.ProcessAttributes,
+			Overrides: automaticloggingprocessor.OverrideConfig{
+				ServiceKey:  args.Overrides.ServiceKey,
+				SpanNameKey: args.Overrides.SpanNameKey,
+				StatusKey:   args.Overrides.StatusKey,
+				DurationKey: args.Overrides.DurationKey,
+				TraceIDKey:  args.Overrides.TraceIDKey,
+			},
+		},
+	}
+}
+
+// Extensions implements processor.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements processor.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements processor.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
@@ -0,0 +1,107 @@
+// Package memorylimiter provides an otelcol.processor.memory_limiter component.
+package memorylimiter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/processor"
+	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.memory_limiter",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := memorylimiterprocessor.NewFactory()
+			return processor.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.memory_limiter component.
+type Arguments struct {
+	CheckInterval time.Duration `river:"check_interval,attr"`
+
+	MemoryLimit           units.Base2Bytes `river:"limit,attr,optional"`
+	MemorySpikeLimit      units.Base2Bytes `river:"spike_limit,attr,optional"`
+	MemoryLimitPercentage uint32           `river:"limit_percentage,attr,optional"`
+	MemorySpikePercentage uint32           `river:"spike_limit_percentage,attr,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+var (
+	_ processor.Arguments = Arguments{}
+	_ river.Unmarshaler   = (*Arguments)(nil)
+)
+
+// UnmarshalRiver implements river.Unmarshaler. It validates settings provided
+// by the user.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = Arguments{}
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.CheckInterval <= 0 {
+		return fmt.Errorf("check_interval must be greater than zero")
+	}
+
+	switch {
+	case args.MemoryLimit > 0 && args.MemoryLimitPercentage > 0:
+		return fmt.Errorf("only one of limit and limit_percentage may be set")
+	case args.MemoryLimit > 0:
+		if args.MemorySpikeLimit >= args.MemoryLimit {
+			return fmt.Errorf("spike_limit must be less than limit")
+		}
+	case args.MemoryLimitPercentage > 0:
+		if args.MemoryLimitPercentage > 100 || args.MemorySpikePercentage >= args.MemoryLimitPercentage {
+			return fmt.Errorf("limit_percentage must be at most 100 and greater than spike_limit_percentage")
+		}
+	default:
+		return fmt.Errorf("one of limit or limit_percentage must be set")
+	}
+
+	return nil
+}
+
+// Convert implements processor.Arguments.
+func (args Arguments) Convert() otelconfig.Processor {
+	return &memorylimiterprocessor.Config{
+		ProcessorSettings: otelconfig.NewProcessorSettings(otelconfig.NewComponentID("memory_limiter")),
+
+		CheckInterval:         args.CheckInterval,
+		MemoryLimitMiB:        uint32(args.MemoryLimit / units.MiB),
+		MemorySpikeLimitMiB:   uint32(args.MemorySpikeLimit / units.MiB),
+		MemoryLimitPercentage: args.MemoryLimitPercentage,
+		MemorySpikePercentage: args.MemorySpikePercentage,
+	}
+}
+
+// Extensions implements processor.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements processor.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements processor.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
@@ -0,0 +1,240 @@
+package processor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/processor"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestProcessor(t *testing.T) {
+	// Channel where processed traces will be written to.
+	tracesCh := make(chan ptrace.Traces, 1)
+
+	te := newTestEnvironment(t)
+	te.Start(fakeProcessorArgs{
+		Output: &otelcol.ConsumerArguments{
+			Traces: []otelcol.Consumer{newTracesConsumer(tracesCh)},
+		},
+	})
+
+	require.NoError(t, te.Controller.WaitExports(1*time.Second), "test component did not generate exports")
+	ce := te.Controller.Exports().(otelcol.ConsumerExports)
+
+	testTraces := createTestTraces()
+	sendTraces(t, ce.Input, testTraces)
+
+	select {
+	case <-time.After(1 * time.Second):
+		require.FailNow(t, "output consumer did not receive traces")
+	case td := <-tracesCh:
+		require.Equal(t, testTraces, td)
+	}
+}
+
+func TestProcessor_UpdateOutput(t *testing.T) {
+	var (
+		firstCh  = make(chan ptrace.Traces, 1)
+		secondCh = make(chan ptrace.Traces, 1)
+	)
+
+	te := newTestEnvironment(t)
+	te.Start(fakeProcessorArgs{
+		Output: &otelcol.ConsumerArguments{
+			Traces: []otelcol.Consumer{newTracesConsumer(firstCh)},
+		},
+	})
+
+	require.NoError(t, te.Controller.WaitExports(1*time.Second), "test component did not generate exports")
+	ce := te.Controller.Exports().(otelcol.ConsumerExports)
+
+	require.NoError(t, te.Controller.Update(fakeProcessorArgs{
+		Output: &otelcol.ConsumerArguments{
+			Traces: []otelcol.Consumer{newTracesConsumer(secondCh)},
+		},
+	}))
+
+	// The exported consumer must not change, but data must now be sent to the
+	// updated output.
+	require.Equal(t, ce, te.Controller.Exports().(otelcol.ConsumerExports))
+
+	testTraces := createTestTraces()
+	sendTraces(t, ce.Input, testTraces)
+
+	select {
+	case <-time.After(1 * time.Second):
+		require.FailNow(t, "updated output consumer did not receive traces")
+	case <-firstCh:
+		require.FailNow(t, "old output consumer received traces")
+	case td := <-secondCh:
+		require.Equal(t, testTraces, td)
+	}
+}
+
+// sendTraces sends td to the input consumer, retrying while the processor
+// hasn't been fully initialized yet.
+func sendTraces(t *testing.T, input otelcol.Consumer, td ptrace.Traces) {
+	t.Helper()
+
+	for {
+		err := input.ConsumeTraces(context.Background(), td)
+		if errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		require.NoError(t, err)
+		return
+	}
+}
+
+type testEnvironment struct {
+	t *testing.T
+
+	Controller *componenttest.Controller
+}
+
+func newTestEnvironment(t *testing.T) *testEnvironment {
+	t.Helper()
+
+	reg := component.Registration{
+		Name:    "testcomponent",
+		Args:    fakeProcessorArgs{},
+		Exports: otelcol.ConsumerExports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			// Create a factory which creates a fake processor forwarding traces
+			// to its next consumer.
+			factory := otelcomponent.NewProcessorFactory(
+				"testcomponent",
+				func() otelconfig.Processor {
+					return fakeProcessorArgs{}.Convert()
+				},
+				otelcomponent.WithTracesProcessor(func(
+					_ context.Context,
+					_ otelcomponent.ProcessorCreateSettings,
+					_ otelconfig.Processor,
+					next otelconsumer.Traces,
+				) (otelcomponent.TracesProcessor, error) {
+					return &fakeProcessor{next: next}, nil
+				}, otelcomponent.StabilityLevelUndefined),
+			)
+
+			return processor.New(opts, factory, args.(processor.Arguments))
+		},
+	}
+
+	return &testEnvironment{
+		t:          t,
+		Controller: componenttest.NewControllerFromReg(util.TestLogger(t), reg),
+	}
+}
+
+func (te *testEnvironment) Start(args fakeProcessorArgs) {
+	go func() {
+		ctx := componenttest.TestContext(te.t)
+		err := te.Controller.Run(ctx, args)
+		require.NoError(te.t, err, "failed to run component")
+	}()
+}
+
+type fakeProcessorArgs struct {
+	Output *otelcol.ConsumerArguments
+}
+
+var _ processor.Arguments = fakeProcessorArgs{}
+
+func (fa fakeProcessorArgs) Convert() otelconfig.Processor {
+	settings := otelconfig.NewProcessorSettings(otelconfig.NewComponentID("testcomponent"))
+	return &settings
+}
+
+func (fa fakeProcessorArgs) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+func (fa fakeProcessorArgs) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+func (fa fakeProcessorArgs) NextConsumers() *otelcol.ConsumerArguments {
+	return fa.Output
+}
+
+type fakeProcessor struct {
+	next otelconsumer.Traces
+}
+
+var _ otelcomponent.TracesProcessor = (*fakeProcessor)(nil)
+
+func (fp *fakeProcessor) Start(_ context.Context, _ otelcomponent.Host) error { return nil }
+func (fp *fakeProcessor) Shutdown(_ context.Context) error                    { return nil }
+
+func (fp *fakeProcessor) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{}
+}
+
+func (fp *fakeProcessor) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return fp.next.ConsumeTraces(ctx, td)
+}
+
+// tracesConsumer is an otelcol.Consumer which writes received traces to a
+// channel.
+type tracesConsumer struct {
+	ch chan<- ptrace.Traces
+}
+
+func newTracesConsumer(ch chan<- ptrace.Traces) otelcol.Consumer {
+	return &tracesConsumer{ch: ch}
+}
+
+func (tc *tracesConsumer) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{}
+}
+
+func (tc *tracesConsumer) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	select {
+	case tc.ch <- td:
+	default:
+	}
+	return nil
+}
+
+func (tc *tracesConsumer) ConsumeMetrics(_ context.Context, _ pmetric.Metrics) error {
+	return nil
+}
+
+func (tc *tracesConsumer) ConsumeLogs(_ context.Context, _ plog.Logs) error {
+	return nil
+}
+
+func createTestTraces() ptrace.Traces {
+	// Matches format from the protobuf definition:
+	// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+	var bb = `{
+		"resource_spans": [{
+			"scope_spans": [{
+				"spans": [{
+					"name": "TestSpan"
+				}]
+			}]
+		}]
+	}`
+
+	data, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces([]byte(bb))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,162 @@
+// Package servicegraph provides an otelcol.processor.service_graph component.
+package servicegraph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/processor"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/traces/contextkeys"
+	"github.com/grafana/agent/pkg/traces/servicegraphprocessor"
+	"github.com/prometheus/client_golang/prometheus"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.service_graph",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := &factory{
+				ProcessorFactory: servicegraphprocessor.NewFactory(),
+				reg:              opts.Registerer,
+			}
+			return processor.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.service_graph component.
+type Arguments struct {
+	Wait     time.Duration `river:"wait,attr,optional"`
+	MaxItems int           `river:"max_items,attr,optional"`
+	Workers  int           `river:"workers,attr,optional"`
+
+	Dimensions                    []string        `river:"dimensions,attr,optional"`
+	ClientLatencyHistogramBuckets []time.Duration `river:"client_latency_histogram_buckets,attr,optional"`
+	ServerLatencyHistogramBuckets []time.Duration `river:"server_latency_histogram_buckets,attr,optional"`
+
+	SuccessCodes *SuccessCodes `river:"success_codes,block,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+// SuccessCodes configures the status codes which mark a request as
+// successful in addition to the default set.
+type SuccessCodes struct {
+	HTTP []int64 `river:"http,attr,optional"`
+	GRPC []int64 `river:"grpc,attr,optional"`
+}
+
+var (
+	_ processor.Arguments = Arguments{}
+	_ river.Unmarshaler   = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Wait:     servicegraphprocessor.DefaultWait,
+	MaxItems: servicegraphprocessor.DefaultMaxItems,
+	Workers:  servicegraphprocessor.DefaultWorkers,
+}
+
+// UnmarshalRiver implements river.Unmarshaler. It applies defaults to args and
+// validates settings provided by the user.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Wait <= 0 {
+		return fmt.Errorf("wait must be greater than zero")
+	}
+	if args.MaxItems <= 0 {
+		return fmt.Errorf("max_items must be greater than zero")
+	}
+	if args.Workers <= 0 {
+		return fmt.Errorf("workers must be greater than zero")
+	}
+	return args.Convert().(*servicegraphprocessor.Config).Validate()
+}
+
+// Convert implements processor.Arguments.
+func (args Arguments) Convert() otelconfig.Processor {
+	cfg := &servicegraphprocessor.Config{
+		ProcessorSettings: otelconfig.NewProcessorSettings(otelconfig.NewComponentID(servicegraphprocessor.TypeStr)),
+		Wait:              args.Wait,
+		MaxItems:          args.MaxItems,
+		Workers:           args.Workers,
+
+		Dimensions:                    args.Dimensions,
+		ClientLatencyHistogramBuckets: args.ClientLatencyHistogramBuckets,
+		ServerLatencyHistogramBuckets: args.ServerLatencyHistogramBuckets,
+	}
+	if args.SuccessCodes != nil {
+		cfg.SuccessCodes = &servicegraphprocessor.SuccessCodes{
+			HTTP: args.SuccessCodes.HTTP,
+			GRPC: args.SuccessCodes.GRPC,
+		}
+	}
+	return cfg
+}
+
+// Extensions implements processor.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements processor.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements processor.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// factory wraps around the service graph processor factory so created
+// processors register their metrics against the registerer of the Flow
+// component.
+type factory struct {
+	otelcomponent.ProcessorFactory
+	reg prometheus.Registerer
+}
+
+func (f *factory) CreateTracesProcessor(
+	ctx context.Context,
+	set otelcomponent.ProcessorCreateSettings,
+	cfg otelconfig.Processor,
+	next otelconsumer.Traces,
+) (otelcomponent.TracesProcessor, error) {
+
+	p, err := f.ProcessorFactory.CreateTracesProcessor(ctx, set, cfg, next)
+	if err != nil {
+		return nil, err
+	}
+	return &registererProcessor{TracesProcessor: p, reg: f.reg}, nil
+}
+
+// registererProcessor injects a Prometheus registerer into the context
+// used to start the wrapped processor.
+type registererProcessor struct {
+	otelcomponent.TracesProcessor
+	reg prometheus.Registerer
+}
+
+func (p *registererProcessor) Start(ctx context.Context, host otelcomponent.Host) error {
+	ctx = context.WithValue(ctx, contextkeys.PrometheusRegisterer, p.reg)
+	return p.TracesProcessor.Start(ctx, host)
+}
@@ -0,0 +1,173 @@
+// Package spanmetrics provides an otelcol.processor.span_metrics component.
+package spanmetrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fanoutconsumer"
+	"github.com/grafana/agent/component/otelcol/processor"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/spanmetricsprocessor"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.span_metrics",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := spanmetricsprocessor.NewFactory()
+			return processor.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Supported values for the aggregation_temporality argument.
+const (
+	AggregationTemporalityCumulative = "CUMULATIVE"
+	AggregationTemporalityDelta      = "DELTA"
+)
+
+// metricsExporterID is the ID of the exporter exposed to the span metrics
+// processor which forwards generated metrics to the Flow consumers listed in
+// output.metrics.
+var metricsExporterID = otelconfig.NewComponentID("otelcol")
+
+// Arguments configures the otelcol.processor.span_metrics component.
+type Arguments struct {
+	LatencyHistogramBuckets []time.Duration `river:"latency_histogram_buckets,attr,optional"`
+	Dimensions              []Dimension     `river:"dimension,block,optional"`
+	DimensionsCacheSize     int             `river:"dimensions_cache_size,attr,optional"`
+	AggregationTemporality  string          `river:"aggregation_temporality,attr,optional"`
+
+	// Output configures where to send processed traces and generated metrics.
+	// Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+// Dimension is an additional span attribute to add as a label to generated
+// metrics.
+type Dimension struct {
+	Name    string  `river:"name,attr"`
+	Default *string `river:"default,attr,optional"`
+}
+
+var (
+	_ processor.Arguments = Arguments{}
+	_ river.Unmarshaler   = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	DimensionsCacheSize:    1000,
+	AggregationTemporality: AggregationTemporalityCumulative,
+}
+
+// UnmarshalRiver implements river.Unmarshaler. It applies defaults to args and
+// validates settings provided by the user.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	switch args.AggregationTemporality {
+	case AggregationTemporalityCumulative, AggregationTemporalityDelta:
+		// no-op
+	default:
+		return fmt.Errorf("aggregation_temporality must be one of %q or %q", AggregationTemporalityCumulative, AggregationTemporalityDelta)
+	}
+
+	if args.DimensionsCacheSize <= 0 {
+		return fmt.Errorf("dimensions_cache_size must be greater than zero")
+	}
+	if len(args.Output.Metrics) == 0 {
+		return fmt.Errorf("at least one metrics consumer must be provided in the output block")
+	}
+	return nil
+}
+
+// Convert implements processor.Arguments.
+func (args Arguments) Convert() otelconfig.Processor {
+	// The default config is used as a starting point since it holds settings
+	// which can't be set outside of the upstream package.
+	cfg := spanmetricsprocessor.NewFactory().CreateDefaultConfig().(*spanmetricsprocessor.Config)
+
+	cfg.MetricsExporter = metricsExporterID.String()
+	cfg.LatencyHistogramBuckets = args.LatencyHistogramBuckets
+	cfg.DimensionsCacheSize = args.DimensionsCacheSize
+	cfg.AggregationTemporality = "AGGREGATION_TEMPORALITY_" + args.AggregationTemporality
+
+	for _, d := range args.Dimensions {
+		cfg.Dimensions = append(cfg.Dimensions, spanmetricsprocessor.Dimension{
+			Name:    d.Name,
+			Default: d.Default,
+		})
+	}
+
+	return cfg
+}
+
+// Extensions implements processor.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements processor.Arguments. The span metrics processor
+// exports metrics through a named metrics exporter, which forwards to the
+// metrics consumers configured in the output block.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter{
+		otelconfig.MetricsDataType: {
+			metricsExporterID: &metricsExporter{Metrics: fanoutconsumer.Metrics(args.Output.Metrics)},
+		},
+	}
+}
+
+// NextConsumers implements processor.Arguments. Processed traces are
+// discarded when no traces consumers are provided so that metrics are still
+// generated.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	next := &otelcol.ConsumerArguments{Traces: args.Output.Traces}
+	if len(next.Traces) == 0 {
+		next.Traces = []otelcol.Consumer{discardConsumer{}}
+	}
+	return next
+}
+
+// metricsExporter exposes a metrics consumer as an OpenTelemetry Collector
+// exporter.
+type metricsExporter struct {
+	otelconsumer.Metrics
+}
+
+var _ otelcomponent.MetricsExporter = (*metricsExporter)(nil)
+
+func (me *metricsExporter) Start(context.Context, otelcomponent.Host) error { return nil }
+func (me *metricsExporter) Shutdown(context.Context) error                  { return nil }
+
+// discardConsumer is an otelcol.Consumer which drops all data.
+type discardConsumer struct{}
+
+var _ otelcol.Consumer = discardConsumer{}
+
+func (discardConsumer) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{}
+}
+
+func (discardConsumer) ConsumeTraces(context.Context, ptrace.Traces) error    { return nil }
+func (discardConsumer) ConsumeMetrics(context.Context, pmetric.Metrics) error { return nil }
+func (discardConsumer) ConsumeLogs(context.Context, plog.Logs) error          { return nil }
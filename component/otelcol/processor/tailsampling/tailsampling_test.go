@@ -0,0 +1,81 @@
+package tailsampling_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol/processor/tailsampling"
+	"github.com/grafana/agent/pkg/river"
+	tsp "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_Convert(t *testing.T) {
+	cfg := `
+		decision_wait = "10s"
+
+		policy {
+			name = "errors"
+			type = "status_code"
+
+			status_code {
+				status_codes = ["ERROR"]
+			}
+		}
+
+		policy {
+			name = "slow-and-important"
+			type = "and"
+
+			and {
+				and_sub_policy {
+					name = "slow"
+					type = "latency"
+
+					latency {
+						threshold_ms = 5000
+					}
+				}
+
+				and_sub_policy {
+					name = "important"
+					type = "string_attribute"
+
+					string_attribute {
+						key    = "tier"
+						values = ["gold"]
+					}
+				}
+			}
+		}
+
+		output {}
+	`
+	var args tailsampling.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	otelCfg := args.Convert().(*tsp.Config)
+
+	require.Equal(t, 10*time.Second, otelCfg.DecisionWait)
+	require.Equal(t, uint64(50_000), otelCfg.NumTraces)
+	require.Len(t, otelCfg.PolicyCfgs, 2)
+
+	errors := otelCfg.PolicyCfgs[0]
+	require.Equal(t, "errors", errors.Name)
+	require.Equal(t, tsp.StatusCode, errors.Type)
+	require.Equal(t, []string{"ERROR"}, errors.StatusCodeCfg.StatusCodes)
+
+	and := otelCfg.PolicyCfgs[1]
+	require.Equal(t, tsp.And, and.Type)
+	require.Len(t, and.AndCfg.SubPolicyCfg, 2)
+	require.Equal(t, tsp.Latency, and.AndCfg.SubPolicyCfg[0].Type)
+	require.Equal(t, int64(5000), and.AndCfg.SubPolicyCfg[0].LatencyCfg.ThresholdMs)
+	require.Equal(t, "tier", and.AndCfg.SubPolicyCfg[1].StringAttributeCfg.Key)
+	require.Equal(t, []string{"gold"}, and.AndCfg.SubPolicyCfg[1].StringAttributeCfg.Values)
+}
+
+func TestArguments_NoPolicies(t *testing.T) {
+	var args tailsampling.Arguments
+	err := river.Unmarshal([]byte(`output {}`), &args)
+	require.Error(t, err)
+}
@@ -0,0 +1,284 @@
+package tailsampling
+
+import (
+	tsp "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor"
+)
+
+// PolicyArguments configures a single top-level sampling policy.
+type PolicyArguments struct {
+	Name string `river:"name,attr"`
+	Type string `river:"type,attr"`
+
+	Latency          LatencyArguments          `river:"latency,block,optional"`
+	NumericAttribute NumericAttributeArguments `river:"numeric_attribute,block,optional"`
+	Probabilistic    ProbabilisticArguments    `river:"probabilistic,block,optional"`
+	StatusCode       StatusCodeArguments       `river:"status_code,block,optional"`
+	StringAttribute  StringAttributeArguments  `river:"string_attribute,block,optional"`
+	RateLimiting     RateLimitingArguments     `river:"rate_limiting,block,optional"`
+	SpanCount        SpanCountArguments        `river:"span_count,block,optional"`
+	TraceState       TraceStateArguments       `river:"trace_state,block,optional"`
+
+	Composite CompositeArguments `river:"composite,block,optional"`
+	And       AndArguments       `river:"and,block,optional"`
+}
+
+// Convert converts args into the upstream type.
+func (args PolicyArguments) Convert() tsp.PolicyCfg {
+	var res tsp.PolicyCfg
+
+	res.Name = args.Name
+	res.Type = tsp.PolicyType(args.Type)
+	res.LatencyCfg = args.Latency.Convert()
+	res.NumericAttributeCfg = args.NumericAttribute.Convert()
+	res.ProbabilisticCfg = args.Probabilistic.Convert()
+	res.StatusCodeCfg = args.StatusCode.Convert()
+	res.StringAttributeCfg = args.StringAttribute.Convert()
+	res.RateLimitingCfg = args.RateLimiting.Convert()
+	res.SpanCountCfg = args.SpanCount.Convert()
+	res.TraceStateCfg = args.TraceState.Convert()
+	res.CompositeCfg = args.Composite.Convert()
+	res.AndCfg = args.And.Convert()
+
+	return res
+}
+
+// CompositeSubPolicyArguments configures a policy which is part of a
+// composite policy.
+type CompositeSubPolicyArguments struct {
+	Name string `river:"name,attr"`
+	Type string `river:"type,attr"`
+
+	Latency          LatencyArguments          `river:"latency,block,optional"`
+	NumericAttribute NumericAttributeArguments `river:"numeric_attribute,block,optional"`
+	Probabilistic    ProbabilisticArguments    `river:"probabilistic,block,optional"`
+	StatusCode       StatusCodeArguments       `river:"status_code,block,optional"`
+	StringAttribute  StringAttributeArguments  `river:"string_attribute,block,optional"`
+	RateLimiting     RateLimitingArguments     `river:"rate_limiting,block,optional"`
+	SpanCount        SpanCountArguments        `river:"span_count,block,optional"`
+	TraceState       TraceStateArguments       `river:"trace_state,block,optional"`
+
+	And AndArguments `river:"and,block,optional"`
+}
+
+// Convert converts args into the upstream type.
+func (args CompositeSubPolicyArguments) Convert() tsp.CompositeSubPolicyCfg {
+	var res tsp.CompositeSubPolicyCfg
+
+	res.Name = args.Name
+	res.Type = tsp.PolicyType(args.Type)
+	res.LatencyCfg = args.Latency.Convert()
+	res.NumericAttributeCfg = args.NumericAttribute.Convert()
+	res.ProbabilisticCfg = args.Probabilistic.Convert()
+	res.StatusCodeCfg = args.StatusCode.Convert()
+	res.StringAttributeCfg = args.StringAttribute.Convert()
+	res.RateLimitingCfg = args.RateLimiting.Convert()
+	res.SpanCountCfg = args.SpanCount.Convert()
+	res.TraceStateCfg = args.TraceState.Convert()
+	res.AndCfg = args.And.Convert()
+
+	return res
+}
+
+// AndSubPolicyArguments configures a policy which is part of an and policy.
+type AndSubPolicyArguments struct {
+	Name string `river:"name,attr"`
+	Type string `river:"type,attr"`
+
+	Latency          LatencyArguments          `river:"latency,block,optional"`
+	NumericAttribute NumericAttributeArguments `river:"numeric_attribute,block,optional"`
+	Probabilistic    ProbabilisticArguments    `river:"probabilistic,block,optional"`
+	StatusCode       StatusCodeArguments       `river:"status_code,block,optional"`
+	StringAttribute  StringAttributeArguments  `river:"string_attribute,block,optional"`
+	RateLimiting     RateLimitingArguments     `river:"rate_limiting,block,optional"`
+	SpanCount        SpanCountArguments        `river:"span_count,block,optional"`
+	TraceState       TraceStateArguments       `river:"trace_state,block,optional"`
+}
+
+// Convert converts args into the upstream type.
+func (args AndSubPolicyArguments) Convert() tsp.AndSubPolicyCfg {
+	var res tsp.AndSubPolicyCfg
+
+	res.Name = args.Name
+	res.Type = tsp.PolicyType(args.Type)
+	res.LatencyCfg = args.Latency.Convert()
+	res.NumericAttributeCfg = args.NumericAttribute.Convert()
+	res.ProbabilisticCfg = args.Probabilistic.Convert()
+	res.StatusCodeCfg = args.StatusCode.Convert()
+	res.StringAttributeCfg = args.StringAttribute.Convert()
+	res.RateLimitingCfg = args.RateLimiting.Convert()
+	res.SpanCountCfg = args.SpanCount.Convert()
+	res.TraceStateCfg = args.TraceState.Convert()
+
+	return res
+}
+
+// LatencyArguments configures a policy which samples traces longer than a
+// threshold.
+type LatencyArguments struct {
+	ThresholdMs int64 `river:"threshold_ms,attr"`
+}
+
+// Convert converts args into the upstream type.
+func (args LatencyArguments) Convert() tsp.LatencyCfg {
+	return tsp.LatencyCfg{ThresholdMs: args.ThresholdMs}
+}
+
+// NumericAttributeArguments configures a policy which samples traces with a
+// numeric attribute in a range.
+type NumericAttributeArguments struct {
+	Key      string `river:"key,attr"`
+	MinValue int64  `river:"min_value,attr"`
+	MaxValue int64  `river:"max_value,attr"`
+}
+
+// Convert converts args into the upstream type.
+func (args NumericAttributeArguments) Convert() tsp.NumericAttributeCfg {
+	return tsp.NumericAttributeCfg{
+		Key:      args.Key,
+		MinValue: args.MinValue,
+		MaxValue: args.MaxValue,
+	}
+}
+
+// ProbabilisticArguments configures a policy which samples a percentage of
+// traces.
+type ProbabilisticArguments struct {
+	HashSalt           string  `river:"hash_salt,attr,optional"`
+	SamplingPercentage float64 `river:"sampling_percentage,attr"`
+}
+
+// Convert converts args into the upstream type.
+func (args ProbabilisticArguments) Convert() tsp.ProbabilisticCfg {
+	return tsp.ProbabilisticCfg{
+		HashSalt:           args.HashSalt,
+		SamplingPercentage: args.SamplingPercentage,
+	}
+}
+
+// StatusCodeArguments configures a policy which samples traces with a given
+// status code.
+type StatusCodeArguments struct {
+	StatusCodes []string `river:"status_codes,attr"`
+}
+
+// Convert converts args into the upstream type.
+func (args StatusCodeArguments) Convert() tsp.StatusCodeCfg {
+	return tsp.StatusCodeCfg{StatusCodes: args.StatusCodes}
+}
+
+// StringAttributeArguments configures a policy which samples traces with a
+// string attribute matching one of a set of values.
+type StringAttributeArguments struct {
+	Key                  string   `river:"key,attr"`
+	Values               []string `river:"values,attr"`
+	EnabledRegexMatching bool     `river:"enabled_regex_matching,attr,optional"`
+	CacheMaxSize         int      `river:"cache_max_size,attr,optional"`
+	InvertMatch          bool     `river:"invert_match,attr,optional"`
+}
+
+// Convert converts args into the upstream type.
+func (args StringAttributeArguments) Convert() tsp.StringAttributeCfg {
+	return tsp.StringAttributeCfg{
+		Key:                  args.Key,
+		Values:               args.Values,
+		EnabledRegexMatching: args.EnabledRegexMatching,
+		CacheMaxSize:         args.CacheMaxSize,
+		InvertMatch:          args.InvertMatch,
+	}
+}
+
+// RateLimitingArguments configures a policy which samples traces until a
+// rate of spans per second is reached.
+type RateLimitingArguments struct {
+	SpansPerSecond int64 `river:"spans_per_second,attr"`
+}
+
+// Convert converts args into the upstream type.
+func (args RateLimitingArguments) Convert() tsp.RateLimitingCfg {
+	return tsp.RateLimitingCfg{SpansPerSecond: args.SpansPerSecond}
+}
+
+// SpanCountArguments configures a policy which samples traces with a minimum
+// number of spans.
+type SpanCountArguments struct {
+	MinSpans int32 `river:"min_spans,attr"`
+}
+
+// Convert converts args into the upstream type.
+func (args SpanCountArguments) Convert() tsp.SpanCountCfg {
+	return tsp.SpanCountCfg{MinSpans: args.MinSpans}
+}
+
+// TraceStateArguments configures a policy which samples traces with a trace
+// state key matching one of a set of values.
+type TraceStateArguments struct {
+	Key    string   `river:"key,attr"`
+	Values []string `river:"values,attr"`
+}
+
+// Convert converts args into the upstream type.
+func (args TraceStateArguments) Convert() tsp.TraceStateCfg {
+	return tsp.TraceStateCfg{
+		Key:    args.Key,
+		Values: args.Values,
+	}
+}
+
+// CompositeArguments configures a policy which combines other policies,
+// allocating a share of the sampling rate to each.
+type CompositeArguments struct {
+	MaxTotalSpansPerSecond int64                         `river:"max_total_spans_per_second,attr,optional"`
+	PolicyOrder            []string                      `river:"policy_order,attr,optional"`
+	SubPolicies            []CompositeSubPolicyArguments `river:"composite_sub_policy,block,optional"`
+	RateAllocation         []RateAllocationArguments     `river:"rate_allocation,block,optional"`
+}
+
+// Convert converts args into the upstream type.
+func (args CompositeArguments) Convert() tsp.CompositeCfg {
+	var subPolicies []tsp.CompositeSubPolicyCfg
+	for _, p := range args.SubPolicies {
+		subPolicies = append(subPolicies, p.Convert())
+	}
+
+	var rateAllocation []tsp.RateAllocationCfg
+	for _, ra := range args.RateAllocation {
+		rateAllocation = append(rateAllocation, ra.Convert())
+	}
+
+	return tsp.CompositeCfg{
+		MaxTotalSpansPerSecond: args.MaxTotalSpansPerSecond,
+		PolicyOrder:            args.PolicyOrder,
+		SubPolicyCfg:           subPolicies,
+		RateAllocation:         rateAllocation,
+	}
+}
+
+// RateAllocationArguments configures the share of the sampling rate given to
+// a policy within a composite policy.
+type RateAllocationArguments struct {
+	Policy  string `river:"policy,attr"`
+	Percent int64  `river:"percent,attr"`
+}
+
+// Convert converts args into the upstream type.
+func (args RateAllocationArguments) Convert() tsp.RateAllocationCfg {
+	return tsp.RateAllocationCfg{
+		Policy:  args.Policy,
+		Percent: args.Percent,
+	}
+}
+
+// AndArguments configures a policy which samples traces only when all of its
+// sub-policies sample them.
+type AndArguments struct {
+	SubPolicies []AndSubPolicyArguments `river:"and_sub_policy,block,optional"`
+}
+
+// Convert converts args into the upstream type.
+func (args AndArguments) Convert() tsp.AndCfg {
+	var subPolicies []tsp.AndSubPolicyCfg
+	for _, p := range args.SubPolicies {
+		subPolicies = append(subPolicies, p.Convert())
+	}
+	return tsp.AndCfg{SubPolicyCfg: subPolicies}
+}
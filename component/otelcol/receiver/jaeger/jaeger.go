@@ -0,0 +1,205 @@
+// Package jaeger provides an otelcol.receiver.jaeger component.
+package jaeger
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/receiver"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/jaegerreceiver"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name: "otelcol.receiver.jaeger",
+		Args: Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := jaegerreceiver.NewFactory()
+			return receiver.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.receiver.jaeger component.
+type Arguments struct {
+	Protocols ProtocolsArguments `river:"protocols,block"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+var (
+	_ receiver.Arguments = Arguments{}
+	_ river.Unmarshaler  = (*Arguments)(nil)
+)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = Arguments{}
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	p := args.Protocols
+	if p.GRPC == nil && p.ThriftHTTP == nil && p.ThriftBinary == nil && p.ThriftCompact == nil {
+		return fmt.Errorf("at least one protocol must be enabled")
+	}
+	return nil
+}
+
+// Convert implements receiver.Arguments.
+func (args Arguments) Convert() otelconfig.Receiver {
+	return &jaegerreceiver.Config{
+		ReceiverSettings: otelconfig.NewReceiverSettings(otelconfig.NewComponentID("jaeger")),
+		Protocols: jaegerreceiver.Protocols{
+			GRPC:          (*otelcol.GRPCServerArguments)(args.Protocols.GRPC).Convert(),
+			ThriftHTTP:    (*otelcol.HTTPServerArguments)(args.Protocols.ThriftHTTP).Convert(),
+			ThriftBinary:  args.Protocols.ThriftBinary.Convert(),
+			ThriftCompact: args.Protocols.ThriftCompact.Convert(),
+		},
+	}
+}
+
+// Extensions implements receiver.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements receiver.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements receiver.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// ProtocolsArguments configures protocols for otelcol.receiver.jaeger to
+// listen on.
+type ProtocolsArguments struct {
+	GRPC          *GRPC          `river:"grpc,block,optional"`
+	ThriftHTTP    *ThriftHTTP    `river:"thrift_http,block,optional"`
+	ThriftBinary  *ThriftBinary  `river:"thrift_binary,block,optional"`
+	ThriftCompact *ThriftCompact `river:"thrift_compact,block,optional"`
+}
+
+// GRPC wraps otelcol.GRPCServerArguments to provide a default value.
+type GRPC otelcol.GRPCServerArguments
+
+var _ river.Unmarshaler = (*GRPC)(nil)
+
+// Default server settings.
+var (
+	DefaultGRPC = GRPC{
+		Endpoint:  "0.0.0.0:14250",
+		Transport: "tcp",
+	}
+
+	DefaultThriftHTTP = ThriftHTTP{
+		Endpoint: "0.0.0.0:14268",
+	}
+
+	DefaultThriftBinary = ThriftBinary{
+		Endpoint:         "0.0.0.0:6832",
+		QueueSize:        1_000,
+		MaxPacketSize:    65_000,
+		Workers:          10,
+		SocketBufferSize: 0,
+	}
+
+	DefaultThriftCompact = ThriftCompact{
+		Endpoint:         "0.0.0.0:6831",
+		QueueSize:        1_000,
+		MaxPacketSize:    65_000,
+		Workers:          10,
+		SocketBufferSize: 0,
+	}
+)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *GRPC) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultGRPC
+	type arguments GRPC
+	return f((*arguments)(args))
+}
+
+// ThriftHTTP wraps otelcol.HTTPServerArguments to provide a default value.
+type ThriftHTTP otelcol.HTTPServerArguments
+
+var _ river.Unmarshaler = (*ThriftHTTP)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *ThriftHTTP) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultThriftHTTP
+	type arguments ThriftHTTP
+	return f((*arguments)(args))
+}
+
+// ProtocolUDP configures a UDP server.
+type ProtocolUDP struct {
+	Endpoint         string           `river:"endpoint,attr,optional"`
+	QueueSize        int              `river:"queue_size,attr,optional"`
+	MaxPacketSize    units.Base2Bytes `river:"max_packet_size,attr,optional"`
+	Workers          int              `river:"workers,attr,optional"`
+	SocketBufferSize units.Base2Bytes `river:"socket_buffer_size,attr,optional"`
+}
+
+// Convert converts proto into the upstream type.
+func (proto *ProtocolUDP) Convert() *jaegerreceiver.ProtocolUDP {
+	if proto == nil {
+		return nil
+	}
+
+	return &jaegerreceiver.ProtocolUDP{
+		Endpoint: proto.Endpoint,
+		ServerConfigUDP: jaegerreceiver.ServerConfigUDP{
+			QueueSize:        proto.QueueSize,
+			MaxPacketSize:    int(proto.MaxPacketSize),
+			Workers:          proto.Workers,
+			SocketBufferSize: int(proto.SocketBufferSize),
+		},
+	}
+}
+
+// ThriftCompact wraps ProtocolUDP and provides additional behavior.
+type ThriftCompact ProtocolUDP
+
+var _ river.Unmarshaler = (*ThriftCompact)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *ThriftCompact) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultThriftCompact
+	type arguments ThriftCompact
+	return f((*arguments)(args))
+}
+
+// Convert converts proto into the upstream type.
+func (args *ThriftCompact) Convert() *jaegerreceiver.ProtocolUDP {
+	return (*ProtocolUDP)(args).Convert()
+}
+
+// ThriftBinary wraps ProtocolUDP and provides additional behavior.
+type ThriftBinary ProtocolUDP
+
+var _ river.Unmarshaler = (*ThriftBinary)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *ThriftBinary) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultThriftBinary
+	type arguments ThriftBinary
+	return f((*arguments)(args))
+}
+
+// Convert converts proto into the upstream type.
+func (args *ThriftBinary) Convert() *jaegerreceiver.ProtocolUDP {
+	return (*ProtocolUDP)(args).Convert()
+}
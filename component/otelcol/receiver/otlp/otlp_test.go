@@ -0,0 +1,169 @@
+package otlp_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/receiver/otlp"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+)
+
+// Test performs a basic integration test which runs the otelcol.receiver.otlp
+// component and ensures that it can receive and forward data.
+func Test(t *testing.T) {
+	httpAddr := getFreeAddr(t)
+
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.receiver.otlp")
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+		http {
+			endpoint = "%s"
+		}
+
+		output { /* no-op */ }
+	`, httpAddr)
+
+	var args otlp.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	// Override our settings so traces get forwarded to tracesCh.
+	tracesCh := make(chan ptrace.Traces)
+	args.Output = makeTracesOutput(tracesCh)
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second))
+
+	// Send traces in the background to our receiver.
+	go func() {
+		request := ptraceotlp.NewRequestFromTraces(createTestTraces())
+		bb, err := request.MarshalProto()
+		require.NoError(t, err)
+
+		// The receiver may not be listening yet, so retry until the request
+		// succeeds.
+		url := fmt.Sprintf("http://%s/v1/traces", httpAddr)
+		for {
+			resp, err := http.Post(url, "application/x-protobuf", bytes.NewReader(bb))
+			if err == nil {
+				resp.Body.Close()
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}()
+
+	// Wait for our client to get a span.
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for traces")
+	case tr := <-tracesCh:
+		require.Equal(t, 1, tr.SpanCount())
+	}
+}
+
+func TestArguments_NoProtocols(t *testing.T) {
+	var args otlp.Arguments
+	err := river.Unmarshal([]byte(`output {}`), &args)
+	require.EqualError(t, err, "at least one of the grpc or http blocks must be provided")
+}
+
+func TestArguments_Defaults(t *testing.T) {
+	var args otlp.Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		grpc {}
+		http {}
+		output {}
+	`), &args))
+
+	require.Equal(t, otlp.DefaultGRPCServerArguments, *args.GRPC)
+	require.Equal(t, otlp.DefaultHTTPServerArguments, *args.HTTP)
+}
+
+// getFreeAddr returns a free address to listen on.
+func getFreeAddr(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	return lis.Addr().String()
+}
+
+// makeTracesOutput returns ConsumerArguments which will forward traces to the
+// provided channel.
+func makeTracesOutput(ch chan ptrace.Traces) *otelcol.ConsumerArguments {
+	return &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{tracesConsumerFunc(func(ctx context.Context, t ptrace.Traces) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- t:
+				return nil
+			}
+		})},
+	}
+}
+
+type tracesConsumerFunc func(ctx context.Context, t ptrace.Traces) error
+
+func (f tracesConsumerFunc) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{}
+}
+
+func (f tracesConsumerFunc) ConsumeTraces(ctx context.Context, t ptrace.Traces) error {
+	return f(ctx, t)
+}
+
+func (f tracesConsumerFunc) ConsumeMetrics(_ context.Context, _ pmetric.Metrics) error {
+	return nil
+}
+
+func (f tracesConsumerFunc) ConsumeLogs(_ context.Context, _ plog.Logs) error {
+	return nil
+}
+
+func createTestTraces() ptrace.Traces {
+	// Matches format from the protobuf definition:
+	// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+	var bb = `{
+		"resource_spans": [{
+			"scope_spans": [{
+				"spans": [{
+					"name": "TestSpan"
+				}]
+			}]
+		}]
+	}`
+
+	data, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces([]byte(bb))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,227 @@
+// Package receiver exposes utilities to create a Flow component from
+// OpenTelemetry Collector receivers.
+package receiver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fanoutconsumer"
+	"github.com/grafana/agent/component/otelcol/internal/lazyconsumer"
+	"github.com/grafana/agent/component/otelcol/internal/scheduler"
+	"github.com/grafana/agent/component/otelcol/internal/zapadapter"
+	"github.com/grafana/agent/pkg/build"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Arguments is an extension of component.Arguments which contains necessary
+// settings for OpenTelemetry Collector receivers.
+type Arguments interface {
+	component.Arguments
+
+	// Convert converts the Arguments into an OpenTelemetry Collector receiver
+	// configuration.
+	Convert() otelconfig.Receiver
+
+	// Extensions returns the set of extensions that the configured component is
+	// allowed to use.
+	Extensions() map[otelconfig.ComponentID]otelcomponent.Extension
+
+	// Exporters returns the set of exporters that are exposed to the configured
+	// component.
+	Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter
+
+	// NextConsumers returns the set of consumers to send data to.
+	NextConsumers() *otelcol.ConsumerArguments
+}
+
+// Receiver is a Flow component shim which manages an OpenTelemetry Collector
+// receiver component.
+type Receiver struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	opts     component.Options
+	factory  otelcomponent.ReceiverFactory
+	consumer *lazyconsumer.Consumer
+
+	sched *scheduler.Scheduler
+
+	// Receivers are only recreated when their configuration or the set of
+	// telemetry signals to receive changes. Otherwise, only the lazy consumer
+	// is updated so that existing connections aren't dropped.
+	mut         sync.Mutex
+	lastConfig  otelconfig.Receiver
+	lastSignals signals
+}
+
+// signals tracks which telemetry signals a receiver has consumers for.
+type signals struct {
+	traces, metrics, logs bool
+}
+
+var (
+	_ component.Component       = (*Receiver)(nil)
+	_ component.HealthComponent = (*Receiver)(nil)
+)
+
+// New creates a new Flow component which encapsulates an OpenTelemetry
+// Collector receiver. args must hold a value of the argument type registered
+// with the Flow component.
+//
+// If the registered Flow component registers exported fields, it is the
+// responsibility of the caller to export values when needed; the Receiver
+// component never exports any values.
+func New(opts component.Options, f otelcomponent.ReceiverFactory, args Arguments) (*Receiver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &Receiver{
+		ctx:    ctx,
+		cancel: cancel,
+
+		opts:     opts,
+		factory:  f,
+		consumer: lazyconsumer.New(ctx),
+
+		sched: scheduler.New(opts.Logger),
+	}
+	if err := r.Update(args); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Run starts the Receiver component.
+func (r *Receiver) Run(ctx context.Context) error {
+	defer r.cancel()
+	return r.sched.Run(ctx)
+}
+
+// Update implements component.Component. It will convert the Arguments into
+// configuration for OpenTelemetry Collector receiver configuration and manage
+// the underlying OpenTelemetry Collector receiver.
+func (r *Receiver) Update(args component.Arguments) error {
+	rargs := args.(Arguments)
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	var (
+		next    = rargs.NextConsumers()
+		signals = signals{
+			traces:  len(next.Traces) > 0,
+			metrics: len(next.Metrics) > 0,
+			logs:    len(next.Logs) > 0,
+		}
+		receiverConfig = rargs.Convert()
+	)
+
+	// Update where received data is sent to first. If the receiver
+	// configuration hasn't changed, this is all that needs to be done.
+	r.consumer.SetConsumers(
+		nextTraces(signals.traces, next),
+		nextMetrics(signals.metrics, next),
+		nextLogs(signals.logs, next),
+	)
+	if r.lastConfig != nil && signals == r.lastSignals && reflect.DeepEqual(receiverConfig, r.lastConfig) {
+		return nil
+	}
+
+	host := scheduler.NewHost(
+		r.opts.Logger,
+		scheduler.WithHostExtensions(rargs.Extensions()),
+		scheduler.WithHostExporters(rargs.Exporters()),
+	)
+
+	settings := otelcomponent.ReceiverCreateSettings{
+		TelemetrySettings: otelcomponent.TelemetrySettings{
+			Logger: zapadapter.New(r.opts.Logger),
+
+			// TODO(rfratto): expose tracing and logging statistics.
+			//
+			// We may want to put off tracing until we have native tracing
+			// instrumentation from Flow, but metrics should come sooner since we're
+			// already set up for supporting component-specific metrics.
+			TracerProvider: trace.NewNoopTracerProvider(),
+			MeterProvider:  metric.NewNoopMeterProvider(),
+		},
+
+		BuildInfo: otelcomponent.BuildInfo{
+			Command:     os.Args[0],
+			Description: "Grafana Agent",
+			Version:     build.Version,
+		},
+	}
+
+	// Create instances of the receiver from our factory for each of our
+	// supported telemetry signals which have a consumer to send data to.
+	var components []otelcomponent.Component
+
+	if signals.traces {
+		tracesReceiver, err := r.factory.CreateTracesReceiver(r.ctx, settings, receiverConfig, r.consumer)
+		if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+			return err
+		} else if tracesReceiver != nil {
+			components = append(components, tracesReceiver)
+		}
+	}
+
+	if signals.metrics {
+		metricsReceiver, err := r.factory.CreateMetricsReceiver(r.ctx, settings, receiverConfig, r.consumer)
+		if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+			return err
+		} else if metricsReceiver != nil {
+			components = append(components, metricsReceiver)
+		}
+	}
+
+	if signals.logs {
+		logsReceiver, err := r.factory.CreateLogsReceiver(r.ctx, settings, receiverConfig, r.consumer)
+		if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+			return err
+		} else if logsReceiver != nil {
+			components = append(components, logsReceiver)
+		}
+	}
+
+	// Schedule the components to run once our component is running.
+	r.sched.Schedule(host, components...)
+	r.lastConfig = receiverConfig
+	r.lastSignals = signals
+	return nil
+}
+
+func nextTraces(enabled bool, next *otelcol.ConsumerArguments) otelconsumer.Traces {
+	if !enabled {
+		return nil
+	}
+	return fanoutconsumer.Traces(next.Traces)
+}
+
+func nextMetrics(enabled bool, next *otelcol.ConsumerArguments) otelconsumer.Metrics {
+	if !enabled {
+		return nil
+	}
+	return fanoutconsumer.Metrics(next.Metrics)
+}
+
+func nextLogs(enabled bool, next *otelcol.ConsumerArguments) otelconsumer.Logs {
+	if !enabled {
+		return nil
+	}
+	return fanoutconsumer.Logs(next.Logs)
+}
+
+// CurrentHealth implements component.HealthComponent.
+func (r *Receiver) CurrentHealth() component.Health {
+	return r.sched.CurrentHealth()
+}
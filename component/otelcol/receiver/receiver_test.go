@@ -0,0 +1,242 @@
+package receiver_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/receiver"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestReceiver(t *testing.T) {
+	// Channel where received traces will be written to.
+	tracesCh := make(chan ptrace.Traces, 1)
+
+	te := newTestEnvironment(t)
+	te.Start(fakeReceiverArgs{
+		Output: &otelcol.ConsumerArguments{
+			Traces: []otelcol.Consumer{newTracesConsumer(tracesCh)},
+		},
+	})
+
+	// Wait for the receiver to be started and then send traces through it.
+	next := te.WaitReceiver(t)
+	testTraces := createTestTraces()
+	require.NoError(t, next.ConsumeTraces(context.Background(), testTraces))
+
+	select {
+	case <-time.After(1 * time.Second):
+		require.FailNow(t, "output consumer did not receive traces")
+	case td := <-tracesCh:
+		require.Equal(t, testTraces, td)
+	}
+}
+
+func TestReceiver_UpdateOutput(t *testing.T) {
+	var (
+		firstCh  = make(chan ptrace.Traces, 1)
+		secondCh = make(chan ptrace.Traces, 1)
+	)
+
+	te := newTestEnvironment(t)
+	te.Start(fakeReceiverArgs{
+		Output: &otelcol.ConsumerArguments{
+			Traces: []otelcol.Consumer{newTracesConsumer(firstCh)},
+		},
+	})
+	next := te.WaitReceiver(t)
+
+	// Changing only the output of the receiver must not recreate the
+	// underlying receiver.
+	require.NoError(t, te.Controller.Update(fakeReceiverArgs{
+		Output: &otelcol.ConsumerArguments{
+			Traces: []otelcol.Consumer{newTracesConsumer(secondCh)},
+		},
+	}))
+	require.Equal(t, 1, te.Created())
+
+	testTraces := createTestTraces()
+	require.NoError(t, next.ConsumeTraces(context.Background(), testTraces))
+
+	select {
+	case <-time.After(1 * time.Second):
+		require.FailNow(t, "updated output consumer did not receive traces")
+	case <-firstCh:
+		require.FailNow(t, "old output consumer received traces")
+	case td := <-secondCh:
+		require.Equal(t, testTraces, td)
+	}
+
+	// Removing all trace consumers stops the receiver from being created.
+	require.NoError(t, te.Controller.Update(fakeReceiverArgs{
+		Output: &otelcol.ConsumerArguments{},
+	}))
+	require.Equal(t, 1, te.Created())
+	require.Error(t, next.ConsumeTraces(context.Background(), testTraces))
+}
+
+type testEnvironment struct {
+	t *testing.T
+
+	Controller *componenttest.Controller
+
+	mut     sync.Mutex
+	created int
+	next    otelconsumer.Traces
+}
+
+func newTestEnvironment(t *testing.T) *testEnvironment {
+	t.Helper()
+
+	te := &testEnvironment{t: t}
+
+	reg := component.Registration{
+		Name: "testcomponent",
+		Args: fakeReceiverArgs{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			// Create a factory which creates a fake receiver capturing the
+			// consumer it was given.
+			factory := otelcomponent.NewReceiverFactory(
+				"testcomponent",
+				func() otelconfig.Receiver {
+					return fakeReceiverArgs{}.Convert()
+				},
+				otelcomponent.WithTracesReceiver(func(
+					_ context.Context,
+					_ otelcomponent.ReceiverCreateSettings,
+					_ otelconfig.Receiver,
+					next otelconsumer.Traces,
+				) (otelcomponent.TracesReceiver, error) {
+					te.mut.Lock()
+					defer te.mut.Unlock()
+					te.created++
+					te.next = next
+					return &fakeReceiver{}, nil
+				}, otelcomponent.StabilityLevelUndefined),
+			)
+
+			return receiver.New(opts, factory, args.(receiver.Arguments))
+		},
+	}
+
+	te.Controller = componenttest.NewControllerFromReg(util.TestLogger(t), reg)
+	return te
+}
+
+func (te *testEnvironment) Start(args fakeReceiverArgs) {
+	go func() {
+		ctx := componenttest.TestContext(te.t)
+		err := te.Controller.Run(ctx, args)
+		require.NoError(te.t, err, "failed to run component")
+	}()
+}
+
+// WaitReceiver waits for the fake receiver to be created and returns the
+// consumer it sends data to.
+func (te *testEnvironment) WaitReceiver(t *testing.T) otelconsumer.Traces {
+	require.NoError(t, te.Controller.WaitRunning(time.Second))
+
+	te.mut.Lock()
+	defer te.mut.Unlock()
+	require.NotNil(t, te.next, "receiver was not created")
+	return te.next
+}
+
+// Created returns the number of times the fake receiver was created.
+func (te *testEnvironment) Created() int {
+	te.mut.Lock()
+	defer te.mut.Unlock()
+	return te.created
+}
+
+type fakeReceiverArgs struct {
+	Output *otelcol.ConsumerArguments
+}
+
+var _ receiver.Arguments = fakeReceiverArgs{}
+
+func (fa fakeReceiverArgs) Convert() otelconfig.Receiver {
+	settings := otelconfig.NewReceiverSettings(otelconfig.NewComponentID("testcomponent"))
+	return &settings
+}
+
+func (fa fakeReceiverArgs) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+func (fa fakeReceiverArgs) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+func (fa fakeReceiverArgs) NextConsumers() *otelcol.ConsumerArguments {
+	return fa.Output
+}
+
+type fakeReceiver struct{}
+
+var _ otelcomponent.TracesReceiver = (*fakeReceiver)(nil)
+
+func (fr *fakeReceiver) Start(_ context.Context, _ otelcomponent.Host) error { return nil }
+func (fr *fakeReceiver) Shutdown(_ context.Context) error                    { return nil }
+
+// tracesConsumer is an otelcol.Consumer which writes received traces to a
+// channel.
+type tracesConsumer struct {
+	ch chan<- ptrace.Traces
+}
+
+func newTracesConsumer(ch chan<- ptrace.Traces) otelcol.Consumer {
+	return &tracesConsumer{ch: ch}
+}
+
+func (tc *tracesConsumer) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{}
+}
+
+func (tc *tracesConsumer) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	select {
+	case tc.ch <- td:
+	default:
+	}
+	return nil
+}
+
+func (tc *tracesConsumer) ConsumeMetrics(_ context.Context, _ pmetric.Metrics) error {
+	return nil
+}
+
+func (tc *tracesConsumer) ConsumeLogs(_ context.Context, _ plog.Logs) error {
+	return nil
+}
+
+func createTestTraces() ptrace.Traces {
+	// Matches format from the protobuf definition:
+	// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+	var bb = `{
+		"resource_spans": [{
+			"scope_spans": [{
+				"spans": [{
+					"name": "TestSpan"
+				}]
+			}]
+		}]
+	}`
+
+	data, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces([]byte(bb))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
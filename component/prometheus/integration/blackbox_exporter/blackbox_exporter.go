@@ -0,0 +1,87 @@
+// Package blackbox_exporter implements the
+// prometheus.integration.blackbox_exporter component.
+package blackbox_exporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	blackbox_integration "github.com/grafana/agent/pkg/integrations/blackbox_exporter"
+	blackbox_config "github.com/prometheus/blackbox_exporter/config"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "blackbox_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the blackbox_exporter
+// integration.
+var DefaultArguments = Arguments{
+	ProbeTimeoutOffset: time.Duration(blackbox_integration.DefaultConfig.ProbeTimeoutOffset * float64(time.Second)),
+}
+
+// Arguments configures the prometheus.integration.blackbox_exporter
+// component.
+type Arguments struct {
+	// ConfigFile and Config hold the blackbox_exporter config with the modules
+	// to use, either as a path to a file or inline as YAML.
+	ConfigFile string `river:"config_file,attr,optional"`
+	Config     string `river:"config,attr,optional"`
+
+	Targets            []Target      `river:"target,block"`
+	ProbeTimeoutOffset time.Duration `river:"probe_timeout_offset,attr,optional"`
+}
+
+// Target is an endpoint to probe. A target is exported for each endpoint.
+type Target struct {
+	Name   string `river:",label"`
+	Target string `river:"address,attr"`
+	Module string `river:"module,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	if err := f((*args)(a)); err != nil {
+		return err
+	}
+
+	if (a.ConfigFile == "") == (a.Config == "") {
+		return fmt.Errorf("exactly one of config_file and config must be provided")
+	}
+	return nil
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	var modules blackbox_config.Config
+	if a.Config != "" {
+		if err := yaml.UnmarshalStrict([]byte(a.Config), &modules); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+
+	targets := make([]blackbox_integration.BlackboxTarget, 0, len(a.Targets))
+	for _, t := range a.Targets {
+		targets = append(targets, blackbox_integration.BlackboxTarget{
+			Name:   t.Name,
+			Target: t.Target,
+			Module: t.Module,
+		})
+	}
+
+	return &blackbox_integration.Config{
+		BlackboxConfigFile: a.ConfigFile,
+		BlackboxTargets:    targets,
+		BlackboxConfig:     modules,
+		ProbeTimeoutOffset: a.ProbeTimeoutOffset.Seconds(),
+	}, nil
+}
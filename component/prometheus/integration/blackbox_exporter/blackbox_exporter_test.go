@@ -0,0 +1,68 @@
+package blackbox_exporter
+
+import (
+	"testing"
+	"time"
+
+	blackbox_integration "github.com/grafana/agent/pkg/integrations/blackbox_exporter"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	cfg := `
+		config = "{ modules: { http_2xx: { prober: http, timeout: 5s } } }"
+
+		target "grafana" {
+			address = "https://grafana.com"
+			module  = "http_2xx"
+		}
+
+		target "prometheus" {
+			address = "https://prometheus.io"
+		}
+	`
+
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	require.Equal(t, 500*time.Millisecond, args.ProbeTimeoutOffset)
+
+	c, err := args.IntegrationConfig()
+	require.NoError(t, err)
+
+	cfgBlackbox := c.(*blackbox_integration.Config)
+	require.Equal(t, []blackbox_integration.BlackboxTarget{
+		{Name: "grafana", Target: "https://grafana.com", Module: "http_2xx"},
+		{Name: "prometheus", Target: "https://prometheus.io"},
+	}, cfgBlackbox.BlackboxTargets)
+	require.Equal(t, "http", cfgBlackbox.BlackboxConfig.Modules["http_2xx"].Prober)
+	require.Equal(t, 5*time.Second, cfgBlackbox.BlackboxConfig.Modules["http_2xx"].Timeout)
+	require.Equal(t, 0.5, cfgBlackbox.ProbeTimeoutOffset)
+}
+
+func TestArguments_Config(t *testing.T) {
+	tt := []struct {
+		name string
+		cfg  string
+	}{
+		{
+			name: "missing config",
+			cfg:  "target \"a\" {\naddress = \"a\"\n}",
+		},
+		{
+			name: "both configs",
+			cfg:  "config_file = \"blackbox.yml\"\nconfig = \"{}\"\ntarget \"a\" {\naddress = \"a\"\n}",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args Arguments
+			require.EqualError(t, river.Unmarshal([]byte(tc.cfg), &args), "exactly one of config_file and config must be provided")
+		})
+	}
+
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte("config = \"{ modules: { http_2xx: { prober: http, unknown_field: true } } }\"\ntarget \"a\" {\naddress = \"a\"\n}"), &args))
+	_, err := args.IntegrationConfig()
+	require.ErrorContains(t, err, "failed to parse config")
+}
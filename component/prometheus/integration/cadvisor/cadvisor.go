@@ -0,0 +1,85 @@
+// Package cadvisor implements the prometheus.integration.cadvisor component.
+package cadvisor
+
+import (
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	cadvisor_integration "github.com/grafana/agent/pkg/integrations/cadvisor"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name:      "cadvisor",
+		Singleton: true,
+		Args:      Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the cadvisor integration.
+var DefaultArguments = Arguments{
+	StoreContainerLabels: cadvisor_integration.DefaultConfig.StoreContainerLabels,
+	ResctrlInterval:      cadvisor_integration.DefaultConfig.ResctrlInterval,
+	StorageDuration:      cadvisor_integration.DefaultConfig.StorageDuration,
+	Containerd:           cadvisor_integration.DefaultConfig.Containerd,
+	ContainerdNamespace:  cadvisor_integration.DefaultConfig.ContainerdNamespace,
+	Docker:               cadvisor_integration.DefaultConfig.Docker,
+	DockerTLS:            cadvisor_integration.DefaultConfig.DockerTLS,
+	DockerTLSCert:        cadvisor_integration.DefaultConfig.DockerTLSCert,
+	DockerTLSKey:         cadvisor_integration.DefaultConfig.DockerTLSKey,
+	DockerTLSCA:          cadvisor_integration.DefaultConfig.DockerTLSCA,
+	DockerOnly:           cadvisor_integration.DefaultConfig.DockerOnly,
+}
+
+// Arguments configures the prometheus.integration.cadvisor component.
+type Arguments struct {
+	StoreContainerLabels       bool          `river:"store_container_labels,attr,optional"`
+	AllowlistedContainerLabels []string      `river:"allowlisted_container_labels,attr,optional"`
+	EnvMetadataAllowlist       []string      `river:"env_metadata_allowlist,attr,optional"`
+	RawCgroupPrefixAllowlist   []string      `river:"raw_cgroup_prefix_allowlist,attr,optional"`
+	PerfEventsConfig           string        `river:"perf_events_config,attr,optional"`
+	ResctrlInterval            int           `river:"resctrl_interval,attr,optional"`
+	DisabledMetrics            []string      `river:"disabled_metrics,attr,optional"`
+	EnabledMetrics             []string      `river:"enabled_metrics,attr,optional"`
+	StorageDuration            time.Duration `river:"storage_duration,attr,optional"`
+	Containerd                 string        `river:"containerd,attr,optional"`
+	ContainerdNamespace        string        `river:"containerd_namespace,attr,optional"`
+	Docker                     string        `river:"docker,attr,optional"`
+	DockerTLS                  bool          `river:"docker_tls,attr,optional"`
+	DockerTLSCert              string        `river:"docker_tls_cert,attr,optional"`
+	DockerTLSKey               string        `river:"docker_tls_key,attr,optional"`
+	DockerTLSCA                string        `river:"docker_tls_ca,attr,optional"`
+	DockerOnly                 bool          `river:"docker_only,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &cadvisor_integration.Config{
+		StoreContainerLabels:       a.StoreContainerLabels,
+		AllowlistedContainerLabels: a.AllowlistedContainerLabels,
+		EnvMetadataAllowlist:       a.EnvMetadataAllowlist,
+		RawCgroupPrefixAllowlist:   a.RawCgroupPrefixAllowlist,
+		PerfEventsConfig:           a.PerfEventsConfig,
+		ResctrlInterval:            a.ResctrlInterval,
+		DisabledMetrics:            a.DisabledMetrics,
+		EnabledMetrics:             a.EnabledMetrics,
+		StorageDuration:            a.StorageDuration,
+		Containerd:                 a.Containerd,
+		ContainerdNamespace:        a.ContainerdNamespace,
+		Docker:                     a.Docker,
+		DockerTLS:                  a.DockerTLS,
+		DockerTLSCert:              a.DockerTLSCert,
+		DockerTLSKey:               a.DockerTLSKey,
+		DockerTLSCA:                a.DockerTLSCA,
+		DockerOnly:                 a.DockerOnly,
+	}, nil
+}
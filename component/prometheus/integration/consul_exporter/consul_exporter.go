@@ -0,0 +1,73 @@
+// Package consul_exporter implements the
+// prometheus.integration.consul_exporter component.
+package consul_exporter
+
+import (
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	consul_integration "github.com/grafana/agent/pkg/integrations/consul_exporter"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "consul_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the consul_exporter
+// integration.
+var DefaultArguments = Arguments{
+	Server:        consul_integration.DefaultConfig.Server,
+	Timeout:       consul_integration.DefaultConfig.Timeout,
+	AllowStale:    consul_integration.DefaultConfig.AllowStale,
+	KVFilter:      consul_integration.DefaultConfig.KVFilter,
+	HealthSummary: consul_integration.DefaultConfig.HealthSummary,
+}
+
+// Arguments configures the prometheus.integration.consul_exporter component.
+type Arguments struct {
+	Server             string        `river:"server,attr,optional"`
+	CAFile             string        `river:"ca_file,attr,optional"`
+	CertFile           string        `river:"cert_file,attr,optional"`
+	KeyFile            string        `river:"key_file,attr,optional"`
+	ServerName         string        `river:"server_name,attr,optional"`
+	Timeout            time.Duration `river:"timeout,attr,optional"`
+	InsecureSkipVerify bool          `river:"insecure_skip_verify,attr,optional"`
+	RequestLimit       int           `river:"concurrent_request_limit,attr,optional"`
+	AllowStale         bool          `river:"allow_stale,attr,optional"`
+	RequireConsistent  bool          `river:"require_consistent,attr,optional"`
+
+	KVPrefix      string `river:"kv_prefix,attr,optional"`
+	KVFilter      string `river:"kv_filter,attr,optional"`
+	HealthSummary bool   `river:"generate_health_summary,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &consul_integration.Config{
+		Server:             a.Server,
+		CAFile:             a.CAFile,
+		CertFile:           a.CertFile,
+		KeyFile:            a.KeyFile,
+		ServerName:         a.ServerName,
+		Timeout:            a.Timeout,
+		InsecureSkipVerify: a.InsecureSkipVerify,
+		RequestLimit:       a.RequestLimit,
+		AllowStale:         a.AllowStale,
+		RequireConsistent:  a.RequireConsistent,
+		KVPrefix:           a.KVPrefix,
+		KVFilter:           a.KVFilter,
+		HealthSummary:      a.HealthSummary,
+	}, nil
+}
@@ -0,0 +1,48 @@
+// Package dnsmasq_exporter implements the
+// prometheus.integration.dnsmasq_exporter component.
+package dnsmasq_exporter
+
+import (
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	dnsmasq_integration "github.com/grafana/agent/pkg/integrations/dnsmasq_exporter"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "dnsmasq_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the dnsmasq_exporter
+// integration.
+var DefaultArguments = Arguments{
+	DnsmasqAddress: dnsmasq_integration.DefaultConfig.DnsmasqAddress,
+	LeasesPath:     dnsmasq_integration.DefaultConfig.LeasesPath,
+}
+
+// Arguments configures the prometheus.integration.dnsmasq_exporter component.
+type Arguments struct {
+	// DnsmasqAddress is the address of the dnsmasq server (host:port).
+	DnsmasqAddress string `river:"dnsmasq_address,attr,optional"`
+
+	// Path to the dnsmasq leases file.
+	LeasesPath string `river:"leases_path,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &dnsmasq_integration.Config{
+		DnsmasqAddress: a.DnsmasqAddress,
+		LeasesPath:     a.LeasesPath,
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Package elasticsearch_exporter implements the
+// prometheus.integration.elasticsearch_exporter component.
+package elasticsearch_exporter
+
+import (
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	elasticsearch_integration "github.com/grafana/agent/pkg/integrations/elasticsearch_exporter"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "elasticsearch_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the elasticsearch_exporter
+// integration.
+var DefaultArguments = Arguments{
+	Address:                   elasticsearch_integration.DefaultConfig.Address,
+	Timeout:                   elasticsearch_integration.DefaultConfig.Timeout,
+	Node:                      elasticsearch_integration.DefaultConfig.Node,
+	ExportClusterInfoInterval: elasticsearch_integration.DefaultConfig.ExportClusterInfoInterval,
+}
+
+// Arguments configures the prometheus.integration.elasticsearch_exporter
+// component.
+type Arguments struct {
+	Address                   string        `river:"address,attr,optional"`
+	Timeout                   time.Duration `river:"timeout,attr,optional"`
+	AllNodes                  bool          `river:"all,attr,optional"`
+	Node                      string        `river:"node,attr,optional"`
+	ExportIndices             bool          `river:"indices,attr,optional"`
+	ExportIndicesSettings     bool          `river:"indices_settings,attr,optional"`
+	ExportClusterSettings     bool          `river:"cluster_settings,attr,optional"`
+	ExportShards              bool          `river:"shards,attr,optional"`
+	ExportSnapshots           bool          `river:"snapshots,attr,optional"`
+	ExportClusterInfoInterval time.Duration `river:"clusterinfo_interval,attr,optional"`
+	CA                        string        `river:"ca,attr,optional"`
+	ClientPrivateKey          string        `river:"client_private_key,attr,optional"`
+	ClientCert                string        `river:"client_cert,attr,optional"`
+	InsecureSkipVerify        bool          `river:"ssl_skip_verify,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &elasticsearch_integration.Config{
+		Address:                   a.Address,
+		Timeout:                   a.Timeout,
+		AllNodes:                  a.AllNodes,
+		Node:                      a.Node,
+		ExportIndices:             a.ExportIndices,
+		ExportIndicesSettings:     a.ExportIndicesSettings,
+		ExportClusterSettings:     a.ExportClusterSettings,
+		ExportShards:              a.ExportShards,
+		ExportSnapshots:           a.ExportSnapshots,
+		ExportClusterInfoInterval: a.ExportClusterInfoInterval,
+		CA:                        a.CA,
+		ClientPrivateKey:          a.ClientPrivateKey,
+		ClientCert:                a.ClientCert,
+		InsecureSkipVerify:        a.InsecureSkipVerify,
+	}, nil
+}
@@ -0,0 +1,54 @@
+// Package github_exporter implements the
+// prometheus.integration.github_exporter component.
+package github_exporter
+
+import (
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations"
+	github_integration "github.com/grafana/agent/pkg/integrations/github_exporter"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "github_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the github_exporter
+// integration.
+var DefaultArguments = Arguments{
+	APIURL: github_integration.DefaultConfig.APIURL,
+}
+
+// Arguments configures the prometheus.integration.github_exporter component.
+type Arguments struct {
+	APIURL        string            `river:"api_url,attr,optional"`
+	Repositories  []string          `river:"repositories,attr,optional"`
+	Organizations []string          `river:"organizations,attr,optional"`
+	Users         []string          `river:"users,attr,optional"`
+	APIToken      rivertypes.Secret `river:"api_token,attr,optional"`
+	APITokenFile  string            `river:"api_token_file,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &github_integration.Config{
+		APIURL:        a.APIURL,
+		Repositories:  a.Repositories,
+		Organizations: a.Organizations,
+		Users:         a.Users,
+		APIToken:      config_util.Secret(a.APIToken),
+		APITokenFile:  a.APITokenFile,
+	}, nil
+}
@@ -0,0 +1,263 @@
+// Package integration implements a bridge which exposes integrations from
+// github.com/grafana/agent/pkg/integrations as prometheus.integration.*
+// components.
+//
+// Each integration component only needs to define its River arguments and
+// convert them into the config of the integration; the bridge takes care of
+// running the integration, serving its metrics, and exporting targets which
+// can be scraped by prometheus.scrape.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/pkg/integrations"
+	"github.com/grafana/agent/pkg/integrations/config"
+	"github.com/prometheus/common/model"
+)
+
+// Arguments is implemented by the arguments of integration components.
+type Arguments interface {
+	// IntegrationConfig converts the arguments into the config of the
+	// integration to run.
+	IntegrationConfig() (integrations.Config, error)
+}
+
+// Registration describes an integration component.
+type Registration struct {
+	// Name of the integration. The component is registered as
+	// prometheus.integration.<Name>.
+	Name string
+
+	// Singleton marks integrations which collect metrics about the machine the
+	// agent runs on, and can therefore only be defined once. Other integrations
+	// require a label so that multiple instances can be defined, such as one
+	// per database.
+	Singleton bool
+
+	// Args is the zero value of the arguments of the component. Its methods
+	// must be defined on the value type.
+	Args Arguments
+}
+
+// Register registers an integration component.
+func Register(r Registration) {
+	component.Register(component.Registration{
+		Name:      "prometheus.integration." + r.Name,
+		Args:      r.Args,
+		Exports:   Exports{},
+		Singleton: r.Singleton,
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Exports are simply a list of targets for a scraper to consume.
+type Exports struct {
+	Targets []discovery.Target `river:"targets,attr"`
+}
+
+// Component runs an integration.
+type Component struct {
+	opts     component.Options
+	agentKey string
+
+	mut         sync.Mutex
+	integration integrations.Integration
+	handler     http.Handler
+	targets     []discovery.Target // Most recently exported targets.
+
+	healthMut sync.RWMutex
+	health    component.Health
+
+	// updated is written to when the integration changes so Run restarts it.
+	updated chan struct{}
+}
+
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.HealthComponent = (*Component)(nil)
+	_ component.HTTPComponent   = (*Component)(nil)
+)
+
+// New creates a new integration component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:     o,
+		agentKey: agentKey(o.HTTPListenAddr),
+		updated:  make(chan struct{}, 1),
+	}
+
+	// Call to Update() to create the integration and set the output once at
+	// the start.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// agentKey returns the key which identifies the agent process, used by
+// integrations which collect metrics about the machine the agent runs on as
+// the value of the instance label. Like in static mode, the key is the
+// hostname of the machine and the port of the HTTP server.
+func agentKey(listenAddr string) string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return listenAddr
+	}
+	_, port, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return hostname
+	}
+	return net.JoinHostPort(hostname, port)
+}
+
+// Run implements component.Component. The integration is restarted whenever
+// the component is updated.
+func (c *Component) Run(ctx context.Context) error {
+	for {
+		c.mut.Lock()
+		integration := c.integration
+		c.mut.Unlock()
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() { done <- integration.Run(runCtx) }()
+
+		c.setHealth(component.Health{
+			Health:     component.HealthTypeHealthy,
+			Message:    "integration running",
+			UpdateTime: time.Now(),
+		})
+
+		var exited bool
+		select {
+		case <-ctx.Done():
+		case <-c.updated:
+		case err := <-done:
+			// The integration stopped on its own. Report it and wait for new
+			// arguments before starting it again.
+			exited = true
+			if err == nil {
+				err = fmt.Errorf("integration exited unexpectedly")
+			}
+			level.Error(c.opts.Logger).Log("msg", "integration stopped", "err", err)
+			c.setHealth(component.Health{
+				Health:     component.HealthTypeUnhealthy,
+				Message:    err.Error(),
+				UpdateTime: time.Now(),
+			})
+
+			select {
+			case <-ctx.Done():
+			case <-c.updated:
+			}
+		}
+
+		cancel()
+		if !exited {
+			<-done
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	cfg, err := args.(Arguments).IntegrationConfig()
+	if err != nil {
+		return err
+	}
+	integration, err := cfg.NewIntegration(c.opts.Logger)
+	if err != nil {
+		return fmt.Errorf("failed to create integration: %w", err)
+	}
+	handler, err := integration.MetricsHandler()
+	if err != nil {
+		return fmt.Errorf("failed to create metrics handler: %w", err)
+	}
+
+	instance, err := cfg.InstanceKey(c.agentKey)
+	if err != nil {
+		level.Warn(c.opts.Logger).Log("msg", "failed to determine instance label, using the component ID instead", "err", err)
+		instance = c.opts.ID
+	}
+	targets := c.buildTargets(integration.ScrapeConfigs(), instance)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	// Run only needs to restart the integration if it was already created.
+	if c.integration != nil {
+		select {
+		case c.updated <- struct{}{}:
+		default:
+		}
+	}
+	c.integration = integration
+	c.handler = handler
+
+	if c.targets == nil || !reflect.DeepEqual(c.targets, targets) {
+		c.targets = targets
+		c.opts.OnStateChange(Exports{Targets: targets})
+	}
+	return nil
+}
+
+// buildTargets returns a target for each scrape config of the integration.
+// Query parameters of the scrape configs are added as __param_<name> labels.
+func (c *Component) buildTargets(scs []config.ScrapeConfig, instance string) []discovery.Target {
+	targets := make([]discovery.Target, 0, len(scs))
+	for _, sc := range scs {
+		t := discovery.Target{
+			model.AddressLabel:     c.opts.HTTPListenAddr,
+			model.SchemeLabel:      "http",
+			model.MetricsPathLabel: path.Join(c.opts.HTTPPath, sc.MetricsPath),
+			model.JobLabel:         "integrations/" + sc.JobName,
+			model.InstanceLabel:    instance,
+		}
+		for name, values := range sc.QueryParams {
+			if len(values) > 0 {
+				t[model.ParamLabelPrefix+name] = values[0]
+			}
+		}
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+// Handler implements component.HTTPComponent and serves the metrics of the
+// integration.
+func (c *Component) Handler() http.Handler {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.handler
+}
+
+// CurrentHealth implements component.HealthComponent.
+func (c *Component) CurrentHealth() component.Health {
+	c.healthMut.RLock()
+	defer c.healthMut.RUnlock()
+	return c.health
+}
+
+func (c *Component) setHealth(h component.Health) {
+	c.healthMut.Lock()
+	defer c.healthMut.Unlock()
+	c.health = h
+}
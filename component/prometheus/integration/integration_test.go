@@ -0,0 +1,187 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	"github.com/grafana/agent/pkg/integrations/config"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestComponent(t *testing.T) {
+	var (
+		runs    atomic.Int32
+		exports = make(chan component.Exports, 10)
+	)
+
+	opts := component.Options{
+		ID:             "prometheus.integration.fake.default",
+		Logger:         util.TestLogger(t),
+		OnStateChange:  func(e component.Exports) { exports <- e },
+		HTTPListenAddr: "127.0.0.1:12345",
+		HTTPPath:       "/api/v0/component/prometheus.integration.fake.default/",
+	}
+
+	c, err := integration.New(opts, fakeArguments{Instance: "first:9000", Metric: "first", runs: &runs})
+	require.NoError(t, err)
+
+	require.Equal(t, integration.Exports{
+		Targets: []discovery.Target{{
+			"__address__":      "127.0.0.1:12345",
+			"__scheme__":       "http",
+			"__metrics_path__": "/api/v0/component/prometheus.integration.fake.default/metrics",
+			"__param_target":   "example.com",
+			"job":              "integrations/fake",
+			"instance":         "first:9000",
+		}},
+	}, <-exports)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, c.Run(ctx))
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, "first\n", scrape(t, c.Handler()))
+
+	// Updating the component restarts the integration and exports new targets
+	// if they changed.
+	require.NoError(t, c.Update(fakeArguments{Instance: "second:9000", Metric: "second", runs: &runs}))
+	require.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, "second\n", scrape(t, c.Handler()))
+
+	e := (<-exports).(integration.Exports)
+	require.Equal(t, "second:9000", e.Targets[0]["instance"])
+	require.Equal(t, component.HealthTypeHealthy, c.CurrentHealth().Health)
+
+	// Unchanged targets aren't exported again.
+	require.NoError(t, c.Update(fakeArguments{Instance: "second:9000", Metric: "third", runs: &runs}))
+	require.Eventually(t, func() bool { return runs.Load() == 3 }, time.Second, 10*time.Millisecond)
+	require.Len(t, exports, 0)
+}
+
+func TestComponent_IntegrationExits(t *testing.T) {
+	opts := component.Options{
+		ID:            "prometheus.integration.fake.default",
+		Logger:        util.TestLogger(t),
+		OnStateChange: func(e component.Exports) {},
+	}
+
+	c, err := integration.New(opts, fakeArguments{Instance: "first:9000", RunErr: fmt.Errorf("connection refused")})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		h := c.CurrentHealth()
+		return h.Health == component.HealthTypeUnhealthy && h.Message == "connection refused"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestComponent_InstanceFallback(t *testing.T) {
+	exports := make(chan component.Exports, 1)
+	opts := component.Options{
+		ID:            "prometheus.integration.fake.default",
+		Logger:        util.TestLogger(t),
+		OnStateChange: func(e component.Exports) { exports <- e },
+	}
+
+	// Integrations which can't determine an instance use the component ID.
+	_, err := integration.New(opts, fakeArguments{})
+	require.NoError(t, err)
+	e := (<-exports).(integration.Exports)
+	require.Equal(t, "prometheus.integration.fake.default", e.Targets[0]["instance"])
+}
+
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	bb, err := io.ReadAll(rec.Result().Body)
+	require.NoError(t, err)
+	return string(bb)
+}
+
+// fakeArguments configures a fake integration which serves Metric as its
+// metrics.
+type fakeArguments struct {
+	Instance string
+	Metric   string
+	RunErr   error
+
+	runs *atomic.Int32
+}
+
+func (a fakeArguments) IntegrationConfig() (integrations.Config, error) {
+	return &fakeConfig{args: a}, nil
+}
+
+type fakeConfig struct {
+	args fakeArguments
+}
+
+func (c *fakeConfig) Name() string { return "fake" }
+
+func (c *fakeConfig) InstanceKey(agentKey string) (string, error) {
+	if c.args.Instance == "" {
+		return "", fmt.Errorf("no instance")
+	}
+	return c.args.Instance, nil
+}
+
+func (c *fakeConfig) NewIntegration(l log.Logger) (integrations.Integration, error) {
+	return &fakeIntegration{args: c.args}, nil
+}
+
+type fakeIntegration struct {
+	args fakeArguments
+}
+
+func (i *fakeIntegration) MetricsHandler() (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, i.args.Metric)
+	}), nil
+}
+
+func (i *fakeIntegration) ScrapeConfigs() []config.ScrapeConfig {
+	return []config.ScrapeConfig{{
+		JobName:     "fake",
+		MetricsPath: "/metrics",
+		QueryParams: url.Values{"target": []string{"example.com"}},
+	}}
+}
+
+func (i *fakeIntegration) Run(ctx context.Context) error {
+	if i.args.runs != nil {
+		i.args.runs.Inc()
+	}
+	if i.args.RunErr != nil {
+		return i.args.RunErr
+	}
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,91 @@
+// Package kafka_exporter implements the
+// prometheus.integration.kafka_exporter component.
+package kafka_exporter
+
+import (
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations"
+	kafka_integration "github.com/grafana/agent/pkg/integrations/kafka_exporter"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "kafka_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the kafka_exporter
+// integration.
+var DefaultArguments = Arguments{
+	UseSASLHandshake:        kafka_integration.DefaultConfig.UseSASLHandshake,
+	KafkaVersion:            kafka_integration.DefaultConfig.KafkaVersion,
+	MetadataRefreshInterval: kafka_integration.DefaultConfig.MetadataRefreshInterval,
+	AllowConcurrent:         kafka_integration.DefaultConfig.AllowConcurrent,
+	MaxOffsets:              kafka_integration.DefaultConfig.MaxOffsets,
+	PruneIntervalSeconds:    kafka_integration.DefaultConfig.PruneIntervalSeconds,
+	TopicsFilter:            kafka_integration.DefaultConfig.TopicsFilter,
+	GroupFilter:             kafka_integration.DefaultConfig.GroupFilter,
+}
+
+// Arguments configures the prometheus.integration.kafka_exporter component.
+type Arguments struct {
+	KafkaURIs               []string          `river:"kafka_uris,attr"`
+	UseSASL                 bool              `river:"use_sasl,attr,optional"`
+	UseSASLHandshake        bool              `river:"use_sasl_handshake,attr,optional"`
+	SASLUsername            string            `river:"sasl_username,attr,optional"`
+	SASLPassword            rivertypes.Secret `river:"sasl_password,attr,optional"`
+	SASLMechanism           string            `river:"sasl_mechanism,attr,optional"`
+	UseTLS                  bool              `river:"use_tls,attr,optional"`
+	CAFile                  string            `river:"ca_file,attr,optional"`
+	CertFile                string            `river:"cert_file,attr,optional"`
+	KeyFile                 string            `river:"key_file,attr,optional"`
+	InsecureSkipVerify      bool              `river:"insecure_skip_verify,attr,optional"`
+	KafkaVersion            string            `river:"kafka_version,attr,optional"`
+	UseZooKeeperLag         bool              `river:"use_zookeeper_lag,attr,optional"`
+	ZookeeperURIs           []string          `river:"zookeeper_uris,attr,optional"`
+	ClusterName             string            `river:"kafka_cluster_name,attr,optional"`
+	MetadataRefreshInterval string            `river:"metadata_refresh_interval,attr,optional"`
+	AllowConcurrent         bool              `river:"allow_concurrency,attr,optional"`
+	MaxOffsets              int               `river:"max_offsets,attr,optional"`
+	PruneIntervalSeconds    int               `river:"prune_interval_seconds,attr,optional"`
+	TopicsFilter            string            `river:"topics_filter_regex,attr,optional"`
+	GroupFilter             string            `river:"groups_filter_regex,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &kafka_integration.Config{
+		KafkaURIs:               a.KafkaURIs,
+		UseSASL:                 a.UseSASL,
+		UseSASLHandshake:        a.UseSASLHandshake,
+		SASLUsername:            a.SASLUsername,
+		SASLPassword:            config_util.Secret(a.SASLPassword),
+		SASLMechanism:           a.SASLMechanism,
+		UseTLS:                  a.UseTLS,
+		CAFile:                  a.CAFile,
+		CertFile:                a.CertFile,
+		KeyFile:                 a.KeyFile,
+		InsecureSkipVerify:      a.InsecureSkipVerify,
+		KafkaVersion:            a.KafkaVersion,
+		UseZooKeeperLag:         a.UseZooKeeperLag,
+		ZookeeperURIs:           a.ZookeeperURIs,
+		ClusterName:             a.ClusterName,
+		MetadataRefreshInterval: a.MetadataRefreshInterval,
+		AllowConcurrent:         a.AllowConcurrent,
+		MaxOffsets:              a.MaxOffsets,
+		PruneIntervalSeconds:    a.PruneIntervalSeconds,
+		TopicsFilter:            a.TopicsFilter,
+		GroupFilter:             a.GroupFilter,
+	}, nil
+}
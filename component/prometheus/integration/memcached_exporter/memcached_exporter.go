@@ -0,0 +1,51 @@
+// Package memcached_exporter implements the
+// prometheus.integration.memcached_exporter component.
+package memcached_exporter
+
+import (
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	memcached_integration "github.com/grafana/agent/pkg/integrations/memcached_exporter"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "memcached_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the memcached_exporter
+// integration.
+var DefaultArguments = Arguments{
+	MemcachedAddress: memcached_integration.DefaultConfig.MemcachedAddress,
+	Timeout:          memcached_integration.DefaultConfig.Timeout,
+}
+
+// Arguments configures the prometheus.integration.memcached_exporter
+// component.
+type Arguments struct {
+	// MemcachedAddress is the address of the memcached server (host:port).
+	MemcachedAddress string `river:"memcached_address,attr,optional"`
+
+	// Timeout is the connection timeout for memcached.
+	Timeout time.Duration `river:"timeout,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &memcached_integration.Config{
+		MemcachedAddress: a.MemcachedAddress,
+		Timeout:          a.Timeout,
+	}, nil
+}
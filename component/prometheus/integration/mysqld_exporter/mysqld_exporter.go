@@ -0,0 +1,141 @@
+// Package mysqld_exporter implements the
+// prometheus.integration.mysqld_exporter component.
+package mysqld_exporter
+
+import (
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations"
+	mysqld_integration "github.com/grafana/agent/pkg/integrations/mysqld_exporter"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "mysqld_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the mysqld_exporter
+// integration.
+var DefaultArguments = Arguments{
+	LockWaitTimeout: mysqld_integration.DefaultConfig.LockWaitTimeout,
+	InfoSchemaProcessList: InfoSchemaProcessList{
+		ProcessesByUser: mysqld_integration.DefaultConfig.InfoSchemaProcessListProcessesByUser,
+		ProcessesByHost: mysqld_integration.DefaultConfig.InfoSchemaProcessListProcessesByHost,
+	},
+	InfoSchemaTables: InfoSchemaTables{
+		Databases: mysqld_integration.DefaultConfig.InfoSchemaTablesDatabases,
+	},
+	PerfSchemaEventsStatements: PerfSchemaEventsStatements{
+		Limit:     mysqld_integration.DefaultConfig.PerfSchemaEventsStatementsLimit,
+		TimeLimit: mysqld_integration.DefaultConfig.PerfSchemaEventsStatementsTimeLimit,
+		TextLimit: mysqld_integration.DefaultConfig.PerfSchemaEventsStatementsTextLimit,
+	},
+	PerfSchemaFileInstances: PerfSchemaFileInstances{
+		Filter:       mysqld_integration.DefaultConfig.PerfSchemaFileInstancesFilter,
+		RemovePrefix: mysqld_integration.DefaultConfig.PerfSchemaFileInstancesRemovePrefix,
+	},
+	Heartbeat: Heartbeat{
+		Database: mysqld_integration.DefaultConfig.HeartbeatDatabase,
+		Table:    mysqld_integration.DefaultConfig.HeartbeatTable,
+	},
+}
+
+// Arguments configures the prometheus.integration.mysqld_exporter component.
+type Arguments struct {
+	// DataSourceName to use to connect to MySQL.
+	DataSourceName rivertypes.Secret `river:"data_source_name,attr"`
+
+	// Collectors to mark as enabled in addition to the default.
+	EnableCollectors []string `river:"enable_collectors,attr,optional"`
+	// Collectors to explicitly mark as disabled.
+	DisableCollectors []string `river:"disable_collectors,attr,optional"`
+	// Overrides the default set of enabled collectors with the given list.
+	SetCollectors []string `river:"set_collectors,attr,optional"`
+
+	// Collector-wide options
+	LockWaitTimeout int  `river:"lock_wait_timeout,attr,optional"`
+	LogSlowFilter   bool `river:"log_slow_filter,attr,optional"`
+
+	// Collector-specific config options
+	InfoSchemaProcessList      InfoSchemaProcessList      `river:"info_schema.processlist,block,optional"`
+	InfoSchemaTables           InfoSchemaTables           `river:"info_schema.tables,block,optional"`
+	PerfSchemaEventsStatements PerfSchemaEventsStatements `river:"perf_schema.eventsstatements,block,optional"`
+	PerfSchemaFileInstances    PerfSchemaFileInstances    `river:"perf_schema.file_instances,block,optional"`
+	Heartbeat                  Heartbeat                  `river:"heartbeat,block,optional"`
+	MySQLUser                  MySQLUser                  `river:"mysql.user,block,optional"`
+}
+
+// InfoSchemaProcessList configures the info_schema.processlist collector.
+type InfoSchemaProcessList struct {
+	MinTime         int  `river:"min_time,attr,optional"`
+	ProcessesByUser bool `river:"processes_by_user,attr,optional"`
+	ProcessesByHost bool `river:"processes_by_host,attr,optional"`
+}
+
+// InfoSchemaTables configures the info_schema.tables collector.
+type InfoSchemaTables struct {
+	Databases string `river:"databases,attr,optional"`
+}
+
+// PerfSchemaEventsStatements configures the perf_schema.eventsstatements
+// collector.
+type PerfSchemaEventsStatements struct {
+	Limit     int `river:"limit,attr,optional"`
+	TimeLimit int `river:"time_limit,attr,optional"`
+	TextLimit int `river:"text_limit,attr,optional"`
+}
+
+// PerfSchemaFileInstances configures the perf_schema.file_instances
+// collector.
+type PerfSchemaFileInstances struct {
+	Filter       string `river:"filter,attr,optional"`
+	RemovePrefix string `river:"remove_prefix,attr,optional"`
+}
+
+// Heartbeat configures the heartbeat collector.
+type Heartbeat struct {
+	Database string `river:"database,attr,optional"`
+	Table    string `river:"table,attr,optional"`
+	UTC      bool   `river:"utc,attr,optional"`
+}
+
+// MySQLUser configures the mysql.user collector.
+type MySQLUser struct {
+	Privileges bool `river:"privileges,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &mysqld_integration.Config{
+		DataSourceName:                       config_util.Secret(a.DataSourceName),
+		EnableCollectors:                     a.EnableCollectors,
+		DisableCollectors:                    a.DisableCollectors,
+		SetCollectors:                        a.SetCollectors,
+		LockWaitTimeout:                      a.LockWaitTimeout,
+		LogSlowFilter:                        a.LogSlowFilter,
+		InfoSchemaProcessListMinTime:         a.InfoSchemaProcessList.MinTime,
+		InfoSchemaProcessListProcessesByUser: a.InfoSchemaProcessList.ProcessesByUser,
+		InfoSchemaProcessListProcessesByHost: a.InfoSchemaProcessList.ProcessesByHost,
+		InfoSchemaTablesDatabases:            a.InfoSchemaTables.Databases,
+		PerfSchemaEventsStatementsLimit:      a.PerfSchemaEventsStatements.Limit,
+		PerfSchemaEventsStatementsTimeLimit:  a.PerfSchemaEventsStatements.TimeLimit,
+		PerfSchemaEventsStatementsTextLimit:  a.PerfSchemaEventsStatements.TextLimit,
+		PerfSchemaFileInstancesFilter:        a.PerfSchemaFileInstances.Filter,
+		PerfSchemaFileInstancesRemovePrefix:  a.PerfSchemaFileInstances.RemovePrefix,
+		HeartbeatDatabase:                    a.Heartbeat.Database,
+		HeartbeatTable:                       a.Heartbeat.Table,
+		HeartbeatUTC:                         a.Heartbeat.UTC,
+		MySQLUserPrivileges:                  a.MySQLUser.Privileges,
+	}, nil
+}
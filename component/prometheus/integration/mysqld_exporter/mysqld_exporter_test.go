@@ -0,0 +1,40 @@
+package mysqld_exporter
+
+import (
+	"testing"
+
+	mysqld_integration "github.com/grafana/agent/pkg/integrations/mysqld_exporter"
+	"github.com/grafana/agent/pkg/river"
+	config_util "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	cfg := `
+		data_source_name  = "root:secret@(localhost:3306)/"
+		enable_collectors = ["perf_schema.eventsstatements"]
+
+		perf_schema.eventsstatements {
+			limit = 100
+		}
+
+		heartbeat {
+			utc = true
+		}
+	`
+
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	c, err := args.IntegrationConfig()
+	require.NoError(t, err)
+
+	// Settings which weren't provided, including those in partially defined
+	// blocks, keep the defaults of the integration.
+	expect := mysqld_integration.DefaultConfig
+	expect.DataSourceName = config_util.Secret("root:secret@(localhost:3306)/")
+	expect.EnableCollectors = []string{"perf_schema.eventsstatements"}
+	expect.PerfSchemaEventsStatementsLimit = 100
+	expect.HeartbeatUTC = true
+	require.Equal(t, &expect, c)
+}
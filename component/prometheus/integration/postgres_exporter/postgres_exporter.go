@@ -0,0 +1,50 @@
+// Package postgres_exporter implements the
+// prometheus.integration.postgres_exporter component.
+package postgres_exporter
+
+import (
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations"
+	postgres_integration "github.com/grafana/agent/pkg/integrations/postgres_exporter"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "postgres_exporter",
+		Args: Arguments{},
+	})
+}
+
+// Arguments configures the prometheus.integration.postgres_exporter
+// component.
+type Arguments struct {
+	// DataSourceNames to use to connect to Postgres.
+	DataSourceNames []rivertypes.Secret `river:"data_source_names,attr"`
+
+	DisableSettingsMetrics bool     `river:"disable_settings_metrics,attr,optional"`
+	AutodiscoverDatabases  bool     `river:"autodiscover_databases,attr,optional"`
+	ExcludeDatabases       []string `river:"exclude_databases,attr,optional"`
+	IncludeDatabases       []string `river:"include_databases,attr,optional"`
+	DisableDefaultMetrics  bool     `river:"disable_default_metrics,attr,optional"`
+	QueryPath              string   `river:"query_path,attr,optional"`
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	dsns := make([]config_util.Secret, 0, len(a.DataSourceNames))
+	for _, dsn := range a.DataSourceNames {
+		dsns = append(dsns, config_util.Secret(dsn))
+	}
+
+	return &postgres_integration.Config{
+		DataSourceNames:        dsns,
+		DisableSettingsMetrics: a.DisableSettingsMetrics,
+		AutodiscoverDatabases:  a.AutodiscoverDatabases,
+		ExcludeDatabases:       a.ExcludeDatabases,
+		IncludeDatabases:       a.IncludeDatabases,
+		DisableDefaultMetrics:  a.DisableDefaultMetrics,
+		QueryPath:              a.QueryPath,
+	}, nil
+}
@@ -0,0 +1,79 @@
+// Package process_exporter implements the
+// prometheus.integration.process_exporter component.
+package process_exporter
+
+import (
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	process_integration "github.com/grafana/agent/pkg/integrations/process_exporter"
+	exporter_config "github.com/ncabatoff/process-exporter/config"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name:      "process_exporter",
+		Singleton: true,
+		Args:      Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the process_exporter
+// integration.
+var DefaultArguments = Arguments{
+	ProcFSPath: process_integration.DefaultConfig.ProcFSPath,
+	Children:   process_integration.DefaultConfig.Children,
+	Threads:    process_integration.DefaultConfig.Threads,
+	SMaps:      process_integration.DefaultConfig.SMaps,
+	Recheck:    process_integration.DefaultConfig.Recheck,
+}
+
+// Arguments configures the prometheus.integration.process_exporter
+// component.
+type Arguments struct {
+	Matchers []Matcher `river:"matcher,block,optional"`
+
+	ProcFSPath string `river:"procfs_path,attr,optional"`
+	Children   bool   `river:"track_children,attr,optional"`
+	Threads    bool   `river:"track_threads,attr,optional"`
+	SMaps      bool   `river:"gather_smaps,attr,optional"`
+	Recheck    bool   `river:"recheck_on_scrape,attr,optional"`
+}
+
+// Matcher groups processes matching any of its rules. Name is a template
+// for the name of the group.
+type Matcher struct {
+	Name         string   `river:"name,attr,optional"`
+	CommRules    []string `river:"comm,attr,optional"`
+	ExeRules     []string `river:"exe,attr,optional"`
+	CmdlineRules []string `river:"cmdline,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	rules := make(exporter_config.MatcherRules, 0, len(a.Matchers))
+	for _, m := range a.Matchers {
+		rules = append(rules, exporter_config.MatcherGroup{
+			Name:         m.Name,
+			CommRules:    m.CommRules,
+			ExeRules:     m.ExeRules,
+			CmdlineRules: m.CmdlineRules,
+		})
+	}
+
+	return &process_integration.Config{
+		ProcessExporter: rules,
+		ProcFSPath:      a.ProcFSPath,
+		Children:        a.Children,
+		Threads:         a.Threads,
+		SMaps:           a.SMaps,
+		Recheck:         a.Recheck,
+	}, nil
+}
@@ -0,0 +1,107 @@
+// Package redis_exporter implements the
+// prometheus.integration.redis_exporter component.
+package redis_exporter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations"
+	redis_integration "github.com/grafana/agent/pkg/integrations/redis_exporter"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "redis_exporter",
+		Args: Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the redis_exporter
+// integration.
+var DefaultArguments = Arguments{
+	Namespace:               redis_integration.DefaultConfig.Namespace,
+	ConfigCommand:           redis_integration.DefaultConfig.ConfigCommand,
+	ConnectionTimeout:       redis_integration.DefaultConfig.ConnectionTimeout,
+	SetClientName:           redis_integration.DefaultConfig.SetClientName,
+	CheckKeyGroupsBatchSize: redis_integration.DefaultConfig.CheckKeyGroupsBatchSize,
+	MaxDistinctKeyGroups:    redis_integration.DefaultConfig.MaxDistinctKeyGroups,
+}
+
+// Arguments configures the prometheus.integration.redis_exporter component.
+type Arguments struct {
+	IncludeExporterMetrics bool `river:"include_exporter_metrics,attr,optional"`
+
+	RedisAddr               string            `river:"redis_addr,attr"`
+	RedisUser               string            `river:"redis_user,attr,optional"`
+	RedisPassword           rivertypes.Secret `river:"redis_password,attr,optional"`
+	RedisPasswordFile       string            `river:"redis_password_file,attr,optional"`
+	Namespace               string            `river:"namespace,attr,optional"`
+	ConfigCommand           string            `river:"config_command,attr,optional"`
+	CheckKeys               []string          `river:"check_keys,attr,optional"`
+	CheckKeyGroups          []string          `river:"check_key_groups,attr,optional"`
+	CheckKeyGroupsBatchSize int64             `river:"check_key_groups_batch_size,attr,optional"`
+	MaxDistinctKeyGroups    int64             `river:"max_distinct_key_groups,attr,optional"`
+	CheckSingleKeys         []string          `river:"check_single_keys,attr,optional"`
+	CheckStreams            []string          `river:"check_streams,attr,optional"`
+	CheckSingleStreams      []string          `river:"check_single_streams,attr,optional"`
+	CountKeys               []string          `river:"count_keys,attr,optional"`
+	ScriptPath              string            `river:"script_path,attr,optional"`
+	ConnectionTimeout       time.Duration     `river:"connection_timeout,attr,optional"`
+	TLSClientKeyFile        string            `river:"tls_client_key_file,attr,optional"`
+	TLSClientCertFile       string            `river:"tls_client_cert_file,attr,optional"`
+	TLSCaCertFile           string            `river:"tls_ca_cert_file,attr,optional"`
+	SetClientName           bool              `river:"set_client_name,attr,optional"`
+	IsTile38                bool              `river:"is_tile38,attr,optional"`
+	ExportClientList        bool              `river:"export_client_list,attr,optional"`
+	ExportClientPort        bool              `river:"export_client_port,attr,optional"`
+	RedisMetricsOnly        bool              `river:"redis_metrics_only,attr,optional"`
+	PingOnConnect           bool              `river:"ping_on_connect,attr,optional"`
+	InclSystemMetrics       bool              `river:"incl_system_metrics,attr,optional"`
+	SkipTLSVerification     bool              `river:"skip_tls_verification,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	return f((*args)(a))
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	return &redis_integration.Config{
+		IncludeExporterMetrics:  a.IncludeExporterMetrics,
+		RedisAddr:               a.RedisAddr,
+		RedisUser:               a.RedisUser,
+		RedisPassword:           config_util.Secret(a.RedisPassword),
+		RedisPasswordFile:       a.RedisPasswordFile,
+		Namespace:               a.Namespace,
+		ConfigCommand:           a.ConfigCommand,
+		CheckKeys:               strings.Join(a.CheckKeys, ","),
+		CheckKeyGroups:          strings.Join(a.CheckKeyGroups, ","),
+		CheckKeyGroupsBatchSize: a.CheckKeyGroupsBatchSize,
+		MaxDistinctKeyGroups:    a.MaxDistinctKeyGroups,
+		CheckSingleKeys:         strings.Join(a.CheckSingleKeys, ","),
+		CheckStreams:            strings.Join(a.CheckStreams, ","),
+		CheckSingleStreams:      strings.Join(a.CheckSingleStreams, ","),
+		CountKeys:               strings.Join(a.CountKeys, ","),
+		ScriptPath:              a.ScriptPath,
+		ConnectionTimeout:       a.ConnectionTimeout,
+		TLSClientKeyFile:        a.TLSClientKeyFile,
+		TLSClientCertFile:       a.TLSClientCertFile,
+		TLSCaCertFile:           a.TLSCaCertFile,
+		SetClientName:           a.SetClientName,
+		IsTile38:                a.IsTile38,
+		ExportClientList:        a.ExportClientList,
+		ExportClientPort:        a.ExportClientPort,
+		RedisMetricsOnly:        a.RedisMetricsOnly,
+		PingOnConnect:           a.PingOnConnect,
+		InclSystemMetrics:       a.InclSystemMetrics,
+		SkipTLSVerification:     a.SkipTLSVerification,
+	}, nil
+}
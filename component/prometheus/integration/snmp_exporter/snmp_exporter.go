@@ -0,0 +1,124 @@
+// Package snmp_exporter implements the
+// prometheus.integration.snmp_exporter component.
+package snmp_exporter
+
+import (
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations"
+	snmp_integration "github.com/grafana/agent/pkg/integrations/snmp_exporter"
+	snmp_config "github.com/prometheus/snmp_exporter/config"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name: "snmp_exporter",
+		Args: Arguments{},
+	})
+}
+
+// Arguments configures the prometheus.integration.snmp_exporter component.
+type Arguments struct {
+	// ConfigFile is the snmp_exporter config file holding the modules to use.
+	// The modules embedded in the agent are used if no file is provided.
+	ConfigFile string       `river:"config_file,attr,optional"`
+	Targets    []Target     `river:"target,block"`
+	WalkParams []WalkParams `river:"walk_param,block,optional"`
+}
+
+// Target is a device to collect metrics from. A target is exported for each
+// device.
+type Target struct {
+	Name       string `river:",label"`
+	Target     string `river:"address,attr"`
+	Module     string `river:"module,attr,optional"`
+	WalkParams string `river:"walk_params,attr,optional"`
+}
+
+// WalkParams holds a named set of parameters for walking devices. Targets
+// refer to walk parameters through their name.
+type WalkParams struct {
+	Name                    string        `river:",label"`
+	Version                 int           `river:"version,attr,optional"`
+	MaxRepetitions          uint32        `river:"max_repetitions,attr,optional"`
+	Retries                 int           `river:"retries,attr,optional"`
+	Timeout                 time.Duration `river:"timeout,attr,optional"`
+	Auth                    Auth          `river:"auth,block,optional"`
+	UseUnconnectedUDPSocket bool          `river:"use_unconnected_udp_socket,attr,optional"`
+}
+
+// DefaultWalkParams holds the default settings for WalkParams.
+var DefaultWalkParams = WalkParams{
+	Version:        snmp_config.DefaultWalkParams.Version,
+	MaxRepetitions: snmp_config.DefaultWalkParams.MaxRepetitions,
+	Retries:        snmp_config.DefaultWalkParams.Retries,
+	Timeout:        snmp_config.DefaultWalkParams.Timeout,
+	Auth: Auth{
+		Community:     rivertypes.Secret(snmp_config.DefaultAuth.Community),
+		SecurityLevel: snmp_config.DefaultAuth.SecurityLevel,
+		AuthProtocol:  snmp_config.DefaultAuth.AuthProtocol,
+		PrivProtocol:  snmp_config.DefaultAuth.PrivProtocol,
+	},
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (w *WalkParams) UnmarshalRiver(f func(interface{}) error) error {
+	*w = DefaultWalkParams
+
+	type walkParams WalkParams
+	return f((*walkParams)(w))
+}
+
+// Auth configures authentication against devices.
+type Auth struct {
+	Community     rivertypes.Secret `river:"community,attr,optional"`
+	SecurityLevel string            `river:"security_level,attr,optional"`
+	Username      string            `river:"username,attr,optional"`
+	Password      rivertypes.Secret `river:"password,attr,optional"`
+	AuthProtocol  string            `river:"auth_protocol,attr,optional"`
+	PrivProtocol  string            `river:"priv_protocol,attr,optional"`
+	PrivPassword  rivertypes.Secret `river:"priv_password,attr,optional"`
+	ContextName   string            `river:"context_name,attr,optional"`
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	targets := make([]snmp_integration.SNMPTarget, 0, len(a.Targets))
+	for _, t := range a.Targets {
+		targets = append(targets, snmp_integration.SNMPTarget{
+			Name:       t.Name,
+			Target:     t.Target,
+			Module:     t.Module,
+			WalkParams: t.WalkParams,
+		})
+	}
+
+	walkParams := make(map[string]snmp_config.WalkParams, len(a.WalkParams))
+	for _, w := range a.WalkParams {
+		walkParams[w.Name] = snmp_config.WalkParams{
+			Version:        w.Version,
+			MaxRepetitions: w.MaxRepetitions,
+			Retries:        w.Retries,
+			Timeout:        w.Timeout,
+			Auth: snmp_config.Auth{
+				Community:     snmp_config.Secret(w.Auth.Community),
+				SecurityLevel: w.Auth.SecurityLevel,
+				Username:      w.Auth.Username,
+				Password:      snmp_config.Secret(w.Auth.Password),
+				AuthProtocol:  w.Auth.AuthProtocol,
+				PrivProtocol:  w.Auth.PrivProtocol,
+				PrivPassword:  snmp_config.Secret(w.Auth.PrivPassword),
+				ContextName:   w.Auth.ContextName,
+			},
+			UseUnconnectedUDPSocket: w.UseUnconnectedUDPSocket,
+		}
+	}
+
+	return &snmp_integration.Config{
+		WalkParams:     walkParams,
+		SnmpConfigFile: a.ConfigFile,
+		SnmpTargets:    targets,
+	}, nil
+}
@@ -0,0 +1,130 @@
+// Package statsd_exporter implements the
+// prometheus.integration.statsd_exporter component.
+package statsd_exporter
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/integration"
+	"github.com/grafana/agent/pkg/integrations"
+	statsd_integration "github.com/grafana/agent/pkg/integrations/statsd_exporter"
+	"github.com/prometheus/statsd_exporter/pkg/mapper"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	integration.Register(integration.Registration{
+		Name:      "statsd_exporter",
+		Singleton: true,
+		Args:      Arguments{},
+	})
+}
+
+// DefaultArguments holds the default settings for the statsd_exporter
+// integration.
+var DefaultArguments = Arguments{
+	ListenUDP:      statsd_integration.DefaultConfig.ListenUDP,
+	ListenTCP:      statsd_integration.DefaultConfig.ListenTCP,
+	UnixSocketMode: statsd_integration.DefaultConfig.UnixSocketMode,
+
+	CacheSize:           statsd_integration.DefaultConfig.CacheSize,
+	CacheType:           statsd_integration.DefaultConfig.CacheType,
+	EventQueueSize:      statsd_integration.DefaultConfig.EventQueueSize,
+	EventFlushThreshold: statsd_integration.DefaultConfig.EventFlushThreshold,
+	EventFlushInterval:  statsd_integration.DefaultConfig.EventFlushInterval,
+
+	ParseDogStatsd: statsd_integration.DefaultConfig.ParseDogStatsd,
+	ParseInfluxDB:  statsd_integration.DefaultConfig.ParseInfluxDB,
+	ParseLibrato:   statsd_integration.DefaultConfig.ParseLibrato,
+	ParseSignalFX:  statsd_integration.DefaultConfig.ParseSignalFX,
+}
+
+// Arguments configures the prometheus.integration.statsd_exporter component.
+type Arguments struct {
+	ListenUDP      string `river:"listen_udp,attr,optional"`
+	ListenTCP      string `river:"listen_tcp,attr,optional"`
+	ListenUnixgram string `river:"listen_unixgram,attr,optional"`
+	UnixSocketMode string `river:"unix_socket_mode,attr,optional"`
+
+	// MappingConfig and MappingConfigPath hold the mapping config in the
+	// statsd_exporter YAML format, either inline or as a path to a file.
+	MappingConfig     string `river:"mapping_config,attr,optional"`
+	MappingConfigPath string `river:"mapping_config_path,attr,optional"`
+
+	ReadBuffer          int           `river:"read_buffer,attr,optional"`
+	CacheSize           int           `river:"cache_size,attr,optional"`
+	CacheType           string        `river:"cache_type,attr,optional"`
+	EventQueueSize      int           `river:"event_queue_size,attr,optional"`
+	EventFlushThreshold int           `river:"event_flush_threshold,attr,optional"`
+	EventFlushInterval  time.Duration `river:"event_flush_interval,attr,optional"`
+
+	ParseDogStatsd bool `river:"parse_dogstatsd_tags,attr,optional"`
+	ParseInfluxDB  bool `river:"parse_influxdb_tags,attr,optional"`
+	ParseLibrato   bool `river:"parse_librato_tags,attr,optional"`
+	ParseSignalFX  bool `river:"parse_signalfx_tags,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	if err := f((*args)(a)); err != nil {
+		return err
+	}
+
+	if a.MappingConfig != "" && a.MappingConfigPath != "" {
+		return fmt.Errorf("at most one of mapping_config and mapping_config_path must be provided")
+	}
+	return nil
+}
+
+// IntegrationConfig implements integration.Arguments.
+func (a Arguments) IntegrationConfig() (integrations.Config, error) {
+	mappingConfig, err := a.mappingConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &statsd_integration.Config{
+		ListenUDP:           a.ListenUDP,
+		ListenTCP:           a.ListenTCP,
+		ListenUnixgram:      a.ListenUnixgram,
+		UnixSocketMode:      a.UnixSocketMode,
+		MappingConfig:       mappingConfig,
+		ReadBuffer:          a.ReadBuffer,
+		CacheSize:           a.CacheSize,
+		CacheType:           a.CacheType,
+		EventQueueSize:      a.EventQueueSize,
+		EventFlushThreshold: a.EventFlushThreshold,
+		EventFlushInterval:  a.EventFlushInterval,
+		ParseDogStatsd:      a.ParseDogStatsd,
+		ParseInfluxDB:       a.ParseInfluxDB,
+		ParseLibrato:        a.ParseLibrato,
+		ParseSignalFX:       a.ParseSignalFX,
+	}, nil
+}
+
+// mappingConfig loads the mapping config. nil is returned if no mapping
+// config was provided.
+func (a Arguments) mappingConfig() (*mapper.MetricMapper, error) {
+	content := a.MappingConfig
+	if a.MappingConfigPath != "" {
+		bb, err := os.ReadFile(a.MappingConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mapping config: %w", err)
+		}
+		content = string(bb)
+	}
+	if content == "" {
+		return nil, nil
+	}
+
+	var m mapper.MetricMapper
+	if err := yaml.UnmarshalStrict([]byte(content), &m); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping config: %w", err)
+	}
+	return &m, nil
+}
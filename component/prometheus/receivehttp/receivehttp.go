@@ -0,0 +1,253 @@
+// Package receivehttp implements the prometheus.receive_http component.
+package receivehttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/alecthomas/units"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/appendable"
+	"github.com/grafana/agent/component/prometheus"
+	"github.com/grafana/agent/pkg/metrics/push"
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "prometheus.receive_http",
+		Args:    Arguments{},
+		Exports: nil,
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// writePath is the path, relative to the component's HTTP handler, which
+// accepts remote_write requests.
+const writePath = "/api/v1/metrics/write"
+
+// Arguments holds values which are used to configure the
+// prometheus.receive_http component.
+type Arguments struct {
+	ForwardTo []*prometheus.Receiver `river:"forward_to,attr"`
+
+	TenantHeader          string           `river:"tenant_header,attr,optional"`
+	TenantLabel           string           `river:"tenant_label,attr,optional"`
+	MaxRequestSize        units.Base2Bytes `river:"max_request_size,attr,optional"`
+	MaxDecodedRequestSize units.Base2Bytes `river:"max_decoded_request_size,attr,optional"`
+}
+
+// DefaultArguments holds default settings for prometheus.receive_http.
+var DefaultArguments = Arguments{
+	TenantHeader:          "X-Scope-OrgID",
+	MaxRequestSize:        10 * units.MiB,
+	MaxDecodedRequestSize: 100 * units.MiB,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.MaxRequestSize <= 0 {
+		return fmt.Errorf("max_request_size must be greater than 0")
+	}
+	if args.MaxDecodedRequestSize <= 0 {
+		return fmt.Errorf("max_decoded_request_size must be greater than 0")
+	}
+	if args.TenantLabel != "" && !model.LabelName(args.TenantLabel).IsValid() {
+		return fmt.Errorf("tenant_label %q is not a valid label name", args.TenantLabel)
+	}
+	return nil
+}
+
+// Component implements the prometheus.receive_http component.
+type Component struct {
+	opts       component.Options
+	appendable *appendable.FlowAppendable
+
+	mut  sync.RWMutex
+	args Arguments
+
+	acceptedSamples  prometheus_client.Counter
+	rejectedSamples  prometheus_client.Counter
+	rejectedRequests *prometheus_client.CounterVec
+}
+
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.HTTPComponent = (*Component)(nil)
+)
+
+// New creates a new prometheus.receive_http component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:       o,
+		appendable: appendable.NewFlowAppendable(),
+
+		acceptedSamples: prometheus_client.NewCounter(prometheus_client.CounterOpts{
+			Name: "agent_prometheus_receive_http_samples_accepted_total",
+			Help: "Total number of samples received and forwarded.",
+		}),
+		rejectedSamples: prometheus_client.NewCounter(prometheus_client.CounterOpts{
+			Name: "agent_prometheus_receive_http_samples_rejected_total",
+			Help: "Total number of samples received which could not be forwarded.",
+		}),
+		rejectedRequests: prometheus_client.NewCounterVec(prometheus_client.CounterOpts{
+			Name: "agent_prometheus_receive_http_requests_rejected_total",
+			Help: "Total number of requests rejected before their samples could be read.",
+		}, []string{"reason"}),
+	}
+
+	for _, m := range []prometheus_client.Collector{c.acceptedSamples, c.rejectedSamples, c.rejectedRequests} {
+		if err := o.Registerer.Register(m); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	<-ctx.Done()
+	c.opts.Registerer.Unregister(c.acceptedSamples)
+	c.opts.Registerer.Unregister(c.rejectedSamples)
+	c.opts.Registerer.Unregister(c.rejectedRequests)
+	return nil
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.args = newArgs
+	c.appendable.SetReceivers(newArgs.ForwardTo)
+	return nil
+}
+
+// Handler implements component.HTTPComponent.
+func (c *Component) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(writePath, c.handleWrite)
+	return mux
+}
+
+func (c *Component) handleWrite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	c.mut.RLock()
+	args := c.args
+	c.mut.RUnlock()
+
+	tenant := r.Header.Get(args.TenantHeader)
+	maxSize := int64(args.MaxRequestSize)
+
+	if r.ContentLength > maxSize {
+		c.rejectedRequests.WithLabelValues("too_large").Inc()
+		http.Error(w, fmt.Sprintf("request body is larger than the maximum of %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Read one byte past the limit so requests which don't set a
+	// Content-Length can be rejected if they're too large.
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		c.rejectedRequests.WithLabelValues("read_error").Inc()
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > maxSize {
+		c.rejectedRequests.WithLabelValues("too_large").Inc()
+		http.Error(w, fmt.Sprintf("request body is larger than the maximum of %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Snappy stores the decoded length up front, so oversized requests can be
+	// rejected without decoding them.
+	maxDecodedSize := int64(args.MaxDecodedRequestSize)
+	if n, err := snappy.DecodedLen(body); err == nil && int64(n) > maxDecodedSize {
+		c.rejectedRequests.WithLabelValues("too_large").Inc()
+		http.Error(w, fmt.Sprintf("decoded request body is larger than the maximum of %d bytes", maxDecodedSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	req, err := decodeWriteRequest(body)
+	if err != nil {
+		c.rejectedRequests.WithLabelValues("decode_error").Inc()
+		level.Debug(c.opts.Logger).Log("msg", "failed to decode remote_write request", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if args.TenantLabel != "" && tenant != "" {
+		addTenantLabel(req, args.TenantLabel, tenant)
+	}
+
+	var samples int
+	for _, ts := range req.Timeseries {
+		samples += len(ts.Samples)
+	}
+
+	if err := push.Write(r.Context(), c.opts.Logger, c.appendable, req); err != nil {
+		c.rejectedSamples.Add(float64(samples))
+		level.Warn(c.opts.Logger).Log("msg", "failed to forward remote_write request", "tenant", tenant, "err", err)
+		http.Error(w, err.Error(), push.ErrorStatus(err))
+		return
+	}
+
+	c.acceptedSamples.Add(float64(samples))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeWriteRequest decodes a snappy-compressed remote_write request.
+func decodeWriteRequest(body []byte) (*prompb.WriteRequest, error) {
+	buf, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var req prompb.WriteRequest
+	if err := proto.Unmarshal(buf, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// addTenantLabel sets the label name to tenant on every series in req,
+// replacing any existing value.
+func addTenantLabel(req *prompb.WriteRequest, name, tenant string) {
+	for i, ts := range req.Timeseries {
+		lbls := make([]prompb.Label, 0, len(ts.Labels)+1)
+		for _, l := range ts.Labels {
+			if l.Name != name {
+				lbls = append(lbls, l)
+			}
+		}
+		req.Timeseries[i].Labels = append(lbls, prompb.Label{Name: name, Value: tenant})
+	}
+}
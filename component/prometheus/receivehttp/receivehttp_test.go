@@ -0,0 +1,164 @@
+package receivehttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/alecthomas/units"
+	"github.com/golang/snappy"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/prometheus"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		forward_to       = []
+		tenant_label     = "tenant"
+		max_request_size = "1MiB"
+	`), &args))
+
+	require.Equal(t, "X-Scope-OrgID", args.TenantHeader)
+	require.Equal(t, "tenant", args.TenantLabel)
+	require.Equal(t, units.MiB, args.MaxRequestSize)
+	require.Equal(t, 100*units.MiB, args.MaxDecodedRequestSize)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`
+		forward_to   = []
+		tenant_label = "not-valid"
+	`), &args)
+	require.EqualError(t, err, `tenant_label "not-valid" is not a valid label name`)
+}
+
+type receivedSample struct {
+	ts     int64
+	labels labels.Labels
+	value  float64
+}
+
+func newTestComponent(t *testing.T, args Arguments) (*Component, func() []receivedSample) {
+	var (
+		mut      sync.Mutex
+		received []receivedSample
+	)
+	args.ForwardTo = []*prometheus.Receiver{{
+		Receive: func(ts int64, metrics []*prometheus.FlowMetric) {
+			mut.Lock()
+			defer mut.Unlock()
+			for _, m := range metrics {
+				received = append(received, receivedSample{ts: ts, labels: m.LabelsCopy(), value: m.Value()})
+			}
+		},
+	}}
+
+	c, err := New(component.Options{
+		ID:            "prometheus.receive_http.test",
+		Logger:        util.TestLogger(t),
+		Registerer:    prometheus_client.NewRegistry(),
+		OnStateChange: func(component.Exports) {},
+	}, args)
+	require.NoError(t, err)
+
+	return c, func() []receivedSample {
+		mut.Lock()
+		defer mut.Unlock()
+		return received
+	}
+}
+
+func encodeRequest(t *testing.T, req *prompb.WriteRequest) []byte {
+	bb, err := req.Marshal()
+	require.NoError(t, err)
+	return snappy.Encode(nil, bb)
+}
+
+func TestReceive(t *testing.T) {
+	args := DefaultArguments
+	args.TenantLabel = "tenant"
+	c, received := newTestComponent(t, args)
+
+	body := encodeRequest(t, &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "tenant", Value: "spoofed"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 10}},
+		}},
+	})
+	req := httptest.NewRequest(http.MethodPost, writePath, bytes.NewReader(body))
+	req.Header.Set("X-Scope-OrgID", "team-a")
+
+	rr := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	require.Equal(t, []receivedSample{{
+		ts:     10,
+		labels: labels.FromStrings("__name__", "up", "tenant", "team-a"),
+		value:  1,
+	}}, received())
+	require.Equal(t, 1.0, testutil.ToFloat64(c.acceptedSamples))
+}
+
+func TestReceive_TooLarge(t *testing.T) {
+	args := DefaultArguments
+	args.MaxRequestSize = 16
+	c, received := newTestComponent(t, args)
+
+	body := encodeRequest(t, &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "a_metric_with_a_long_name"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 10}},
+		}},
+	})
+
+	rr := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, writePath, bytes.NewReader(body)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Empty(t, received())
+	require.Equal(t, 1.0, testutil.ToFloat64(c.rejectedRequests.WithLabelValues("too_large")))
+}
+
+func TestReceive_DecodedTooLarge(t *testing.T) {
+	args := DefaultArguments
+	args.MaxDecodedRequestSize = 16
+	c, received := newTestComponent(t, args)
+
+	body := encodeRequest(t, &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "a_metric_with_a_long_name"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 10}},
+		}},
+	})
+
+	rr := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, writePath, bytes.NewReader(body)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Empty(t, received())
+	require.Equal(t, 1.0, testutil.ToFloat64(c.rejectedRequests.WithLabelValues("too_large")))
+}
+
+func TestReceive_BadRequest(t *testing.T) {
+	c, received := newTestComponent(t, DefaultArguments)
+
+	rr := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, writePath, bytes.NewReader([]byte("not snappy"))))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Empty(t, received())
+	require.Equal(t, 1.0, testutil.ToFloat64(c.rejectedRequests.WithLabelValues("decode_error")))
+
+	rr = httptest.NewRecorder()
+	c.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, writePath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
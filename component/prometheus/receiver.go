@@ -1,11 +1,16 @@
 package prometheus
 
 import (
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
 	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/model/value"
 )
 
-// Receiver is used to pass an array of metrics to another receiver
+// Receiver is used to pass an array of metrics to another receiver. Staleness
+// markers are passed as metrics whose value is a stale NaN, and exemplars and
+// metadata are passed alongside the metric of the series they belong to.
 type Receiver struct {
 	// metrics should be considered immutable
 	Receive func(timestamp int64, metrics []*FlowMetric)
@@ -14,24 +19,52 @@ type Receiver struct {
 // RiverCapsule marks receivers as a capsule.
 func (r Receiver) RiverCapsule() {}
 
-// FlowMetric is a wrapper around a single metric without the timestamp.
+// FlowMetric is a wrapper around a single metric without the timestamp. Along
+// with the sample value, a FlowMetric may carry the exemplars and metadata
+// which were appended for its series.
 type FlowMetric struct {
 	globalRefID uint64
 	labels      labels.Labels
 	value       float64
+
+	exemplars   []exemplar.Exemplar
+	metadata    metadata.Metadata
+	hasMetadata bool
+}
+
+// FlowMetricOption sets optional data on a FlowMetric when it is created.
+type FlowMetricOption func(fm *FlowMetric)
+
+// WithExemplars attaches exemplars to a FlowMetric.
+func WithExemplars(exemplars ...exemplar.Exemplar) FlowMetricOption {
+	return func(fm *FlowMetric) {
+		fm.exemplars = append(fm.exemplars, exemplars...)
+	}
+}
+
+// WithMetadata attaches metadata to a FlowMetric.
+func WithMetadata(md metadata.Metadata) FlowMetricOption {
+	return func(fm *FlowMetric) {
+		fm.metadata = md
+		fm.hasMetadata = true
+	}
 }
 
 // NewFlowMetric instantiates a new flow metric
-func NewFlowMetric(globalRefID uint64, lbls labels.Labels, value float64) *FlowMetric {
+func NewFlowMetric(globalRefID uint64, lbls labels.Labels, value float64, opts ...FlowMetricOption) *FlowMetric {
 	// Always ensure we have a valid global ref id
 	if globalRefID == 0 {
 		globalRefID = GlobalRefMapping.GetOrAddGlobalRefID(lbls)
 	}
-	return &FlowMetric{
+	fm := &FlowMetric{
 		globalRefID: globalRefID,
 		labels:      lbls,
 		value:       value,
 	}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
 // GlobalRefID Retrieves the GlobalRefID
@@ -40,6 +73,17 @@ func (fw *FlowMetric) GlobalRefID() uint64 { return fw.globalRefID }
 // Value returns the value
 func (fw *FlowMetric) Value() float64 { return fw.value }
 
+// IsStaleNaN returns true if the value is a Prometheus staleness marker.
+func (fw *FlowMetric) IsStaleNaN() bool { return value.IsStaleNaN(fw.value) }
+
+// Exemplars returns the exemplars attached to the metric. The returned slice
+// should be treated as immutable.
+func (fw *FlowMetric) Exemplars() []exemplar.Exemplar { return fw.exemplars }
+
+// Metadata returns the metadata attached to the metric. The second return
+// value is false if the metric has no metadata.
+func (fw *FlowMetric) Metadata() (metadata.Metadata, bool) { return fw.metadata, fw.hasMetadata }
+
 // LabelsCopy returns a copy of the labels structure
 func (fw *FlowMetric) LabelsCopy() labels.Labels {
 	return fw.labels.Copy()
@@ -60,5 +104,18 @@ func (fw *FlowMetric) Relabel(cfgs ...*relabel.Config) *FlowMetric {
 	if retLbls.Hash() == fw.labels.Hash() && labels.Equal(retLbls, fw.labels) {
 		return fw
 	}
-	return NewFlowMetric(0, retLbls, fw.value)
+	return NewFlowMetric(0, retLbls, fw.value, fw.options()...)
+}
+
+// options returns the options needed to carry over the exemplars and metadata
+// of fw into a new FlowMetric.
+func (fw *FlowMetric) options() []FlowMetricOption {
+	var opts []FlowMetricOption
+	if len(fw.exemplars) > 0 {
+		opts = append(opts, WithExemplars(fw.exemplars...))
+	}
+	if fw.hasMetadata {
+		opts = append(opts, WithMetadata(fw.metadata))
+	}
+	return opts
 }
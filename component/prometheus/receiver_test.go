@@ -1,10 +1,16 @@
 package prometheus
 
 import (
+	"math"
 	"testing"
 
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
 	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/model/textparse"
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,3 +47,34 @@ func TestRelabelTheSame(t *testing.T) {
 	require.True(t, newfm.globalRefID == fm.globalRefID)
 	require.True(t, labels.Equal(newfm.labels, fm.labels))
 }
+
+func TestRelabelKeepsExemplarsAndMetadata(t *testing.T) {
+	var (
+		ex = exemplar.Exemplar{Labels: labels.FromStrings("trace_id", "abc"), Value: 1, Ts: 1, HasTs: true}
+		md = metadata.Metadata{Type: textparse.MetricTypeCounter, Help: "help"}
+	)
+	fm := NewFlowMetric(0, labels.FromStrings("__name__", "requests_total"), 1, WithExemplars(ex), WithMetadata(md))
+
+	rg, _ := relabel.NewRegexp("(.*)")
+	newfm := fm.Relabel(&relabel.Config{
+		SourceLabels: model.LabelNames{"__name__"},
+		Replacement:  "${1}_new",
+		Action:       "replace",
+		TargetLabel:  "new",
+		Regex:        rg,
+	})
+	require.NotSame(t, fm, newfm)
+	require.Equal(t, []exemplar.Exemplar{ex}, newfm.Exemplars())
+
+	actual, ok := newfm.Metadata()
+	require.True(t, ok)
+	require.Equal(t, md, actual)
+}
+
+func TestStaleNaN(t *testing.T) {
+	fm := NewFlowMetric(0, labels.FromStrings("key", "value"), math.Float64frombits(value.StaleNaN))
+	require.True(t, fm.IsStaleNaN())
+
+	_, ok := fm.Metadata()
+	require.False(t, ok)
+}
@@ -2,9 +2,11 @@ package relabel
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/livedebugging"
 	flow_relabel "github.com/grafana/agent/component/common/relabel"
 	"github.com/grafana/agent/component/prometheus"
 	prometheus_client "github.com/prometheus/client_golang/prometheus"
@@ -45,10 +47,12 @@ type Component struct {
 	forwardto        []*prometheus.Receiver
 	receiver         *prometheus.Receiver
 	metricsProcessed prometheus_client.Counter
+	debug            livedebugging.Publisher
 }
 
 var (
-	_ component.Component = (*Component)(nil)
+	_ component.Component              = (*Component)(nil)
+	_ component.LiveDebuggingComponent = (*Component)(nil)
 )
 
 // New creates a new prometheus.relabel component.
@@ -108,6 +112,9 @@ func (c *Component) Receive(ts int64, metricArr []*prometheus.FlowMetric) {
 	for _, m := range metricArr {
 		// Relabel may return the original flowmetric if no changes applied, nil if everything was removed or an entirely new flowmetric.
 		relabelledFm := m.Relabel(c.mrc...)
+		if c.debug.Active() {
+			c.debug.Publish(relabelDebugString(ts, m, relabelledFm))
+		}
 		if relabelledFm == nil {
 			continue
 		}
@@ -120,3 +127,16 @@ func (c *Component) Receive(ts int64, metricArr []*prometheus.FlowMetric) {
 		forward.Receive(ts, relabelledMetrics)
 	}
 }
+
+// LiveDebugging implements component.LiveDebuggingComponent. Each received
+// sample is streamed along with its labels after relabeling.
+func (c *Component) LiveDebugging(fn func(data string)) (unregister func()) {
+	return c.debug.Subscribe(fn)
+}
+
+func relabelDebugString(ts int64, in, out *prometheus.FlowMetric) string {
+	if out == nil {
+		return fmt.Sprintf("ts=%d value=%g %s => dropped", ts, in.Value(), in.RawLabels())
+	}
+	return fmt.Sprintf("ts=%d value=%g %s => %s", ts, in.Value(), in.RawLabels(), out.RawLabels())
+}
@@ -42,9 +42,10 @@ type Component struct {
 	log  log.Logger
 	opts component.Options
 
-	walStore    *wal.Storage
-	remoteStore *remote.Storage
-	storage     storage.Storage
+	walStore       *wal.Storage
+	remoteStore    *remote.Storage
+	metadataSender *wal.MetadataSender
+	storage        storage.Storage
 
 	mut sync.RWMutex
 	cfg Arguments
@@ -65,11 +66,12 @@ func NewComponent(o component.Options, c Arguments) (*Component, error) {
 	remoteStore := remote.NewStorage(remoteLogger, o.Registerer, startTime, dataPath, remoteFlushDeadline, nil)
 
 	res := &Component{
-		log:         o.Logger,
-		opts:        o,
-		walStore:    walStorage,
-		remoteStore: remoteStore,
-		storage:     storage.NewFanout(o.Logger, walStorage, remoteStore),
+		log:            o.Logger,
+		opts:           o,
+		walStore:       walStorage,
+		remoteStore:    remoteStore,
+		metadataSender: wal.NewMetadataSender(remoteLogger, walStorage),
+		storage:        storage.NewFanout(o.Logger, walStorage, remoteStore),
 	}
 	res.receiver = &prometheus.Receiver{Receive: res.Receive}
 	if err := res.Update(c); err != nil {
@@ -86,6 +88,8 @@ var _ component.Component = (*Component)(nil)
 func (c *Component) Run(ctx context.Context) error {
 	c.opts.OnStateChange(Exports{Receiver: c.receiver})
 	defer func() {
+		c.metadataSender.Stop()
+
 		level.Debug(c.log).Log("msg", "closing storage")
 		err := c.storage.Close()
 		level.Debug(c.log).Log("msg", "storage closed")
@@ -167,7 +171,18 @@ func (c *Component) Update(newConfig component.Arguments) error {
 	if err != nil {
 		return err
 	}
-	err = c.remoteStore.ApplyConfig(convertedConfig)
+	// Metadata is read from the WAL and sent by the metadata sender instead of
+	// the remote storage.
+	remoteStoreConfig := *convertedConfig
+	remoteStoreConfig.RemoteWriteConfigs, err = wal.WithoutMetadataSending(convertedConfig.RemoteWriteConfigs)
+	if err != nil {
+		return err
+	}
+	err = c.remoteStore.ApplyConfig(&remoteStoreConfig)
+	if err != nil {
+		return err
+	}
+	err = c.metadataSender.ApplyConfig(convertedConfig.RemoteWriteConfigs)
 	if err != nil {
 		return err
 	}
@@ -194,6 +209,19 @@ func (c *Component) Receive(ts int64, metricArr []*prometheus.FlowMetric) {
 			level.Error(c.log).Log("err", err, "msg", "error receiving metrics", "component", c.opts.ID)
 			return
 		}
+
+		// Exemplars and metadata are best-effort; failing to append them
+		// shouldn't cause the sample to be dropped.
+		for _, e := range m.Exemplars() {
+			if _, err := app.AppendExemplar(newLocal, m.RawLabels(), e); err != nil {
+				level.Debug(c.log).Log("msg", "failed to append exemplar", "series", m.RawLabels(), "err", err)
+			}
+		}
+		if md, ok := m.Metadata(); ok {
+			if _, err := app.UpdateMetadata(newLocal, m.RawLabels(), md); err != nil {
+				level.Debug(c.log).Log("msg", "failed to update metadata", "series", m.RawLabels(), "err", err)
+			}
+		}
 	}
 
 	err := app.Commit()
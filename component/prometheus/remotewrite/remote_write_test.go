@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/agent/pkg/flow/componenttest"
 	"github.com/grafana/agent/pkg/river"
 	"github.com/grafana/agent/pkg/util"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/prometheus/prometheus/storage/remote"
@@ -111,3 +112,80 @@ func Test(t *testing.T) {
 		require.Equal(t, expect, res.Timeseries)
 	}
 }
+
+// TestExemplars ensures that exemplars attached to metrics are sent over
+// remote_write.
+func TestExemplars(t *testing.T) {
+	writeResult := make(chan *prompb.WriteRequest, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := remote.DecodeWriteRequest(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeResult <- req
+	}))
+	defer srv.Close()
+
+	cfg := fmt.Sprintf(`
+		endpoint {
+			url            = "%s/api/v1/write"
+			remote_timeout = "100ms"
+			send_exemplars = true
+
+			queue_config {
+				batch_send_deadline = "100ms"
+			}
+		}
+	`, srv.URL)
+
+	var args remotewrite.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	tc, err := componenttest.NewControllerFromID(util.TestLogger(t), "prometheus.remote_write")
+	require.NoError(t, err)
+	go func() {
+		err = tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, tc.WaitExports(time.Second))
+
+	sampleTimestamp := time.Now().Add(time.Minute).UnixMilli()
+	ex := exemplar.Exemplar{
+		Labels: labels.FromStrings("trace_id", "abc"),
+		Value:  12,
+		Ts:     sampleTimestamp,
+		HasTs:  true,
+	}
+
+	rwExports := tc.Exports().(remotewrite.Exports)
+	rwExports.Receiver.Receive(sampleTimestamp, []*prometheus.FlowMetric{
+		prometheus.NewFlowMetric(0, labels.FromStrings("foo", "bar"), 12, prometheus.WithExemplars(ex)),
+	})
+
+	expect := prompb.Exemplar{
+		Labels:    []prompb.Label{{Name: "trace_id", Value: "abc"}},
+		Value:     12,
+		Timestamp: sampleTimestamp,
+	}
+
+	// Samples and exemplars may be sent in separate requests, so wait for a
+	// request which holds the exemplar.
+	timeout := time.After(time.Minute)
+	for {
+		select {
+		case <-timeout:
+			require.FailNow(t, "timed out waiting for exemplars")
+		case res := <-writeResult:
+			for _, ts := range res.Timeseries {
+				if len(ts.Exemplars) == 0 {
+					continue
+				}
+				require.Equal(t, []prompb.Label{{Name: "foo", Value: "bar"}}, ts.Labels)
+				require.Equal(t, []prompb.Exemplar{expect}, ts.Exemplars)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/agent/component/common/livedebugging"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+)
+
+// LiveDebugging implements component.LiveDebuggingComponent. Targets are
+// streamed when they're passed to the scrape manager, and samples are
+// streamed as they're scraped.
+func (c *Component) LiveDebugging(fn func(data string)) (unregister func()) {
+	return c.debug.Subscribe(fn)
+}
+
+// debugAppendable wraps a storage.Appendable and publishes appended samples
+// to a livedebugging.Publisher.
+type debugAppendable struct {
+	storage.Appendable
+	debug *livedebugging.Publisher
+}
+
+func (a debugAppendable) Appender(ctx context.Context) storage.Appender {
+	return debugAppender{Appender: a.Appendable.Appender(ctx), debug: a.debug}
+}
+
+type debugAppender struct {
+	storage.Appender
+	debug *livedebugging.Publisher
+}
+
+func (a debugAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	if a.debug.Active() {
+		a.debug.Publish(fmt.Sprintf("sample ts=%d value=%g %s", t, v, l))
+	}
+	return a.Appender.Append(ref, l, t, v)
+}
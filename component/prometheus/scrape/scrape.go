@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/agent/component"
 	fa "github.com/grafana/agent/component/common/appendable"
 	component_config "github.com/grafana/agent/component/common/config"
+	"github.com/grafana/agent/component/common/livedebugging"
 	"github.com/grafana/agent/component/discovery"
 	"github.com/grafana/agent/component/prometheus"
 	"github.com/grafana/agent/pkg/build"
@@ -19,6 +21,9 @@ import (
 	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/discovery/targetgroup"
 	"github.com/prometheus/prometheus/scrape"
+	"github.com/rfratto/ckit"
+	"github.com/rfratto/ckit/peer"
+	"github.com/rfratto/ckit/shard"
 )
 
 func init() {
@@ -79,6 +84,15 @@ type Arguments struct {
 
 	// Scrape Options
 	ExtraMetrics bool `river:"extra_metrics,attr,optional"`
+
+	Clustering Clustering `river:"clustering,block,optional"`
+}
+
+// Clustering holds values that configure clustering-specific behavior.
+type Clustering struct {
+	// Enabled distributes targets across the agents of the cluster, so that
+	// each target is only scraped by the agent which owns it.
+	Enabled bool `river:"enabled,attr"`
 }
 
 // DefaultArguments defines the default settings for a scrape job.
@@ -110,24 +124,31 @@ type Component struct {
 	args       Arguments
 	scraper    *scrape.Manager
 	appendable *fa.FlowAppendable
+
+	debug livedebugging.Publisher
 }
 
 var (
-	_ component.Component = (*Component)(nil)
+	_ component.Component              = (*Component)(nil)
+	_ component.LiveDebuggingComponent = (*Component)(nil)
 )
 
 // New creates a new prometheus.scrape component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	flowAppendable := fa.NewFlowAppendable(args.ForwardTo...)
 
-	scrapeOptions := &scrape.Options{ExtraMetrics: args.ExtraMetrics}
-	scraper := scrape.NewManager(scrapeOptions, o.Logger, flowAppendable)
+	scrapeOptions := &scrape.Options{
+		ExtraMetrics: args.ExtraMetrics,
+		// Metadata of scraped targets is passed to the appendable so it can be
+		// forwarded to receivers alongside the samples.
+		PassMetadataInContext: true,
+	}
 	c := &Component{
 		opts:          o,
 		reloadTargets: make(chan struct{}, 1),
-		scraper:       scraper,
 		appendable:    flowAppendable,
 	}
+	c.scraper = scrape.NewManager(scrapeOptions, o.Logger, debugAppendable{Appendable: flowAppendable, debug: &c.debug})
 
 	// Call to Update() to set the receivers and targets once at the start.
 	if err := c.Update(args); err != nil {
@@ -151,6 +172,19 @@ func (c *Component) Run(ctx context.Context) error {
 		}
 	}()
 
+	// Recompute the set of owned targets whenever the participants of the
+	// cluster change. The observer unregisters itself once the component
+	// exits.
+	if c.opts.Clusterer != nil {
+		c.opts.Clusterer.Observe(ckit.ParticipantObserver(ckit.FuncObserver(func(_ []peer.Peer) (reregister bool) {
+			select {
+			case c.reloadTargets <- struct{}{}:
+			default:
+			}
+			return ctx.Err() == nil
+		})))
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -158,14 +192,24 @@ func (c *Component) Run(ctx context.Context) error {
 		case <-c.reloadTargets:
 			c.mut.RLock()
 			var (
-				tgs     = c.args.Targets
-				jobName = c.opts.ID
+				tgs        = c.args.Targets
+				jobName    = c.opts.ID
+				clustering = c.args.Clustering.Enabled
 			)
 			if c.args.JobName != "" {
 				jobName = c.args.JobName
 			}
 			c.mut.RUnlock()
+
+			if clustering {
+				tgs = c.ownedTargets(tgs)
+			}
 			promTargets := c.componentTargetsToProm(jobName, tgs)
+			if c.debug.Active() {
+				for _, tg := range tgs {
+					c.debug.Publish(fmt.Sprintf("target job=%q %s", jobName, convertLabelSet(tg)))
+				}
+			}
 
 			select {
 			case targetSetsChan <- promTargets:
@@ -279,6 +323,57 @@ func (c *Component) DebugInfo() interface{} {
 	return ScraperStatus{TargetStatus: res}
 }
 
+// ownedTargets filters tgs down to the targets owned by the local agent.
+// Targets are assigned to agents by consistent hashing of their labels, so
+// every agent in the cluster must be given the same set of targets.
+//
+// Targets whose owner can't be determined are kept so that they continue to
+// be scraped, even if that means more than one agent scrapes them.
+func (c *Component) ownedTargets(tgs []discovery.Target) []discovery.Target {
+	if c.opts.Clusterer == nil {
+		return tgs
+	}
+
+	var (
+		owned = make([]discovery.Target, 0, len(tgs))
+		kb    = shard.NewKeyBuilder()
+	)
+	for _, tg := range tgs {
+		kb.Reset()
+		writeTargetKey(kb, tg)
+
+		peers, err := c.opts.Clusterer.Lookup(kb.Key(), 1, shard.OpReadWrite)
+		if err != nil {
+			level.Debug(c.opts.Logger).Log("msg", "failed to determine target owner, keeping target", "err", err)
+			owned = append(owned, tg)
+			continue
+		}
+		if len(peers) == 0 || peers[0].Self {
+			owned = append(owned, tg)
+		}
+	}
+
+	level.Debug(c.opts.Logger).Log("msg", "filtered targets by cluster ownership", "total", len(tgs), "owned", len(owned))
+	return owned
+}
+
+// writeTargetKey writes the labels of tg to kb in sorted order so that the
+// same target produces the same key on every agent.
+func writeTargetKey(kb *shard.KeyBuilder, tg discovery.Target) {
+	names := make([]string, 0, len(tg))
+	for name := range tg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, _ = kb.Write([]byte(name))
+		_, _ = kb.Write([]byte{0xff})
+		_, _ = kb.Write([]byte(tg[name]))
+		_, _ = kb.Write([]byte{0xff})
+	}
+}
+
 func (c *Component) componentTargetsToProm(jobName string, tgs []discovery.Target) map[string][]*targetgroup.Group {
 	promGroup := &targetgroup.Group{Source: jobName}
 	for _, tg := range tgs {
@@ -2,15 +2,27 @@ package scrape
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
 	"github.com/grafana/agent/component/prometheus"
 	"github.com/grafana/agent/pkg/flow/logging"
+	"github.com/grafana/agent/pkg/util"
 	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/textparse"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/rfratto/ckit"
+	"github.com/rfratto/ckit/peer"
+	"github.com/rfratto/ckit/shard"
 	"github.com/stretchr/testify/require"
 )
 
@@ -73,3 +85,210 @@ func TestForwardingToAppendable(t *testing.T) {
 	require.Len(t, receivedSamples, 1)
 	require.Equal(t, receivedSamples[0], sample)
 }
+
+func TestForwardingExemplarsAndMetadata(t *testing.T) {
+	l, err := logging.New(os.Stderr, logging.DefaultOptions)
+	require.NoError(t, err)
+	opts := component.Options{
+		Logger:     l,
+		Registerer: prometheus_client.NewRegistry(),
+	}
+
+	var receivedSamples []*prometheus.FlowMetric
+	args := DefaultArguments
+	args.ForwardTo = []*prometheus.Receiver{{
+		Receive: func(_ int64, m []*prometheus.FlowMetric) { receivedSamples = m },
+	}}
+
+	s, err := New(opts, args)
+	require.NoError(t, err)
+
+	var (
+		lbls = labels.FromStrings("__name__", "requests_total")
+		ex   = exemplar.Exemplar{Labels: labels.FromStrings("trace_id", "abc"), Value: 1, Ts: 10, HasTs: true}
+		md   = metadata.Metadata{Type: textparse.MetricTypeCounter, Help: "Total requests."}
+	)
+
+	appender := s.appendable.Appender(context.Background())
+	ref, err := appender.Append(0, lbls, 10, 1)
+	require.NoError(t, err)
+	_, err = appender.AppendExemplar(ref, lbls, ex)
+	require.NoError(t, err)
+	_, err = appender.UpdateMetadata(ref, lbls, md)
+	require.NoError(t, err)
+
+	// Exemplars for series which weren't appended in the same batch are
+	// rejected.
+	_, err = appender.AppendExemplar(ref+1, labels.FromStrings("foo", "bar"), ex)
+	require.Error(t, err)
+
+	require.NoError(t, appender.Commit())
+
+	require.Len(t, receivedSamples, 1)
+	require.Equal(t, []exemplar.Exemplar{ex}, receivedSamples[0].Exemplars())
+	actual, ok := receivedSamples[0].Metadata()
+	require.True(t, ok)
+	require.Equal(t, md, actual)
+}
+
+func TestForwardingScrapedMetadata(t *testing.T) {
+	l, err := logging.New(os.Stderr, logging.DefaultOptions)
+	require.NoError(t, err)
+	opts := component.Options{
+		Logger:     l,
+		Registerer: prometheus_client.NewRegistry(),
+	}
+
+	var receivedSamples []*prometheus.FlowMetric
+	args := DefaultArguments
+	args.ForwardTo = []*prometheus.Receiver{{
+		Receive: func(_ int64, m []*prometheus.FlowMetric) { receivedSamples = m },
+	}}
+
+	s, err := New(opts, args)
+	require.NoError(t, err)
+
+	// Scrape loops pass the metadata of their target through the context.
+	ctx := scrape.ContextWithMetricMetadataStore(context.Background(), testMetadataStore{
+		"requests": {Metric: "requests", Type: textparse.MetricTypeCounter, Help: "Total requests."},
+	})
+	appender := s.appendable.Appender(ctx)
+	_, err = appender.Append(0, labels.FromStrings("__name__", "requests_total"), 10, 1)
+	require.NoError(t, err)
+	_, err = appender.Append(0, labels.FromStrings("__name__", "unknown"), 10, 1)
+	require.NoError(t, err)
+	require.NoError(t, appender.Commit())
+
+	require.Len(t, receivedSamples, 2)
+
+	md, ok := receivedSamples[0].Metadata()
+	require.True(t, ok)
+	require.Equal(t, metadata.Metadata{Type: textparse.MetricTypeCounter, Help: "Total requests."}, md)
+
+	_, ok = receivedSamples[1].Metadata()
+	require.False(t, ok)
+}
+
+func TestForwardingMetadataOnlyOnChange(t *testing.T) {
+	l, err := logging.New(os.Stderr, logging.DefaultOptions)
+	require.NoError(t, err)
+	opts := component.Options{
+		Logger:     l,
+		Registerer: prometheus_client.NewRegistry(),
+	}
+
+	var receivedSamples []*prometheus.FlowMetric
+	args := DefaultArguments
+	args.ForwardTo = []*prometheus.Receiver{{
+		Receive: func(_ int64, m []*prometheus.FlowMetric) { receivedSamples = m },
+	}}
+
+	s, err := New(opts, args)
+	require.NoError(t, err)
+
+	ctx := scrape.ContextWithMetricMetadataStore(context.Background(), testMetadataStore{
+		"jobs_processed": {Metric: "jobs_processed", Type: textparse.MetricTypeCounter, Help: "Total jobs processed."},
+	})
+	series := labels.FromStrings("__name__", "jobs_processed_total")
+
+	appendSample := func(v float64) (metadata.Metadata, bool) {
+		appender := s.appendable.Appender(ctx)
+		_, err := appender.Append(0, series, 10, v)
+		require.NoError(t, err)
+		require.NoError(t, appender.Commit())
+		require.Len(t, receivedSamples, 1)
+		return receivedSamples[0].Metadata()
+	}
+
+	_, ok := appendSample(1)
+	require.True(t, ok, "metadata should be forwarded with the first sample")
+	_, ok = appendSample(2)
+	require.False(t, ok, "unchanged metadata should not be forwarded again")
+
+	// A stale marker resets the series, so metadata is forwarded again with
+	// the next sample.
+	appendSample(math.Float64frombits(value.StaleNaN))
+	_, ok = appendSample(3)
+	require.True(t, ok, "metadata should be forwarded after a stale marker")
+
+	// New receivers haven't seen the metadata yet.
+	s.appendable.SetReceivers(args.ForwardTo)
+	_, ok = appendSample(4)
+	require.True(t, ok, "metadata should be forwarded after the receivers change")
+	_, ok = appendSample(5)
+	require.False(t, ok)
+}
+
+type testMetadataStore map[string]scrape.MetricMetadata
+
+func (s testMetadataStore) ListMetadata() []scrape.MetricMetadata {
+	res := make([]scrape.MetricMetadata, 0, len(s))
+	for _, md := range s {
+		res = append(res, md)
+	}
+	return res
+}
+
+func (s testMetadataStore) GetMetadata(metric string) (scrape.MetricMetadata, bool) {
+	md, ok := s[metric]
+	return md, ok
+}
+
+func (s testMetadataStore) SizeMetadata() int   { return 0 }
+func (s testMetadataStore) LengthMetadata() int { return len(s) }
+
+func TestOwnedTargets(t *testing.T) {
+	var targets []discovery.Target
+	for i := 0; i < 100; i++ {
+		targets = append(targets, discovery.Target{
+			"__address__": fmt.Sprintf("10.0.0.%d:9100", i),
+			"job":         "node",
+		})
+	}
+
+	peers := []peer.Peer{
+		{Name: "agent-a", Addr: "agent-a:12346", State: peer.StateParticipant},
+		{Name: "agent-b", Addr: "agent-b:12346", State: peer.StateParticipant},
+	}
+	ownedBy := func(name string) []discovery.Target {
+		c := &Component{opts: component.Options{
+			Logger:    util.TestLogger(t),
+			Clusterer: newTestNode(name, peers),
+		}}
+		return c.ownedTargets(targets)
+	}
+
+	var (
+		ownedA = ownedBy("agent-a")
+		ownedB = ownedBy("agent-b")
+	)
+	require.NotEmpty(t, ownedA)
+	require.NotEmpty(t, ownedB)
+	require.ElementsMatch(t, targets, append(ownedA, ownedB...), "every target must be owned by exactly one agent")
+}
+
+// testNode is a cluster.Node with a static set of peers.
+type testNode struct {
+	peers   []peer.Peer
+	sharder shard.Sharder
+}
+
+func newTestNode(self string, peers []peer.Peer) *testNode {
+	local := make([]peer.Peer, len(peers))
+	for i, p := range peers {
+		p.Self = p.Name == self
+		local[i] = p
+	}
+
+	sharder := shard.Ring(256)
+	sharder.SetPeers(local)
+	return &testNode{peers: local, sharder: sharder}
+}
+
+func (n *testNode) Lookup(key shard.Key, replicationFactor int, op shard.Op) ([]peer.Peer, error) {
+	return n.sharder.Lookup(key, replicationFactor, op)
+}
+
+func (n *testNode) Observe(ckit.Observer) {}
+
+func (n *testNode) Peers() []peer.Peer { return n.peers }
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/go-kit/log"
+	"github.com/grafana/agent/pkg/cluster"
 	"github.com/grafana/regexp"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -60,6 +61,17 @@ type Options struct {
 	// HTTPPath is the base path that requests need in order to route to this component.
 	// Requests received by a component handler will have this already trimmed off.
 	HTTPPath string
+
+	// ModuleController allows the component to create nested Flow controllers
+	// (modules). Modules created by the component are scoped to the component's
+	// ID.
+	ModuleController ModuleController
+
+	// Clusterer is the cluster node the agent is a member of. Components can
+	// use it to distribute work across agents by determining ownership of
+	// keys. Clusterer is never nil; agents which are not clustered act as a
+	// single-node cluster which owns every key.
+	Clusterer cluster.Node
 }
 
 // Registration describes a single component.
@@ -0,0 +1,258 @@
+// Package http implements the remote.http component.
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/config"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "remote.http",
+		Args:    Arguments{},
+		Exports: Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the remote.http
+// component.
+type Arguments struct {
+	URL           string        `river:"url,attr"`
+	PollFrequency time.Duration `river:"poll_frequency,attr,optional"`
+	PollTimeout   time.Duration `river:"poll_timeout,attr,optional"`
+	IsSecret      bool          `river:"is_secret,attr,optional"`
+
+	Method  string                       `river:"method,attr,optional"`
+	Headers map[string]rivertypes.Secret `river:"headers,attr,optional"`
+	Body    string                       `river:"body,attr,optional"`
+
+	HTTPClientConfig config.HTTPClientConfig `river:"http_client_config,block,optional"`
+}
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	PollFrequency: time.Minute,
+	PollTimeout:   10 * time.Second,
+	Method:        http.MethodGet,
+	HTTPClientConfig: config.HTTPClientConfig{
+		FollowRedirects: true,
+		EnableHTTP2:     true,
+	},
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(a)); err != nil {
+		return err
+	}
+
+	if a.PollFrequency <= 0 {
+		return fmt.Errorf("poll_frequency must be greater than 0")
+	}
+	if a.PollTimeout <= 0 {
+		return fmt.Errorf("poll_timeout must be greater than 0")
+	}
+	if a.PollTimeout >= a.PollFrequency {
+		return fmt.Errorf("poll_timeout must be less than poll_frequency")
+	}
+
+	a.Method = strings.ToUpper(a.Method)
+	switch a.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut:
+	default:
+		return fmt.Errorf("unsupported method %q, expected GET, POST or PUT", a.Method)
+	}
+	return nil
+}
+
+// Exports holds values which are exported by the remote.http component.
+type Exports struct {
+	// Content of the response body.
+	Content rivertypes.OptionalSecret `river:"content,attr"`
+}
+
+// Component implements the remote.http component.
+type Component struct {
+	opts component.Options
+
+	mut     sync.Mutex
+	args    Arguments
+	client  *http.Client
+	content *string // Most recently exported content; nil before the first poll.
+
+	healthMut sync.RWMutex
+	health    component.Health
+
+	// updated is written to when the arguments change so the poll timer is
+	// reset.
+	updated chan struct{}
+}
+
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.HealthComponent = (*Component)(nil)
+)
+
+// New creates a new remote.http component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:    o,
+		updated: make(chan struct{}, 1),
+	}
+
+	// Perform an update which will immediately set our exports to the initial
+	// contents of the response.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	for {
+		c.mut.Lock()
+		pollFrequency := c.args.PollFrequency
+		c.mut.Unlock()
+
+		timer := time.NewTimer(pollFrequency)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-c.updated:
+			timer.Stop()
+		case <-timer.C:
+			// We ignore the error here from poll since poll will log errors and
+			// also report the error as the health of the component.
+			c.mut.Lock()
+			_ = c.poll(ctx)
+			c.mut.Unlock()
+		}
+	}
+}
+
+// poll sends a request to the URL and exports the response body if it
+// changed since the last poll. mut must be held when calling poll.
+func (c *Component) poll(ctx context.Context) error {
+	content, err := c.fetch(ctx)
+	if err != nil {
+		c.setHealth(component.Health{
+			Health:     component.HealthTypeUnhealthy,
+			Message:    err.Error(),
+			UpdateTime: time.Now(),
+		})
+		level.Error(c.opts.Logger).Log("msg", "failed to poll url", "url", c.args.URL, "err", err)
+		return err
+	}
+
+	if c.content == nil || *c.content != content {
+		c.content = &content
+		c.opts.OnStateChange(Exports{
+			Content: rivertypes.OptionalSecret{
+				IsSecret: c.args.IsSecret,
+				Value:    content,
+			},
+		})
+	}
+
+	c.setHealth(component.Health{
+		Health:     component.HealthTypeHealthy,
+		Message:    "polled url",
+		UpdateTime: time.Now(),
+	})
+	return nil
+}
+
+func (c *Component) fetch(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.args.PollTimeout)
+	defer cancel()
+
+	var body io.Reader
+	if c.args.Body != "" {
+		body = strings.NewReader(c.args.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, c.args.Method, c.args.URL, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	for name, value := range c.args.Headers {
+		req.Header.Set(name, string(value))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bb, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("unexpected status code %s", resp.Status)
+	}
+	return string(bb), nil
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	client, err := config_util.NewClientFromConfig(*newArgs.HTTPClientConfig.Convert(), c.opts.ID)
+	if err != nil {
+		return fmt.Errorf("failed to build http client: %w", err)
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.args = newArgs
+	c.client = client
+	// Always export the content after an update, since is_secret may have
+	// changed.
+	c.content = nil
+
+	select {
+	case c.updated <- struct{}{}:
+	default:
+	}
+
+	// Force an immediate poll to report any potential errors early.
+	return c.poll(context.Background())
+}
+
+// CurrentHealth implements component.HealthComponent.
+func (c *Component) CurrentHealth() component.Health {
+	c.healthMut.RLock()
+	defer c.healthMut.RUnlock()
+	return c.health
+}
+
+func (c *Component) setHealth(h component.Health) {
+	c.healthMut.Lock()
+	defer c.healthMut.Unlock()
+	c.health = h
+}
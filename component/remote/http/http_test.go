@@ -0,0 +1,135 @@
+package http_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	remotehttp "github.com/grafana/agent/component/remote/http"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP(t *testing.T) {
+	var (
+		mut     sync.Mutex
+		content = "first"
+		status  = http.StatusOK
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mut.Lock()
+		defer mut.Unlock()
+
+		if r.Header.Get("X-Token") != "secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if u, p, _ := r.BasicAuth(); u != "user" || p != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || string(body) != `{"query": "config"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, content)
+	}))
+	defer srv.Close()
+
+	var args remotehttp.Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		url            = "`+srv.URL+`"
+		poll_frequency = "50ms"
+		poll_timeout   = "25ms"
+		is_secret      = true
+		method         = "post"
+		headers        = {
+			"X-Token" = "secret-token",
+		}
+		body = "{\"query\": \"config\"}"
+
+		http_client_config {
+			basic_auth {
+				username = "user"
+				password = "pass"
+			}
+		}
+	`), &args))
+
+	tc, err := componenttest.NewControllerFromID(nil, "remote.http")
+	require.NoError(t, err)
+	go func() {
+		err := tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, tc.WaitExports(time.Second))
+	require.Equal(t, remotehttp.Exports{
+		Content: rivertypes.OptionalSecret{IsSecret: true, Value: "first"},
+	}, tc.Exports())
+
+	// Failed polls keep the last content.
+	mut.Lock()
+	status = http.StatusInternalServerError
+	content = "ignored"
+	mut.Unlock()
+	require.Eventually(t, func() bool {
+		return tc.Exports().(remotehttp.Exports).Content.Value == "first"
+	}, time.Second, 10*time.Millisecond)
+
+	mut.Lock()
+	status = http.StatusOK
+	content = "second"
+	mut.Unlock()
+	require.Eventually(t, func() bool {
+		return tc.Exports().(remotehttp.Exports).Content.Value == "second"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHTTP_FailedInitialPoll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	tc, err := componenttest.NewControllerFromID(nil, "remote.http")
+	require.NoError(t, err)
+
+	args := remotehttp.DefaultArguments
+	args.URL = srv.URL
+	err = tc.Run(componenttest.TestContext(t), args)
+	require.EqualError(t, err, "unexpected status code 404 Not Found")
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name:   "timeout larger than frequency",
+			cfg:    "url = \"http://localhost\"\npoll_frequency = \"10s\"\npoll_timeout = \"1m\"",
+			expect: "poll_timeout must be less than poll_frequency",
+		},
+		{
+			name:   "unsupported method",
+			cfg:    "url = \"http://localhost\"\nmethod = \"DELETE\"",
+			expect: `unsupported method "DELETE", expected GET, POST or PUT`,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args remotehttp.Arguments
+			require.EqualError(t, river.Unmarshal([]byte(tc.cfg), &args), tc.expect)
+		})
+	}
+}
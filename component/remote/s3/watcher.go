@@ -1,7 +1,6 @@
 package s3
 
 import (
-	"errors"
 	"io"
 	"sync"
 	"time"
@@ -92,16 +91,28 @@ func (w *watcher) downloadSynchronously() (string, error) {
 
 // getObject ensure that the return []byte is never nil
 func (w *watcher) getObject(ctx context.Context) ([]byte, error) {
-	output, err := w.downloader.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(w.bucket),
-		Key:    aws.String(w.file),
+	return getObject(ctx, w.downloader, w.bucket, w.file)
+}
+
+// GetObject downloads the file at path, in the form s3://bucket/key, with
+// client. The returned []byte is never nil.
+func GetObject(ctx context.Context, client *s3.Client, path string) ([]byte, error) {
+	bucket, file := getPathBucketAndFile(path)
+	return getObject(ctx, client, bucket, file)
+}
+
+func getObject(ctx context.Context, client *s3.Client, bucket, file string) ([]byte, error) {
+	output, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(file),
 	})
 	if err != nil {
 		return []byte{}, err
 	}
-	buf := make([]byte, output.ContentLength)
-	_, err = output.Body.Read(buf)
-	if !errors.Is(err, io.EOF) {
+	defer output.Body.Close()
+
+	buf, err := io.ReadAll(output.Body)
+	if err != nil {
 		return []byte{}, err
 	}
 	return buf, nil
@@ -0,0 +1,112 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	vault "github.com/hashicorp/vault/api"
+)
+
+// authMethod authenticates a Vault client.
+type authMethod interface {
+	// login authenticates cli and sets its token. The returned secret holds
+	// the lease of the token, and is nil if the lifetime of the token isn't
+	// managed by the component.
+	login(cli *vault.Client) (*vault.Secret, error)
+}
+
+// AuthToken authenticates with a static Vault token. The lifetime of the
+// token isn't managed by the component.
+type AuthToken struct {
+	Token rivertypes.Secret `river:"token,attr"`
+}
+
+func (a *AuthToken) login(cli *vault.Client) (*vault.Secret, error) {
+	cli.SetToken(string(a.Token))
+	return nil, nil
+}
+
+// AuthAppRole authenticates with the AppRole auth method.
+type AuthAppRole struct {
+	RoleID    string            `river:"role_id,attr"`
+	SecretID  rivertypes.Secret `river:"secret_id,attr,optional"`
+	MountPath string            `river:"mount_path,attr,optional"`
+}
+
+// DefaultAuthAppRole holds default settings for AuthAppRole.
+var DefaultAuthAppRole = AuthAppRole{
+	MountPath: "approle",
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *AuthAppRole) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultAuthAppRole
+
+	type authAppRole AuthAppRole
+	return f((*authAppRole)(a))
+}
+
+func (a *AuthAppRole) login(cli *vault.Client) (*vault.Secret, error) {
+	data := map[string]interface{}{"role_id": a.RoleID}
+	if a.SecretID != "" {
+		data["secret_id"] = string(a.SecretID)
+	}
+	return writeLogin(cli, a.MountPath, data)
+}
+
+// AuthKubernetes authenticates with the Kubernetes auth method, using the
+// token of the service account of the pod.
+type AuthKubernetes struct {
+	Role                    string `river:"role,attr"`
+	ServiceAccountTokenFile string `river:"service_account_file,attr,optional"`
+	MountPath               string `river:"mount_path,attr,optional"`
+}
+
+// DefaultAuthKubernetes holds default settings for AuthKubernetes.
+var DefaultAuthKubernetes = AuthKubernetes{
+	ServiceAccountTokenFile: "/var/run/secrets/kubernetes.io/serviceaccount/token",
+	MountPath:               "kubernetes",
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *AuthKubernetes) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultAuthKubernetes
+
+	type authKubernetes AuthKubernetes
+	return f((*authKubernetes)(a))
+}
+
+func (a *AuthKubernetes) login(cli *vault.Client) (*vault.Secret, error) {
+	// The service account token is read on every login since Kubernetes
+	// rotates it.
+	jwt, err := os.ReadFile(a.ServiceAccountTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+	return writeLogin(cli, a.MountPath, map[string]interface{}{
+		"role": a.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+}
+
+// writeLogin logs in through the auth method mounted at mountPath and sets
+// the token of cli to the resulting client token.
+func writeLogin(cli *vault.Client, mountPath string, data map[string]interface{}) (*vault.Secret, error) {
+	// Logging in must not use a previous, possibly expired, token.
+	cli.ClearToken()
+
+	loginPath := path.Join("auth", strings.Trim(mountPath, "/"), "login")
+	secret, err := cli.Logical().Write(loginPath, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log in: %w", err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("failed to log in: no token returned by %s", loginPath)
+	}
+
+	cli.SetToken(secret.Auth.ClientToken)
+	return secret, nil
+}
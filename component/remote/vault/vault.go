@@ -0,0 +1,551 @@
+// Package vault implements the remote.vault component.
+package vault
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/config"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/prometheus/client_golang/prometheus"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "remote.vault",
+		Args:    Arguments{},
+		Exports: Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// retryInterval is how long to wait before logging in or reading the secret
+// again after a failure.
+var retryInterval = 10 * time.Second
+
+// Arguments holds values which are used to configure the remote.vault
+// component.
+type Arguments struct {
+	Server          string        `river:"server,attr"`
+	Namespace       string        `river:"namespace,attr,optional"`
+	Mount           string        `river:"mount,attr,optional"`
+	Path            string        `river:"path,attr"`
+	KVVersion       int           `river:"kv_version,attr,optional"`
+	RereadFrequency time.Duration `river:"reread_frequency,attr,optional"`
+
+	ClientOptions ClientOptions `river:"client_options,block,optional"`
+
+	AuthToken      *AuthToken      `river:"auth.token,block,optional"`
+	AuthAppRole    *AuthAppRole    `river:"auth.approle,block,optional"`
+	AuthKubernetes *AuthKubernetes `river:"auth.kubernetes,block,optional"`
+}
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Mount:           "secret",
+	KVVersion:       2,
+	RereadFrequency: 5 * time.Minute,
+	ClientOptions:   DefaultClientOptions,
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(a)); err != nil {
+		return err
+	}
+
+	if a.KVVersion != 1 && a.KVVersion != 2 {
+		return fmt.Errorf("kv_version must be 1 or 2")
+	}
+	if a.RereadFrequency < 0 {
+		return fmt.Errorf("reread_frequency must not be negative")
+	}
+
+	var methods int
+	for _, set := range []bool{a.AuthToken != nil, a.AuthAppRole != nil, a.AuthKubernetes != nil} {
+		if set {
+			methods++
+		}
+	}
+	if methods != 1 {
+		return fmt.Errorf("exactly one of auth.token, auth.approle and auth.kubernetes must be provided")
+	}
+	return nil
+}
+
+// authMethod returns the auth method configured by the arguments.
+func (a *Arguments) authMethod() authMethod {
+	switch {
+	case a.AuthAppRole != nil:
+		return a.AuthAppRole
+	case a.AuthKubernetes != nil:
+		return a.AuthKubernetes
+	default:
+		return a.AuthToken
+	}
+}
+
+// secretPath returns the logical path to read the secret from.
+func (a *Arguments) secretPath() string {
+	mount := strings.Trim(a.Mount, "/")
+	if a.KVVersion == 2 {
+		return path.Join(mount, "data", a.Path)
+	}
+	return path.Join(mount, a.Path)
+}
+
+// ClientOptions configures the Vault client.
+type ClientOptions struct {
+	Timeout    time.Duration    `river:"timeout,attr,optional"`
+	MaxRetries int              `river:"max_retries,attr,optional"`
+	TLSConfig  config.TLSConfig `river:"tls_config,block,optional"`
+}
+
+// DefaultClientOptions holds default settings for ClientOptions.
+var DefaultClientOptions = ClientOptions{
+	Timeout:    time.Minute,
+	MaxRetries: 2,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (o *ClientOptions) UnmarshalRiver(f func(interface{}) error) error {
+	*o = DefaultClientOptions
+
+	type clientOptions ClientOptions
+	return f((*clientOptions)(o))
+}
+
+// Exports holds values which are exported by the remote.vault component.
+type Exports struct {
+	// Data holds the key-value pairs of the secret.
+	Data map[string]rivertypes.Secret `river:"data,attr"`
+}
+
+// Component implements the remote.vault component.
+type Component struct {
+	opts    component.Options
+	metrics *metrics
+
+	mut        sync.Mutex
+	args       Arguments
+	client     *vault.Client
+	auth       authMethod
+	token      *vault.Secret     // Lease of the client token; nil if not managed.
+	secret     *vault.Secret     // Most recently read secret.
+	data       map[string]string // Most recently exported data.
+	needLogin  bool              // Whether the client must log in before reading.
+	lastFailed bool              // Whether the last refresh failed.
+
+	healthMut sync.RWMutex
+	health    component.Health
+
+	// updated is written to when the arguments, the token or the secret
+	// change so Run watches the new leases.
+	updated chan struct{}
+}
+
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.HealthComponent = (*Component)(nil)
+)
+
+// New creates a new remote.vault component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	m, err := newMetrics(o.Registerer)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		opts:    o,
+		metrics: m,
+		updated: make(chan struct{}, 1),
+	}
+
+	// Perform an update which will immediately log in and set our exports to
+	// the initial data of the secret.
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component. The leases of the token and the secret
+// are renewed while Run is running. When a lease can't be renewed any
+// further, the component logs in again or reads the secret again.
+func (c *Component) Run(ctx context.Context) error {
+	w := c.startWatchers()
+	defer func() { w.Stop() }()
+
+	for {
+		var (
+			timer   *time.Timer
+			timerCh <-chan time.Time
+		)
+		if next := c.nextRefresh(); next > 0 {
+			timer = time.NewTimer(next)
+			timerCh = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-c.updated:
+			w.Stop()
+			w = c.startWatchers()
+
+		case err := <-doneCh(w.token):
+			level.Info(c.opts.Logger).Log("msg", "token lease ended, logging in again", "err", err)
+			c.mut.Lock()
+			c.needLogin = true
+			_ = c.refresh()
+			c.mut.Unlock()
+		case err := <-doneCh(w.secret):
+			level.Info(c.opts.Logger).Log("msg", "secret lease ended, reading secret again", "err", err)
+			c.mut.Lock()
+			_ = c.refresh()
+			c.mut.Unlock()
+
+		case <-renewCh(w.token):
+			c.metrics.renewals.WithLabelValues("token").Inc()
+		case <-renewCh(w.secret):
+			c.metrics.renewals.WithLabelValues("secret").Inc()
+
+		case <-timerCh:
+			c.mut.Lock()
+			_ = c.refresh()
+			c.mut.Unlock()
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// nextRefresh returns how long to wait before the secret is read again. 0
+// means the secret is only read again when its lease ends.
+func (c *Component) nextRefresh() time.Duration {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.lastFailed {
+		return retryInterval
+	}
+	return c.args.RereadFrequency
+}
+
+// watchers renew the leases of the token and the secret. Either watcher may
+// be nil.
+type watchers struct {
+	token, secret *vault.LifetimeWatcher
+}
+
+// startWatchers starts watching the current leases of the token and the
+// secret.
+func (c *Component) startWatchers() watchers {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	// Leases without a duration never expire and don't need to be watched.
+	var w watchers
+	if c.token != nil && c.token.Auth.LeaseDuration > 0 {
+		w.token = c.startWatcher(c.token)
+	}
+	if c.secret != nil && c.secret.LeaseID != "" && c.secret.LeaseDuration > 0 {
+		w.secret = c.startWatcher(c.secret)
+	}
+	return w
+}
+
+// startWatcher starts a watcher which renews the lease of secret until it
+// can't be renewed any further. mut must be held when calling startWatcher.
+func (c *Component) startWatcher(secret *vault.Secret) *vault.LifetimeWatcher {
+	w, err := c.client.NewLifetimeWatcher(&vault.LifetimeWatcherInput{
+		Secret:        secret,
+		RenewBehavior: vault.RenewBehaviorIgnoreErrors,
+	})
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "failed to watch lease", "err", err)
+		return nil
+	}
+	go w.Start()
+	return w
+}
+
+// Stop stops the watchers.
+func (w watchers) Stop() {
+	if w.token != nil {
+		w.token.Stop()
+	}
+	if w.secret != nil {
+		w.secret.Stop()
+	}
+}
+
+// doneCh returns the channel which is written to when w stops renewing its
+// lease. A nil channel is returned if w is nil.
+func doneCh(w *vault.LifetimeWatcher) <-chan error {
+	if w == nil {
+		return nil
+	}
+	return w.DoneCh()
+}
+
+// renewCh returns the channel which is written to when w renewed its lease.
+// A nil channel is returned if w is nil.
+func renewCh(w *vault.LifetimeWatcher) <-chan *vault.RenewOutput {
+	if w == nil {
+		return nil
+	}
+	return w.RenewCh()
+}
+
+// refresh logs in if needed and reads the secret, exporting its data if it
+// changed. mut must be held when calling refresh.
+func (c *Component) refresh() error {
+	prevToken, prevLease := c.token, leaseID(c.secret)
+
+	if err := c.loginAndRead(); err != nil {
+		c.lastFailed = true
+		c.metrics.errors.Inc()
+		c.setHealth(component.Health{
+			Health:     component.HealthTypeUnhealthy,
+			Message:    err.Error(),
+			UpdateTime: time.Now(),
+		})
+		level.Error(c.opts.Logger).Log("msg", "failed to read secret from vault", "err", err)
+		return err
+	}
+
+	c.lastFailed = false
+	c.setHealth(component.Health{
+		Health:     component.HealthTypeHealthy,
+		Message:    "read secret",
+		UpdateTime: time.Now(),
+	})
+
+	if c.token != prevToken || leaseID(c.secret) != prevLease {
+		select {
+		case c.updated <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func leaseID(s *vault.Secret) string {
+	if s == nil {
+		return ""
+	}
+	return s.LeaseID
+}
+
+func (c *Component) loginAndRead() error {
+	loggedIn := c.needLogin
+	if c.needLogin {
+		token, err := c.auth.login(c.client)
+		if err != nil {
+			return err
+		}
+		c.metrics.logins.Inc()
+		c.token = token
+		c.needLogin = false
+	}
+
+	secretPath := c.args.secretPath()
+	secret, err := c.client.Logical().Read(secretPath)
+	if err != nil {
+		var respErr *vault.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden {
+			// The token may have been revoked. Log in again, retrying the read
+			// once if the token wasn't just issued.
+			c.needLogin = true
+			if !loggedIn {
+				return c.loginAndRead()
+			}
+		}
+		return fmt.Errorf("failed to read secret %s: %w", secretPath, err)
+	}
+	if secret == nil {
+		return fmt.Errorf("secret %s not found", secretPath)
+	}
+	c.metrics.reads.Inc()
+
+	data, err := secretData(secret, c.args.KVVersion)
+	if err != nil {
+		return fmt.Errorf("secret %s: %w", secretPath, err)
+	}
+
+	c.secret = secret
+	if c.data == nil || !reflect.DeepEqual(c.data, data) {
+		c.data = data
+
+		exports := make(map[string]rivertypes.Secret, len(data))
+		for k, v := range data {
+			exports[k] = rivertypes.Secret(v)
+		}
+		c.opts.OnStateChange(Exports{Data: exports})
+	}
+	return nil
+}
+
+// secretData returns the key-value pairs of secret. Values which aren't
+// strings are encoded as JSON.
+func secretData(secret *vault.Secret, kvVersion int) (map[string]string, error) {
+	raw := secret.Data
+	if kvVersion == 2 {
+		// KV v2 nests the key-value pairs under a data key, next to the metadata
+		// of the secret.
+		nested, ok := secret.Data["data"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("no data found; check that kv_version matches the version of the secrets engine")
+		}
+		raw = nested
+	}
+
+	data := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case string:
+			data[k] = v
+		default:
+			bb, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode value of %s: %w", k, err)
+			}
+			data[k] = string(bb)
+		}
+	}
+	return data, nil
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	client, err := newClient(newArgs)
+	if err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.args = newArgs
+	c.client = client
+	c.auth = newArgs.authMethod()
+	c.token = nil
+	c.secret = nil
+	c.data = nil
+	c.needLogin = true
+
+	select {
+	case c.updated <- struct{}{}:
+	default:
+	}
+
+	// Log in and read the secret immediately to report any potential errors
+	// early.
+	return c.refresh()
+}
+
+// newClient creates a Vault client for the server described by args.
+func newClient(args Arguments) (*vault.Client, error) {
+	cfg := vault.DefaultConfig()
+	if cfg.Error != nil {
+		return nil, fmt.Errorf("failed to build vault client config: %w", cfg.Error)
+	}
+	cfg.Address = args.Server
+	cfg.Timeout = args.ClientOptions.Timeout
+	cfg.MaxRetries = args.ClientOptions.MaxRetries
+
+	tlsConfig, err := config_util.NewTLSConfig(args.ClientOptions.TLSConfig.Convert())
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls_config: %w", err)
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+	cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+
+	client, err := vault.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build vault client: %w", err)
+	}
+	if args.Namespace != "" {
+		client.SetNamespace(args.Namespace)
+	}
+	return client, nil
+}
+
+// CurrentHealth implements component.HealthComponent.
+func (c *Component) CurrentHealth() component.Health {
+	c.healthMut.RLock()
+	defer c.healthMut.RUnlock()
+	return c.health
+}
+
+func (c *Component) setHealth(h component.Health) {
+	c.healthMut.Lock()
+	defer c.healthMut.Unlock()
+	c.health = h
+}
+
+type metrics struct {
+	logins   prometheus.Counter
+	reads    prometheus.Counter
+	errors   prometheus.Counter
+	renewals *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		logins: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "agent_remote_vault_logins_total",
+			Help: "Total number of times the component logged in to Vault.",
+		}),
+		reads: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "agent_remote_vault_reads_total",
+			Help: "Total number of times the secret was read from Vault.",
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "agent_remote_vault_errors_total",
+			Help: "Total number of failed attempts to log in or read the secret.",
+		}),
+		renewals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "agent_remote_vault_lease_renewals_total",
+			Help: "Total number of lease renewals by type of lease.",
+		}, []string{"lease"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.logins, m.reads, m.errors, m.renewals} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
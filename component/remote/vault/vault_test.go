@@ -0,0 +1,346 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/remote/vault"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault is an in-process fake of the subset of the Vault HTTP API used by
+// remote.vault.
+type fakeVault struct {
+	t *testing.T
+
+	mut      sync.Mutex
+	tokens   map[string]bool   // Valid client tokens.
+	logins   map[string]string // Expected login data by auth mount.
+	secrets  map[string]map[string]interface{}
+	leases   map[string]int // Lease duration in seconds by secret path.
+	renewals int
+	issued   int
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	return &fakeVault{
+		t:       t,
+		tokens:  map[string]bool{},
+		logins:  map[string]string{},
+		secrets: map[string]map[string]interface{}{},
+		leases:  map[string]int{},
+	}
+}
+
+func (f *fakeVault) SetSecret(path string, data map[string]interface{}) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.secrets[path] = data
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+
+	var body map[string]interface{}
+	if r.Method == http.MethodPut || r.Method == http.MethodPost {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case strings.HasPrefix(path, "auth/") && strings.HasSuffix(path, "/login"):
+		mount := strings.TrimSuffix(strings.TrimPrefix(path, "auth/"), "/login")
+		bb, _ := json.Marshal(body)
+		if expect, ok := f.logins[mount]; !ok || expect != string(bb) {
+			f.writeError(w, http.StatusBadRequest)
+			return
+		}
+		f.issued++
+		token := fmt.Sprintf("token-%d", f.issued)
+		f.tokens[token] = true
+		f.writeJSON(w, map[string]interface{}{
+			"auth": map[string]interface{}{
+				"client_token":   token,
+				"lease_duration": 3600,
+				"renewable":      true,
+			},
+		})
+		return
+	}
+
+	token := r.Header.Get("X-Vault-Token")
+	if !f.tokens[token] {
+		f.writeError(w, http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case path == "auth/token/renew-self":
+		f.renewals++
+		f.writeJSON(w, map[string]interface{}{
+			"auth": map[string]interface{}{
+				"client_token":   token,
+				"lease_duration": 3600,
+				"renewable":      true,
+			},
+		})
+	case r.Method == http.MethodGet:
+		data, ok := f.secrets[path]
+		if !ok {
+			f.writeError(w, http.StatusNotFound)
+			return
+		}
+		resp := map[string]interface{}{"data": data}
+		if lease, ok := f.leases[path]; ok {
+			resp["lease_id"] = fmt.Sprintf("%s/%d", path, time.Now().UnixNano())
+			resp["lease_duration"] = lease
+			resp["renewable"] = false
+		}
+		f.writeJSON(w, resp)
+	default:
+		f.writeError(w, http.StatusNotFound)
+	}
+}
+
+func (f *fakeVault) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(f.t, json.NewEncoder(w).Encode(v))
+}
+
+func (f *fakeVault) writeError(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintf(w, `{"errors":[%q]}`, http.StatusText(code))
+}
+
+func TestVault_KVv2_AppRole(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.logins["approle"] = `{"role_id":"agent","secret_id":"s3cr3t"}`
+	fake.SetSecret("secret/data/app", map[string]interface{}{
+		"data": map[string]interface{}{
+			"password": "hunter2",
+			"port":     8080,
+		},
+		"metadata": map[string]interface{}{"version": 1},
+	})
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	args := parseArguments(t, `
+		server           = "`+srv.URL+`"
+		path             = "app"
+		reread_frequency = "50ms"
+
+		auth.approle {
+			role_id   = "agent"
+			secret_id = "s3cr3t"
+		}
+	`)
+
+	tc, err := componenttest.NewControllerFromID(nil, "remote.vault")
+	require.NoError(t, err)
+	go func() {
+		err := tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, tc.WaitExports(time.Second))
+	require.Equal(t, vault.Exports{
+		Data: map[string]rivertypes.Secret{
+			"password": "hunter2",
+			"port":     "8080",
+		},
+	}, tc.Exports())
+
+	// The token is renewed while the component runs.
+	require.Eventually(t, func() bool {
+		fake.mut.Lock()
+		defer fake.mut.Unlock()
+		return fake.renewals > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// Rotated secrets are exported again.
+	fake.SetSecret("secret/data/app", map[string]interface{}{
+		"data": map[string]interface{}{"password": "rotated"},
+	})
+	require.NoError(t, tc.WaitExports(time.Second))
+	require.Equal(t, vault.Exports{
+		Data: map[string]rivertypes.Secret{"password": "rotated"},
+	}, tc.Exports())
+
+	// Revoked tokens cause the component to log in again.
+	fake.mut.Lock()
+	fake.tokens = map[string]bool{}
+	fake.mut.Unlock()
+	fake.SetSecret("secret/data/app", map[string]interface{}{
+		"data": map[string]interface{}{"password": "after-login"},
+	})
+	require.Eventually(t, func() bool {
+		data := tc.Exports().(vault.Exports).Data
+		return data["password"] == "after-login"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestVault_KVv1_Kubernetes_Lease(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("jwt-token\n"), 0600))
+
+	fake := newFakeVault(t)
+	fake.logins["k8s"] = `{"jwt":"jwt-token","role":"agent"}`
+	fake.SetSecret("database/creds/agent", map[string]interface{}{"username": "first"})
+	fake.leases["database/creds/agent"] = 1
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	args := parseArguments(t, `
+		server           = "`+srv.URL+`"
+		mount            = "database"
+		path             = "creds/agent"
+		kv_version       = 1
+		reread_frequency = "0s"
+
+		auth.kubernetes {
+			role                 = "agent"
+			service_account_file = "`+tokenFile+`"
+			mount_path           = "k8s"
+		}
+	`)
+
+	tc, err := componenttest.NewControllerFromID(nil, "remote.vault")
+	require.NoError(t, err)
+	go func() {
+		err := tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, tc.WaitExports(time.Second))
+	require.Equal(t, vault.Exports{
+		Data: map[string]rivertypes.Secret{"username": "first"},
+	}, tc.Exports())
+
+	// The secret is read again when its lease ends, even though rereading is
+	// disabled.
+	fake.SetSecret("database/creds/agent", map[string]interface{}{"username": "second"})
+	require.NoError(t, tc.WaitExports(3*time.Second))
+	require.Equal(t, vault.Exports{
+		Data: map[string]rivertypes.Secret{"username": "second"},
+	}, tc.Exports())
+}
+
+func TestVault_Token(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.tokens["static-token"] = true
+	fake.SetSecret("kv/app", map[string]interface{}{"key": "value"})
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	args := parseArguments(t, `
+		server     = "`+srv.URL+`"
+		mount      = "kv"
+		path       = "app"
+		kv_version = 1
+
+		auth.token {
+			token = "static-token"
+		}
+	`)
+
+	tc, err := componenttest.NewControllerFromID(nil, "remote.vault")
+	require.NoError(t, err)
+	go func() {
+		err := tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, tc.WaitExports(time.Second))
+	require.Equal(t, vault.Exports{
+		Data: map[string]rivertypes.Secret{"key": "value"},
+	}, tc.Exports())
+}
+
+func TestVault_WrongKVVersion(t *testing.T) {
+	fake := newFakeVault(t)
+	fake.tokens["static-token"] = true
+	fake.SetSecret("secret/data/app", map[string]interface{}{"key": "value"})
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	args := parseArguments(t, `
+		server = "`+srv.URL+`"
+		path   = "app"
+
+		auth.token {
+			token = "static-token"
+		}
+	`)
+
+	tc, err := componenttest.NewControllerFromID(nil, "remote.vault")
+	require.NoError(t, err)
+	err = tc.Run(componenttest.TestContext(t), args)
+	require.ErrorContains(t, err, "check that kv_version matches the version of the secrets engine")
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	args := parseArguments(t, `
+		server = "https://vault:8200"
+		path   = "app"
+
+		auth.approle {
+			role_id = "agent"
+		}
+	`)
+	require.Equal(t, "secret", args.Mount)
+	require.Equal(t, 2, args.KVVersion)
+	require.Equal(t, 5*time.Minute, args.RereadFrequency)
+	require.Equal(t, vault.DefaultClientOptions, args.ClientOptions)
+	require.Equal(t, "approle", args.AuthAppRole.MountPath)
+
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name:   "no auth",
+			cfg:    "server = \"https://vault:8200\"\npath = \"app\"",
+			expect: "exactly one of auth.token, auth.approle and auth.kubernetes must be provided",
+		},
+		{
+			name:   "multiple auth",
+			cfg:    "server = \"https://vault:8200\"\npath = \"app\"\nauth.token {\ntoken = \"t\"\n}\nauth.approle {\nrole_id = \"r\"\n}",
+			expect: "exactly one of auth.token, auth.approle and auth.kubernetes must be provided",
+		},
+		{
+			name:   "invalid kv version",
+			cfg:    "server = \"https://vault:8200\"\npath = \"app\"\nkv_version = 3\nauth.token {\ntoken = \"t\"\n}",
+			expect: "kv_version must be 1 or 2",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args vault.Arguments
+			require.EqualError(t, river.Unmarshal([]byte(tc.cfg), &args), tc.expect)
+		})
+	}
+}
+
+func parseArguments(t *testing.T, cfg string) vault.Arguments {
+	t.Helper()
+
+	var args vault.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	return args
+}
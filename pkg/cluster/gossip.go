@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/dskit/flagext"
 	"github.com/hashicorp/go-discover"
 	"github.com/hashicorp/go-discover/provider/k8s"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rfratto/ckit"
 	"github.com/rfratto/ckit/advertise"
 	"github.com/rfratto/ckit/clientpool"
@@ -243,6 +244,12 @@ func (n *GossipNode) Peers() []peer.Peer {
 	return n.innerNode.Peers()
 }
 
+// Metrics returns a prometheus collector which exposes metrics about the
+// node's view of the cluster.
+func (n *GossipNode) Metrics() prometheus.Collector {
+	return n.innerNode.Metrics()
+}
+
 // Start starts the node. Start will connect to peers if configured to do so.
 //
 // Start must only be called after the gRPC server is running, otherwise Start
@@ -0,0 +1,164 @@
+// Package converter converts static mode configuration files into Grafana
+// Agent Flow configuration files.
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/agent/pkg/metrics"
+	"github.com/grafana/agent/pkg/river/token/builder"
+	"github.com/prometheus/prometheus/config"
+	"gopkg.in/yaml.v2"
+
+	// Register Prometheus service discovery mechanisms so scrape configs can
+	// be decoded.
+	_ "github.com/prometheus/prometheus/discovery/install"
+)
+
+// staticConfig holds the sections of a static mode configuration file. Only
+// the metrics section is decoded in full; the other sections are only
+// inspected to report what can't be converted.
+type staticConfig struct {
+	Server       yaml.MapSlice   `yaml:"server,omitempty"`
+	Metrics      *metrics.Config `yaml:"metrics,omitempty"`
+	Integrations yaml.MapSlice   `yaml:"integrations,omitempty"`
+	Traces       yaml.MapSlice   `yaml:"traces,omitempty"`
+	Logs         yaml.MapSlice   `yaml:"logs,omitempty"`
+
+	// Deprecated names of the sections above.
+	Prometheus *metrics.Config `yaml:"prometheus,omitempty"`
+	Loki       yaml.MapSlice   `yaml:"loki,omitempty"`
+	Tempo      yaml.MapSlice   `yaml:"tempo,omitempty"`
+}
+
+// Convert converts a static mode configuration file into an equivalent Flow
+// configuration file. Settings which can't be converted are reported as
+// diagnostics rather than being dropped silently; the returned file is only
+// equivalent to the input if no diagnostics at SeverityError are returned.
+//
+// A nil file is returned if in can't be decoded.
+func Convert(in []byte) ([]byte, Diagnostics) {
+	var (
+		sc    staticConfig
+		diags Diagnostics
+	)
+	if err := yaml.UnmarshalStrict(in, &sc); err != nil {
+		diags.Add(SeverityError, "failed to parse static configuration: %s", err)
+		return nil, diags
+	}
+
+	c := &converter{
+		file:   builder.NewFile(),
+		labels: make(labeler),
+	}
+	c.convertServer(sc.Server)
+
+	switch {
+	case sc.Metrics != nil && sc.Prometheus != nil:
+		c.diags.Add(SeverityError, "at most one of metrics and prometheus may be specified; only metrics was converted")
+		c.convertMetrics(sc.Metrics)
+	case sc.Metrics != nil:
+		c.convertMetrics(sc.Metrics)
+	case sc.Prometheus != nil:
+		c.convertMetrics(sc.Prometheus)
+	}
+
+	for _, item := range sc.Integrations {
+		c.diags.Add(SeverityError, "integrations.%v cannot be converted", item.Key)
+	}
+	if len(sc.Traces) > 0 || len(sc.Tempo) > 0 {
+		c.diags.Add(SeverityError, "the traces section cannot be converted; use otelcol components to collect traces")
+	}
+	if len(sc.Logs) > 0 || len(sc.Loki) > 0 {
+		c.diags.Add(SeverityError, "the logs section cannot be converted; use loki components to collect logs")
+	}
+
+	var buf bytes.Buffer
+	if _, err := c.file.WriteTo(&buf); err != nil {
+		c.diags.Add(SeverityError, "failed to render Flow configuration: %s", err)
+		return nil, c.diags
+	}
+
+	// Add a newline at the end of the file.
+	_, _ = buf.Write([]byte{'\n'})
+	return buf.Bytes(), c.diags
+}
+
+// converter holds the state of an in-progress conversion.
+type converter struct {
+	file   *builder.File
+	labels labeler
+	diags  Diagnostics
+
+	// instance is the name of the metrics instance being converted.
+	instance string
+}
+
+// appendBlock appends a new block for the component called name to the file
+// and returns its body.
+func (c *converter) appendBlock(name, label string) *builder.Body {
+	block := builder.NewBlock(strings.Split(name, "."), label)
+	c.file.Body().AppendBlock(block)
+	return block.Body()
+}
+
+// convertServer converts the server section into a logging block. Other
+// server settings are configured through command-line flags in Flow.
+func (c *converter) convertServer(server yaml.MapSlice) {
+	block := builder.NewBlock([]string{"logging"}, "")
+
+	for _, item := range server {
+		switch item.Key {
+		case "log_level":
+			setAttr(block.Body(), "level", fmt.Sprint(item.Value), "info")
+		case "log_format":
+			setAttr(block.Body(), "format", fmt.Sprint(item.Value), "logfmt")
+		default:
+			c.diags.Add(SeverityWarn, "server.%v cannot be converted; use the command-line flags of agent run instead", item.Key)
+		}
+	}
+
+	if len(block.Body().Tokens()) > 0 {
+		c.file.Body().AppendBlock(block)
+	}
+}
+
+// convertMetrics converts every instance in the metrics section.
+func (c *converter) convertMetrics(cfg *metrics.Config) {
+	if cfg.ServiceConfig.Enabled {
+		c.diags.Add(SeverityError, "metrics.scraping_service cannot be converted; use clustering in prometheus.scrape to distribute scrape load instead")
+	}
+	// The default WAL directory comes from a flag, so decode an empty config to
+	// find out whether it was changed.
+	var defaults metrics.Config
+	_ = yaml.Unmarshal([]byte("{}"), &defaults)
+	if cfg.WALDir != defaults.WALDir {
+		c.diags.Add(SeverityInfo, "metrics.wal_directory was ignored; WALs are stored in the directory set by --storage.path")
+	}
+
+	for _, inst := range cfg.Configs {
+		// ApplyDefaults generates names for unnamed remote_write configs. Those
+		// names are reset afterwards so they don't appear in the output.
+		unnamed := make(map[*config.RemoteWriteConfig]struct{})
+		for _, rws := range [][]*config.RemoteWriteConfig{inst.RemoteWrite, cfg.Global.RemoteWrite} {
+			for _, rw := range rws {
+				if rw != nil && rw.Name == "" {
+					unnamed[rw] = struct{}{}
+				}
+			}
+		}
+
+		err := inst.ApplyDefaults(cfg.Global)
+		for rw := range unnamed {
+			rw.Name = ""
+		}
+		if err != nil {
+			c.diags.Add(SeverityError, "metrics instance %q is invalid and was not converted: %s", inst.Name, err)
+			continue
+		}
+
+		c.appendInstance(cfg.Global, inst)
+	}
+}
@@ -0,0 +1,79 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConvert converts each YAML file in testdata and compares the result to
+// the .river and .diags files with the same name.
+func TestConvert(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(input, ".yaml")
+
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			in, err := os.ReadFile(input)
+			require.NoError(t, err)
+			expectRiver, err := os.ReadFile(name + ".river")
+			require.NoError(t, err)
+			expectDiags, err := os.ReadFile(name + ".diags")
+			require.NoError(t, err)
+
+			out, diags := Convert(in)
+			require.Equal(t, string(expectRiver), string(out))
+			require.Equal(t, strings.TrimSpace(string(expectDiags)), diags.Error())
+		})
+	}
+}
+
+func TestConvert_InvalidYAML(t *testing.T) {
+	out, diags := Convert([]byte("metrics:\n  unknown_field: true\n"))
+	require.Nil(t, out)
+	require.True(t, diags.HasErrors())
+	require.Contains(t, diags.Error(), "failed to parse static configuration")
+}
+
+func TestConvert_InvalidInstance(t *testing.T) {
+	out, diags := Convert([]byte(`
+metrics:
+  configs:
+    - scrape_configs: []
+`))
+	require.Empty(t, strings.TrimSpace(string(out)))
+	require.EqualError(t, diags, `error: metrics instance "" is invalid and was not converted: missing instance name`)
+}
+
+func TestLabeler(t *testing.T) {
+	l := make(labeler)
+
+	require.Equal(t, "default_node_exporter", l.Label("prometheus.scrape", "default", "node-exporter"))
+	require.Equal(t, "default_node_exporter_2", l.Label("prometheus.scrape", "default", "node.exporter"))
+	require.Equal(t, "default_node_exporter", l.Label("discovery.relabel", "default", "node-exporter"))
+	require.Equal(t, "_1st_job", l.Label("prometheus.scrape", "1st job"))
+	require.Equal(t, "default", l.Label("prometheus.remote_write", ""))
+}
+
+func TestFormatDuration(t *testing.T) {
+	tt := map[time.Duration]string{
+		15 * time.Second:             "15s",
+		time.Minute:                  "1m",
+		90 * time.Second:             "1m30s",
+		2 * time.Hour:                "2h",
+		2*time.Hour + 30*time.Minute: "2h30m",
+		500 * time.Millisecond:       "500ms",
+		time.Hour + time.Second:      "1h0m1s",
+		24*time.Hour + 5*time.Minute: "24h5m",
+	}
+	for d, expect := range tt {
+		require.Equal(t, expect, formatDuration(d), "formatting %s", d)
+	}
+}
@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity denotes the severity level of a Diagnostic.
+type Severity int
+
+// Supported severity levels. Diagnostics at SeverityError mean the converted
+// configuration doesn't behave the same as the input.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarn
+	SeverityError
+)
+
+// String returns the name of the severity level.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Diagnostic reports a problem found while converting a configuration.
+type Diagnostic struct {
+	Severity Severity
+	Summary  string
+}
+
+// String returns a human-readable representation of the diagnostic.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Severity, d.Summary)
+}
+
+// Diagnostics is a collection of diagnostics.
+type Diagnostics []Diagnostic
+
+// Add appends a new diagnostic with the given severity.
+func (ds *Diagnostics) Add(severity Severity, format string, args ...interface{}) {
+	*ds = append(*ds, Diagnostic{Severity: severity, Summary: fmt.Sprintf(format, args...)})
+}
+
+// HasErrors returns true if any diagnostic is at SeverityError.
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Error implements error, so Diagnostics can be returned as one.
+func (ds Diagnostics) Error() string {
+	lines := make([]string, 0, len(ds))
+	for _, d := range ds {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,234 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/agent/pkg/river/token/builder"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery"
+	"github.com/prometheus/prometheus/discovery/aws"
+	"github.com/prometheus/prometheus/discovery/consul"
+	"github.com/prometheus/prometheus/discovery/dns"
+	"github.com/prometheus/prometheus/discovery/file"
+	"github.com/prometheus/prometheus/discovery/gce"
+	"github.com/prometheus/prometheus/discovery/http"
+	"github.com/prometheus/prometheus/discovery/kubernetes"
+	"github.com/prometheus/prometheus/discovery/moby"
+)
+
+// appendDiscovery appends discovery components for the service discovery
+// configs of the scrape job named job. It returns an expression which
+// evaluates to the combined list of discovered targets.
+func (c *converter) appendDiscovery(job string, configs discovery.Configs) expr {
+	var (
+		static  []map[string]string
+		sources []expr
+	)
+
+	for _, sdc := range configs {
+		if sc, ok := sdc.(discovery.StaticConfig); ok {
+			static = append(static, staticTargets(sc)...)
+			continue
+		}
+
+		name := "discovery." + sdc.Name()
+		label, ok := c.appendDiscoveryComponent(name, job, sdc)
+		if !ok {
+			c.diags.Add(SeverityError, "scrape job %q: %s_sd_configs cannot be converted; no Flow component supports it", job, sdc.Name())
+			continue
+		}
+		sources = append(sources, expr(fmt.Sprintf("%s.%s.targets", name, label)))
+	}
+
+	switch {
+	case len(sources) == 0:
+		return literalExpr(static)
+	case len(sources) == 1 && len(static) == 0:
+		return sources[0]
+	}
+
+	args := make([]string, 0, len(sources)+1)
+	for _, s := range sources {
+		args = append(args, string(s))
+	}
+	if len(static) > 0 {
+		args = append(args, string(literalExpr(static)))
+	}
+	return expr(fmt.Sprintf("concat(%s)", strings.Join(args, ", ")))
+}
+
+// appendDiscoveryComponent appends the Flow component equivalent to sdc. ok
+// is false if sdc can't be converted.
+func (c *converter) appendDiscoveryComponent(name, job string, sdc discovery.Config) (label string, ok bool) {
+	var body *builder.Body
+	newBody := func() *builder.Body {
+		label = c.labels.Label(name, c.instance, job)
+		return c.appendBlock(name, label)
+	}
+
+	switch sdc := sdc.(type) {
+	case *kubernetes.SDConfig:
+		body = newBody()
+		if sdc.APIServer.URL != nil {
+			body.SetAttributeValue("api_server", sdc.APIServer.String())
+		}
+		body.SetAttributeValue("role", string(sdc.Role))
+		setAttr(body, "kubeconfig_file", sdc.KubeConfig, "")
+		appendHTTPClientConfig(body, sdc.HTTPClientConfig)
+		if sdc.NamespaceDiscovery.IncludeOwnNamespace || len(sdc.NamespaceDiscovery.Names) > 0 {
+			ns := builder.NewBlock([]string{"namespaces"}, "")
+			setAttr(ns.Body(), "own_namespace", sdc.NamespaceDiscovery.IncludeOwnNamespace, false)
+			if len(sdc.NamespaceDiscovery.Names) > 0 {
+				ns.Body().SetAttributeValue("names", sdc.NamespaceDiscovery.Names)
+			}
+			body.AppendBlock(ns)
+		}
+		for _, sel := range sdc.Selectors {
+			selector := builder.NewBlock([]string{"selectors"}, "")
+			selector.Body().SetAttributeValue("role", string(sel.Role))
+			setAttr(selector.Body(), "label", sel.Label, "")
+			setAttr(selector.Body(), "field", sel.Field, "")
+			body.AppendBlock(selector)
+		}
+		if sdc.AttachMetadata.Node {
+			c.diags.Add(SeverityError, "scrape job %q: kubernetes_sd_configs attach_metadata is not supported by discovery.kubernetes", job)
+		}
+
+	case *file.SDConfig:
+		body = newBody()
+		body.SetAttributeValue("files", sdc.Files)
+		setDuration(body, "refresh_interval", time.Duration(sdc.RefreshInterval), 5*time.Minute)
+
+	case *http.SDConfig:
+		body = newBody()
+		body.SetAttributeValue("url", sdc.URL)
+		setDuration(body, "refresh_interval", time.Duration(sdc.RefreshInterval), time.Minute)
+		appendHTTPClientConfig(body, sdc.HTTPClientConfig)
+
+	case *dns.SDConfig:
+		body = newBody()
+		body.SetAttributeValue("names", sdc.Names)
+		setDuration(body, "refresh_interval", time.Duration(sdc.RefreshInterval), 30*time.Second)
+		setAttr(body, "type", sdc.Type, "SRV")
+		setAttr(body, "port", sdc.Port, 0)
+
+	case *moby.DockerSDConfig:
+		body = newBody()
+		body.SetAttributeValue("host", sdc.Host)
+		setAttr(body, "port", sdc.Port, 80)
+		setAttr(body, "host_networking_host", sdc.HostNetworkingHost, "localhost")
+		setDuration(body, "refresh_interval", time.Duration(sdc.RefreshInterval), time.Minute)
+		for _, f := range sdc.Filters {
+			filter := builder.NewBlock([]string{"filter"}, "")
+			filter.Body().SetAttributeValue("name", f.Name)
+			filter.Body().SetAttributeValue("values", f.Values)
+			body.AppendBlock(filter)
+		}
+		appendHTTPClientConfig(body, sdc.HTTPClientConfig)
+
+	case *aws.EC2SDConfig:
+		body = newBody()
+		setAttr(body, "endpoint", sdc.Endpoint, "")
+		setAttr(body, "region", sdc.Region, "")
+		setAttr(body, "access_key", sdc.AccessKey, "")
+		setAttr(body, "secret_key", string(sdc.SecretKey), "")
+		setAttr(body, "profile", sdc.Profile, "")
+		setAttr(body, "role_arn", sdc.RoleARN, "")
+		setDuration(body, "refresh_interval", time.Duration(sdc.RefreshInterval), time.Minute)
+		setAttr(body, "port", sdc.Port, 80)
+		for _, f := range sdc.Filters {
+			filter := builder.NewBlock([]string{"filter"}, "")
+			filter.Body().SetAttributeValue("name", f.Name)
+			filter.Body().SetAttributeValue("values", f.Values)
+			body.AppendBlock(filter)
+		}
+
+	case *gce.SDConfig:
+		body = newBody()
+		body.SetAttributeValue("project", sdc.Project)
+		body.SetAttributeValue("zone", sdc.Zone)
+		setAttr(body, "filter", sdc.Filter, "")
+		setDuration(body, "refresh_interval", time.Duration(sdc.RefreshInterval), time.Minute)
+		setAttr(body, "port", sdc.Port, 80)
+		setAttr(body, "tag_separator", sdc.TagSeparator, ",")
+
+	case *consul.SDConfig:
+		body = newBody()
+		setAttr(body, "server", sdc.Server, "localhost:8500")
+		setAttr(body, "token", string(sdc.Token), "")
+		setAttr(body, "datacenter", sdc.Datacenter, "")
+		setAttr(body, "namespace", sdc.Namespace, "")
+		setAttr(body, "tag_separator", sdc.TagSeparator, ",")
+		setAttr(body, "scheme", sdc.Scheme, "http")
+		setAttr(body, "username", sdc.Username, "")
+		setAttr(body, "password", string(sdc.Password), "")
+		setAttr(body, "allow_stale", sdc.AllowStale, true)
+		if len(sdc.Services) > 0 {
+			body.SetAttributeValue("services", sdc.Services)
+		}
+		if len(sdc.ServiceTags) > 0 {
+			body.SetAttributeValue("tags", sdc.ServiceTags)
+		}
+		if len(sdc.NodeMeta) > 0 {
+			body.SetAttributeValue("node_meta", sdc.NodeMeta)
+		}
+		setDuration(body, "refresh_interval", time.Duration(sdc.RefreshInterval), 30*time.Second)
+		appendHTTPClientConfig(body, sdc.HTTPClientConfig)
+
+	default:
+		return "", false
+	}
+
+	return label, true
+}
+
+// staticTargets flattens a static_configs section into a list of targets,
+// where each target holds the labels of its group.
+func staticTargets(sc discovery.StaticConfig) []map[string]string {
+	var res []map[string]string
+	for _, group := range sc {
+		for _, target := range group.Targets {
+			t := make(map[string]string, len(group.Labels)+len(target))
+			for k, v := range group.Labels {
+				t[string(k)] = string(v)
+			}
+			for k, v := range target {
+				t[string(k)] = string(v)
+			}
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// literalExpr returns a River expression for a literal list of targets.
+// __address__ is always written first in each target.
+func literalExpr(targets []map[string]string) expr {
+	elems := make([]string, 0, len(targets))
+	for _, t := range targets {
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			if k != model.AddressLabel {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		if _, ok := t[model.AddressLabel]; ok {
+			keys = append([]string{model.AddressLabel}, keys...)
+		}
+
+		fields := make([]string, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, fmt.Sprintf("%s = %q", objectKey(k), t[k]))
+		}
+		elems = append(elems, fmt.Sprintf("{%s}", strings.Join(fields, ", ")))
+	}
+
+	if len(elems) <= 1 {
+		return expr(fmt.Sprintf("[%s]", strings.Join(elems, "")))
+	}
+	return expr(fmt.Sprintf("[\n%s,\n]", strings.Join(elems, ",\n")))
+}
@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"reflect"
+
+	"github.com/grafana/agent/pkg/river/token/builder"
+	config_util "github.com/prometheus/common/config"
+)
+
+// appendHTTPClientConfig appends an http_client_config block to b when cfg
+// differs from the defaults.
+func appendHTTPClientConfig(b *builder.Body, cfg config_util.HTTPClientConfig) {
+	if reflect.DeepEqual(cfg, config_util.DefaultHTTPClientConfig) {
+		return
+	}
+
+	block := builder.NewBlock([]string{"http_client_config"}, "")
+	body := block.Body()
+
+	if ba := cfg.BasicAuth; ba != nil {
+		inner := builder.NewBlock([]string{"basic_auth"}, "")
+		setAttr(inner.Body(), "username", ba.Username, "")
+		setAttr(inner.Body(), "password", string(ba.Password), "")
+		setAttr(inner.Body(), "password_file", ba.PasswordFile, "")
+		body.AppendBlock(inner)
+	}
+	if auth := cfg.Authorization; auth != nil {
+		inner := builder.NewBlock([]string{"authorization"}, "")
+		setAttr(inner.Body(), "type", auth.Type, "")
+		setAttr(inner.Body(), "credentials", string(auth.Credentials), "")
+		setAttr(inner.Body(), "credentials_file", auth.CredentialsFile, "")
+		body.AppendBlock(inner)
+	}
+	if oauth := cfg.OAuth2; oauth != nil {
+		inner := builder.NewBlock([]string{"oauth2"}, "")
+		setAttr(inner.Body(), "client_id", oauth.ClientID, "")
+		setAttr(inner.Body(), "client_secret", string(oauth.ClientSecret), "")
+		setAttr(inner.Body(), "client_secret_file", oauth.ClientSecretFile, "")
+		if len(oauth.Scopes) > 0 {
+			inner.Body().SetAttributeValue("scopes", oauth.Scopes)
+		}
+		setAttr(inner.Body(), "token_url", oauth.TokenURL, "")
+		if len(oauth.EndpointParams) > 0 {
+			inner.Body().SetAttributeValue("endpoint_params", oauth.EndpointParams)
+		}
+		if oauth.ProxyURL.URL != nil {
+			inner.Body().SetAttributeValue("proxy_url", oauth.ProxyURL.String())
+		}
+		if tls := tlsConfigFields(oauth.TLSConfig); len(tls) > 0 {
+			obj := make(map[string]interface{}, len(tls))
+			for _, kv := range tls {
+				obj[kv.key] = kv.value
+			}
+			inner.Body().SetAttributeValue("tls_config", obj)
+		}
+		body.AppendBlock(inner)
+	}
+
+	setAttr(body, "bearer_token", string(cfg.BearerToken), "")
+	setAttr(body, "bearer_token_file", cfg.BearerTokenFile, "")
+	if cfg.ProxyURL.URL != nil {
+		body.SetAttributeValue("proxy_url", cfg.ProxyURL.String())
+	}
+	if tls := tlsConfigFields(cfg.TLSConfig); len(tls) > 0 {
+		inner := builder.NewBlock([]string{"tls_config"}, "")
+		for _, kv := range tls {
+			inner.Body().SetAttributeValue(kv.key, kv.value)
+		}
+		body.AppendBlock(inner)
+	}
+	setAttr(body, "follow_redirects", cfg.FollowRedirects, true)
+	setAttr(body, "enable_http2", cfg.EnableHTTP2, true)
+
+	b.AppendBlock(block)
+}
+
+// keyValue is a single attribute to write to a block.
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+// tlsConfigFields returns the non-default settings of cfg in the order they
+// are declared in Flow components.
+func tlsConfigFields(cfg config_util.TLSConfig) []keyValue {
+	var res []keyValue
+	add := func(key string, value, def interface{}) {
+		if !reflect.DeepEqual(value, def) {
+			res = append(res, keyValue{key, value})
+		}
+	}
+
+	add("ca_file", cfg.CAFile, "")
+	add("cert_file", cfg.CertFile, "")
+	add("key_file", cfg.KeyFile, "")
+	add("server_name", cfg.ServerName, "")
+	add("insecure_skip_verify", cfg.InsecureSkipVerify, false)
+	if cfg.MinVersion != 0 {
+		for name, v := range config_util.TLSVersions {
+			if v == cfg.MinVersion {
+				add("min_version", name, "")
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,211 @@
+package converter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/pkg/metrics/instance"
+	"github.com/grafana/agent/pkg/river/token/builder"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+// appendInstance appends the components equivalent to a metrics instance.
+// Each scrape job is converted into a prometheus.scrape component, and all
+// remote_write settings are combined into a single prometheus.remote_write
+// component.
+func (c *converter) appendInstance(global instance.GlobalConfig, inst instance.Config) {
+	c.instance = inst.Name
+
+	rwLabel := c.labels.Label("prometheus.remote_write", inst.Name)
+	receiver := expr(fmt.Sprintf("prometheus.remote_write.%s.receiver", rwLabel))
+
+	for _, sc := range inst.ScrapeConfigs {
+		c.appendScrapeConfig(sc, receiver)
+	}
+	c.appendRemoteWrite(rwLabel, global, inst)
+
+	if inst.HostFilter || len(inst.HostFilterRelabelConfigs) > 0 {
+		c.diags.Add(SeverityError, "metrics instance %q: host_filter cannot be converted; add a discovery.relabel rule to filter targets instead", inst.Name)
+	}
+	if inst.RemoteFlushDeadline != instance.DefaultConfig.RemoteFlushDeadline {
+		c.diags.Add(SeverityWarn, "metrics instance %q: remote_flush_deadline has no Flow equivalent and was ignored", inst.Name)
+	}
+	if inst.WriteStaleOnShutdown {
+		c.diags.Add(SeverityWarn, "metrics instance %q: write_stale_on_shutdown has no Flow equivalent and was ignored", inst.Name)
+	}
+}
+
+// appendScrapeConfig appends the components for a single scrape job, which
+// sends its samples to receiver.
+func (c *converter) appendScrapeConfig(sc *config.ScrapeConfig, receiver expr) {
+	targets := c.appendDiscovery(sc.JobName, sc.ServiceDiscoveryConfigs)
+
+	if len(sc.RelabelConfigs) > 0 {
+		label := c.labels.Label("discovery.relabel", c.instance, sc.JobName)
+		body := c.appendBlock("discovery.relabel", label)
+		body.SetAttributeValue("targets", targets)
+		appendRelabelRules(body, sc.RelabelConfigs)
+
+		targets = expr(fmt.Sprintf("discovery.relabel.%s.output", label))
+	}
+
+	forwardTo := receiver
+	if len(sc.MetricRelabelConfigs) > 0 {
+		label := c.labels.Label("prometheus.relabel", c.instance, sc.JobName)
+		body := c.appendBlock("prometheus.relabel", label)
+		body.SetAttributeValue("forward_to", []expr{receiver})
+		appendRelabelRules(body, sc.MetricRelabelConfigs)
+
+		forwardTo = expr(fmt.Sprintf("prometheus.relabel.%s.receiver", label))
+	}
+
+	label := c.labels.Label("prometheus.scrape", c.instance, sc.JobName)
+	body := c.appendBlock("prometheus.scrape", label)
+	body.SetAttributeValue("targets", targets)
+	body.SetAttributeValue("forward_to", []expr{forwardTo})
+	body.SetAttributeValue("job_name", sc.JobName)
+	setAttr(body, "honor_labels", sc.HonorLabels, false)
+	setAttr(body, "honor_timestamps", sc.HonorTimestamps, true)
+	if len(sc.Params) > 0 {
+		body.SetAttributeValue("params", map[string][]string(sc.Params))
+	}
+	setDuration(body, "scrape_interval", time.Duration(sc.ScrapeInterval), time.Minute)
+	setDuration(body, "scrape_timeout", time.Duration(sc.ScrapeTimeout), 10*time.Second)
+	setAttr(body, "metrics_path", sc.MetricsPath, "/metrics")
+	setAttr(body, "scheme", sc.Scheme, "http")
+	if sc.BodySizeLimit > 0 {
+		body.SetAttributeValue("body_size_limit", sc.BodySizeLimit.String())
+	}
+	setAttr(body, "sample_limit", sc.SampleLimit, uint(0))
+	setAttr(body, "target_limit", sc.TargetLimit, uint(0))
+	setAttr(body, "label_limit", sc.LabelLimit, uint(0))
+	setAttr(body, "label_name_length_limit", sc.LabelNameLengthLimit, uint(0))
+	setAttr(body, "label_value_length_limit", sc.LabelValueLengthLimit, uint(0))
+	appendHTTPClientConfig(body, sc.HTTPClientConfig)
+}
+
+// appendRemoteWrite appends a prometheus.remote_write component for the
+// remote_write settings of inst. inst must have had its defaults applied so
+// the global remote_write settings are used when it doesn't have its own.
+func (c *converter) appendRemoteWrite(label string, global instance.GlobalConfig, inst instance.Config) {
+	body := c.appendBlock("prometheus.remote_write", label)
+
+	if len(global.Prometheus.ExternalLabels) > 0 {
+		body.SetAttributeValue("external_labels", global.Prometheus.ExternalLabels.Map())
+	}
+
+	if len(inst.RemoteWrite) == 0 {
+		c.diags.Add(SeverityWarn, "metrics instance %q has no remote_write settings; samples will only be written to the WAL", inst.Name)
+	}
+
+	for _, rw := range inst.RemoteWrite {
+		endpoint := builder.NewBlock([]string{"endpoint"}, "")
+		eb := endpoint.Body()
+
+		setAttr(eb, "name", rw.Name, "")
+		if rw.URL != nil && rw.URL.URL != nil {
+			eb.SetAttributeValue("url", rw.URL.String())
+		}
+		setDuration(eb, "remote_timeout", time.Duration(rw.RemoteTimeout), 30*time.Second)
+		if len(rw.Headers) > 0 {
+			eb.SetAttributeValue("headers", rw.Headers)
+		}
+		setAttr(eb, "send_exemplars", rw.SendExemplars, true)
+		appendHTTPClientConfig(eb, rw.HTTPClientConfig)
+		appendQueueConfig(eb, rw.QueueConfig)
+		appendMetadataConfig(eb, rw.MetadataConfig)
+
+		if len(rw.WriteRelabelConfigs) > 0 {
+			c.diags.Add(SeverityError, "metrics instance %q: write_relabel_configs for remote_write %s cannot be converted; prometheus.remote_write doesn't support them", inst.Name, remoteWriteName(rw))
+		}
+		if rw.SigV4Config != nil {
+			c.diags.Add(SeverityError, "metrics instance %q: sigv4 for remote_write %s cannot be converted; prometheus.remote_write doesn't support it", inst.Name, remoteWriteName(rw))
+		}
+
+		body.AppendBlock(endpoint)
+	}
+
+	appendWALConfig(body, inst)
+}
+
+// remoteWriteName returns a name to identify rw in diagnostics.
+func remoteWriteName(rw *config.RemoteWriteConfig) string {
+	if rw.Name != "" {
+		return fmt.Sprintf("%q", rw.Name)
+	}
+	if rw.URL != nil && rw.URL.URL != nil {
+		return fmt.Sprintf("to %s", rw.URL.Redacted())
+	}
+	return "(unnamed)"
+}
+
+func appendQueueConfig(b *builder.Body, qc config.QueueConfig) {
+	block := builder.NewBlock([]string{"queue_config"}, "")
+	body := block.Body()
+
+	setAttr(body, "capacity", qc.Capacity, 2500)
+	setAttr(body, "max_shards", qc.MaxShards, 200)
+	setAttr(body, "min_shards", qc.MinShards, 1)
+	setAttr(body, "max_samples_per_send", qc.MaxSamplesPerSend, 500)
+	setDuration(body, "batch_send_deadline", time.Duration(qc.BatchSendDeadline), 5*time.Second)
+	setDuration(body, "min_backoff", time.Duration(qc.MinBackoff), 30*time.Millisecond)
+	setDuration(body, "max_backoff", time.Duration(qc.MaxBackoff), 5*time.Second)
+	setAttr(body, "retry_on_http_429", qc.RetryOnRateLimit, false)
+
+	if len(body.Tokens()) > 0 {
+		b.AppendBlock(block)
+	}
+}
+
+func appendMetadataConfig(b *builder.Body, mc config.MetadataConfig) {
+	block := builder.NewBlock([]string{"metadata_config"}, "")
+	body := block.Body()
+
+	setAttr(body, "send", mc.Send, true)
+	setDuration(body, "send_interval", time.Duration(mc.SendInterval), time.Minute)
+	setAttr(body, "max_samples_per_send", mc.MaxSamplesPerSend, 500)
+
+	if len(body.Tokens()) > 0 {
+		b.AppendBlock(block)
+	}
+}
+
+func appendWALConfig(b *builder.Body, inst instance.Config) {
+	block := builder.NewBlock([]string{"wal"}, "")
+	body := block.Body()
+
+	setDuration(body, "truncate_frequency", inst.WALTruncateFrequency, 2*time.Hour)
+	setDuration(body, "min_keepalive_time", inst.MinWALTime, 5*time.Minute)
+	setDuration(body, "max_keepalive_time", inst.MaxWALTime, 8*time.Hour)
+
+	if len(body.Tokens()) > 0 {
+		b.AppendBlock(block)
+	}
+}
+
+// appendRelabelRules appends a rule block to b for each relabel config.
+func appendRelabelRules(b *builder.Body, rcs []*relabel.Config) {
+	for _, rc := range rcs {
+		rule := builder.NewBlock([]string{"rule"}, "")
+		body := rule.Body()
+
+		if len(rc.SourceLabels) > 0 {
+			sourceLabels := make([]string, 0, len(rc.SourceLabels))
+			for _, l := range rc.SourceLabels {
+				sourceLabels = append(sourceLabels, string(l))
+			}
+			body.SetAttributeValue("source_labels", sourceLabels)
+		}
+		setAttr(body, "separator", rc.Separator, relabel.DefaultRelabelConfig.Separator)
+		if rc.Regex.Regexp != nil {
+			setAttr(body, "regex", rc.Regex.String(), relabel.DefaultRelabelConfig.Regex.String())
+		}
+		setAttr(body, "modulus", rc.Modulus, uint64(0))
+		setAttr(body, "target_label", rc.TargetLabel, "")
+		setAttr(body, "replacement", rc.Replacement, relabel.DefaultRelabelConfig.Replacement)
+		setAttr(body, "action", string(rc.Action), string(relabel.DefaultRelabelConfig.Action))
+
+		b.AppendBlock(rule)
+	}
+}
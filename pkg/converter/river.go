@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/grafana/agent/pkg/river/scanner"
+	"github.com/grafana/agent/pkg/river/token"
+	"github.com/grafana/agent/pkg/river/token/builder"
+)
+
+// expr is a raw River expression, such as a reference to the exports of
+// another component, which is written to the output as-is.
+type expr string
+
+// RiverTokenize implements builder.Tokenizer.
+func (e expr) RiverTokenize() []builder.Token {
+	return []builder.Token{{Tok: token.LITERAL, Lit: string(e)}}
+}
+
+// labeler generates unique component labels.
+type labeler map[string]map[string]struct{}
+
+// Label returns a valid label for a component named name, built from parts.
+// If the label is already in use by another component with the same name, a
+// numeric suffix is appended to it.
+func (l labeler) Label(name string, parts ...string) string {
+	base := sanitizeLabel(strings.Join(parts, "_"))
+
+	used, ok := l[name]
+	if !ok {
+		used = make(map[string]struct{})
+		l[name] = used
+	}
+
+	label := base
+	for i := 2; ; i++ {
+		if _, exists := used[label]; !exists {
+			break
+		}
+		label = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[label] = struct{}{}
+	return label
+}
+
+// sanitizeLabel converts s into a valid River identifier.
+func sanitizeLabel(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+
+	res := sb.String()
+	switch {
+	case res == "":
+		return "default"
+	case res[0] >= '0' && res[0] <= '9':
+		return "_" + res
+	default:
+		return res
+	}
+}
+
+// objectKey returns key formatted as a River object key, quoting it if it
+// isn't a valid identifier.
+func objectKey(key string) string {
+	s := scanner.New(nil, []byte(key), nil, 0)
+	if _, tok, lit := s.Scan(); tok == token.IDENT && lit == key {
+		return key
+	}
+	return fmt.Sprintf("%q", key)
+}
+
+// setAttr sets the attribute name to value in b if value is different from
+// the default of the Flow component being written.
+func setAttr(b *builder.Body, name string, value, def interface{}) {
+	if reflect.DeepEqual(value, def) {
+		return
+	}
+	b.SetAttributeValue(name, value)
+}
+
+// setDuration sets the attribute name to d in b if d is different from def.
+func setDuration(b *builder.Body, name string, d, def time.Duration) {
+	if d == def {
+		return
+	}
+	b.SetAttributeValue(name, formatDuration(d))
+}
+
+// formatDuration formats d as a string which can be decoded by River,
+// removing trailing zero units (e.g., "1h" instead of "1h0m0s").
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/cluster"
 )
 
 // A Controller is a testing controller which controls a single component.
@@ -135,6 +136,7 @@ func (c *Controller) buildComponent(dataPath string, args component.Arguments) (
 		DataPath:      dataPath,
 		OnStateChange: c.onStateChange,
 		Registerer:    prometheus.NewRegistry(),
+		Clusterer:     cluster.NewLocalNode("localhost:12346"),
 	}
 
 	inner, err := c.reg.Build(opts, args)
@@ -1,9 +1,12 @@
 package flow
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/grafana/agent/pkg/flow/internal/controller"
 	"github.com/grafana/agent/pkg/flow/logging"
 	"github.com/grafana/agent/pkg/river/ast"
 	"github.com/grafana/agent/pkg/river/diag"
@@ -16,11 +19,19 @@ type File struct {
 	Name string    // File name given to ReadFile.
 	Node *ast.File // Raw File node.
 
+	// Source holds the contents given to ReadFile. See ReadFiles for the
+	// contents of merged files.
+	Source []byte
+
 	Logging logging.Options
 
 	// Components holds the list of raw River AST blocks describing components.
 	// The Flow controller can interpret them.
 	Components []*ast.BlockStmt
+
+	// ConfigBlocks holds the list of raw River AST blocks describing the
+	// arguments and exports of a module.
+	ConfigBlocks []*ast.BlockStmt
 }
 
 // ReadFile parses the River file specified by bb into a File. name should be
@@ -30,15 +41,61 @@ func ReadFile(name string, bb []byte) (*File, error) {
 	if err != nil {
 		return nil, err
 	}
+	return buildFile(name, node, bb)
+}
+
+// ReadFiles parses the River files specified by files, keyed by file name,
+// and merges them into a single File with the given name. Errors are
+// reported using the name of the file they were found in.
+//
+// The Source of the resulting File holds the contents of each file in name
+// order, each preceded by a comment with the name of the file. A single file
+// is read the same way as ReadFile.
+func ReadFiles(name string, files map[string][]byte) (*File, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files to read")
+	}
+
+	names := make([]string, 0, len(files))
+	for fn := range files {
+		names = append(names, fn)
+	}
+	sort.Strings(names)
+
+	if len(names) == 1 {
+		return ReadFile(names[0], files[names[0]])
+	}
+
+	var (
+		merged = &ast.File{Name: name}
+		source bytes.Buffer
+	)
+	for _, fn := range names {
+		node, err := parser.ParseFile(fn, files[fn])
+		if err != nil {
+			return nil, err
+		}
+		merged.Body = append(merged.Body, node.Body...)
+		merged.Comments = append(merged.Comments, node.Comments...)
+
+		fmt.Fprintf(&source, "// %s\n", fn)
+		source.Write(files[fn])
+		source.WriteString("\n")
+	}
+	return buildFile(name, merged, source.Bytes())
+}
 
+// buildFile builds a File from a parsed River file.
+func buildFile(name string, node *ast.File, source []byte) (*File, error) {
 	// Look for predefined non-components blocks (i.e., logging), and store
 	// everything else into a list of components.
 	//
 	// TODO(rfratto): should this code be brought into a helper somewhere? Maybe
 	// in ast?
 	var (
-		loggerBlock *ast.BlockStmt
-		components  []*ast.BlockStmt
+		loggerBlock  *ast.BlockStmt
+		components   []*ast.BlockStmt
+		configBlocks []*ast.BlockStmt
 	)
 
 	for _, stmt := range node.Body {
@@ -53,9 +110,19 @@ func ReadFile(name string, bb []byte) (*File, error) {
 
 		case *ast.BlockStmt:
 			fullName := strings.Join(stmt.Name, ".")
-			switch fullName {
-			case "logging":
+			switch {
+			case fullName == "logging":
+				if loggerBlock != nil {
+					return nil, diag.Diagnostic{
+						Severity: diag.SeverityLevelError,
+						StartPos: ast.StartPos(stmt).Position(),
+						EndPos:   stmt.NamePos.Add(len(fullName) - 1).Position(),
+						Message:  fmt.Sprintf("logging block already declared at %s", ast.StartPos(loggerBlock).Position()),
+					}
+				}
 				loggerBlock = stmt
+			case controller.IsConfigBlock(fullName):
+				configBlocks = append(configBlocks, stmt)
 			default:
 				components = append(components, stmt)
 			}
@@ -78,9 +145,11 @@ func ReadFile(name string, bb []byte) (*File, error) {
 	}
 
 	return &File{
-		Name:       name,
-		Node:       node,
-		Logging:    loggingOpts,
-		Components: components,
+		Name:         name,
+		Node:         node,
+		Source:       source,
+		Logging:      loggingOpts,
+		Components:   components,
+		ConfigBlocks: configBlocks,
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/grafana/agent/pkg/flow"
+	"github.com/grafana/agent/pkg/flow/logging"
 	"github.com/grafana/agent/pkg/river/ast"
 	"github.com/stretchr/testify/require"
 
@@ -31,6 +32,34 @@ func TestReadFile(t *testing.T) {
 	require.Equal(t, "testcomponents.passthrough.static", getBlockID(f.Components[1]))
 }
 
+func TestReadFile_ConfigBlocks(t *testing.T) {
+	content := `
+		argument "input" {
+			optional = true
+			default  = "hello"
+		}
+
+		testcomponents.passthrough "static" {
+			input = argument.input.value
+		}
+
+		export "output" {
+			value = testcomponents.passthrough.static.output
+		}
+	`
+
+	f, err := flow.ReadFile(t.Name(), []byte(content))
+	require.NoError(t, err)
+	require.NotNil(t, f)
+
+	require.Len(t, f.Components, 1)
+	require.Equal(t, "testcomponents.passthrough.static", getBlockID(f.Components[0]))
+
+	require.Len(t, f.ConfigBlocks, 2)
+	require.Equal(t, "argument.input", getBlockID(f.ConfigBlocks[0]))
+	require.Equal(t, "export.output", getBlockID(f.ConfigBlocks[1]))
+}
+
 func TestReadFile_Defaults(t *testing.T) {
 	f, err := flow.ReadFile(t.Name(), []byte(``))
 	require.NotNil(t, f)
@@ -39,6 +68,39 @@ func TestReadFile_Defaults(t *testing.T) {
 	require.Len(t, f.Components, 0)
 }
 
+func TestReadFiles(t *testing.T) {
+	f, err := flow.ReadFiles("dir", map[string][]byte{
+		"b.river": []byte(`testcomponents.passthrough "static" { input = "b" }`),
+		"a.river": []byte(`
+			logging {
+				level = "debug"
+			}
+
+			testcomponents.tick "ticker_a" {
+				frequency = "1s"
+			}
+		`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "dir", f.Name)
+	require.Equal(t, logging.LevelDebug, f.Logging.Level)
+
+	// Blocks are merged in file name order, and positions refer to the file
+	// the block was declared in.
+	require.Len(t, f.Components, 2)
+	require.Equal(t, "testcomponents.tick.ticker_a", getBlockID(f.Components[0]))
+	require.Equal(t, "testcomponents.passthrough.static", getBlockID(f.Components[1]))
+	require.Equal(t, "b.river", ast.StartPos(f.Components[1]).Position().Filename)
+}
+
+func TestReadFiles_DuplicateLogging(t *testing.T) {
+	_, err := flow.ReadFiles("dir", map[string][]byte{
+		"a.river": []byte(`logging {}`),
+		"b.river": []byte(`logging {}`),
+	})
+	require.ErrorContains(t, err, "logging block already declared at a.river:1:1")
+}
+
 func getBlockID(b *ast.BlockStmt) string {
 	var parts []string
 	parts = append(parts, b.Name...)
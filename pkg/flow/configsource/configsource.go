@@ -0,0 +1,43 @@
+// Package configsource retrieves Flow config files from local files,
+// directories of files, HTTP(S) servers, and S3.
+package configsource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/config"
+)
+
+// Source retrieves the files which make up a Flow config.
+type Source interface {
+	// Name returns the path or URL of the source.
+	Name() string
+
+	// Read retrieves the files of the config, keyed by file name.
+	Read(ctx context.Context) (map[string][]byte, error)
+}
+
+// Options configures sources created by New.
+type Options struct {
+	// HTTPClientConfig configures the client used for http:// and https://
+	// sources.
+	HTTPClientConfig config.HTTPClientConfig
+}
+
+// New creates a Source for path. path may be an http:// or https:// URL, an
+// s3:// URL in the form s3://bucket/key, or a path to a local file or
+// directory. Directories are read as the set of .river files they contain.
+func New(path string, opts Options) (Source, error) {
+	switch {
+	case path == "":
+		return nil, fmt.Errorf("config path must not be empty")
+	case strings.HasPrefix(path, "http://"), strings.HasPrefix(path, "https://"):
+		return newHTTPSource(path, opts.HTTPClientConfig)
+	case strings.HasPrefix(path, "s3://"):
+		return newS3Source(path)
+	default:
+		return fileSource{path: path}, nil
+	}
+}
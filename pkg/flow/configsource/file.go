@@ -0,0 +1,58 @@
+package configsource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileExtension is the extension of files read from directories.
+const fileExtension = ".river"
+
+// fileSource reads a config from a local file, or from the .river files in a
+// local directory. Subdirectories are not read.
+type fileSource struct {
+	path string
+}
+
+// Name implements Source.
+func (s fileSource) Name() string { return s.path }
+
+// Read implements Source.
+func (s fileSource) Read(_ context.Context) (map[string][]byte, error) {
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		bb, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{s.path: bb}, nil
+	}
+
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	for _, ent := range entries {
+		if ent.IsDir() || filepath.Ext(ent.Name()) != fileExtension {
+			continue
+		}
+
+		name := filepath.Join(s.path, ent.Name())
+		bb, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = bb
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s files found in directory %q", fileExtension, s.path)
+	}
+	return files, nil
+}
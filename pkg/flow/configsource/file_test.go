@@ -0,0 +1,43 @@
+package configsource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSource_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.river"), `a`)
+	writeFile(t, filepath.Join(dir, "b.river"), `b`)
+	writeFile(t, filepath.Join(dir, "README.md"), `ignored`)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.river"), 0755))
+
+	src, err := New(dir, Options{})
+	require.NoError(t, err)
+
+	files, err := src.Read(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{
+		filepath.Join(dir, "a.river"): []byte("a"),
+		filepath.Join(dir, "b.river"): []byte("b"),
+	}, files)
+}
+
+func TestFileSource_EmptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	src, err := New(dir, Options{})
+	require.NoError(t, err)
+
+	_, err = src.Read(context.Background())
+	require.ErrorContains(t, err, "no .river files found")
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(name, []byte(content), 0644))
+}
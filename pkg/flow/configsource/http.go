@@ -0,0 +1,72 @@
+package configsource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/common/config"
+)
+
+// httpSource reads a config from an HTTP(S) server. The ETag of the last
+// response is sent with the next request, so servers may respond with 304 Not
+// Modified when the config didn't change.
+type httpSource struct {
+	url    string
+	client *http.Client
+
+	mut     sync.Mutex
+	etag    string
+	content []byte
+}
+
+func newHTTPSource(url string, cfg config.HTTPClientConfig) (*httpSource, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	client, err := config.NewClientFromConfig(cfg, "flow-config")
+	if err != nil {
+		return nil, err
+	}
+	return &httpSource{url: url, client: client}, nil
+}
+
+// Name implements Source.
+func (s *httpSource) Name() string { return s.url }
+
+// Read implements Source.
+func (s *httpSource) Read(ctx context.Context) (map[string][]byte, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && s.content != nil:
+		return map[string][]byte{s.url: s.content}, nil
+	case resp.StatusCode/100 != 2:
+		return nil, fmt.Errorf("error fetching config: status code: %d", resp.StatusCode)
+	}
+
+	bb, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.etag = resp.Header.Get("ETag")
+	s.content = bb
+	return map[string][]byte{s.url: bb}, nil
+}
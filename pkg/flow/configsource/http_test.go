@@ -0,0 +1,49 @@
+package configsource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSource_ETag(t *testing.T) {
+	var requests, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer srv.Close()
+
+	src, err := New(srv.URL, Options{})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		files, err := src.Read(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, map[string][]byte{srv.URL: []byte("content")}, files)
+	}
+	require.Equal(t, 2, requests)
+	require.Equal(t, 1, notModified)
+}
+
+func TestHTTPSource_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	src, err := New(srv.URL, Options{})
+	require.NoError(t, err)
+
+	_, err = src.Read(context.Background())
+	require.ErrorContains(t, err, "status code: 500")
+}
@@ -0,0 +1,36 @@
+package configsource
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	remote_s3 "github.com/grafana/agent/component/remote/s3"
+)
+
+// s3Source reads a config from a file in S3. Credentials and the region are
+// read from the default AWS configuration sources, such as environment
+// variables.
+type s3Source struct {
+	path   string
+	client *s3.Client
+}
+
+func newS3Source(path string) (*s3Source, error) {
+	client, err := remote_s3.NewClient(remote_s3.ClientOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &s3Source{path: path, client: client}, nil
+}
+
+// Name implements Source.
+func (s *s3Source) Name() string { return s.path }
+
+// Read implements Source.
+func (s *s3Source) Read(ctx context.Context) (map[string][]byte, error) {
+	bb, err := remote_s3.GetObject(ctx, s.client, s.path)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{s.path: bb}, nil
+}
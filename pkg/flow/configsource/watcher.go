@@ -0,0 +1,168 @@
+package configsource
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/pkg/flow"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Watcher reads the config from a Source and loads it. Watcher polls the
+// Source while running and only loads the config again when it changes.
+//
+// Configs which can't be fetched or parsed are never loaded, so the
+// previously loaded config keeps running. A config which fails to load isn't
+// retried until it changes or Reload is called.
+type Watcher struct {
+	log  log.Logger
+	src  Source
+	load func(f *flow.File, force bool) error
+
+	mut       sync.Mutex
+	lastHash  [sha256.Size]byte
+	lastFiles map[string][]byte
+
+	fetchFailures   prometheus.Counter
+	lastFetchOK     prometheus.Gauge
+	lastFetchTime   prometheus.Gauge
+	lastLoadSuccess prometheus.Gauge
+}
+
+// NewWatcher creates a new Watcher which reads the config from src and
+// passes it to load. force is true when the config is loaded by Reload rather
+// than because it changed.
+func NewWatcher(l log.Logger, reg prometheus.Registerer, src Source, load func(f *flow.File, force bool) error) *Watcher {
+	w := &Watcher{
+		log:  l,
+		src:  src,
+		load: load,
+
+		fetchFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "agent_flow_config_fetch_failures_total",
+			Help: "Total number of times the config couldn't be fetched from its source.",
+		}),
+		lastFetchOK: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "agent_flow_config_last_fetch_successful",
+			Help: "Whether the last fetch of the config from its source succeeded.",
+		}),
+		lastFetchTime: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "agent_flow_config_last_fetch_success_timestamp_seconds",
+			Help: "Timestamp of the last successful fetch of the config from its source.",
+		}),
+		lastLoadSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "agent_flow_config_last_load_successful",
+			Help: "Whether the last fetched config was parsed and loaded successfully.",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(w.fetchFailures, w.lastFetchOK, w.lastFetchTime, w.lastLoadSuccess)
+	}
+	return w
+}
+
+// Reload reads the config from the source and loads it, even if it didn't
+// change since the last load.
+func (w *Watcher) Reload(ctx context.Context) error {
+	_, err := w.reload(ctx, true)
+	return err
+}
+
+// Files returns the most recently fetched config files, keyed by file name.
+// Files can be used to print diagnostics for errors returned by Reload.
+func (w *Watcher) Files() map[string][]byte {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return w.lastFiles
+}
+
+// Run polls the source every interval until ctx is canceled, loading the
+// config whenever it changes.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			loaded, err := w.reload(ctx, false)
+			switch {
+			case err != nil:
+				level.Error(w.log).Log("msg", "failed to reload config", "source", w.src.Name(), "err", err)
+			case loaded:
+				level.Info(w.log).Log("msg", "config changed and was reloaded", "source", w.src.Name())
+			}
+		}
+	}
+}
+
+// reload fetches the config and loads it if force is true or the config
+// changed since the last attempt to load it. reload reports whether loading the
+// config was attempted.
+func (w *Watcher) reload(ctx context.Context, force bool) (loaded bool, err error) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	files, err := w.src.Read(ctx)
+	if err != nil {
+		w.fetchFailures.Inc()
+		w.lastFetchOK.Set(0)
+		return false, fmt.Errorf("reading config from %q: %w", w.src.Name(), err)
+	}
+	w.lastFetchOK.Set(1)
+	w.lastFetchTime.SetToCurrentTime()
+
+	hash := hashFiles(files)
+	if !force && hash == w.lastHash {
+		return false, nil
+	}
+	// The hash is recorded before loading so that a config which fails to
+	// load isn't retried until it changes or a reload is forced.
+	w.lastHash = hash
+	w.lastFiles = files
+
+	defer func() {
+		if err != nil {
+			w.lastLoadSuccess.Set(0)
+		} else {
+			w.lastLoadSuccess.Set(1)
+		}
+	}()
+
+	f, err := flow.ReadFiles(w.src.Name(), files)
+	if err != nil {
+		return false, err
+	}
+	if err := w.load(f, force); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// hashFiles returns a hash of the names and contents of files.
+func hashFiles(files map[string][]byte) [sha256.Size]byte {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(files[name]))
+		h.Write(files[name])
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
@@ -0,0 +1,117 @@
+package configsource
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/agent/pkg/flow"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.river"), `testcomponents.passthrough "a" { input = "a" }`)
+	writeFile(t, filepath.Join(dir, "b.river"), `testcomponents.passthrough "b" { input = "b" }`)
+
+	src, err := New(dir, Options{})
+	require.NoError(t, err)
+
+	var (
+		loaded []*flow.File
+		forced []bool
+	)
+	w := NewWatcher(log.NewNopLogger(), prometheus.NewRegistry(), src, func(f *flow.File, force bool) error {
+		loaded = append(loaded, f)
+		forced = append(forced, force)
+		return nil
+	})
+
+	// Files in a directory are merged into a single file.
+	require.NoError(t, w.Reload(context.Background()))
+	require.Len(t, loaded, 1)
+	require.Len(t, loaded[0].Components, 2)
+
+	// The config shouldn't be loaded again if it didn't change.
+	changed, err := w.reload(context.Background(), false)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Len(t, loaded, 1)
+
+	writeFile(t, filepath.Join(dir, "b.river"), `testcomponents.passthrough "b" { input = "changed" }`)
+	changed, err = w.reload(context.Background(), false)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Len(t, loaded, 2)
+
+	// Only Reload forces the config to be loaded.
+	require.Equal(t, []bool{true, false}, forced)
+
+	// Configs which can't be parsed are never loaded.
+	writeFile(t, filepath.Join(dir, "b.river"), `testcomponents.passthrough "b" {`)
+	_, err = w.reload(context.Background(), false)
+	require.Error(t, err)
+	require.Len(t, loaded, 2)
+	require.Equal(t, 0.0, testutil.ToFloat64(w.lastLoadSuccess))
+	require.Equal(t, 1.0, testutil.ToFloat64(w.lastFetchOK))
+}
+
+func TestWatcher_LoadFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.river"), `testcomponents.passthrough "a" { input = "a" }`)
+
+	src, err := New(dir, Options{})
+	require.NoError(t, err)
+
+	var (
+		loadErr = errors.New("transient failure")
+		loads   int
+	)
+	w := NewWatcher(log.NewNopLogger(), prometheus.NewRegistry(), src, func(f *flow.File, force bool) error {
+		loads++
+		return loadErr
+	})
+
+	loaded, err := w.reload(context.Background(), false)
+	require.ErrorIs(t, err, loadErr)
+	require.True(t, loaded)
+	require.Equal(t, 0.0, testutil.ToFloat64(w.lastLoadSuccess))
+
+	// The unchanged config isn't retried on the next poll, even though it
+	// failed to load.
+	loadErr = nil
+	loaded, err = w.reload(context.Background(), false)
+	require.NoError(t, err)
+	require.False(t, loaded)
+	require.Equal(t, 1, loads)
+	require.Equal(t, 0.0, testutil.ToFloat64(w.lastLoadSuccess))
+
+	// An explicit reload retries it.
+	require.NoError(t, w.Reload(context.Background()))
+	require.Equal(t, 2, loads)
+	require.Equal(t, 1.0, testutil.ToFloat64(w.lastLoadSuccess))
+
+	// Changing the config loads it again.
+	writeFile(t, filepath.Join(dir, "a.river"), `testcomponents.passthrough "a" { input = "b" }`)
+	loaded, err = w.reload(context.Background(), false)
+	require.NoError(t, err)
+	require.True(t, loaded)
+	require.Equal(t, 3, loads)
+}
+
+func TestWatcher_FetchFailure(t *testing.T) {
+	src, err := New(filepath.Join(t.TempDir(), "missing.river"), Options{})
+	require.NoError(t, err)
+
+	w := NewWatcher(log.NewNopLogger(), prometheus.NewRegistry(), src, func(f *flow.File, force bool) error {
+		require.FailNow(t, "config shouldn't be loaded")
+		return nil
+	})
+	require.Error(t, w.Reload(context.Background()))
+	require.Equal(t, 1.0, testutil.ToFloat64(w.fetchFailures))
+	require.Equal(t, 0.0, testutil.ToFloat64(w.lastFetchOK))
+}
@@ -43,6 +43,17 @@
 // state if a component shuts down or is given an invalid config. This prevents
 // a domino effect of a single failed component taking down other components
 // which are otherwise healthy.
+//
+// # Modules
+//
+// Components may run nested Flow controllers, called modules, through the
+// ModuleController provided in their options. A module is a Flow file which
+// may declare argument blocks (values passed in by the component) and export
+// blocks (values exposed back to the component).
+//
+// Each module gets its own Loader and DAG. Components within a module are
+// identified by their ID scoped to the module, such as
+// "module.string.example/prometheus.scrape.default".
 package flow
 
 import (
@@ -50,14 +61,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/cluster"
 	"github.com/grafana/agent/pkg/flow/internal/controller"
 	"github.com/grafana/agent/pkg/flow/internal/dag"
 	"github.com/grafana/agent/pkg/flow/logging"
+	"github.com/grafana/agent/pkg/flow/tracing"
+	"github.com/grafana/agent/pkg/river/diag"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Options holds static options for a flow controller.
@@ -77,23 +95,46 @@ type Options struct {
 	// The controller does not itself listen here, but some components
 	// need to know this to set the correct targets.
 	HTTPListenAddr string
+
+	// Clusterer is the cluster node shared with components. A single-node
+	// cluster will be used if this is nil.
+	Clusterer cluster.Node
+
+	// TracerProvider is used to trace graph evaluations, component updates and
+	// scheduling. A no-op provider will be used if this is nil.
+	TracerProvider trace.TracerProvider
+
+	// controllerID is the global ID of the module which owns the controller.
+	// Empty for the root controller.
+	controllerID string
+
+	// onExportsChange is invoked when the exports of a module change. Only set
+	// for module controllers.
+	onExportsChange func(exports map[string]any)
 }
 
 // Flow is the Flow system.
 type Flow struct {
-	log  *logging.Logger
-	opts Options
+	log    *logging.Logger
+	tracer trace.Tracer
+	opts   Options
 
 	updateQueue *controller.Queue
 	sched       *controller.Scheduler
 	loader      *controller.Loader
 
-	cancel       context.CancelFunc
-	exited       chan struct{}
-	loadFinished chan struct{}
+	cancel           context.CancelFunc
+	exited           chan struct{}
+	loadFinished     chan struct{}
+	runnablesChanged chan struct{}
 
 	loadMut    sync.RWMutex
 	loadedOnce bool
+	reloads    []*reloadRecord // Bounded history of calls to LoadFile, oldest first
+	lastGood   *reloadRecord   // Most recent file which loaded successfully
+
+	modulesMut sync.RWMutex
+	modules    map[string]*module // Running modules created by components, by global ID
 }
 
 // New creates and starts a new Flow controller. Call Close to stop
@@ -115,34 +156,56 @@ func newFlow(o Options) (*Flow, context.Context) {
 			panic(err)
 		}
 	}
+	if o.Clusterer == nil {
+		o.Clusterer = cluster.NewLocalNode(o.HTTPListenAddr)
+	}
+	if o.TracerProvider == nil {
+		o.TracerProvider = trace.NewNoopTracerProvider()
+	}
 
-	var (
-		queue  = controller.NewQueue()
-		sched  = controller.NewScheduler()
-		loader = controller.NewLoader(controller.ComponentGlobals{
-			Logger:   log,
-			DataPath: o.DataPath,
-			OnExportsChange: func(cn *controller.ComponentNode) {
-				// Changed components should be queued for reevaluation.
-				queue.Enqueue(cn)
-			},
-			Registerer:     o.Reg,
-			HTTPListenAddr: o.HTTPListenAddr,
-		})
-	)
+	f := &Flow{
+		log:    log,
+		tracer: o.TracerProvider.Tracer(tracing.InstrumentationName),
+		opts:   o,
+
+		updateQueue: controller.NewQueue(),
+		sched:       controller.NewScheduler(),
 
-	return &Flow{
-		log:  log,
-		opts: o,
+		cancel:           cancel,
+		exited:           make(chan struct{}, 1),
+		loadFinished:     make(chan struct{}, 1),
+		runnablesChanged: make(chan struct{}, 1),
+
+		modules: make(map[string]*module),
+	}
 
-		updateQueue: queue,
-		sched:       sched,
-		loader:      loader,
+	f.loader = controller.NewLoader(controller.ComponentGlobals{
+		Logger:   log,
+		DataPath: o.DataPath,
+		OnExportsChange: func(cn *controller.ComponentNode) {
+			// Changed components should be queued for reevaluation.
+			f.updateQueue.Enqueue(cn)
+		},
+		Registerer:     o.Reg,
+		HTTPListenAddr: o.HTTPListenAddr,
+		Clusterer:      o.Clusterer,
+		TracerProvider: o.TracerProvider,
+
+		ControllerID:          o.controllerID,
+		OnModuleExportsChange: o.onExportsChange,
+		NewModuleController: func(id string, reg prometheus.Registerer) component.ModuleController {
+			return newModuleController(f, id, reg)
+		},
+		OnRunnablesChange: func() {
+			select {
+			case f.runnablesChanged <- struct{}{}:
+			default:
+				// A refresh is already scheduled
+			}
+		},
+	})
 
-		cancel:       cancel,
-		exited:       make(chan struct{}, 1),
-		loadFinished: make(chan struct{}, 1),
-	}, ctx
+	return f, ctx
 }
 
 func (c *Flow) run(ctx context.Context) {
@@ -162,51 +225,124 @@ func (c *Flow) run(ctx context.Context) {
 			// If we only pop a single element, other components may sit waiting for
 			// evaluation forever.
 			for {
-				updated := c.updateQueue.TryDequeue()
+				updated, enqueuedAt := c.updateQueue.TryDequeue()
 				if updated == nil {
 					break
 				}
 
 				level.Debug(c.log).Log("msg", "handling component with updated state", "node_id", updated.NodeID())
-				c.loader.EvaluateDependencies(nil, updated)
+				c.handleUpdate(ctx, updated, enqueuedAt)
 			}
 
 		case <-c.loadFinished:
 			level.Info(c.log).Log("msg", "scheduling loaded components")
-
-			components := c.loader.Components()
-			runnables := make([]controller.RunnableNode, 0, len(components))
-			for _, uc := range components {
-				runnables = append(runnables, uc)
-			}
-			err := c.sched.Synchronize(runnables)
-			if err != nil {
-				level.Error(c.log).Log("msg", "failed to load components", "err", err)
+			c.schedule()
+
+		case <-c.runnablesChanged:
+			// Components may be enabled, disabled, or change their set of for_each
+			// instances at any time, but nothing may run until a load succeeded.
+			c.loadMut.RLock()
+			loaded := c.loadedOnce
+			c.loadMut.RUnlock()
+
+			if loaded {
+				level.Debug(c.log).Log("msg", "rescheduling components")
+				c.schedule()
 			}
 		}
 	}
 }
 
+// handleUpdate re-evaluates the dependants of a component which updated its
+// exports and was queued at enqueuedAt.
+func (c *Flow) handleUpdate(ctx context.Context, updated *controller.ComponentNode, enqueuedAt time.Time) {
+	ctx, span := c.tracer.Start(ctx, tracing.SpanComponentUpdate,
+		trace.WithTimestamp(enqueuedAt),
+		trace.WithAttributes(tracing.KeyNodeID.String(updated.GlobalID())),
+	)
+	defer span.End()
+
+	_, wait := c.tracer.Start(ctx, tracing.SpanQueueWait, trace.WithTimestamp(enqueuedAt))
+	wait.End()
+
+	c.loader.EvaluateDependencies(ctx, nil, updated)
+}
+
+// schedule synchronizes the scheduler with the set of nodes which should be
+// running.
+func (c *Flow) schedule() {
+	runnables := c.loader.Runnables()
+
+	ctx, span := c.tracer.Start(context.Background(), tracing.SpanSynchronize, trace.WithAttributes(
+		tracing.KeyControllerID.String(c.opts.controllerID),
+		tracing.KeyRunnables.Int(len(runnables)),
+	))
+	defer span.End()
+
+	err := c.sched.Synchronize(ctx, runnables)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		level.Error(c.log).Log("msg", "failed to load components", "err", err)
+	}
+}
+
 // LoadFile synchronizes the state of the controller with the current config
-// file. Components in the graph will be marked as unhealthy if there was an
-// error encountered during Load.
+// file. Only components whose block or dependencies changed since the last
+// load are re-evaluated.
+//
+// args holds values for the argument blocks declared in file, and may be nil
+// if file doesn't declare any arguments.
 //
 // The controller will only start running components after Load is called once
-// without any configuration errors.
-func (c *Flow) LoadFile(file *File) error {
+// without any configuration errors. file is validated before it's applied, so
+// a file with configuration errors doesn't change the running components. If
+// components fail to build or update while applying file, the last file which
+// loaded successfully is applied again. In both cases the error is returned.
+//
+// Every call to LoadFile is recorded in the reload history. See
+// ReloadHistory.
+func (c *Flow) LoadFile(file *File, args map[string]any) error {
 	c.loadMut.Lock()
 	defer c.loadMut.Unlock()
+	return c.loadFile(file, args, controller.ApplyOptions{})
+}
 
-	err := c.log.Update(file.Logging)
-	if err != nil {
-		return fmt.Errorf("error updating logger: %w", err)
+// ReloadFile is like LoadFile, but evaluates every component even if its
+// block didn't change since the last load. ReloadFile should be used for
+// explicit reloads so that expressions such as calls to env pick up changes
+// made outside of the config file.
+func (c *Flow) ReloadFile(file *File, args map[string]any) error {
+	c.loadMut.Lock()
+	defer c.loadMut.Unlock()
+	return c.loadFile(file, args, controller.ApplyOptions{EvaluateAll: true})
+}
+
+// loadFile implements LoadFile. loadMut must be held when calling loadFile.
+func (c *Flow) loadFile(file *File, args map[string]any, opts controller.ApplyOptions) error {
+	if diags := c.loader.Validate(args, file.Components, file.ConfigBlocks); diags.HasErrors() {
+		c.recordReload(file, args, diags)
+		return diags
 	}
 
-	diags := c.loader.Apply(nil, file.Components)
-	if !c.loadedOnce && diags.HasErrors() {
+	diags, err := c.applyFile(file, args, opts)
+	applyFailed := err == nil && diags.HasErrors()
+	if applyFailed {
+		err = diags
+	}
+	rec := c.recordReload(file, args, err)
+
+	switch {
+	case err == nil:
+		c.lastGood = rec
+	case !c.loadedOnce:
 		// The first call to Load should not run any components if there were
 		// errors in the configuration file.
-		return diags
+		return err
+	case applyFailed:
+		c.restoreLastGood()
+	default:
+		// The loader wasn't changed, so there's nothing to restore or schedule.
+		return err
 	}
 	c.loadedOnce = true
 
@@ -215,24 +351,81 @@ func (c *Flow) LoadFile(file *File) error {
 	default:
 		// A refresh is already scheduled
 	}
+	if err != nil {
+		return err
+	}
 	return diags.ErrorOrNil()
 }
 
-// ComponentInfos returns the component infos.
+// applyFile applies file to the loader. The returned error is non-nil if the
+// logger couldn't be updated, in which case the loader isn't changed.
+func (c *Flow) applyFile(file *File, args map[string]any, opts controller.ApplyOptions) (diag.Diagnostics, error) {
+	// Modules share the logger of the root controller, so only the root
+	// controller may update it.
+	if c.opts.controllerID == "" {
+		err := c.log.Update(file.Logging)
+		if err != nil {
+			return nil, fmt.Errorf("error updating logger: %w", err)
+		}
+	}
+
+	return c.loader.Apply(args, file.Components, file.ConfigBlocks, opts), nil
+}
+
+// ComponentInfos returns the component infos. Components running inside of
+// modules are included, identified by their module-scoped IDs.
 func (c *Flow) ComponentInfos() []*ComponentInfo {
 	c.loadMut.RLock()
-	defer c.loadMut.RUnlock()
-
 	cns := c.loader.Components()
 	infos := make([]*ComponentInfo, len(cns))
 	edges := c.loader.OriginalGraph().Edges()
 	for i, com := range cns {
-		nn := newFromNode(com, edges)
+		nn := c.newFromNode(com, edges)
 		infos[i] = nn
+
+		for _, inst := range com.Instances() {
+			infos = append(infos, c.newFromNode(inst, edges))
+		}
+	}
+	c.loadMut.RUnlock()
+
+	for _, mod := range c.runningModules() {
+		infos = append(infos, mod.f.ComponentInfos()...)
 	}
 	return infos
 }
 
+// findComponent finds a component by its global ID, searching through running
+// modules if necessary.
+func (c *Flow) findComponent(globalID string) *controller.ComponentNode {
+	for _, cn := range c.components() {
+		if cn.GlobalID() == globalID {
+			return cn
+		}
+	}
+
+	for _, mod := range c.runningModules() {
+		if !strings.HasPrefix(globalID, mod.f.opts.controllerID+"/") {
+			continue
+		}
+		if cn := mod.f.findComponent(globalID); cn != nil {
+			return cn
+		}
+	}
+	return nil
+}
+
+// components returns the components of the controller, including instances
+// of components created through for_each.
+func (c *Flow) components() []*controller.ComponentNode {
+	var res []*controller.ComponentNode
+	for _, cn := range c.loader.Components() {
+		res = append(res, cn)
+		res = append(res, cn.Instances()...)
+	}
+	return res
+}
+
 // Close closes the controller and all running components.
 func (c *Flow) Close() error {
 	c.cancel()
@@ -240,20 +433,29 @@ func (c *Flow) Close() error {
 	return c.sched.Close()
 }
 
-func newFromNode(cn *controller.ComponentNode, edges []dag.Edge) *ComponentInfo {
+func (c *Flow) newFromNode(cn *controller.ComponentNode, edges []dag.Edge) *ComponentInfo {
 	references := make([]string, 0)
 	referencedBy := make([]string, 0)
 	for _, e := range edges {
-		if e.From.NodeID() == cn.NodeID() {
-			references = append(references, e.To.NodeID())
-		} else if e.To.NodeID() == cn.NodeID() {
-			referencedBy = append(referencedBy, e.From.NodeID())
+		// Only edges between components are reported; argument and export blocks
+		// of modules aren't exposed as components.
+		from, fromComponent := e.From.(*controller.ComponentNode)
+		to, toComponent := e.To.(*controller.ComponentNode)
+		if !fromComponent || !toComponent {
+			continue
+		}
+
+		if from == cn {
+			references = append(references, to.GlobalID())
+		} else if to == cn {
+			referencedBy = append(referencedBy, from.GlobalID())
 		}
 	}
 	h := cn.CurrentHealth()
 	ci := &ComponentInfo{
 		Label:        cn.Label(),
-		ID:           cn.NodeID(),
+		ID:           cn.GlobalID(),
+		ModuleID:     c.opts.controllerID,
 		Name:         cn.ComponentName(),
 		Type:         "block",
 		References:   references,
@@ -263,6 +465,7 @@ func newFromNode(cn *controller.ComponentNode, edges []dag.Edge) *ComponentInfo
 			Message:     h.Message,
 			UpdatedTime: h.UpdateTime,
 		},
+		LiveDebugging: cn.SupportsLiveDebugging(),
 	}
 	return ci
 }
@@ -272,6 +475,7 @@ type ComponentInfo struct {
 	Name         string           `json:"name,omitempty"`
 	Type         string           `json:"type,omitempty"`
 	ID           string           `json:"id,omitempty"`
+	ModuleID     string           `json:"moduleID,omitempty"`
 	Label        string           `json:"label,omitempty"`
 	References   []string         `json:"referencesTo"`
 	ReferencedBy []string         `json:"referencedBy"`
@@ -280,6 +484,10 @@ type ComponentInfo struct {
 	Arguments    json.RawMessage  `json:"arguments,omitempty"`
 	Exports      json.RawMessage  `json:"exports,omitempty"`
 	DebugInfo    json.RawMessage  `json:"debugInfo,omitempty"`
+
+	// LiveDebugging is true when the component can stream the data passing
+	// through it through Flow.LiveDebugging.
+	LiveDebugging bool `json:"liveDebugging,omitempty"`
 }
 
 // ComponentHealth represents the health of a component.
@@ -0,0 +1,125 @@
+package flow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/grafana/agent/component"
+)
+
+// HealthReport aggregates the health of components.
+type HealthReport struct {
+	// Healthy is true when every component in Components is healthy.
+	Healthy bool `json:"healthy"`
+
+	// Counts holds the number of components in each health state.
+	Counts map[string]int `json:"counts"`
+
+	// Components holds the health of individual components, sorted by ID.
+	Components []ComponentHealthReport `json:"components"`
+
+	// Missing holds IDs of components which were requested but don't exist.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// ComponentHealthReport is the health of a single component.
+type ComponentHealthReport struct {
+	ID string `json:"id"`
+	ComponentHealth
+}
+
+// HealthReport returns the health of components. Components running inside of
+// modules are included. If ids is non-empty, only the components with those
+// IDs are reported, and any of them which don't exist make the report
+// unhealthy.
+func (c *Flow) HealthReport(ids []string) HealthReport {
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = false
+	}
+
+	report := HealthReport{
+		Healthy:    true,
+		Counts:     make(map[string]int),
+		Components: make([]ComponentHealthReport, 0),
+	}
+	for _, ci := range c.ComponentInfos() {
+		if _, ok := want[ci.ID]; len(ids) > 0 && !ok {
+			continue
+		}
+		want[ci.ID] = true
+
+		report.Counts[ci.Health.State]++
+		report.Components = append(report.Components, ComponentHealthReport{
+			ID:              ci.ID,
+			ComponentHealth: *ci.Health,
+		})
+		if ci.Health.State != component.HealthTypeHealthy.String() {
+			report.Healthy = false
+		}
+	}
+
+	for id, found := range want {
+		if !found {
+			report.Missing = append(report.Missing, id)
+			report.Healthy = false
+		}
+	}
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].ID < report.Components[j].ID
+	})
+	sort.Strings(report.Missing)
+	return report
+}
+
+// Err returns an error describing the unhealthy and missing components of
+// the report. Err returns nil if the report is healthy.
+func (r HealthReport) Err() error {
+	if r.Healthy {
+		return nil
+	}
+
+	var problems []string
+	for _, ch := range r.Components {
+		if ch.State != component.HealthTypeHealthy.String() {
+			problems = append(problems, fmt.Sprintf("%s is %s", ch.ID, ch.State))
+		}
+	}
+	for _, id := range r.Missing {
+		problems = append(problems, fmt.Sprintf("%s does not exist", id))
+	}
+	return fmt.Errorf("components are not healthy: %s", strings.Join(problems, ", "))
+}
+
+// HealthHandler returns an http.HandlerFunc which writes the HealthReport for
+// the components with the given IDs as JSON. All components are reported if
+// ids is empty. The handler responds with 503 Service Unavailable when the
+// report is unhealthy.
+//
+// The IDs of the components to report can be overridden per request through
+// the component query parameter, which may be given more than once.
+func (c *Flow) HealthHandler(ids []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requested := ids
+		if override := r.URL.Query()["component"]; len(override) > 0 {
+			requested = override
+		}
+
+		report := c.HealthReport(requested)
+		bb, err := json.Marshal(report)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = w.Write(bb)
+	}
+}
@@ -0,0 +1,90 @@
+package flow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_HealthReport(t *testing.T) {
+	ctrl, _ := newFlow(testOptions(t))
+
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	report := ctrl.HealthReport(nil)
+	require.True(t, report.Healthy)
+	require.NoError(t, report.Err())
+	require.Equal(t, map[string]int{"healthy": 4}, report.Counts)
+	require.Len(t, report.Components, 4)
+	require.Equal(t, "testcomponents.passthrough.forwarded", report.Components[0].ID)
+
+	report = ctrl.HealthReport([]string{"testcomponents.tick.ticker", "testcomponents.passthrough.missing"})
+	require.False(t, report.Healthy)
+	require.Len(t, report.Components, 1)
+	require.Equal(t, "testcomponents.tick.ticker", report.Components[0].ID)
+	require.Equal(t, []string{"testcomponents.passthrough.missing"}, report.Missing)
+	require.EqualError(t, report.Err(), "components are not healthy: testcomponents.passthrough.missing does not exist")
+}
+
+func TestController_HealthHandler(t *testing.T) {
+	ctrl, _ := newFlow(testOptions(t))
+
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	handler := ctrl.HealthHandler([]string{"testcomponents.passthrough.static"})
+
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest(http.MethodGet, "/-/healthy", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	require.True(t, report.Healthy)
+	require.Len(t, report.Components, 1)
+	require.Equal(t, "testcomponents.passthrough.static", report.Components[0].ID)
+	require.Equal(t, "healthy", report.Components[0].State)
+
+	// The component query parameter overrides the components to report.
+	rr = httptest.NewRecorder()
+	handler(rr, httptest.NewRequest(http.MethodGet, "/-/healthy?component=testcomponents.passthrough.missing", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	require.False(t, report.Healthy)
+	require.Equal(t, []string{"testcomponents.passthrough.missing"}, report.Missing)
+}
+
+func TestController_HealthMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	opts := testOptions(t)
+	opts.Reg = reg
+	ctrl, _ := newFlow(opts)
+
+	f, err := ReadFile(t.Name(), []byte(`
+		testcomponents.passthrough "static" {
+			input = "hello, world!"
+		}
+	`))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	expect := `
+		# HELP agent_component_health Health of a component. Set to 1 for the current health type of the component and 0 for the others.
+		# TYPE agent_component_health gauge
+		agent_component_health{component_id="testcomponents.passthrough.static",health_type="exited"} 0
+		agent_component_health{component_id="testcomponents.passthrough.static",health_type="healthy"} 1
+		agent_component_health{component_id="testcomponents.passthrough.static",health_type="unhealthy"} 0
+		agent_component_health{component_id="testcomponents.passthrough.static",health_type="unknown"} 0
+	`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expect), "agent_component_health"))
+}
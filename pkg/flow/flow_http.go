@@ -9,21 +9,33 @@ import (
 
 	"github.com/grafana/agent/pkg/river/encoding"
 
-	"github.com/gorilla/mux"
 	"github.com/grafana/agent/pkg/flow/internal/controller"
 )
 
-// ComponentHandler returns an http.HandlerFunc which will delegate all requests to
-// a component named by the first path segment
+// ComponentHandler returns an http.HandlerFunc which will delegate all
+// requests under /component/{id}/ to the component named by id. Requests for
+// components running inside of modules are delegated through the component
+// which owns the module, such that /component/{module}/{id}/ routes to the
+// component {id} running inside of {module}.
 func (f *Flow) ComponentHandler() http.HandlerFunc {
+	inner := f.componentHandler()
+
 	return func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		id := vars["id"]
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/component")
+		inner.ServeHTTP(w, r)
+	}
+}
+
+// componentHandler returns an http.HandlerFunc which delegates requests to a
+// component named by the first path segment.
+func (f *Flow) componentHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
 
 		// find node with ID
 		var node *controller.ComponentNode
-		for _, n := range f.loader.Components() {
-			if n.ID().String() == id {
+		for _, n := range f.components() {
+			if n.NodeID() == id {
 				node = n
 				break
 			}
@@ -38,8 +50,8 @@ func (f *Flow) ComponentHandler() http.HandlerFunc {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		// remove /component/{id} from front of path, so each component can handle paths from their own root path
-		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/component/"+id)
+		// remove /{id} from front of path, so each component can handle paths from their own root path
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/"+id)
 		handler.ServeHTTP(w, r)
 	}
 }
@@ -49,29 +61,26 @@ func (f *Flow) ComponentJSON(w io.Writer, ci *ComponentInfo) error {
 	f.loadMut.RLock()
 	defer f.loadMut.RUnlock()
 
-	var foundComponent *controller.ComponentNode
-	for _, c := range f.loader.Components() {
-		if c.ID().String() == ci.ID {
-			foundComponent = c
-			break
-		}
-	}
+	foundComponent := f.findComponent(ci.ID)
 	if foundComponent == nil {
 		return fmt.Errorf("unable to find component named %q", ci.ID)
 	}
 
-	var err error
-	args, err := encoding.ConvertRiverBodyToJSON(foundComponent.Arguments())
-	if err != nil {
-		return err
-	}
-	ci.Arguments = args
+	// Components using for_each don't have arguments and exports of their own;
+	// they're reported by each of the instances instead.
+	if !foundComponent.ForEach() {
+		args, err := encoding.ConvertRiverBodyToJSON(foundComponent.Arguments())
+		if err != nil {
+			return err
+		}
+		ci.Arguments = args
 
-	exports, err := encoding.ConvertRiverBodyToJSON(foundComponent.Exports())
-	if err != nil {
-		return err
+		exports, err := encoding.ConvertRiverBodyToJSON(foundComponent.Exports())
+		if err != nil {
+			return err
+		}
+		ci.Exports = exports
 	}
-	ci.Exports = exports
 
 	debugInfo, err := encoding.ConvertRiverBodyToJSON(foundComponent.DebugInfo())
 	if err != nil {
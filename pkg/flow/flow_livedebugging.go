@@ -0,0 +1,31 @@
+package flow
+
+import "errors"
+
+// Errors returned by LiveDebugging.
+var (
+	ErrComponentNotFound        = errors.New("component not found")
+	ErrLiveDebuggingUnsupported = errors.New("component does not support live debugging")
+)
+
+// LiveDebugging registers fn to receive the data passing through the
+// component with the given global ID. Calling the returned function
+// unregisters fn.
+//
+// ErrComponentNotFound is returned if the component doesn't exist, and
+// ErrLiveDebuggingUnsupported is returned if the component isn't running or
+// doesn't support live debugging.
+func (f *Flow) LiveDebugging(globalID string, fn func(data string)) (unregister func(), err error) {
+	f.loadMut.RLock()
+	cn := f.findComponent(globalID)
+	f.loadMut.RUnlock()
+	if cn == nil {
+		return nil, ErrComponentNotFound
+	}
+
+	unregister, ok := cn.LiveDebugging(fn)
+	if !ok {
+		return nil, ErrLiveDebuggingUnsupported
+	}
+	return unregister, nil
+}
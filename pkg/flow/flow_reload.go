@@ -0,0 +1,140 @@
+package flow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/pkg/flow/internal/controller"
+)
+
+// reloadHistorySize is the number of entries kept in the reload history.
+const reloadHistorySize = 10
+
+// ErrReloadNotFound is returned by Rollback when there is no successful entry
+// in the reload history with the requested hash.
+var ErrReloadNotFound = errors.New("no successful reload found with the given hash")
+
+// ReloadEntry describes a call to LoadFile.
+type ReloadEntry struct {
+	// Hash is the hex-encoded SHA256 hash of the loaded file.
+	Hash string `json:"hash"`
+	// Timestamp is the time the file was loaded.
+	Timestamp time.Time `json:"timestamp"`
+	// Error holds the error encountered while loading the file, if any.
+	Error string `json:"error,omitempty"`
+	// Active is true for the entry of the file currently in use.
+	Active bool `json:"active"`
+}
+
+// reloadRecord is an entry in the reload history along with what's needed to
+// load the file again.
+type reloadRecord struct {
+	entry ReloadEntry
+	file  *File
+	args  map[string]any
+}
+
+// hashFile returns the hex-encoded SHA256 hash of the source of f.
+func hashFile(f *File) string {
+	sum := sha256.Sum256(f.Source)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordReload adds a call to LoadFile to the reload history, dropping the
+// oldest entry if the history is full. The record of the file in use is kept
+// in lastGood, so it isn't lost when it's dropped from the history. loadMut
+// must be held when calling recordReload.
+func (c *Flow) recordReload(file *File, args map[string]any, err error) *reloadRecord {
+	rec := &reloadRecord{
+		entry: ReloadEntry{
+			Hash:      hashFile(file),
+			Timestamp: time.Now(),
+		},
+		file: file,
+		args: args,
+	}
+	if err != nil {
+		rec.entry.Error = err.Error()
+	}
+
+	c.reloads = append(c.reloads, rec)
+	if len(c.reloads) > reloadHistorySize {
+		c.reloads = c.reloads[len(c.reloads)-reloadHistorySize:]
+	}
+	return rec
+}
+
+// restoreLastGood reapplies the last file which loaded successfully after a
+// file failed to apply. Only blocks which differ from the failed file are
+// evaluated again, and components removed by the failed file are reused
+// rather than created again. loadMut must be held when calling
+// restoreLastGood.
+func (c *Flow) restoreLastGood() {
+	if c.lastGood == nil {
+		return
+	}
+
+	hash := c.lastGood.entry.Hash
+	diags, err := c.applyFile(c.lastGood.file, c.lastGood.args, controller.ApplyOptions{Revert: true})
+	if err == nil && diags.HasErrors() {
+		err = diags
+	}
+	if err != nil {
+		level.Error(c.log).Log("msg", "failed to restore last good config", "hash", hash, "err", err)
+		return
+	}
+	level.Warn(c.log).Log("msg", "config failed to load; restored last good config", "hash", hash)
+}
+
+// ReloadHistory returns the most recent calls to LoadFile, oldest first. The
+// entry of the file currently in use is marked as active, and is always
+// included even if it's older than the rest of the history.
+func (c *Flow) ReloadHistory() []ReloadEntry {
+	c.loadMut.RLock()
+	defer c.loadMut.RUnlock()
+
+	history := c.history()
+	res := make([]ReloadEntry, len(history))
+	for i, rec := range history {
+		res[i] = rec.entry
+		res[i].Active = rec == c.lastGood
+	}
+	return res
+}
+
+// history returns the records of the reload history, oldest first, along
+// with lastGood if it was dropped from the history. loadMut must be held when
+// calling history.
+func (c *Flow) history() []*reloadRecord {
+	if c.lastGood == nil {
+		return c.reloads
+	}
+	for _, rec := range c.reloads {
+		if rec == c.lastGood {
+			return c.reloads
+		}
+	}
+	return append([]*reloadRecord{c.lastGood}, c.reloads...)
+}
+
+// Rollback loads the file from the most recent successful entry in the reload
+// history with the given hash. ErrReloadNotFound is returned if there is no
+// such entry. Rolling back is recorded in the reload history like any other
+// call to LoadFile.
+func (c *Flow) Rollback(hash string) error {
+	c.loadMut.Lock()
+	defer c.loadMut.Unlock()
+
+	history := c.history()
+	for i := len(history) - 1; i >= 0; i-- {
+		rec := history[i]
+		if rec.entry.Hash != hash || rec.entry.Error != "" {
+			continue
+		}
+		return c.loadFile(rec.file, rec.args, controller.ApplyOptions{})
+	}
+	return ErrReloadNotFound
+}
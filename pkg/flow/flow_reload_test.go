@@ -0,0 +1,172 @@
+package flow
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/flow/internal/controller"
+	"github.com/grafana/agent/pkg/flow/internal/testcomponents"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_ReloadHistory(t *testing.T) {
+	ctrl, _ := newFlow(testOptions(t))
+
+	load := func(content string) (*File, error) {
+		f, err := ReadFile(t.Name(), []byte(content))
+		require.NoError(t, err)
+		return f, ctrl.LoadFile(f, nil)
+	}
+
+	good, err := load(testFile)
+	require.NoError(t, err)
+	static := ctrl.loader.Graph().GetByID("testcomponents.passthrough.static")
+
+	// A file which fails validation shouldn't change the running components.
+	_, err = load(`
+		testcomponents.passthrough "static" {
+			input = testcomponents.passthrough.doesnotexist.output
+		}
+
+		testcomponents.passthrough "extra" {
+			input = "extra"
+		}
+	`)
+	require.Error(t, err)
+	require.Len(t, ctrl.loader.Components(), 4)
+	require.Nil(t, ctrl.loader.Graph().GetByID("testcomponents.passthrough.extra"))
+	require.Same(t, static, ctrl.loader.Graph().GetByID("testcomponents.passthrough.static"))
+
+	in, _ := getFields(t, ctrl.loader.Graph(), "testcomponents.passthrough.static")
+	require.Equal(t, "hello, world!", in.(testcomponents.PassthroughConfig).Input)
+
+	history := ctrl.ReloadHistory()
+	require.Len(t, history, 2)
+	require.Equal(t, hashFile(good), history[0].Hash)
+	require.True(t, history[0].Active)
+	require.Empty(t, history[0].Error)
+	require.False(t, history[1].Active)
+	require.NotEmpty(t, history[1].Error)
+
+	// Rolling back to a previous file should load it again.
+	_, err = load(`
+		testcomponents.passthrough "static" {
+			input = "changed"
+		}
+	`)
+	require.NoError(t, err)
+	require.Len(t, ctrl.loader.Components(), 1)
+
+	require.NoError(t, ctrl.Rollback(hashFile(good)))
+	require.Len(t, ctrl.loader.Components(), 4)
+
+	history = ctrl.ReloadHistory()
+	require.Len(t, history, 4)
+	require.Equal(t, hashFile(good), history[3].Hash)
+	require.True(t, history[3].Active)
+	require.False(t, history[0].Active)
+
+	// Failed files can't be rolled back to.
+	require.ErrorIs(t, ctrl.Rollback(history[1].Hash), ErrReloadNotFound)
+	require.ErrorIs(t, ctrl.Rollback("doesnotexist"), ErrReloadNotFound)
+}
+
+func TestController_ReloadHistory_Bounded(t *testing.T) {
+	ctrl, _ := newFlow(testOptions(t))
+
+	for i := 0; i < reloadHistorySize+5; i++ {
+		f, err := ReadFile(t.Name(), []byte(fmt.Sprintf(`
+			testcomponents.passthrough "static" {
+				input = "%d"
+			}
+		`, i)))
+		require.NoError(t, err)
+		require.NoError(t, ctrl.LoadFile(f, nil))
+	}
+
+	history := ctrl.ReloadHistory()
+	require.Len(t, history, reloadHistorySize)
+	require.True(t, history[len(history)-1].Active)
+}
+
+func TestController_LoadFile_Validation(t *testing.T) {
+	ctrl, _ := newFlow(testOptions(t))
+
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+	static := ctrl.loader.Graph().GetByID("testcomponents.passthrough.static").(*controller.ComponentNode)
+	health := static.CurrentHealth()
+
+	// Blocks which can't be decoded are found before any component is
+	// evaluated.
+	f, err = ReadFile(t.Name(), []byte(`
+		testcomponents.passthrough "static" {
+			input = [1, 2]
+		}
+	`))
+	require.NoError(t, err)
+	require.Error(t, ctrl.LoadFile(f, nil))
+	require.Len(t, ctrl.loader.Components(), 4)
+	require.Equal(t, health, static.CurrentHealth())
+}
+
+func TestController_LoadFile_RestoreReusesComponents(t *testing.T) {
+	ctrl, _ := newFlow(testOptions(t))
+
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+	forwarded := ctrl.loader.Graph().GetByID("testcomponents.passthrough.forwarded")
+
+	// The tick component fails to update, which can only be found by applying
+	// the file. Restoring the last good file shouldn't create the removed
+	// component again.
+	f, err = ReadFile(t.Name(), []byte(`
+		testcomponents.tick "ticker" {
+			frequency = "0s"
+		}
+
+		testcomponents.passthrough "static" {
+			input = "hello, world!"
+		}
+
+		testcomponents.passthrough "ticker" {
+			input = testcomponents.tick.ticker.tick_time
+		}
+	`))
+	require.NoError(t, err)
+	require.Error(t, ctrl.LoadFile(f, nil))
+	require.Len(t, ctrl.loader.Components(), 4)
+	require.Same(t, forwarded, ctrl.loader.Graph().GetByID("testcomponents.passthrough.forwarded"))
+
+	in, _ := getFields(t, ctrl.loader.Graph(), "testcomponents.tick.ticker")
+	require.Equal(t, time.Second, in.(testcomponents.TickConfig).Frequency)
+}
+
+func TestController_ReloadHistory_KeepsActive(t *testing.T) {
+	ctrl, _ := newFlow(testOptions(t))
+
+	good, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(good, nil))
+
+	// Fill the history with failed loads.
+	for i := 0; i < reloadHistorySize; i++ {
+		f, err := ReadFile(t.Name(), []byte(fmt.Sprintf(`
+			testcomponents.passthrough "static" {
+				input = testcomponents.passthrough.missing%d.output
+			}
+		`, i)))
+		require.NoError(t, err)
+		require.Error(t, ctrl.LoadFile(f, nil))
+	}
+
+	history := ctrl.ReloadHistory()
+	require.Len(t, history, reloadHistorySize+1)
+	require.Equal(t, hashFile(good), history[0].Hash)
+	require.True(t, history[0].Active)
+
+	require.NoError(t, ctrl.Rollback(hashFile(good)))
+}
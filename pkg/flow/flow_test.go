@@ -1,8 +1,10 @@
 package flow
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/pkg/flow/internal/controller"
@@ -38,7 +40,7 @@ func TestController_LoadFile_Evaluation(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, f)
 
-	err = ctrl.LoadFile(f)
+	err = ctrl.LoadFile(f, nil)
 	require.NoError(t, err)
 	require.Len(t, ctrl.loader.Components(), 4)
 
@@ -49,6 +51,52 @@ func TestController_LoadFile_Evaluation(t *testing.T) {
 	require.Equal(t, "hello, world!", out.(testcomponents.PassthroughExports).Output)
 }
 
+func TestController_ForEach(t *testing.T) {
+	content := `
+		testcomponents.passthrough "names" {
+			for_each = ["first", "second"]
+			input    = each.value
+		}
+
+		testcomponents.passthrough "disabled" {
+			enabled = false
+			input   = "hello"
+		}
+	`
+
+	ctrl, ctx := newFlow(testOptions(t))
+
+	f, err := ReadFile(t.Name(), []byte(content))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go ctrl.run(ctx)
+	defer func() { _ = ctrl.Close() }()
+
+	// Each instance should be reported as its own component and be scheduled
+	// to run.
+	require.Eventually(t, func() bool {
+		var running int
+		for _, info := range ctrl.ComponentInfos() {
+			switch info.ID {
+			case "testcomponents.passthrough.names[0]", "testcomponents.passthrough.names[1]":
+				if info.Health.Message == "started component" {
+					running++
+				}
+			}
+		}
+		return running == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NotNil(t, ctrl.findComponent("testcomponents.passthrough.names[1]"))
+
+	disabled := ctrl.findComponent("testcomponents.passthrough.disabled")
+	require.NotNil(t, disabled)
+	require.Equal(t, "component disabled", disabled.CurrentHealth().Message)
+}
+
 func getFields(t *testing.T, g *dag.Graph, nodeID string) (component.Arguments, component.Exports) {
 	t.Helper()
 
@@ -0,0 +1,75 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/flow/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Tracing_Load(t *testing.T) {
+	tp, err := tracing.New(tracing.DefaultOptions)
+	require.NoError(t, err)
+
+	opts := testOptions(t)
+	opts.TracerProvider = tp
+	ctrl, _ := newFlow(opts)
+
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	evaluations := tp.Recorder().Evaluations()
+	require.Len(t, evaluations, 1)
+	require.Equal(t, tracing.TriggerLoad, evaluations[0].Trigger)
+	require.Len(t, evaluations[0].Nodes, 4)
+
+	nodes := make(map[string]tracing.NodeEvaluation)
+	for _, n := range evaluations[0].Nodes {
+		nodes[n.ID] = n
+	}
+	require.Equal(t, []string{"testcomponents.passthrough.ticker"}, nodes["testcomponents.passthrough.forwarded"].Dependencies)
+	require.True(t, nodes["testcomponents.passthrough.forwarded"].ArgumentsChanged)
+
+	// Loading the same file again doesn't evaluate anything.
+	require.NoError(t, ctrl.LoadFile(f, nil))
+	evaluations = tp.Recorder().Evaluations()
+	require.Len(t, evaluations, 2)
+	require.Empty(t, evaluations[0].Nodes)
+}
+
+func TestController_Tracing_Update(t *testing.T) {
+	tp, err := tracing.New(tracing.DefaultOptions)
+	require.NoError(t, err)
+
+	opts := testOptions(t)
+	opts.TracerProvider = tp
+	ctrl := New(opts)
+	defer func() { require.NoError(t, ctrl.Close()) }()
+
+	f, err := ReadFile(t.Name(), []byte(`
+		testcomponents.tick "ticker" {
+			frequency = "10ms"
+		}
+
+		testcomponents.passthrough "ticker" {
+			input = testcomponents.tick.ticker.tick_time
+		}
+	`))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	require.Eventually(t, func() bool {
+		for _, ev := range tp.Recorder().Evaluations() {
+			if ev.Trigger != tracing.TriggerExportsChange {
+				continue
+			}
+			return ev.Originator == "testcomponents.tick.ticker" &&
+				len(ev.Nodes) == 1 &&
+				ev.Nodes[0].ID == "testcomponents.passthrough.ticker" &&
+				ev.Nodes[0].ArgumentsChanged
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"github.com/grafana/agent/pkg/flow/internal/dag"
+	"github.com/grafana/agent/pkg/river/ast"
+	"github.com/grafana/agent/pkg/river/vm"
+)
+
+// BlockNode is a node in the DAG which manages a River block and can be
+// evaluated.
+type BlockNode interface {
+	dag.Node
+
+	// Block returns the current block of the managed node.
+	Block() *ast.BlockStmt
+
+	// Evaluate updates the managed node by re-evaluating its River block with
+	// the provided scope.
+	Evaluate(scope *vm.Scope) error
+}
+
+// Config block names which are interpreted by the controller itself rather
+// than by components.
+const (
+	argumentBlockName = "argument"
+	exportBlockName   = "export"
+)
+
+// IsConfigBlock returns true if the block with the given name is handled by
+// the controller directly rather than describing a component.
+func IsConfigBlock(name string) bool {
+	switch name {
+	case argumentBlockName, exportBlockName:
+		return true
+	default:
+		return false
+	}
+}
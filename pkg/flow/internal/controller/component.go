@@ -14,10 +14,12 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/component"
-	"github.com/grafana/agent/pkg/flow/internal/dag"
+	"github.com/grafana/agent/pkg/cluster"
 	"github.com/grafana/agent/pkg/river/ast"
 	"github.com/grafana/agent/pkg/river/vm"
+	"github.com/hashicorp/go-multierror"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/atomic"
 )
 
@@ -62,6 +64,40 @@ type ComponentGlobals struct {
 	OnExportsChange func(cn *ComponentNode) // Invoked when the managed component updated its exports
 	Registerer      prometheus.Registerer   // Registerer for serving agent and component metrics
 	HTTPListenAddr  string                  // Base address for server
+	Clusterer       cluster.Node            // Cluster node shared between all managed components
+
+	// ControllerID is the ID of the module which owns the components. Empty
+	// for the root controller.
+	ControllerID string
+
+	// OnModuleExportsChange is invoked when the set of exports from export
+	// blocks changes. May be nil.
+	OnModuleExportsChange func(exports map[string]any)
+
+	// NewModuleController creates a ModuleController for the component with the
+	// given global ID. reg is an unwrapped registerer owned by the component
+	// which modules can use for their own metrics. May be nil if modules are not
+	// supported.
+	NewModuleController func(id string, reg prometheus.Registerer) component.ModuleController
+
+	// OnRunnablesChange is invoked when the set of nodes which should be
+	// running changes outside of Loader.Apply, such as when a component is
+	// enabled or disabled, or when the for_each instances of a component
+	// change. May be nil.
+	OnRunnablesChange func()
+
+	// TracerProvider is used to trace graph and node evaluations. Evaluations
+	// aren't traced if nil.
+	TracerProvider trace.TracerProvider
+}
+
+// GlobalID returns the ID of a node with the given local ID scoped by the
+// ControllerID of globals.
+func (g ComponentGlobals) GlobalID(localID string) string {
+	if g.ControllerID == "" {
+		return localID
+	}
+	return g.ControllerID + "/" + localID
 }
 
 // ComponentNode is a controller node which manages a user-defined component.
@@ -69,23 +105,41 @@ type ComponentGlobals struct {
 // ComponentNode manages the underlying component and caches its current
 // arguments and exports. ComponentNode manages the arguments for the component
 // from a River block.
+//
+// Blocks which set the for_each meta-argument don't manage a component
+// directly. Instead, a ComponentNode is created for each element of the
+// for_each collection, referred to as an instance. Instances are never added
+// to the graph; their exports are merged into the exports of the
+// ComponentNode which created them.
 type ComponentNode struct {
-	id              ComponentID
-	label           string
-	componentName   string
-	nodeID          string // Cached from id.String() to avoid allocating new strings every time NodeID is called.
-	reg             component.Registration
-	managedOpts     component.Options
-	register        *wrappedRegisterer
-	exportsType     reflect.Type
-	onExportsChange func(cn *ComponentNode) // Informs controller that we changed our exports
+	id                ComponentID
+	label             string
+	componentName     string
+	nodeID            string // Cached from id.String() to avoid allocating new strings every time NodeID is called.
+	globalID          string // nodeID scoped by the ID of the module which owns the component.
+	instanceKey       string // Key of the for_each element for instances.
+	globals           ComponentGlobals
+	reg               component.Registration
+	managedOpts       component.Options
+	register          *wrappedRegisterer
+	exportsType       reflect.Type
+	onExportsChange   func(cn *ComponentNode) // Informs controller that we changed our exports
+	onRunnablesChange func()                  // Informs controller that Runnables changed
 
 	mut     sync.RWMutex
 	block   *ast.BlockStmt // Current River block to derive args from
-	eval    *vm.Evaluator
+	eval    *vm.Evaluator  // Evaluator for block without meta-arguments
+	meta    metaArguments
+	enabled bool                // Value of the enabled meta-argument from the last evaluation
 	managed component.Component // Inner managed component
 	args    component.Arguments // Evaluated arguments for the managed component
 
+	// instancesMut is separate from mut so instances can report their exports
+	// while the ComponentNode is being evaluated.
+	instancesMut  sync.RWMutex
+	instances     []*ComponentNode // Instances created by for_each, in order
+	instanceArray bool             // Whether for_each was last given an array
+
 	doingEval atomic.Bool
 
 	// NOTE(rfratto): health and exports have their own mutex because they may be
@@ -100,7 +154,7 @@ type ComponentNode struct {
 	exports    component.Exports // Evaluated exports for the managed component
 }
 
-var _ dag.Node = (*ComponentNode)(nil)
+var _ BlockNode = (*ComponentNode)(nil)
 
 // NewComponentNode creates a new ComponentNode from an initial ast.BlockStmt.
 // The underlying managed component isn't created until Evaluate is called.
@@ -110,6 +164,33 @@ func NewComponentNode(globals ComponentGlobals, b *ast.BlockStmt) *ComponentNode
 		nodeID = id.String()
 	)
 
+	cn := newComponentNode(globals, b, nodeID)
+	cn.onExportsChange = globals.OnExportsChange
+	cn.onRunnablesChange = globals.OnRunnablesChange
+	cn.block = b
+	cn.eval, cn.meta = newBlockEvaluators(b)
+	return cn
+}
+
+// newInstance creates a new instance of cn for the for_each element with the
+// given key. The instance is evaluated with the same River block as cn.
+func (cn *ComponentNode) newInstance(key string) *ComponentNode {
+	inst := newComponentNode(cn.globals, cn.block, instanceNodeID(cn.nodeID, key))
+	inst.instanceKey = key
+	inst.onExportsChange = func(*ComponentNode) { cn.updateInstanceExports() }
+	inst.block = cn.block
+	inst.eval = cn.eval
+	return inst
+}
+
+// newBlockEvaluators returns an evaluator for the arguments of b and the
+// meta-arguments set in b.
+func newBlockEvaluators(b *ast.BlockStmt) (*vm.Evaluator, metaArguments) {
+	body, meta := splitMetaArguments(b.Body)
+	return vm.New(body), meta
+}
+
+func newComponentNode(globals ComponentGlobals, b *ast.BlockStmt, nodeID string) *ComponentNode {
 	reg, ok := component.Get(ComponentID(b.Name).String())
 	if !ok {
 		// NOTE(rfratto): It's normally not possible to get to this point; the
@@ -125,16 +206,14 @@ func NewComponentNode(globals ComponentGlobals, b *ast.BlockStmt) *ComponentNode
 	}
 
 	cn := &ComponentNode{
-		id:              id,
-		label:           b.Label,
-		nodeID:          nodeID,
-		componentName:   strings.Join(b.Name, "."),
-		reg:             reg,
-		exportsType:     getExportsType(reg),
-		onExportsChange: globals.OnExportsChange,
-
-		block: b,
-		eval:  vm.New(b.Body),
+		id:            BlockComponentID(b),
+		label:         b.Label,
+		nodeID:        nodeID,
+		globalID:      globals.GlobalID(nodeID),
+		globals:       globals,
+		componentName: strings.Join(b.Name, "."),
+		reg:           reg,
+		exportsType:   getExportsType(reg),
 
 		// Prepopulate arguments and exports with their zero values.
 		args:    reg.Args,
@@ -151,16 +230,24 @@ func NewComponentNode(globals ComponentGlobals, b *ast.BlockStmt) *ComponentNode
 func getManagedOptions(globals ComponentGlobals, cn *ComponentNode) component.Options {
 	wrapped := newWrappedRegisterer()
 	cn.register = wrapped
+
+	var moduleController component.ModuleController
+	if globals.NewModuleController != nil {
+		moduleController = globals.NewModuleController(cn.globalID, wrapped)
+	}
+
 	return component.Options{
-		ID:            cn.nodeID,
-		Logger:        log.With(globals.Logger, "component", cn.nodeID),
-		DataPath:      filepath.Join(globals.DataPath, cn.nodeID),
+		ID:            cn.globalID,
+		Logger:        log.With(globals.Logger, "component", cn.globalID),
+		DataPath:      filepath.Join(globals.DataPath, cn.globalID),
 		OnStateChange: cn.setExports,
 		Registerer: prometheus.WrapRegistererWith(prometheus.Labels{
-			"component_id": cn.nodeID,
+			"component_id": cn.globalID,
 		}, wrapped),
-		HTTPListenAddr: globals.HTTPListenAddr,
-		HTTPPath:       fmt.Sprintf("/component/%s/", cn.nodeID),
+		HTTPListenAddr:   globals.HTTPListenAddr,
+		HTTPPath:         fmt.Sprintf("/component/%s/", cn.globalID),
+		ModuleController: moduleController,
+		Clusterer:        globals.Clusterer,
 	}
 }
 
@@ -185,6 +272,18 @@ func (cn *ComponentNode) ComponentName() string { return cn.componentName }
 // block.
 func (cn *ComponentNode) NodeID() string { return cn.nodeID }
 
+// GlobalID returns the ID of the component scoped by the module which owns
+// it. GlobalID is the same as NodeID for components in the root controller.
+func (cn *ComponentNode) GlobalID() string { return cn.globalID }
+
+// Block implements BlockNode and returns the current block of the managed
+// component.
+func (cn *ComponentNode) Block() *ast.BlockStmt {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.block
+}
+
 // UpdateBlock updates the River block used to construct arguments for the
 // managed component. The new block isn't used until the next time Evaluate is
 // invoked.
@@ -199,7 +298,39 @@ func (cn *ComponentNode) UpdateBlock(b *ast.BlockStmt) {
 	cn.mut.Lock()
 	defer cn.mut.Unlock()
 	cn.block = b
-	cn.eval = vm.New(b.Body)
+	cn.eval, cn.meta = newBlockEvaluators(b)
+}
+
+// ForEach returns true if the component uses the for_each meta-argument.
+func (cn *ComponentNode) ForEach() bool {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.meta.forEach != nil
+}
+
+// Instances returns the instances created by the for_each meta-argument.
+// Instances returns nil if the component doesn't use for_each.
+func (cn *ComponentNode) Instances() []*ComponentNode {
+	cn.instancesMut.RLock()
+	defer cn.instancesMut.RUnlock()
+	return cn.instances
+}
+
+// Runnables returns the set of nodes which should be running for the
+// component. Components which are disabled or haven't been built don't have
+// any runnables. Components using for_each return their built instances.
+func (cn *ComponentNode) Runnables() []RunnableNode {
+	var rr []RunnableNode
+	for _, inst := range cn.Instances() {
+		rr = append(rr, inst.Runnables()...)
+	}
+
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	if cn.managed != nil {
+		rr = append(rr, cn)
+	}
+	return rr
 }
 
 // Evaluate updates the arguments for the managed component by re-evaluating
@@ -209,19 +340,73 @@ func (cn *ComponentNode) UpdateBlock(b *ast.BlockStmt) {
 // Evaluate will return an error if the River block cannot be evaluated or if
 // decoding to arguments fails.
 func (cn *ComponentNode) Evaluate(scope *vm.Scope) error {
+	prevRunnables := cn.Runnables()
 	err := cn.evaluate(scope)
 
-	switch err {
-	case nil:
-		cn.setEvalHealth(component.HealthTypeHealthy, "component evaluated")
-	default:
+	switch {
+	case err != nil:
 		msg := fmt.Sprintf("component evaluation failed: %s", err)
 		cn.setEvalHealth(component.HealthTypeUnhealthy, msg)
+	case !cn.isEnabled():
+		cn.setEvalHealth(component.HealthTypeHealthy, "component disabled")
+	default:
+		cn.setEvalHealth(component.HealthTypeHealthy, "component evaluated")
 	}
 
+	if cn.onRunnablesChange != nil && !sameRunnables(prevRunnables, cn.Runnables()) {
+		cn.onRunnablesChange()
+	}
 	return err
 }
 
+// validate evaluates the River block of cn with the provided scope without
+// building or updating the managed component. Blocks using for_each aren't
+// checked since their instances are evaluated with their own scope.
+func (cn *ComponentNode) validate(scope *vm.Scope) error {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+
+	if cn.meta.forEach != nil {
+		return nil
+	}
+
+	enabled := true
+	if cn.meta.enabled != nil {
+		if err := cn.meta.enabled.Evaluate(scope, &enabled); err != nil {
+			return fmt.Errorf("decoding %s: %w", enabledAttr, err)
+		}
+	}
+	if !enabled {
+		return nil
+	}
+
+	if err := cn.eval.Evaluate(scope, cn.reg.CloneArguments()); err != nil {
+		return fmt.Errorf("decoding River: %w", err)
+	}
+	return nil
+}
+
+// sameRunnables returns true if a and b hold the same nodes.
+func sameRunnables(a, b []RunnableNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isEnabled returns true if the component was enabled during its last
+// evaluation.
+func (cn *ComponentNode) isEnabled() bool {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.enabled
+}
+
 func (cn *ComponentNode) evaluate(scope *vm.Scope) error {
 	cn.mut.Lock()
 	defer cn.mut.Unlock()
@@ -229,6 +414,30 @@ func (cn *ComponentNode) evaluate(scope *vm.Scope) error {
 	cn.doingEval.Store(true)
 	defer cn.doingEval.Store(false)
 
+	cn.enabled = true
+	if cn.meta.enabled != nil {
+		if err := cn.meta.enabled.Evaluate(scope, &cn.enabled); err != nil {
+			return fmt.Errorf("decoding %s: %w", enabledAttr, err)
+		}
+	}
+	if !cn.enabled {
+		cn.disable()
+		return nil
+	}
+
+	// Blocks may start or stop using for_each between evaluations, so only the
+	// managed component or the instances may exist at a time.
+	if cn.meta.forEach != nil {
+		cn.removeManaged()
+		return cn.evaluateForEach(scope)
+	}
+	cn.removeInstances()
+	return cn.evaluateManaged(scope)
+}
+
+// evaluateManaged evaluates the arguments for the managed component, building
+// it if it doesn't exist yet. mut must be held when calling evaluateManaged.
+func (cn *ComponentNode) evaluateManaged(scope *vm.Scope) error {
 	args := cn.reg.CloneArguments()
 	if err := cn.eval.Evaluate(scope, args); err != nil {
 		return fmt.Errorf("decoding River: %w", err)
@@ -266,6 +475,127 @@ func (cn *ComponentNode) evaluate(scope *vm.Scope) error {
 	return nil
 }
 
+// evaluateForEach evaluates the for_each meta-argument, creating, updating,
+// and removing instances to match the elements of the for_each collection.
+// mut must be held when calling evaluateForEach.
+func (cn *ComponentNode) evaluateForEach(scope *vm.Scope) error {
+	var forEach interface{}
+	if err := cn.meta.forEach.Evaluate(scope, &forEach); err != nil {
+		return fmt.Errorf("decoding %s: %w", forEachAttr, err)
+	}
+	items, isArray, err := forEachItems(forEach)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*ComponentNode)
+	for _, inst := range cn.Instances() {
+		existing[inst.NodeID()] = inst
+	}
+
+	var (
+		errs      error
+		instances = make([]*ComponentNode, 0, len(items))
+	)
+	for _, item := range items {
+		inst, ok := existing[instanceNodeID(cn.nodeID, item.key)]
+		if ok {
+			inst.mut.Lock()
+			inst.block, inst.eval = cn.block, cn.eval
+			inst.mut.Unlock()
+		} else {
+			inst = cn.newInstance(item.key)
+		}
+
+		if err := inst.Evaluate(instanceScope(scope, item)); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s: %w", inst.NodeID(), err))
+		}
+		instances = append(instances, inst)
+	}
+
+	cn.instancesMut.Lock()
+	cn.instances = instances
+	cn.instanceArray = isArray
+	cn.instancesMut.Unlock()
+
+	cn.updateInstanceExports()
+	return errs
+}
+
+// disable removes the managed component and all instances, resetting the
+// exports of the component to their zero value so references to the
+// component remain valid. mut must be held when calling disable.
+func (cn *ComponentNode) disable() {
+	cn.removeManaged()
+	cn.removeInstances()
+}
+
+// removeManaged removes the managed component if one was built. mut must be
+// held when calling removeManaged.
+func (cn *ComponentNode) removeManaged() {
+	if cn.managed == nil {
+		return
+	}
+	cn.managed = nil
+	cn.args = cn.reg.Args
+	cn.register.unregisterAll()
+	cn.resetExports()
+}
+
+// removeInstances removes all instances created by for_each. mut must be held
+// when calling removeInstances.
+func (cn *ComponentNode) removeInstances() {
+	cn.instancesMut.Lock()
+	hadInstances := cn.instances != nil
+	cn.instances = nil
+	cn.instancesMut.Unlock()
+
+	if hadInstances {
+		cn.resetExports()
+	}
+}
+
+// resetExports sets the exports of the component to their zero value.
+func (cn *ComponentNode) resetExports() {
+	cn.exportsMut.Lock()
+	defer cn.exportsMut.Unlock()
+	cn.exports = cn.reg.Exports
+}
+
+// updateInstanceExports merges the exports of all instances into the exports
+// of cn. Exports are merged into an array when for_each was given an array,
+// and into an object keyed by instance key otherwise.
+func (cn *ComponentNode) updateInstanceExports() {
+	cn.instancesMut.RLock()
+	var exports component.Exports
+	if cn.instanceArray {
+		list := make([]interface{}, 0, len(cn.instances))
+		for _, inst := range cn.instances {
+			list = append(list, inst.Exports())
+		}
+		exports = list
+	} else {
+		keyed := make(map[string]interface{}, len(cn.instances))
+		for _, inst := range cn.instances {
+			keyed[inst.instanceKey] = inst.Exports()
+		}
+		exports = keyed
+	}
+	cn.instancesMut.RUnlock()
+
+	cn.exportsMut.Lock()
+	changed := !reflect.DeepEqual(cn.exports, exports)
+	cn.exports = exports
+	cn.exportsMut.Unlock()
+
+	// Changes made during evaluation will be picked up by the caller of
+	// Evaluate, so the controller only needs to be informed about changes
+	// which happen while instances are running.
+	if changed && !cn.doingEval.Load() {
+		cn.onExportsChange(cn)
+	}
+}
+
 // Run runs the managed component in the calling goroutine until ctx is
 // canceled. Evaluate must have been called at least once without retuning an
 // error before calling Run.
@@ -282,7 +612,16 @@ func (cn *ComponentNode) Run(ctx context.Context) error {
 	}
 
 	cn.setRunHealth(component.HealthTypeHealthy, "started component")
-	err := cn.managed.Run(ctx)
+	err := managed.Run(ctx)
+
+	cn.mut.RLock()
+	replaced := cn.managed != managed
+	cn.mut.RUnlock()
+	if replaced {
+		// The component was disabled while it was running; its health reports
+		// that it's disabled rather than that it exited.
+		return err
+	}
 
 	var exitMsg string
 	log := cn.managedOpts.Logger
@@ -369,10 +708,23 @@ func (cn *ComponentNode) setExports(e component.Exports) {
 //  3. Health reported by the managed component (if any)
 //  4. Latest health from Run() or Evaluate(), if the managed component does not
 //     report health.
+//
+// Components using for_each report the health of the first unhealthy
+// instance if their last evaluation succeeded.
 func (cn *ComponentNode) CurrentHealth() component.Health {
+	instances := cn.Instances()
+
 	cn.healthMut.RLock()
 	defer cn.healthMut.RUnlock()
 
+	if cn.evalHealth.Health == component.HealthTypeHealthy {
+		for _, inst := range instances {
+			if h := inst.CurrentHealth(); h.Health != component.HealthTypeHealthy {
+				return h
+			}
+		}
+	}
+
 	// A component which stopped running takes precedence over all other health
 	// states
 	if cn.runHealth.Health == component.HealthTypeExited {
@@ -410,6 +762,13 @@ func (cn *ComponentNode) DebugInfo() interface{} {
 	return nil
 }
 
+// evaluationFailed reports whether the last evaluation of cn failed.
+func (cn *ComponentNode) evaluationFailed() bool {
+	cn.healthMut.RLock()
+	defer cn.healthMut.RUnlock()
+	return cn.evalHealth.Health == component.HealthTypeUnhealthy
+}
+
 // setEvalHealth sets the internal health from a call to Evaluate. See Health
 // for information on how overall health is calculated.
 func (cn *ComponentNode) setEvalHealth(t component.HealthType, msg string) {
@@ -445,3 +804,27 @@ func (cn *ComponentNode) HTTPHandler() http.Handler {
 	}
 	return handler.Handler()
 }
+
+// SupportsLiveDebugging returns true if the managed component is running and
+// implements LiveDebuggingComponent.
+func (cn *ComponentNode) SupportsLiveDebugging() bool {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	_, ok := cn.managed.(component.LiveDebuggingComponent)
+	return ok
+}
+
+// LiveDebugging registers fn to receive live debugging data from the managed
+// component. ok is false if the component isn't running or doesn't implement
+// LiveDebuggingComponent.
+func (cn *ComponentNode) LiveDebugging(fn func(data string)) (unregister func(), ok bool) {
+	cn.mut.RLock()
+	managed := cn.managed
+	cn.mut.RUnlock()
+
+	ldc, ok := managed.(component.LiveDebuggingComponent)
+	if !ok {
+		return nil, false
+	}
+	return ldc.LiveDebugging(fn), true
+}
@@ -15,20 +15,22 @@ import (
 // will be (field_a, field_b, field_c).
 type Traversal []*ast.Ident
 
-// Reference describes an River expression reference to a ComponentNode.
+// Reference describes an River expression reference to a BlockNode.
 type Reference struct {
-	Target *ComponentNode // Component being referenced
+	Target BlockNode // Node being referenced
 
 	// Traversal describes which nested field relative to Target is being
 	// accessed.
 	Traversal Traversal
 }
 
-// ComponentReferences returns the list of references a component is making to
-// other components.
-func ComponentReferences(cn *ComponentNode, g *dag.Graph) ([]Reference, diag.Diagnostics) {
+// ComponentReferences returns the list of references a node is making to
+// other nodes.
+func ComponentReferences(cn BlockNode, g *dag.Graph) ([]Reference, diag.Diagnostics) {
 	var (
-		traversals = componentTraversals(cn)
+		block      = cn.Block()
+		traversals = expressionsFromBody(block.Body)
+		forEach    = usesForEach(block)
 
 		diags diag.Diagnostics
 	)
@@ -45,6 +47,12 @@ func ComponentReferences(cn *ComponentNode, g *dag.Graph) ([]Reference, diag.Dia
 			continue
 		}
 
+		// Blocks using for_each may refer to the element of the current instance
+		// through each.key and each.value.
+		if forEach && t[0].Name == eachVariable {
+			continue
+		}
+
 		ref, resolveDiags := resolveTraversal(t, g)
 		diags = append(diags, resolveDiags...)
 		if resolveDiags.HasErrors() {
@@ -56,13 +64,6 @@ func ComponentReferences(cn *ComponentNode, g *dag.Graph) ([]Reference, diag.Dia
 	return refs, diags
 }
 
-// componentTraversals gets the set of Traverals for a given component.
-func componentTraversals(cn *ComponentNode) []Traversal {
-	cn.mut.RLock()
-	defer cn.mut.RUnlock()
-	return expressionsFromBody(cn.block.Body)
-}
-
 // expressionsFromSyntaxBody recurses through body and finds all variable
 // references.
 func expressionsFromBody(body ast.Body) []Traversal {
@@ -139,8 +140,18 @@ func resolveTraversal(t Traversal, g *dag.Graph) (Reference, diag.Diagnostics) {
 
 	for {
 		if n := g.GetByID(partial.String()); n != nil {
+			if _, isExport := n.(*ExportConfigNode); isExport {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.SeverityLevelError,
+					Message:  fmt.Sprintf("%q cannot be referenced; export blocks are only visible outside of the module", partial),
+					StartPos: ast.StartPos(t[0]).Position(),
+					EndPos:   ast.StartPos(t[len(t)-1]).Position(),
+				})
+				return Reference{}, diags
+			}
+
 			return Reference{
-				Target:    n.(*ComponentNode),
+				Target:    n.(BlockNode),
 				Traversal: rem,
 			}, nil
 		}
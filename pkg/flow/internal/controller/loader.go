@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -11,10 +15,15 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/pkg/flow/internal/dag"
+	"github.com/grafana/agent/pkg/flow/tracing"
 	"github.com/grafana/agent/pkg/river/ast"
 	"github.com/grafana/agent/pkg/river/diag"
+	"github.com/grafana/agent/pkg/river/printer"
 	"github.com/grafana/agent/pkg/river/vm"
 	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	_ "github.com/grafana/agent/pkg/flow/internal/testcomponents" // Include test components
 )
@@ -22,6 +31,7 @@ import (
 // The Loader builds and evaluates ComponentNodes from River blocks.
 type Loader struct {
 	log     log.Logger
+	tracer  trace.Tracer
 	globals ComponentGlobals
 
 	mut           sync.RWMutex
@@ -31,22 +41,61 @@ type Loader struct {
 	cache         *valueCache
 	blocks        []*ast.BlockStmt // Most recently loaded blocks, used for writing
 	cm            *controllerMetrics
+	moduleArgs    map[string]any // Most recently provided module arguments
+	moduleExports map[string]any // Most recently reported module exports
+
+	// signatures holds the block signature of every node as of its last
+	// successful evaluation during Apply, by node ID.
+	signatures map[string]string
+
+	// dropped holds the nodes removed from the graph by the last call to
+	// Apply, by node ID. dropped is only used to revert that call.
+	dropped map[string]dag.Node
+}
+
+// ApplyOptions customizes a call to Apply.
+type ApplyOptions struct {
+	// EvaluateAll evaluates every block, even if it didn't change since the
+	// last call to Apply. Expressions in unchanged blocks, such as calls to
+	// env, are only evaluated again when EvaluateAll is set.
+	EvaluateAll bool
+
+	// Revert reuses the nodes removed by the previous call to Apply instead of
+	// creating them again. Revert must only be set when undoing the previous
+	// call to Apply before its changes were scheduled, so the reused
+	// components are still running.
+	Revert bool
 }
 
 // NewLoader creates a new Loader. Components built by the Loader will be built
 // with co for their options.
 func NewLoader(globals ComponentGlobals) *Loader {
+	reg := globals.Registerer
+	if reg != nil && globals.ControllerID != "" {
+		// Metrics of nested controllers are distinguished from the metrics of the
+		// root controller by the ID of the module.
+		reg = prometheus.WrapRegistererWith(prometheus.Labels{
+			"controller_id": globals.ControllerID,
+		}, reg)
+	}
+
+	tp := globals.TracerProvider
+	if tp == nil {
+		tp = trace.NewNoopTracerProvider()
+	}
+
 	l := &Loader{
 		log:     globals.Logger,
+		tracer:  tp.Tracer(tracing.InstrumentationName),
 		globals: globals,
 
 		graph: &dag.Graph{},
 		cache: newValueCache(),
-		cm:    newControllerMetrics(globals.Registerer),
+		cm:    newControllerMetrics(reg),
 	}
 	cc := newControllerCollector(l)
-	if globals.Registerer != nil {
-		globals.Registerer.MustRegister(cc)
+	if reg != nil {
+		reg.MustRegister(cc)
 	}
 	return l
 }
@@ -59,23 +108,50 @@ func NewLoader(globals ComponentGlobals) *Loader {
 // matches the component ID specified by any of the provided River blocks.
 // Reused components will be updated to point at the new River block.
 //
-// Apply will perform an evaluation of all loaded components before returning.
-// The provided parentContext can be used to provide global variables and
-// functions to components. A child context will be constructed from the parent
-// to expose values of other components.
-func (l *Loader) Apply(parentScope *vm.Scope, blocks []*ast.BlockStmt) diag.Diagnostics {
+// configBlocks holds the argument and export blocks of a module. args holds
+// the values to use for argument blocks and must only contain keys for
+// declared arguments.
+//
+// Unless opts.EvaluateAll is set, Apply only evaluates blocks which are new,
+// whose block or set of dependencies changed since the last call to Apply,
+// whose last evaluation failed, or which depend on a block that was
+// evaluated. All other blocks keep their current arguments and exports.
+// Evaluation finishes before Apply returns.
+//
+// Apply doesn't check the blocks in advance; use Validate to find errors
+// before any component is changed.
+func (l *Loader) Apply(args map[string]any, componentBlocks []*ast.BlockStmt, configBlocks []*ast.BlockStmt, opts ApplyOptions) diag.Diagnostics {
 	start := time.Now()
 	l.mut.Lock()
 	defer l.mut.Unlock()
 	l.cm.controllerEvaluation.Set(1)
 	defer l.cm.controllerEvaluation.Set(0)
 
+	ctx, span := l.tracer.Start(context.Background(), tracing.SpanGraphEvaluation, trace.WithAttributes(
+		tracing.KeyControllerID.String(l.globals.ControllerID),
+		tracing.KeyTrigger.String(tracing.TriggerLoad),
+	))
+	defer span.End()
+
 	var (
 		diags    diag.Diagnostics
 		newGraph dag.Graph
 	)
 
-	populateDiags := l.populateGraph(&newGraph, blocks)
+	prevArgs := l.moduleArgs
+	l.moduleArgs = args
+
+	existing := func(id string) dag.Node {
+		if n := l.graph.GetByID(id); n != nil {
+			return n
+		}
+		if opts.Revert {
+			return l.dropped[id]
+		}
+		return nil
+	}
+
+	populateDiags := l.populateGraph(&newGraph, componentBlocks, configBlocks, existing)
 	diags = append(diags, populateDiags...)
 
 	wireDiags := l.wireGraphEdges(&newGraph)
@@ -84,75 +160,334 @@ func (l *Loader) Apply(parentScope *vm.Scope, blocks []*ast.BlockStmt) diag.Diag
 	// Validate graph to detect cycles
 	err := dag.Validate(&newGraph)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		diags = append(diags, multierrToDiags(err)...)
 		return diags
 	}
 	// Copy the original graph, this is so we can have access to the original graph for things like displaying a UI or
-	// debug information.
-	l.originalGraph = newGraph.Clone()
+	// debug information. Block signatures are computed from the original graph
+	// since the reduced graph omits transitive dependencies.
+	originalGraph := newGraph.Clone()
 	// Perform a transitive reduction of the graph to clean it up.
 	dag.Reduce(&newGraph)
 
 	var (
-		components   = make([]*ComponentNode, 0, len(blocks))
-		componentIDs = make([]ComponentID, 0, len(blocks))
+		components    = make([]*ComponentNode, 0, len(componentBlocks))
+		componentIDs  = make([]ComponentID, 0, len(componentBlocks)+len(configBlocks))
+		argumentNodes = make([]*ArgumentConfigNode, 0, len(configBlocks))
+		exportNames   = make([]string, 0, len(configBlocks))
+
+		signatures = make(map[string]string, len(componentBlocks)+len(configBlocks))
+		evaluated  = make(map[dag.Node]struct{})
 	)
 
-	// Evaluate all of the components.
+	// Evaluate the components which changed.
 	_ = dag.WalkTopological(&newGraph, newGraph.Leaves(), func(n dag.Node) error {
-		c := n.(*ComponentNode)
+		switch n := n.(type) {
+		case *ComponentNode:
+			components = append(components, n)
+			componentIDs = append(componentIDs, n.ID())
+		case *ArgumentConfigNode:
+			argumentNodes = append(argumentNodes, n)
+			componentIDs = append(componentIDs, BlockComponentID(n.Block()))
+		case *ExportConfigNode:
+			exportNames = append(exportNames, n.Label())
+		}
 
-		components = append(components, c)
-		componentIDs = append(componentIDs, c.ID())
+		sig := blockSignature(originalGraph, n.(BlockNode))
+		if !opts.EvaluateAll && !l.needsEvaluation(&newGraph, n.(BlockNode), sig, prevArgs, evaluated) {
+			signatures[n.NodeID()] = sig
+			return nil
+		}
+		evaluated[n] = struct{}{}
 
-		if err := l.evaluate(parentScope, n.(*ComponentNode)); err != nil {
+		if err := l.evaluate(ctx, &newGraph, nil, n.(BlockNode)); err != nil {
 			var evalDiags diag.Diagnostics
 			if errors.As(err, &evalDiags) {
 				diags = append(diags, evalDiags...)
 			} else {
+				block := n.(BlockNode).Block()
 				diags.Add(diag.Diagnostic{
 					Severity: diag.SeverityLevelError,
-					Message:  fmt.Sprintf("Failed to build component: %s", err),
-					StartPos: ast.StartPos(n.(*ComponentNode).block).Position(),
-					EndPos:   ast.EndPos(n.(*ComponentNode).block).Position(),
+					Message:  fmt.Sprintf("Failed to build %s: %s", blockKind(block), err),
+					StartPos: ast.StartPos(block).Position(),
+					EndPos:   ast.EndPos(block).Position(),
 				})
 			}
+			// Don't store the signature of failed blocks so they're evaluated
+			// again on the next Apply.
+			return nil
 		}
+		signatures[n.NodeID()] = sig
 		return nil
 	})
 
+	if err := validateArgumentNames(argumentNodes, args); err != nil {
+		diags.Add(diag.Diagnostic{
+			Severity: diag.SeverityLevelError,
+			Message:  err.Error(),
+		})
+	}
+
+	dropped := make(map[string]dag.Node)
+	for _, n := range l.graph.Nodes() {
+		if newGraph.GetByID(n.NodeID()) == nil {
+			dropped[n.NodeID()] = n
+		}
+	}
+
 	l.components = components
 	l.graph = &newGraph
+	l.originalGraph = originalGraph
+	l.dropped = dropped
 	l.cache.SyncIDs(componentIDs)
-	l.blocks = blocks
+	l.cache.SyncModuleExports(exportNames)
+	l.blocks = componentBlocks
+	l.signatures = signatures
 	l.cm.componentEvaluationTime.Observe(time.Since(start).Seconds())
+	level.Debug(l.log).Log("msg", "applied config", "evaluated", len(evaluated), "skipped", len(newGraph.Nodes())-len(evaluated))
+	span.SetAttributes(
+		tracing.KeyEvaluated.Int(len(evaluated)),
+		tracing.KeySkipped.Int(len(newGraph.Nodes())-len(evaluated)),
+	)
+	if diags.HasErrors() {
+		span.SetStatus(codes.Error, "failed to evaluate config")
+	}
+
+	l.checkModuleExports()
 	return diags
 }
 
-func (l *Loader) populateGraph(g *dag.Graph, blocks []*ast.BlockStmt) diag.Diagnostics {
-	// Fill our graph with components.
+// Validate reports the diagnostics which applying the given blocks would
+// produce, without changing the Loader or any of its components. The blocks
+// are checked for unknown and redefined components, invalid references,
+// dependency cycles, and undeclared module arguments. Component blocks which
+// only reference components whose blocks didn't change are also decoded
+// against the current exports of those components.
+//
+// Validate can't detect errors which only occur when a component is built or
+// updated; those are reported by Apply.
+func (l *Loader) Validate(args map[string]any, componentBlocks []*ast.BlockStmt, configBlocks []*ast.BlockStmt) diag.Diagnostics {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
+
+	var (
+		diags diag.Diagnostics
+		g     dag.Graph
+	)
+
+	// The blocks are loaded into new nodes so that existing nodes don't see
+	// the new blocks until Apply is called.
+	diags = append(diags, l.populateGraph(&g, componentBlocks, configBlocks, nil)...)
+	diags = append(diags, l.wireGraphEdges(&g)...)
+
+	if err := dag.Validate(&g); err != nil {
+		return append(diags, multierrToDiags(err)...)
+	}
+
+	var argumentNodes []*ArgumentConfigNode
+	for _, n := range g.Nodes() {
+		switch n := n.(type) {
+		case *ArgumentConfigNode:
+			argumentNodes = append(argumentNodes, n)
+		case *ComponentNode:
+			if !l.dependsOnUnchanged(&g, n) {
+				continue
+			}
+			if err := n.validate(l.cache.BuildContext(nil)); err != nil {
+				block := n.Block()
+				diags.Add(diag.Diagnostic{
+					Severity: diag.SeverityLevelError,
+					Message:  fmt.Sprintf("Failed to build component: %s", err),
+					StartPos: ast.StartPos(block).Position(),
+					EndPos:   ast.EndPos(block).Position(),
+				})
+			}
+		}
+	}
+
+	if err := validateArgumentNames(argumentNodes, args); err != nil {
+		diags.Add(diag.Diagnostic{
+			Severity: diag.SeverityLevelError,
+			Message:  err.Error(),
+		})
+	}
+	return diags
+}
+
+// dependsOnUnchanged reports whether every dependency of n in g is a
+// component which was evaluated successfully during the last call to Apply
+// and whose block and dependencies are the same in g. The cached exports of
+// such components are the values n would be evaluated with. mut must be held
+// when calling dependsOnUnchanged.
+func (l *Loader) dependsOnUnchanged(g *dag.Graph, n *ComponentNode) bool {
+	for _, dep := range g.Dependencies(n) {
+		if _, ok := dep.(*ComponentNode); !ok {
+			return false
+		}
+		prev, ok := l.signatures[dep.NodeID()]
+		if !ok || prev != blockSignature(g, dep.(BlockNode)) {
+			return false
+		}
+	}
+	return true
+}
+
+// needsEvaluation reports whether n must be evaluated during Apply. sig is
+// the current signature of n, prevArgs holds the module arguments from the
+// previous call to Apply, and evaluated holds the nodes which have already
+// been evaluated during the current call to Apply.
+func (l *Loader) needsEvaluation(g *dag.Graph, n BlockNode, sig string, prevArgs map[string]any, evaluated map[dag.Node]struct{}) bool {
+	if prev, ok := l.signatures[n.NodeID()]; !ok || prev != sig {
+		return true
+	}
+	for _, dep := range g.Dependencies(n) {
+		if _, ok := evaluated[dep]; ok {
+			return true
+		}
+	}
+
+	switch n := n.(type) {
+	case *ComponentNode:
+		// Components may also fail evaluation when their dependencies change at
+		// runtime; give them another chance.
+		return n.evaluationFailed()
+	case *ArgumentConfigNode:
+		prev, prevOK := prevArgs[n.Label()]
+		cur, curOK := l.moduleArgs[n.Label()]
+		return prevOK != curOK || !reflect.DeepEqual(prev, cur)
+	default:
+		// Export blocks are cheap to evaluate and nothing depends on them, so
+		// they're always evaluated to keep module exports up to date.
+		return true
+	}
+}
+
+// blockSignature returns a string which identifies the contents of the block
+// of n and the set of nodes n depends on in g. Comments in the block don't
+// affect its signature.
+func blockSignature(g *dag.Graph, n BlockNode) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, n.Block()); err != nil {
+		// Blocks which can't be printed get a unique signature so they're
+		// always evaluated.
+		return fmt.Sprintf("%p", n.Block())
+	}
+
+	deps := g.Dependencies(n)
+	depIDs := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		depIDs = append(depIDs, dep.NodeID())
+	}
+	sort.Strings(depIDs)
+
+	buf.WriteString("\n")
+	buf.WriteString(strings.Join(depIDs, ","))
+	return buf.String()
+}
+
+// blockKind returns a human-friendly description of what b defines.
+func blockKind(b *ast.BlockStmt) string {
+	switch strings.Join(b.Name, ".") {
+	case argumentBlockName:
+		return "argument"
+	case exportBlockName:
+		return "export"
+	default:
+		return "component"
+	}
+}
+
+// populateGraph adds nodes for the given blocks to g. existing returns the
+// node to reuse for a block ID, if any; new nodes are created for every block
+// if existing is nil.
+func (l *Loader) populateGraph(g *dag.Graph, componentBlocks []*ast.BlockStmt, configBlocks []*ast.BlockStmt, existing func(id string) dag.Node) diag.Diagnostics {
 	var (
 		diags    diag.Diagnostics
-		blockMap = make(map[string]*ast.BlockStmt, len(blocks))
+		blockMap = make(map[string]*ast.BlockStmt, len(componentBlocks)+len(configBlocks))
 	)
-	for _, block := range blocks {
-		var c *ComponentNode
-		id := BlockComponentID(block).String()
 
+	// checkRedefined reports whether the block has already been declared,
+	// adding a diagnostic if it was.
+	checkRedefined := func(block *ast.BlockStmt) bool {
+		id := BlockComponentID(block).String()
 		if orig, redefined := blockMap[id]; redefined {
 			diags.Add(diag.Diagnostic{
 				Severity: diag.SeverityLevelError,
-				Message:  fmt.Sprintf("Component %s already declared at %s", id, ast.StartPos(orig).Position()),
+				Message:  fmt.Sprintf("Block %s already declared at %s", id, ast.StartPos(orig).Position()),
 				StartPos: block.NamePos.Position(),
 				EndPos:   block.NamePos.Add(len(id) - 1).Position(),
 			})
-			continue
+			return true
 		}
 		blockMap[id] = block
+		return false
+	}
+
+	// Fill our graph with argument and export blocks.
+	for _, block := range configBlocks {
+		name := strings.Join(block.Name, ".")
+		if block.Label == "" {
+			diags.Add(diag.Diagnostic{
+				Severity: diag.SeverityLevelError,
+				Message:  fmt.Sprintf("%s block must have a label", name),
+				StartPos: block.NamePos.Position(),
+				EndPos:   block.NamePos.Add(len(name) - 1).Position(),
+			})
+			continue
+		}
+		if checkRedefined(block) {
+			continue
+		}
+
+		id := BlockComponentID(block).String()
+		var exist dag.Node
+		if existing != nil {
+			exist = existing(id)
+		}
+
+		switch name {
+		case argumentBlockName:
+			if c, ok := exist.(*ArgumentConfigNode); ok {
+				c.UpdateBlock(block)
+				g.Add(c)
+			} else {
+				g.Add(NewArgumentConfigNode(block))
+			}
+		case exportBlockName:
+			if c, ok := exist.(*ExportConfigNode); ok {
+				c.UpdateBlock(block)
+				g.Add(c)
+			} else {
+				g.Add(NewExportConfigNode(block))
+			}
+		default:
+			diags.Add(diag.Diagnostic{
+				Severity: diag.SeverityLevelError,
+				Message:  fmt.Sprintf("Unrecognized config block %q", name),
+				StartPos: block.NamePos.Position(),
+				EndPos:   block.NamePos.Add(len(name) - 1).Position(),
+			})
+		}
+	}
 
-		if exist := l.graph.GetByID(id); exist != nil {
+	// Fill our graph with components.
+	for _, block := range componentBlocks {
+		var c *ComponentNode
+		id := BlockComponentID(block).String()
+
+		if checkRedefined(block) {
+			continue
+		}
+
+		var exist dag.Node
+		if existing != nil {
+			exist = existing(id)
+		}
+
+		if exist, ok := exist.(*ComponentNode); ok {
 			// Re-use the existing component and update its block
-			c = exist.(*ComponentNode)
+			c = exist
 			c.UpdateBlock(block)
 		} else {
 			componentName := strings.Join(block.Name, ".")
@@ -191,6 +526,16 @@ func (l *Loader) populateGraph(g *dag.Graph, blocks []*ast.BlockStmt) diag.Diagn
 			c = NewComponentNode(l.globals, block)
 		}
 
+		if c.reg.Singleton && usesForEach(block) {
+			diags.Add(diag.Diagnostic{
+				Severity: diag.SeverityLevelError,
+				Message:  fmt.Sprintf("Component %q does not support %s", c.ComponentName(), forEachAttr),
+				StartPos: block.NamePos.Position(),
+				EndPos:   block.NamePos.Add(len(c.ComponentName()) - 1).Position(),
+			})
+			continue
+		}
+
 		g.Add(c)
 	}
 
@@ -201,7 +546,7 @@ func (l *Loader) wireGraphEdges(g *dag.Graph) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	for _, n := range g.Nodes() {
-		refs, nodeDiags := ComponentReferences(n.(*ComponentNode), g)
+		refs, nodeDiags := ComponentReferences(n.(BlockNode), g)
 		for _, ref := range refs {
 			g.AddEdge(dag.Edge{From: n, To: ref.Target})
 		}
@@ -224,6 +569,17 @@ func (l *Loader) Components() []*ComponentNode {
 	return l.components
 }
 
+// Runnables returns the current set of nodes which should be running.
+// Components which are disabled or not built are excluded, and components
+// using for_each are replaced by their instances.
+func (l *Loader) Runnables() []RunnableNode {
+	var rr []RunnableNode
+	for _, cn := range l.Components() {
+		rr = append(rr, cn.Runnables()...)
+	}
+	return rr
+}
+
 // Graph returns a copy of the DAG managed by the Loader.
 func (l *Loader) Graph() *dag.Graph {
 	l.mut.RLock()
@@ -246,7 +602,9 @@ func (l *Loader) OriginalGraph() *dag.Graph {
 // The provided parentContext can be used to provide global variables and
 // functions to components. A child context will be constructed from the parent
 // to expose values of other components.
-func (l *Loader) EvaluateDependencies(parentScope *vm.Scope, c *ComponentNode) {
+//
+// The span of the graph evaluation is started from ctx.
+func (l *Loader) EvaluateDependencies(ctx context.Context, parentScope *vm.Scope, c *ComponentNode) {
 	l.mut.RLock()
 	defer l.mut.RUnlock()
 
@@ -254,6 +612,14 @@ func (l *Loader) EvaluateDependencies(parentScope *vm.Scope, c *ComponentNode) {
 	defer l.cm.controllerEvaluation.Set(0)
 	start := time.Now()
 
+	ctx, span := l.tracer.Start(ctx, tracing.SpanGraphEvaluation, trace.WithAttributes(
+		tracing.KeyControllerID.String(l.globals.ControllerID),
+		tracing.KeyTrigger.String(tracing.TriggerExportsChange),
+		tracing.KeyOriginator.String(c.GlobalID()),
+	))
+	defer span.End()
+	var evaluated int
+
 	// Make sure we're in-sync with the current exports of c.
 	l.cache.CacheExports(c.ID(), c.Exports())
 
@@ -264,29 +630,97 @@ func (l *Loader) EvaluateDependencies(parentScope *vm.Scope, c *ComponentNode) {
 			// arguments will need re-evaluation.
 			return nil
 		}
-		_ = l.evaluate(parentScope, n.(*ComponentNode))
+		evaluated++
+		_ = l.evaluate(ctx, l.graph, parentScope, n.(BlockNode))
 		return nil
 	})
 
 	l.cm.componentEvaluationTime.Observe(time.Since(start).Seconds())
+	span.SetAttributes(tracing.KeyEvaluated.Int(evaluated))
+	l.checkModuleExports()
 }
 
-// evaluate constructs the final context for c and evaluates it. mut must be
-// held when calling evaluate.
-func (l *Loader) evaluate(parent *vm.Scope, c *ComponentNode) error {
+// evaluate constructs the final context for n and evaluates it. g is the
+// graph n belongs to. mut must be held when calling evaluate.
+//
+// The evaluation is traced as a child span of ctx.
+func (l *Loader) evaluate(ctx context.Context, g *dag.Graph, parent *vm.Scope, n BlockNode) error {
+	_, span := l.tracer.Start(ctx, tracing.SpanNodeEvaluation, trace.WithAttributes(
+		tracing.KeyNodeID.String(l.globals.GlobalID(n.NodeID())),
+	))
+	defer span.End()
+
+	// Avoid the cost of collecting attributes for spans which aren't recorded.
+	recording := span.IsRecording()
+	if recording {
+		span.SetAttributes(tracing.KeyDependencies.StringSlice(l.dependencyIDs(g, n)))
+	}
+
 	ectx := l.cache.BuildContext(parent)
-	err := c.Evaluate(ectx)
-	// Always update the cache both the arguments and exports, since both might
-	// change when a component gets re-evaluated. We also want to cache the arguments and exports in case of an error
-	l.cache.CacheArguments(c.ID(), c.Arguments())
-	l.cache.CacheExports(c.ID(), c.Exports())
+
+	var err error
+	switch n := n.(type) {
+	case *ComponentNode:
+		prevArgs := n.Arguments()
+		err = n.Evaluate(ectx)
+		if recording {
+			span.SetAttributes(tracing.KeyArgsChanged.Bool(!reflect.DeepEqual(prevArgs, n.Arguments())))
+		}
+
+		// Always update the cache both the arguments and exports, since both might
+		// change when a component gets re-evaluated. We also want to cache the arguments and exports in case of an error
+		l.cache.CacheArguments(n.ID(), n.Arguments())
+		l.cache.CacheExports(n.ID(), n.Exports())
+
+	case *ArgumentConfigNode:
+		var value any
+		err = n.Evaluate(ectx)
+		if err == nil {
+			value, err = n.Value(l.moduleArgs)
+		}
+		l.cache.CacheExports(BlockComponentID(n.Block()), argumentExports(value))
+
+	case *ExportConfigNode:
+		err = n.Evaluate(ectx)
+		l.cache.CacheModuleExport(n.Label(), n.Value())
+	}
+
 	if err != nil {
-		level.Error(l.log).Log("msg", "failed to evaluate component", "component", c.NodeID(), "err", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		level.Error(l.log).Log("msg", "failed to evaluate block", "node", n.NodeID(), "err", err)
 		return err
 	}
 	return nil
 }
 
+// dependencyIDs returns the global IDs of the nodes n directly depends on in
+// g.
+func (l *Loader) dependencyIDs(g *dag.Graph, n BlockNode) []string {
+	deps := g.Dependencies(n)
+	ids := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		ids = append(ids, l.globals.GlobalID(dep.NodeID()))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// checkModuleExports informs the owner of the Loader when the values of
+// export blocks changed since the last check. mut must be held when calling
+// checkModuleExports.
+func (l *Loader) checkModuleExports() {
+	exports := l.cache.ModuleExports()
+	if reflect.DeepEqual(l.moduleExports, exports) {
+		return
+	}
+	l.moduleExports = exports
+
+	if l.globals.OnModuleExportsChange != nil {
+		l.globals.OnModuleExportsChange(exports)
+	}
+}
+
 func multierrToDiags(errors error) diag.Diagnostics {
 	var diags diag.Diagnostics
 	for _, err := range errors.(*multierror.Error).Errors {
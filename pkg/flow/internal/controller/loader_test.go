@@ -2,12 +2,15 @@ package controller_test
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/grafana/agent/pkg/flow/internal/controller"
 	"github.com/grafana/agent/pkg/flow/internal/dag"
+	"github.com/grafana/agent/pkg/flow/internal/testcomponents"
 	"github.com/grafana/agent/pkg/river/ast"
 	"github.com/grafana/agent/pkg/river/diag"
 	"github.com/grafana/agent/pkg/river/parser"
@@ -167,6 +170,473 @@ func TestLoader(t *testing.T) {
 	})
 }
 
+func TestLoader_ChangedBlocks(t *testing.T) {
+	startFile := `
+		testcomponents.passthrough "a" {
+			input = "a"
+		}
+
+		testcomponents.passthrough "b" {
+			input = testcomponents.passthrough.a.output
+		}
+
+		testcomponents.passthrough "c" {
+			input = "c"
+		}
+	`
+
+	l := controller.NewLoader(controller.ComponentGlobals{
+		Logger:          log.NewNopLogger(),
+		DataPath:        t.TempDir(),
+		OnExportsChange: func(cn *controller.ComponentNode) { /* no-op */ },
+		Registerer:      prometheus.NewRegistry(),
+	})
+	require.NoError(t, applyFromContent(t, l, []byte(startFile)).ErrorOrNil())
+
+	evaluationTimes := func() map[string]time.Time {
+		res := make(map[string]time.Time)
+		for _, cn := range l.Components() {
+			res[cn.NodeID()] = cn.CurrentHealth().UpdateTime
+		}
+		return res
+	}
+	before := evaluationTimes()
+
+	t.Run("Unchanged blocks aren't evaluated", func(t *testing.T) {
+		reformatted := `
+			// Comments don't count as a change.
+			testcomponents.passthrough "c" { input = "c" }
+			testcomponents.passthrough "b" { input = testcomponents.passthrough.a.output }
+			testcomponents.passthrough "a" { input = "a" }
+		`
+		require.NoError(t, applyFromContent(t, l, []byte(reformatted)).ErrorOrNil())
+		require.Equal(t, before, evaluationTimes())
+	})
+
+	t.Run("Changed blocks and their dependants are evaluated", func(t *testing.T) {
+		changedFile := `
+			testcomponents.passthrough "a" {
+				input = "changed"
+			}
+
+			testcomponents.passthrough "b" {
+				input = testcomponents.passthrough.a.output
+			}
+
+			testcomponents.passthrough "c" {
+				input = "c"
+			}
+		`
+		require.NoError(t, applyFromContent(t, l, []byte(changedFile)).ErrorOrNil())
+
+		after := evaluationTimes()
+		require.NotEqual(t, before["testcomponents.passthrough.a"], after["testcomponents.passthrough.a"])
+		require.NotEqual(t, before["testcomponents.passthrough.b"], after["testcomponents.passthrough.b"])
+		require.Equal(t, before["testcomponents.passthrough.c"], after["testcomponents.passthrough.c"])
+
+		b := l.Graph().GetByID("testcomponents.passthrough.b").(*controller.ComponentNode)
+		require.Equal(t, testcomponents.PassthroughConfig{Input: "changed"}, b.Arguments())
+	})
+
+	t.Run("All blocks are evaluated with EvaluateAll", func(t *testing.T) {
+		before := evaluationTimes()
+		diags := applyFromContentWithOptions(t, l, []byte(startFile), nil, controller.ApplyOptions{EvaluateAll: true})
+		require.NoError(t, diags.ErrorOrNil())
+
+		after := evaluationTimes()
+		for id := range before {
+			require.NotEqual(t, before[id], after[id], "%s wasn't evaluated", id)
+		}
+	})
+}
+
+func TestLoader_Validate(t *testing.T) {
+	l := controller.NewLoader(controller.ComponentGlobals{
+		Logger:          log.NewNopLogger(),
+		DataPath:        t.TempDir(),
+		OnExportsChange: func(cn *controller.ComponentNode) { /* no-op */ },
+		Registerer:      prometheus.NewRegistry(),
+	})
+	require.NoError(t, applyFromContent(t, l, []byte(`
+		testcomponents.passthrough "a" {
+			input = "a"
+		}
+	`)).ErrorOrNil())
+	a := l.Graph().GetByID("testcomponents.passthrough.a").(*controller.ComponentNode)
+	block := a.Block()
+
+	tt := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "Invalid reference",
+			content: `
+				testcomponents.passthrough "a" {
+					input = testcomponents.passthrough.missing.output
+				}
+			`,
+		},
+		{
+			name: "Invalid arguments",
+			content: `
+				testcomponents.passthrough "a" {
+					input = [1, 2]
+				}
+			`,
+		},
+		{
+			name: "Cycle",
+			content: `
+				testcomponents.passthrough "a" {
+					input = testcomponents.passthrough.b.output
+				}
+
+				testcomponents.passthrough "b" {
+					input = testcomponents.passthrough.a.output
+				}
+			`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := parser.ParseFile(t.Name(), []byte(tc.content))
+			require.NoError(t, err)
+
+			var blocks []*ast.BlockStmt
+			for _, stmt := range file.Body {
+				blocks = append(blocks, stmt.(*ast.BlockStmt))
+			}
+			require.True(t, l.Validate(nil, blocks, nil).HasErrors())
+
+			// The loaded components must not be changed.
+			require.Len(t, l.Components(), 1)
+			require.Same(t, block, a.Block())
+		})
+	}
+}
+
+func TestLoader_ModuleConfigBlocks(t *testing.T) {
+	testFile := `
+		argument "input" {}
+
+		argument "optional_input" {
+			optional = true
+			default  = "default value"
+		}
+
+		testcomponents.passthrough "required" {
+			input = argument.input.value
+		}
+
+		testcomponents.passthrough "optional" {
+			input = argument.optional_input.value
+		}
+
+		export "output" {
+			value = testcomponents.passthrough.required.output
+		}
+	`
+
+	var exports map[string]any
+	newGlobals := func() controller.ComponentGlobals {
+		return controller.ComponentGlobals{
+			Logger:                log.NewNopLogger(),
+			DataPath:              t.TempDir(),
+			OnExportsChange:       func(cn *controller.ComponentNode) { /* no-op */ },
+			OnModuleExportsChange: func(e map[string]any) { exports = e },
+			Registerer:            prometheus.NewRegistry(),
+			ControllerID:          "module.string.example",
+		}
+	}
+
+	t.Run("Arguments are exposed to components", func(t *testing.T) {
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContentWithArgs(t, l, []byte(testFile), map[string]any{"input": "hello"})
+		require.NoError(t, diags.ErrorOrNil())
+
+		requireGraph(t, l.Graph(), graphDefinition{
+			Nodes: []string{
+				"argument.input",
+				"argument.optional_input",
+				"testcomponents.passthrough.required",
+				"testcomponents.passthrough.optional",
+				"export.output",
+			},
+			OutEdges: []edge{
+				{From: "testcomponents.passthrough.required", To: "argument.input"},
+				{From: "testcomponents.passthrough.optional", To: "argument.optional_input"},
+				{From: "export.output", To: "testcomponents.passthrough.required"},
+			},
+		})
+
+		require.Equal(t, map[string]any{"output": "hello"}, exports)
+
+		optional := l.Graph().GetByID("testcomponents.passthrough.optional").(*controller.ComponentNode)
+		require.Equal(t, "default value", optional.Exports().(testcomponents.PassthroughExports).Output)
+		require.Equal(t, "module.string.example/testcomponents.passthrough.optional", optional.GlobalID())
+	})
+
+	t.Run("Missing required argument", func(t *testing.T) {
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContentWithArgs(t, l, []byte(testFile), nil)
+		require.ErrorContains(t, diags.ErrorOrNil(), `missing required argument "input" to module`)
+	})
+
+	t.Run("Unknown argument", func(t *testing.T) {
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContentWithArgs(t, l, []byte(testFile), map[string]any{
+			"input":   "hello",
+			"unknown": true,
+		})
+		require.ErrorContains(t, diags.ErrorOrNil(), `unsupported arguments provided to module: "unknown"`)
+	})
+
+	t.Run("Exports cannot be referenced", func(t *testing.T) {
+		invalidFile := `
+			export "output" {
+				value = "hello"
+			}
+
+			testcomponents.passthrough "invalid" {
+				input = export.output.value
+			}
+		`
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContent(t, l, []byte(invalidFile))
+		require.ErrorContains(t, diags.ErrorOrNil(), `"export.output" cannot be referenced`)
+	})
+}
+
+func TestLoader_ForEach(t *testing.T) {
+	newGlobals := func() controller.ComponentGlobals {
+		return controller.ComponentGlobals{
+			Logger:          log.NewNopLogger(),
+			DataPath:        t.TempDir(),
+			OnExportsChange: func(cn *controller.ComponentNode) { /* no-op */ },
+			Registerer:      prometheus.NewRegistry(),
+		}
+	}
+
+	instanceOutputs := func(cn *controller.ComponentNode) map[string]string {
+		res := make(map[string]string)
+		for _, inst := range cn.Instances() {
+			res[inst.NodeID()] = inst.Exports().(testcomponents.PassthroughExports).Output
+		}
+		return res
+	}
+
+	t.Run("Object", func(t *testing.T) {
+		testFile := `
+			testcomponents.passthrough "names" {
+				for_each = { a = "first", b = "second" }
+				input    = each.key + "=" + each.value
+			}
+
+			testcomponents.passthrough "forwarded" {
+				input = testcomponents.passthrough.names["b"].output
+			}
+		`
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContent(t, l, []byte(testFile))
+		require.NoError(t, diags.ErrorOrNil())
+
+		requireGraph(t, l.Graph(), graphDefinition{
+			Nodes: []string{
+				"testcomponents.passthrough.names",
+				"testcomponents.passthrough.forwarded",
+			},
+			OutEdges: []edge{
+				{From: "testcomponents.passthrough.forwarded", To: "testcomponents.passthrough.names"},
+			},
+		})
+
+		names := l.Graph().GetByID("testcomponents.passthrough.names").(*controller.ComponentNode)
+		require.Equal(t, map[string]string{
+			"testcomponents.passthrough.names[a]": "a=first",
+			"testcomponents.passthrough.names[b]": "b=second",
+		}, instanceOutputs(names))
+		require.Len(t, l.Runnables(), 3)
+
+		forwarded := l.Graph().GetByID("testcomponents.passthrough.forwarded").(*controller.ComponentNode)
+		require.Equal(t, "b=second", forwarded.Exports().(testcomponents.PassthroughExports).Output)
+	})
+
+	t.Run("Array", func(t *testing.T) {
+		testFile := `
+			testcomponents.passthrough "names" {
+				for_each = ["first", "second"]
+				input    = each.value
+			}
+
+			testcomponents.passthrough "forwarded" {
+				input = testcomponents.passthrough.names[1].output
+			}
+		`
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContent(t, l, []byte(testFile))
+		require.NoError(t, diags.ErrorOrNil())
+
+		names := l.Graph().GetByID("testcomponents.passthrough.names").(*controller.ComponentNode)
+		require.Equal(t, map[string]string{
+			"testcomponents.passthrough.names[0]": "first",
+			"testcomponents.passthrough.names[1]": "second",
+		}, instanceOutputs(names))
+
+		forwarded := l.Graph().GetByID("testcomponents.passthrough.forwarded").(*controller.ComponentNode)
+		require.Equal(t, "second", forwarded.Exports().(testcomponents.PassthroughExports).Output)
+	})
+
+	t.Run("Instances are added and removed", func(t *testing.T) {
+		startFile := `
+			testcomponents.passthrough "names" {
+				for_each = { a = "first", b = "second" }
+				input    = each.value
+			}
+		`
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContent(t, l, []byte(startFile))
+		require.NoError(t, diags.ErrorOrNil())
+
+		names := l.Graph().GetByID("testcomponents.passthrough.names").(*controller.ComponentNode)
+		origInstance := names.Instances()[0]
+		require.Equal(t, "testcomponents.passthrough.names[a]", origInstance.NodeID())
+
+		updatedFile := `
+			testcomponents.passthrough "names" {
+				for_each = { a = "updated", c = "third" }
+				input    = each.value
+			}
+		`
+		diags = applyFromContent(t, l, []byte(updatedFile))
+		require.NoError(t, diags.ErrorOrNil())
+
+		require.Equal(t, map[string]string{
+			"testcomponents.passthrough.names[a]": "updated",
+			"testcomponents.passthrough.names[c]": "third",
+		}, instanceOutputs(names))
+
+		// Existing instances should be reused.
+		require.Equal(t, origInstance, names.Instances()[0])
+	})
+
+	t.Run("Blocks can start and stop using for_each", func(t *testing.T) {
+		singleFile := `
+			testcomponents.passthrough "names" {
+				input = "single"
+			}
+		`
+		forEachFile := `
+			testcomponents.passthrough "names" {
+				for_each = ["first"]
+				input    = each.value
+			}
+		`
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContent(t, l, []byte(singleFile))
+		require.NoError(t, diags.ErrorOrNil())
+
+		names := l.Graph().GetByID("testcomponents.passthrough.names").(*controller.ComponentNode)
+		require.Equal(t, []controller.RunnableNode{names}, names.Runnables())
+
+		diags = applyFromContent(t, l, []byte(forEachFile))
+		require.NoError(t, diags.ErrorOrNil())
+		require.Equal(t, map[string]string{"testcomponents.passthrough.names[0]": "first"}, instanceOutputs(names))
+		require.Len(t, names.Runnables(), 1)
+		require.NotEqual(t, names, names.Runnables()[0])
+
+		diags = applyFromContent(t, l, []byte(singleFile))
+		require.NoError(t, diags.ErrorOrNil())
+		require.Nil(t, names.Instances())
+		require.Equal(t, []controller.RunnableNode{names}, names.Runnables())
+		require.Equal(t, "single", names.Exports().(testcomponents.PassthroughExports).Output)
+	})
+
+	t.Run("Invalid for_each", func(t *testing.T) {
+		invalidFile := `
+			testcomponents.passthrough "names" {
+				for_each = "not a collection"
+				input    = each.value
+			}
+		`
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContent(t, l, []byte(invalidFile))
+		require.ErrorContains(t, diags.ErrorOrNil(), "for_each must be an array or object")
+	})
+
+	t.Run("each is only available with for_each", func(t *testing.T) {
+		invalidFile := `
+			testcomponents.passthrough "names" {
+				input = each.value
+			}
+		`
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContent(t, l, []byte(invalidFile))
+		require.ErrorContains(t, diags.ErrorOrNil(), `component "each.value" does not exist`)
+	})
+
+	t.Run("Singletons do not support for_each", func(t *testing.T) {
+		invalidFile := `
+			testcomponents.singleton {
+				for_each = [1, 2]
+			}
+		`
+		l := controller.NewLoader(newGlobals())
+		diags := applyFromContent(t, l, []byte(invalidFile))
+		require.ErrorContains(t, diags.ErrorOrNil(), `Component "testcomponents.singleton" does not support for_each`)
+	})
+}
+
+func TestLoader_Enabled(t *testing.T) {
+	var runnablesChanged int
+	newGlobals := func() controller.ComponentGlobals {
+		return controller.ComponentGlobals{
+			Logger:            log.NewNopLogger(),
+			DataPath:          t.TempDir(),
+			OnExportsChange:   func(cn *controller.ComponentNode) { /* no-op */ },
+			OnRunnablesChange: func() { runnablesChanged++ },
+			Registerer:        prometheus.NewRegistry(),
+		}
+	}
+
+	testFile := func(enabled bool) []byte {
+		return []byte(fmt.Sprintf(`
+			testcomponents.passthrough "toggled" {
+				enabled = %t
+				input   = "hello"
+			}
+
+			testcomponents.passthrough "forwarded" {
+				input = testcomponents.passthrough.toggled.output
+			}
+		`, enabled))
+	}
+
+	l := controller.NewLoader(newGlobals())
+	diags := applyFromContent(t, l, testFile(false))
+	require.NoError(t, diags.ErrorOrNil())
+
+	// Disabled components aren't built, but references to them remain valid.
+	toggled := l.Graph().GetByID("testcomponents.passthrough.toggled").(*controller.ComponentNode)
+	forwarded := l.Graph().GetByID("testcomponents.passthrough.forwarded").(*controller.ComponentNode)
+	require.Empty(t, toggled.Runnables())
+	require.Equal(t, "", forwarded.Exports().(testcomponents.PassthroughExports).Output)
+	require.Equal(t, 1, runnablesChanged) // Only forwarded was built
+
+	diags = applyFromContent(t, l, testFile(true))
+	require.NoError(t, diags.ErrorOrNil())
+	require.Len(t, toggled.Runnables(), 1)
+	require.Equal(t, "hello", forwarded.Exports().(testcomponents.PassthroughExports).Output)
+	require.Equal(t, 2, runnablesChanged)
+
+	diags = applyFromContent(t, l, testFile(false))
+	require.NoError(t, diags.ErrorOrNil())
+	require.Empty(t, toggled.Runnables())
+	require.Equal(t, "", forwarded.Exports().(testcomponents.PassthroughExports).Output)
+	require.Equal(t, 3, runnablesChanged)
+}
+
 // TestScopeWithFailingComponent is used to ensure that the scope is filled out, even if the component
 // fails to properly start.
 func TestScopeWithFailingComponent(t *testing.T) {
@@ -205,6 +675,16 @@ func TestScopeWithFailingComponent(t *testing.T) {
 
 func applyFromContent(t *testing.T, l *controller.Loader, bb []byte) diag.Diagnostics {
 	t.Helper()
+	return applyFromContentWithArgs(t, l, bb, nil)
+}
+
+func applyFromContentWithArgs(t *testing.T, l *controller.Loader, bb []byte, args map[string]any) diag.Diagnostics {
+	t.Helper()
+	return applyFromContentWithOptions(t, l, bb, args, controller.ApplyOptions{})
+}
+
+func applyFromContentWithOptions(t *testing.T, l *controller.Loader, bb []byte, args map[string]any, opts controller.ApplyOptions) diag.Diagnostics {
+	t.Helper()
 
 	var diags diag.Diagnostics
 
@@ -215,10 +695,14 @@ func applyFromContent(t *testing.T, l *controller.Loader, bb []byte) diag.Diagno
 		return parseDiags
 	}
 
-	var blocks []*ast.BlockStmt
+	var blocks, configBlocks []*ast.BlockStmt
 	for _, stmt := range file.Body {
 		switch stmt := stmt.(type) {
 		case *ast.BlockStmt:
+			if controller.IsConfigBlock(strings.Join(stmt.Name, ".")) {
+				configBlocks = append(configBlocks, stmt)
+				continue
+			}
 			blocks = append(blocks, stmt)
 		default:
 			diags = append(diags, diag.Diagnostic{
@@ -233,7 +717,7 @@ func applyFromContent(t *testing.T, l *controller.Loader, bb []byte) diag.Diagno
 		return diags
 	}
 
-	applyDiags := l.Apply(nil, blocks)
+	applyDiags := l.Apply(args, blocks, configBlocks, opts)
 	diags = append(diags, applyDiags...)
 
 	return diags
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/agent/pkg/river/ast"
+	"github.com/grafana/agent/pkg/river/vm"
+)
+
+// Names of meta-arguments which may be set on any component block. Meta-
+// arguments are handled by the controller and are never passed to the
+// managed component.
+const (
+	forEachAttr = "for_each"
+	enabledAttr = "enabled"
+
+	// eachVariable is the name of the variable exposed to blocks using
+	// for_each, holding the key and value of the current instance.
+	eachVariable = "each"
+)
+
+// metaArguments holds the evaluators for meta-arguments set in a component
+// block.
+type metaArguments struct {
+	forEach *vm.Evaluator // nil if for_each is unset
+	enabled *vm.Evaluator // nil if enabled is unset
+}
+
+// splitMetaArguments splits the meta-arguments out of body. The returned body
+// holds the remaining statements which are evaluated as the arguments of the
+// managed component.
+func splitMetaArguments(body ast.Body) (ast.Body, metaArguments) {
+	var (
+		rest = make(ast.Body, 0, len(body))
+		meta metaArguments
+	)
+
+	for _, stmt := range body {
+		attr, ok := stmt.(*ast.AttributeStmt)
+		if !ok {
+			rest = append(rest, stmt)
+			continue
+		}
+
+		switch attr.Name.Name {
+		case forEachAttr:
+			meta.forEach = vm.New(attr.Value)
+		case enabledAttr:
+			meta.enabled = vm.New(attr.Value)
+		default:
+			rest = append(rest, stmt)
+		}
+	}
+
+	return rest, meta
+}
+
+// usesForEach returns true if the block sets the for_each meta-argument.
+func usesForEach(b *ast.BlockStmt) bool {
+	for _, stmt := range b.Body {
+		if attr, ok := stmt.(*ast.AttributeStmt); ok && attr.Name.Name == forEachAttr {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachItem is an individual element of a for_each collection.
+type forEachItem struct {
+	key      string      // String form of the key, used in the instance ID.
+	keyValue interface{} // Key exposed to the block as each.key.
+	value    interface{} // Value exposed to the block as each.value.
+}
+
+// forEachItems converts the evaluated value of a for_each meta-argument into
+// a list of items. Arrays are keyed by element index, while objects are keyed
+// by their field names. isArray reports whether v was an array.
+func forEachItems(v interface{}) (items []forEachItem, isArray bool, err error) {
+	switch v := v.(type) {
+	case []interface{}:
+		items = make([]forEachItem, 0, len(v))
+		for i, elem := range v {
+			items = append(items, forEachItem{
+				key:      strconv.Itoa(i),
+				keyValue: i,
+				value:    elem,
+			})
+		}
+		return items, true, nil
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if strings.Contains(key, "/") {
+				return nil, false, fmt.Errorf("for_each key %q must not contain a slash", key)
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items = make([]forEachItem, 0, len(v))
+		for _, key := range keys {
+			items = append(items, forEachItem{
+				key:      key,
+				keyValue: key,
+				value:    v[key],
+			})
+		}
+		return items, false, nil
+
+	default:
+		return nil, false, fmt.Errorf("for_each must be an array or object, got %T", v)
+	}
+}
+
+// instanceScope returns a scope for evaluating the instance of a component
+// created for item.
+func instanceScope(parent *vm.Scope, item forEachItem) *vm.Scope {
+	return &vm.Scope{
+		Parent: parent,
+		Variables: map[string]interface{}{
+			eachVariable: map[string]interface{}{
+				"key":   item.keyValue,
+				"value": item.value,
+			},
+		},
+	}
+}
+
+// instanceNodeID returns the node ID of an instance of the component with
+// the given node ID, such as "local.file.example[key]".
+func instanceNodeID(nodeID, key string) string {
+	return fmt.Sprintf("%s[%s]", nodeID, key)
+}
@@ -1,6 +1,9 @@
 package controller
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/grafana/agent/component"
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // controllerMetrics contains the metrics for components controller
 type controllerMetrics struct {
@@ -38,6 +41,7 @@ func newControllerMetrics(r prometheus.Registerer) *controllerMetrics {
 type controllerCollector struct {
 	l                      *Loader
 	runningComponentsTotal *prometheus.Desc
+	componentHealth        *prometheus.Desc
 }
 
 func newControllerCollector(l *Loader) prometheus.Collector {
@@ -49,16 +53,36 @@ func newControllerCollector(l *Loader) prometheus.Collector {
 			[]string{"health_type"},
 			nil,
 		),
+		componentHealth: prometheus.NewDesc(
+			"agent_component_health",
+			"Health of a component. Set to 1 for the current health type of the component and 0 for the others.",
+			[]string{"component_id", "health_type"},
+			nil,
+		),
 	}
 }
 
+// healthTypes are the health types reported by agent_component_health.
+var healthTypes = []component.HealthType{
+	component.HealthTypeUnknown,
+	component.HealthTypeHealthy,
+	component.HealthTypeUnhealthy,
+	component.HealthTypeExited,
+}
+
 func (cc *controllerCollector) Collect(ch chan<- prometheus.Metric) {
 	componentsByHealth := make(map[string]int)
 
-	for _, component := range cc.l.Components() {
-		health := component.CurrentHealth().Health.String()
+	for _, cn := range cc.l.Components() {
+		health := cn.CurrentHealth().Health.String()
 		componentsByHealth[health]++
-		component.register.Collect(ch)
+		cn.register.Collect(ch)
+		cc.collectHealth(ch, cn)
+
+		for _, inst := range cn.Instances() {
+			inst.register.Collect(ch)
+			cc.collectHealth(ch, inst)
+		}
 	}
 
 	for health, count := range componentsByHealth {
@@ -66,6 +90,19 @@ func (cc *controllerCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// collectHealth sends the agent_component_health series for cn.
+func (cc *controllerCollector) collectHealth(ch chan<- prometheus.Metric, cn *ComponentNode) {
+	current := cn.CurrentHealth().Health
+	for _, ht := range healthTypes {
+		var val float64
+		if ht == current {
+			val = 1
+		}
+		ch <- prometheus.MustNewConstMetric(cc.componentHealth, prometheus.GaugeValue, val, cn.GlobalID(), ht.String())
+	}
+}
+
 func (cc *controllerCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- cc.runningComponentsTotal
+	ch <- cc.componentHealth
 }
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/grafana/agent/pkg/river/ast"
+	"github.com/grafana/agent/pkg/river/vm"
+)
+
+// ArgumentConfigNode is a controller node which manages an argument block of
+// a module:
+//
+//	argument "NAME" {
+//	  optional = true
+//	  default  = "VALUE"
+//	}
+//
+// The value of the argument is exposed to other blocks as argument.NAME.value.
+type ArgumentConfigNode struct {
+	label  string
+	nodeID string
+
+	mut   sync.RWMutex
+	block *ast.BlockStmt
+	eval  *vm.Evaluator
+	args  argumentBlock
+}
+
+// argumentBlock is the set of fields which can be set in an argument block.
+type argumentBlock struct {
+	Optional bool        `river:"optional,attr,optional"`
+	Default  interface{} `river:"default,attr,optional"`
+	Comment  string      `river:"comment,attr,optional"`
+}
+
+var _ BlockNode = (*ArgumentConfigNode)(nil)
+
+// NewArgumentConfigNode creates a new ArgumentConfigNode from an initial
+// ast.BlockStmt.
+func NewArgumentConfigNode(b *ast.BlockStmt) *ArgumentConfigNode {
+	return &ArgumentConfigNode{
+		label:  b.Label,
+		nodeID: BlockComponentID(b).String(),
+
+		block: b,
+		eval:  vm.New(b.Body),
+	}
+}
+
+// Label returns the name of the argument.
+func (cn *ArgumentConfigNode) Label() string { return cn.label }
+
+// NodeID implements dag.Node and returns the unique ID for this node.
+func (cn *ArgumentConfigNode) NodeID() string { return cn.nodeID }
+
+// Block implements BlockNode and returns the current block of the managed
+// argument.
+func (cn *ArgumentConfigNode) Block() *ast.BlockStmt {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.block
+}
+
+// UpdateBlock updates the River block used to evaluate the argument. The new
+// block isn't used until the next time Evaluate is invoked.
+func (cn *ArgumentConfigNode) UpdateBlock(b *ast.BlockStmt) {
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+	cn.block = b
+	cn.eval = vm.New(b.Body)
+}
+
+// Evaluate implements BlockNode and evaluates the fields of the argument
+// block.
+func (cn *ArgumentConfigNode) Evaluate(scope *vm.Scope) error {
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+
+	var args argumentBlock
+	if err := cn.eval.Evaluate(scope, &args); err != nil {
+		return fmt.Errorf("decoding River: %w", err)
+	}
+	cn.args = args
+	return nil
+}
+
+// Value returns the value of the argument given the set of values passed to
+// the module. An error is returned if the argument is required and was not
+// provided.
+func (cn *ArgumentConfigNode) Value(moduleArgs map[string]any) (any, error) {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+
+	if v, ok := moduleArgs[cn.label]; ok {
+		return v, nil
+	}
+	if !cn.args.Optional {
+		return nil, fmt.Errorf("missing required argument %q to module", cn.label)
+	}
+	return cn.args.Default, nil
+}
+
+// argumentExports returns the value to expose to other blocks for the given
+// argument value.
+func argumentExports(v any) map[string]any {
+	return map[string]any{"value": v}
+}
+
+// validateArgumentNames returns an error if moduleArgs contains any keys which
+// are not declared by an argument node in nodes.
+func validateArgumentNames(nodes []*ArgumentConfigNode, moduleArgs map[string]any) error {
+	declared := make(map[string]struct{}, len(nodes))
+	for _, n := range nodes {
+		declared[n.label] = struct{}{}
+	}
+
+	var unknown []string
+	for name := range moduleArgs {
+		if _, ok := declared[name]; !ok {
+			unknown = append(unknown, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unsupported arguments provided to module: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/grafana/agent/pkg/river/ast"
+	"github.com/grafana/agent/pkg/river/vm"
+)
+
+// ExportConfigNode is a controller node which manages an export block of a
+// module:
+//
+//	export "NAME" {
+//	  value = EXPRESSION
+//	}
+//
+// The evaluated value is exposed to the component which loaded the module.
+// Export blocks cannot be referenced by other blocks in the same module.
+type ExportConfigNode struct {
+	label  string
+	nodeID string
+
+	mut   sync.RWMutex
+	block *ast.BlockStmt
+	eval  *vm.Evaluator
+	value any
+}
+
+// exportBlock is the set of fields which can be set in an export block.
+type exportBlock struct {
+	Value interface{} `river:"value,attr"`
+}
+
+var _ BlockNode = (*ExportConfigNode)(nil)
+
+// NewExportConfigNode creates a new ExportConfigNode from an initial
+// ast.BlockStmt.
+func NewExportConfigNode(b *ast.BlockStmt) *ExportConfigNode {
+	return &ExportConfigNode{
+		label:  b.Label,
+		nodeID: BlockComponentID(b).String(),
+
+		block: b,
+		eval:  vm.New(b.Body),
+	}
+}
+
+// Label returns the name of the export.
+func (cn *ExportConfigNode) Label() string { return cn.label }
+
+// NodeID implements dag.Node and returns the unique ID for this node.
+func (cn *ExportConfigNode) NodeID() string { return cn.nodeID }
+
+// Block implements BlockNode and returns the current block of the managed
+// export.
+func (cn *ExportConfigNode) Block() *ast.BlockStmt {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.block
+}
+
+// UpdateBlock updates the River block used to evaluate the export. The new
+// block isn't used until the next time Evaluate is invoked.
+func (cn *ExportConfigNode) UpdateBlock(b *ast.BlockStmt) {
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+	cn.block = b
+	cn.eval = vm.New(b.Body)
+}
+
+// Evaluate implements BlockNode and evaluates the value of the export.
+func (cn *ExportConfigNode) Evaluate(scope *vm.Scope) error {
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+
+	var export exportBlock
+	if err := cn.eval.Evaluate(scope, &export); err != nil {
+		return fmt.Errorf("decoding River: %w", err)
+	}
+	cn.value = export.Value
+	return nil
+}
+
+// Value returns the most recently evaluated value of the export.
+func (cn *ExportConfigNode) Value() any {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.value
+}
@@ -1,6 +1,9 @@
 package controller
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 // Queue is an unordered queue of components.
 //
@@ -8,7 +11,7 @@ import "sync"
 // for later reevaluation.
 type Queue struct {
 	mut    sync.Mutex
-	queued map[*ComponentNode]struct{}
+	queued map[*ComponentNode]time.Time // Time each component was enqueued
 
 	updateCh chan struct{}
 }
@@ -17,7 +20,7 @@ type Queue struct {
 func NewQueue() *Queue {
 	return &Queue{
 		updateCh: make(chan struct{}, 1),
-		queued:   make(map[*ComponentNode]struct{}),
+		queued:   make(map[*ComponentNode]time.Time),
 	}
 }
 
@@ -26,7 +29,9 @@ func NewQueue() *Queue {
 func (q *Queue) Enqueue(c *ComponentNode) {
 	q.mut.Lock()
 	defer q.mut.Unlock()
-	q.queued[c] = struct{}{}
+	if _, queued := q.queued[c]; !queued {
+		q.queued[c] = time.Now()
+	}
 	select {
 	case q.updateCh <- struct{}{}:
 	default:
@@ -36,16 +41,16 @@ func (q *Queue) Enqueue(c *ComponentNode) {
 // Chan returns a channel which is written to when the queue is non-empty.
 func (q *Queue) Chan() <-chan struct{} { return q.updateCh }
 
-// TryDequeue dequeues a randomly queued component. TryDequeue will return nil
-// if the queue is empty.
-func (q *Queue) TryDequeue() *ComponentNode {
+// TryDequeue dequeues a randomly queued component along with the time it was
+// enqueued. TryDequeue will return nil if the queue is empty.
+func (q *Queue) TryDequeue() (*ComponentNode, time.Time) {
 	q.mut.Lock()
 	defer q.mut.Unlock()
 
-	for c := range q.queued {
+	for c, enqueuedAt := range q.queued {
 		delete(q.queued, c)
-		return c
+		return c, enqueuedAt
 	}
 
-	return nil
+	return nil, time.Time{}
 }
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -11,6 +12,26 @@ func TestEnqueueDequeue(t *testing.T) {
 	q := NewQueue()
 	q.Enqueue(tn)
 	require.Lenf(t, q.queued, 1, "queue should be 1")
-	fn := q.TryDequeue()
+	fn, _ := q.TryDequeue()
 	require.True(t, fn == tn)
 }
+
+func TestEnqueueDequeue_Time(t *testing.T) {
+	tn := &ComponentNode{}
+	q := NewQueue()
+
+	before := time.Now()
+	q.Enqueue(tn)
+	enqueuedAt := q.queued[tn]
+	require.False(t, enqueuedAt.Before(before))
+
+	// Enqueuing a component which is already queued keeps its original time.
+	q.Enqueue(tn)
+	fn, ts := q.TryDequeue()
+	require.True(t, fn == tn)
+	require.Equal(t, enqueuedAt, ts)
+
+	fn, ts = q.TryDequeue()
+	require.Nil(t, fn)
+	require.True(t, ts.IsZero())
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"sync"
+
+	"github.com/grafana/agent/pkg/flow/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RunnableNode is any dag.Node which can also be ran.
@@ -44,7 +47,10 @@ func NewScheduler() *Scheduler {
 //
 // Existing components will be restarted if they stopped since the previous
 // call to Synchronize.
-func (s *Scheduler) Synchronize(rr []RunnableNode) error {
+//
+// The number of started and stopped RunnableNodes is recorded on the span
+// in ctx, if any.
+func (s *Scheduler) Synchronize(ctx context.Context, rr []RunnableNode) error {
 	s.tasksMut.Lock()
 	defer s.tasksMut.Unlock()
 
@@ -57,6 +63,8 @@ func (s *Scheduler) Synchronize(rr []RunnableNode) error {
 		newRunnables[r.NodeID()] = r
 	}
 
+	var started, stopped int
+
 	// Stop tasks that are not defined in rr.
 	var stopping sync.WaitGroup
 	for id, t := range s.tasks {
@@ -64,6 +72,7 @@ func (s *Scheduler) Synchronize(rr []RunnableNode) error {
 			continue
 		}
 
+		stopped++
 		stopping.Add(1)
 		go func(t *task) {
 			defer stopping.Done()
@@ -94,12 +103,18 @@ func (s *Scheduler) Synchronize(rr []RunnableNode) error {
 			},
 		}
 
+		started++
 		s.running.Add(1)
 		s.tasks[nodeID] = newTask(opts)
 	}
 
 	// Wait for all stopping runnables to exit.
 	stopping.Wait()
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		tracing.KeyStarted.Int(started),
+		tracing.KeyStopped.Int(stopped),
+	)
 	return nil
 }
 
@@ -25,7 +25,7 @@ func TestScheduler_Synchronize(t *testing.T) {
 		}
 
 		sched := controller.NewScheduler()
-		sched.Synchronize([]controller.RunnableNode{
+		sched.Synchronize(context.Background(), []controller.RunnableNode{
 			fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: runFunc}},
 			fakeRunnable{ID: "component-b", Component: mockComponent{RunFunc: runFunc}},
 			fakeRunnable{ID: "component-c", Component: mockComponent{RunFunc: runFunc}},
@@ -51,7 +51,7 @@ func TestScheduler_Synchronize(t *testing.T) {
 		for i := 0; i < 10; i++ {
 			// If a new runnable is created, runFunc will panic since the WaitGroup
 			// only supports 1 goroutine.
-			sched.Synchronize([]controller.RunnableNode{
+			sched.Synchronize(context.Background(), []controller.RunnableNode{
 				fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: runFunc}},
 			})
 		}
@@ -74,12 +74,12 @@ func TestScheduler_Synchronize(t *testing.T) {
 
 		sched := controller.NewScheduler()
 
-		sched.Synchronize([]controller.RunnableNode{
+		sched.Synchronize(context.Background(), []controller.RunnableNode{
 			fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: runFunc}},
 		})
 		started.Wait()
 
-		sched.Synchronize([]controller.RunnableNode{})
+		sched.Synchronize(context.Background(), []controller.RunnableNode{})
 
 		finished.Wait()
 		require.NoError(t, sched.Close())
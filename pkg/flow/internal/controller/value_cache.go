@@ -17,6 +17,8 @@ type valueCache struct {
 	components map[string]ComponentID // NodeID -> ComponentID
 	args       map[string]interface{} // NodeID -> component arguments value
 	exports    map[string]interface{} // NodeID -> component exports value
+
+	moduleExports map[string]interface{} // Export block label -> value
 }
 
 // newValueCache cretes a new ValueCache.
@@ -25,6 +27,8 @@ func newValueCache() *valueCache {
 		components: make(map[string]ComponentID),
 		args:       make(map[string]interface{}),
 		exports:    make(map[string]interface{}),
+
+		moduleExports: make(map[string]interface{}),
 	}
 }
 
@@ -82,6 +86,43 @@ func (vc *valueCache) SyncIDs(ids []ComponentID) {
 	}
 }
 
+// CacheModuleExport caches the value of the export block with the given name.
+func (vc *valueCache) CacheModuleExport(name string, value interface{}) {
+	vc.mut.Lock()
+	defer vc.mut.Unlock()
+	vc.moduleExports[name] = value
+}
+
+// SyncModuleExports will remove any cached export block values whose name is
+// not in names.
+func (vc *valueCache) SyncModuleExports(names []string) {
+	expectMap := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		expectMap[name] = struct{}{}
+	}
+
+	vc.mut.Lock()
+	defer vc.mut.Unlock()
+
+	for name := range vc.moduleExports {
+		if _, keep := expectMap[name]; !keep {
+			delete(vc.moduleExports, name)
+		}
+	}
+}
+
+// ModuleExports returns a copy of the cached export block values.
+func (vc *valueCache) ModuleExports() map[string]interface{} {
+	vc.mut.RLock()
+	defer vc.mut.RUnlock()
+
+	exports := make(map[string]interface{}, len(vc.moduleExports))
+	for name, value := range vc.moduleExports {
+		exports[name] = value
+	}
+	return exports
+}
+
 // BuildContext builds a vm.Scope based on the current set of cached values.
 // The arguments and exports for the same ID are merged into one object.
 func (vc *valueCache) BuildContext(parent *vm.Scope) *vm.Scope {
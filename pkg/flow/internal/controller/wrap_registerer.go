@@ -63,3 +63,11 @@ func (w *wrappedRegisterer) Unregister(collector prometheus.Collector) bool {
 	delete(w.internalCollectors, collector)
 	return true
 }
+
+// unregisterAll unregisters all collectors.
+func (w *wrappedRegisterer) unregisterAll() {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	w.internalCollectors = make(map[prometheus.Collector]struct{})
+}
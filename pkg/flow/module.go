@@ -0,0 +1,125 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/river/ast"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// moduleController implements component.ModuleController. Each component
+// gets its own moduleController.
+type moduleController struct {
+	parent *Flow                 // Controller which owns the component.
+	id     string                // Global ID of the component.
+	reg    prometheus.Registerer // Registerer owned by the component.
+}
+
+var _ component.ModuleController = (*moduleController)(nil)
+
+// newModuleController creates a new moduleController for the component with
+// the given global ID.
+func newModuleController(parent *Flow, id string, reg prometheus.Registerer) *moduleController {
+	return &moduleController{parent: parent, id: id, reg: reg}
+}
+
+// NewModule implements component.ModuleController.
+func (mc *moduleController) NewModule(id string, export component.ExportFunc) (component.Module, error) {
+	if strings.Contains(id, "/") {
+		return nil, fmt.Errorf("module ID %q must not contain a slash", id)
+	}
+
+	fullID := mc.id
+	if id != "" {
+		fullID += "." + id
+	}
+
+	f, ctx := newFlow(Options{
+		Logger:         mc.parent.log,
+		DataPath:       mc.parent.opts.DataPath,
+		Reg:            mc.reg,
+		HTTPListenAddr: mc.parent.opts.HTTPListenAddr,
+		Clusterer:      mc.parent.opts.Clusterer,
+		TracerProvider: mc.parent.opts.TracerProvider,
+
+		controllerID:    fullID,
+		onExportsChange: export,
+	})
+
+	return &module{
+		parent: mc.parent,
+		f:      f,
+		ctx:    ctx,
+	}, nil
+}
+
+// module implements component.Module by wrapping a nested Flow controller.
+type module struct {
+	parent *Flow
+	f      *Flow
+	ctx    context.Context // Context used for running f.
+}
+
+var _ component.Module = (*module)(nil)
+
+// LoadConfig implements component.Module.
+func (m *module) LoadConfig(config []byte, args map[string]any) error {
+	ff, err := ReadFile(m.f.opts.controllerID, config)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range ff.Node.Body {
+		if block, ok := stmt.(*ast.BlockStmt); ok && strings.Join(block.Name, ".") == "logging" {
+			return fmt.Errorf("logging block not allowed inside a module")
+		}
+	}
+
+	return m.f.LoadFile(ff, args)
+}
+
+// Run implements component.Module.
+func (m *module) Run(ctx context.Context) {
+	m.parent.addModule(m)
+	defer m.parent.removeModule(m)
+
+	go m.f.run(m.ctx)
+
+	<-ctx.Done()
+	_ = m.f.Close()
+}
+
+// ComponentHandler implements component.Module.
+func (m *module) ComponentHandler() http.Handler {
+	return m.f.componentHandler()
+}
+
+// addModule marks a module as running so its components can be discovered.
+func (c *Flow) addModule(m *module) {
+	c.modulesMut.Lock()
+	defer c.modulesMut.Unlock()
+	c.modules[m.f.opts.controllerID] = m
+}
+
+// removeModule removes a module which is no longer running.
+func (c *Flow) removeModule(m *module) {
+	c.modulesMut.Lock()
+	defer c.modulesMut.Unlock()
+	delete(c.modules, m.f.opts.controllerID)
+}
+
+// runningModules returns the set of modules running under c.
+func (c *Flow) runningModules() []*module {
+	c.modulesMut.RLock()
+	defer c.modulesMut.RUnlock()
+
+	mods := make([]*module, 0, len(c.modules))
+	for _, m := range c.modules {
+		mods = append(mods, m)
+	}
+	return mods
+}
@@ -0,0 +1,91 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/flow/internal/testcomponents"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/grafana/agent/component/module/string" // Include module.string
+)
+
+const testModule = `
+	argument "input" {}
+
+	testcomponents.passthrough "inner" {
+		input = argument.input.value
+	}
+
+	export "output" {
+		value = testcomponents.passthrough.inner.output
+	}
+`
+
+func TestModule(t *testing.T) {
+	content := fmt.Sprintf(`
+		testcomponents.passthrough "input" {
+			input = "hello, world!"
+		}
+
+		module.string "example" {
+			content   = %s
+			arguments = {
+				input = testcomponents.passthrough.input.output,
+			}
+		}
+
+		testcomponents.passthrough "output" {
+			input = module.string.example.exports.output
+		}
+	`, strconv.Quote(testModule))
+
+	ctrl, ctx := newFlow(testOptions(t))
+
+	f, err := ReadFile(t.Name(), []byte(content))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	// The module's exports should be immediately available after loading
+	// without needing to run the components.
+	in, out := getFields(t, ctrl.loader.Graph(), "testcomponents.passthrough.output")
+	require.Equal(t, "hello, world!", in.(testcomponents.PassthroughConfig).Input)
+	require.Equal(t, "hello, world!", out.(testcomponents.PassthroughExports).Output)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go ctrl.run(ctx)
+	defer func() { _ = ctrl.Close() }()
+
+	// Components inside of the module should be reported with module-scoped
+	// IDs once the module is running.
+	require.Eventually(t, func() bool {
+		for _, info := range ctrl.ComponentInfos() {
+			if info.ID == "module.string.example/testcomponents.passthrough.inner" {
+				return info.ModuleID == "module.string.example"
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NotNil(t, ctrl.findComponent("module.string.example/testcomponents.passthrough.inner"))
+}
+
+func TestModule_InvalidContent(t *testing.T) {
+	content := fmt.Sprintf(`
+		module.string "example" {
+			content = %s
+		}
+	`, strconv.Quote(testModule))
+
+	ctrl, _ := newFlow(testOptions(t))
+
+	f, err := ReadFile(t.Name(), []byte(content))
+	require.NoError(t, err)
+
+	err = ctrl.LoadFile(f, nil)
+	require.ErrorContains(t, err, `missing required argument "input" to module`)
+}
@@ -27,6 +27,7 @@ var (
 	_ river.Capsule                = OptionalSecret{}
 	_ river.ConvertibleIntoCapsule = OptionalSecret{}
 	_ river.ConvertibleFromCapsule = (*OptionalSecret)(nil)
+	_ river.SecretCapsule          = OptionalSecret{}
 
 	_ builder.Tokenizer = OptionalSecret{}
 )
@@ -34,6 +35,10 @@ var (
 // RiverCapsule marks OptionalSecret as a RiverCapsule.
 func (s OptionalSecret) RiverCapsule() {}
 
+// RevealSecret returns the Value of the OptionalSecret, regardless of whether
+// IsSecret is set. It is used by the nonsensitive standard library function.
+func (s OptionalSecret) RevealSecret() string { return s.Value }
+
 // ConvertInto converts the OptionalSecret and stores it into the Go value
 // pointed at by dst. OptionalSecrets can always be converted into *Secret.
 // OptionalSecrets can only be converted into *string if IsSecret is false. In
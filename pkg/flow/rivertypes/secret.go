@@ -20,6 +20,7 @@ var (
 	_ river.Capsule                = Secret("")
 	_ river.ConvertibleIntoCapsule = Secret("")
 	_ river.ConvertibleFromCapsule = (*Secret)(nil)
+	_ river.SecretCapsule          = Secret("")
 
 	_ builder.Tokenizer = Secret("")
 )
@@ -27,6 +28,10 @@ var (
 // RiverCapsule marks Secret as a RiverCapsule.
 func (s Secret) RiverCapsule() {}
 
+// RevealSecret returns the sensitive value of the Secret. It is used by the
+// nonsensitive standard library function.
+func (s Secret) RevealSecret() string { return string(s) }
+
 // ConvertInto converts the Secret and stores it into the Go value pointed at
 // by dst. Secrets can be converted into *OptionalSecret. In other cases, this
 // method will return an explicit error or river.ErrNoConversion.
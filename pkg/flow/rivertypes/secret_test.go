@@ -30,6 +30,41 @@ func TestSecret(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, rivertypes.Secret("Hello, world!"), s)
 	})
+
+	t.Run("secrets can be converted to strings with nonsensitive", func(t *testing.T) {
+		expr, err := parser.ParseExpression("nonsensitive(val)")
+		require.NoError(t, err)
+
+		var s string
+		err = vm.New(expr).Evaluate(&vm.Scope{
+			Variables: map[string]interface{}{
+				"val": rivertypes.Secret("Hello, world!"),
+			},
+		}, &s)
+		require.NoError(t, err)
+		require.Equal(t, "Hello, world!", s)
+	})
+
+	for _, input := range []string{
+		`format("%s", val)`,
+		`json_encode(val)`,
+		`json_encode({ a = val })`,
+	} {
+		t.Run("secrets cannot be converted to strings with "+input, func(t *testing.T) {
+			expr, err := parser.ParseExpression(input)
+			require.NoError(t, err)
+
+			var s string
+			err = vm.New(expr).Evaluate(&vm.Scope{
+				Variables: map[string]interface{}{
+					"val": rivertypes.Secret("Hello, world!"),
+				},
+			}, &s)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "secrets may not be converted into strings")
+			require.Empty(t, s)
+		})
+	}
 }
 
 func decodeTo(t *testing.T, input interface{}, target interface{}) error {
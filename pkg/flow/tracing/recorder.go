@@ -0,0 +1,354 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Evaluation is a graph evaluation recorded by a Recorder.
+type Evaluation struct {
+	ControllerID string           `json:"controller_id,omitempty"`
+	Trigger      string           `json:"trigger"`
+	Originator   string           `json:"originator,omitempty"`
+	Start        time.Time        `json:"start"`
+	Duration     time.Duration    `json:"duration"`
+	QueueWait    time.Duration    `json:"queue_wait,omitempty"`
+	Nodes        []NodeEvaluation `json:"nodes"` // Evaluated nodes in evaluation order.
+}
+
+// NodeEvaluation is the evaluation of a single node within an Evaluation.
+type NodeEvaluation struct {
+	ID               string        `json:"id"`
+	Start            time.Time     `json:"start"`
+	Duration         time.Duration `json:"duration"`
+	Dependencies     []string      `json:"dependencies,omitempty"`
+	ArgumentsChanged bool          `json:"arguments_changed"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// SlowestChain returns the chain of dependent nodes in e with the largest
+// combined evaluation time, starting from the node evaluated first.
+func (e Evaluation) SlowestChain() []NodeEvaluation {
+	var (
+		byID  = make(map[string]NodeEvaluation, len(e.Nodes))
+		total = make(map[string]time.Duration, len(e.Nodes))
+		prev  = make(map[string]string, len(e.Nodes))
+		end   string
+	)
+
+	// Nodes are evaluated in topological order, so the dependencies of a node
+	// have always been visited before the node itself.
+	for _, n := range e.Nodes {
+		byID[n.ID] = n
+
+		var slowest time.Duration
+		for _, dep := range n.Dependencies {
+			t, ok := total[dep]
+			if !ok {
+				// The dependency wasn't evaluated.
+				continue
+			}
+			if _, set := prev[n.ID]; !set || t > slowest {
+				slowest = t
+				prev[n.ID] = dep
+			}
+		}
+
+		total[n.ID] = slowest + n.Duration
+		if end == "" || total[n.ID] > total[end] {
+			end = n.ID
+		}
+	}
+
+	var chain []NodeEvaluation
+	for id := end; id != ""; id = prev[id] {
+		chain = append([]NodeEvaluation{byID[id]}, chain...)
+	}
+	return chain
+}
+
+// ComponentStats summarizes the evaluations of a node across the graph
+// evaluations held by a Recorder.
+type ComponentStats struct {
+	ID               string        `json:"id"`
+	Evaluations      int           `json:"evaluations"`
+	Total            time.Duration `json:"total"`
+	Max              time.Duration `json:"max"`
+	ArgumentsChanged int           `json:"arguments_changed"`
+	Errors           int           `json:"errors"`
+	LastError        string        `json:"last_error,omitempty"`
+}
+
+// Mean returns the mean evaluation time of the node.
+func (cs ComponentStats) Mean() time.Duration {
+	if cs.Evaluations == 0 {
+		return 0
+	}
+	return cs.Total / time.Duration(cs.Evaluations)
+}
+
+// Recorder is an sdktrace.SpanProcessor which keeps the graph evaluations
+// from recent traces of the Flow controller in memory. Recorder implements
+// http.Handler to expose the slowest components and dependency chains of the
+// recorded evaluations.
+type Recorder struct {
+	size int
+
+	mut         sync.Mutex
+	pending     map[trace.TraceID][]sdktrace.ReadOnlySpan // Ended spans of in-progress traces.
+	evaluations []Evaluation                              // Oldest first.
+}
+
+var (
+	_ sdktrace.SpanProcessor = (*Recorder)(nil)
+	_ http.Handler           = (*Recorder)(nil)
+)
+
+// NewRecorder creates a Recorder which holds the size most recent graph
+// evaluations.
+func NewRecorder(size int) *Recorder {
+	if size <= 0 {
+		size = DefaultOptions.RecordedEvaluations
+	}
+	return &Recorder{
+		size:    size,
+		pending: make(map[trace.TraceID][]sdktrace.ReadOnlySpan),
+	}
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (r *Recorder) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd implements sdktrace.SpanProcessor. Spans are held until the root
+// span of their trace ends, after which the graph evaluation of the trace is
+// recorded.
+func (r *Recorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	traceID := s.SpanContext().TraceID()
+	spans := append(r.pending[traceID], s)
+	if s.Parent().IsValid() && !s.Parent().IsRemote() {
+		r.pending[traceID] = spans
+		return
+	}
+	delete(r.pending, traceID)
+
+	ev, ok := buildEvaluation(spans)
+	if !ok {
+		return
+	}
+	r.evaluations = append(r.evaluations, ev)
+	if len(r.evaluations) > r.size {
+		r.evaluations = r.evaluations[len(r.evaluations)-r.size:]
+	}
+}
+
+// buildEvaluation builds an Evaluation from the spans of a trace. ok is false
+// if the trace doesn't contain a graph evaluation.
+func buildEvaluation(spans []sdktrace.ReadOnlySpan) (ev Evaluation, ok bool) {
+	var (
+		graph     sdktrace.ReadOnlySpan
+		queueWait time.Duration
+	)
+	for _, s := range spans {
+		switch s.Name() {
+		case SpanGraphEvaluation:
+			graph = s
+		case SpanQueueWait:
+			queueWait = s.EndTime().Sub(s.StartTime())
+		}
+	}
+	if graph == nil {
+		return ev, false
+	}
+
+	attrs := attribute.NewSet(graph.Attributes()...)
+	ev = Evaluation{
+		ControllerID: attributeValue(attrs, KeyControllerID).AsString(),
+		Trigger:      attributeValue(attrs, KeyTrigger).AsString(),
+		Originator:   attributeValue(attrs, KeyOriginator).AsString(),
+		Start:        graph.StartTime(),
+		Duration:     graph.EndTime().Sub(graph.StartTime()),
+		QueueWait:    queueWait,
+		Nodes:        make([]NodeEvaluation, 0),
+	}
+
+	for _, s := range spans {
+		if s.Name() != SpanNodeEvaluation || s.Parent().SpanID() != graph.SpanContext().SpanID() {
+			continue
+		}
+
+		attrs := attribute.NewSet(s.Attributes()...)
+		ne := NodeEvaluation{
+			ID:               attributeValue(attrs, KeyNodeID).AsString(),
+			Start:            s.StartTime(),
+			Duration:         s.EndTime().Sub(s.StartTime()),
+			Dependencies:     attributeValue(attrs, KeyDependencies).AsStringSlice(),
+			ArgumentsChanged: attributeValue(attrs, KeyArgsChanged).AsBool(),
+		}
+		if s.Status().Code == codes.Error {
+			ne.Error = s.Status().Description
+		}
+		ev.Nodes = append(ev.Nodes, ne)
+	}
+
+	sort.SliceStable(ev.Nodes, func(i, j int) bool {
+		return ev.Nodes[i].Start.Before(ev.Nodes[j].Start)
+	})
+	return ev, true
+}
+
+// Shutdown implements sdktrace.SpanProcessor.
+func (r *Recorder) Shutdown(context.Context) error { return nil }
+
+// ForceFlush implements sdktrace.SpanProcessor.
+func (r *Recorder) ForceFlush(context.Context) error { return nil }
+
+// Evaluations returns the recorded graph evaluations, newest first.
+func (r *Recorder) Evaluations() []Evaluation {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	res := make([]Evaluation, 0, len(r.evaluations))
+	for i := len(r.evaluations) - 1; i >= 0; i-- {
+		res = append(res, r.evaluations[i])
+	}
+	return res
+}
+
+// ComponentStats summarizes the evaluations of every node across the
+// recorded graph evaluations. Nodes are sorted by their slowest evaluation,
+// slowest first.
+func (r *Recorder) ComponentStats() []ComponentStats {
+	evaluations := r.Evaluations()
+
+	byID := make(map[string]*ComponentStats)
+	// Evaluations are newest first, so iterate in reverse to keep the newest
+	// error as the last one.
+	for i := len(evaluations) - 1; i >= 0; i-- {
+		for _, n := range evaluations[i].Nodes {
+			cs, ok := byID[n.ID]
+			if !ok {
+				cs = &ComponentStats{ID: n.ID}
+				byID[n.ID] = cs
+			}
+
+			cs.Evaluations++
+			cs.Total += n.Duration
+			if n.Duration > cs.Max {
+				cs.Max = n.Duration
+			}
+			if n.ArgumentsChanged {
+				cs.ArgumentsChanged++
+			}
+			if n.Error != "" {
+				cs.Errors++
+				cs.LastError = n.Error
+			}
+		}
+	}
+
+	res := make([]ComponentStats, 0, len(byID))
+	for _, cs := range byID {
+		res = append(res, *cs)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Max != res[j].Max {
+			return res[i].Max > res[j].Max
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// ServeHTTP implements http.Handler. It writes the slowest components and
+// the slowest dependency chain of every recorded graph evaluation as plain
+// text, or as JSON when the format query parameter is set to json.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var (
+		stats       = r.ComponentStats()
+		evaluations = r.Evaluations()
+	)
+
+	if req.URL.Query().Get("format") == "json" {
+		bb, err := json.Marshal(struct {
+			Components  []ComponentStats `json:"components"`
+			Evaluations []Evaluation     `json:"evaluations"`
+		}{stats, evaluations})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(bb)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writeText(w, stats, evaluations)
+}
+
+func writeText(w io.Writer, stats []ComponentStats, evaluations []Evaluation) {
+	fmt.Fprintf(w, "Slowest components over the last %d graph evaluations:\n\n", len(evaluations))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tEVALUATIONS\tMAX\tMEAN\tTOTAL\tARGUMENT CHANGES\tERRORS")
+	for _, cs := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%d\t%d\n", cs.ID, cs.Evaluations, cs.Max, cs.Mean(), cs.Total, cs.ArgumentsChanged, cs.Errors)
+	}
+	_ = tw.Flush()
+
+	fmt.Fprint(w, "\nRecent graph evaluations, newest first:\n")
+	for _, ev := range evaluations {
+		fmt.Fprintf(w, "\n%s  %s", ev.Start.Format(time.RFC3339Nano), ev.Trigger)
+		if ev.Originator != "" {
+			fmt.Fprintf(w, " from %s", ev.Originator)
+		}
+		if ev.ControllerID != "" {
+			fmt.Fprintf(w, " in %s", ev.ControllerID)
+		}
+		fmt.Fprintf(w, ": %d nodes in %s", len(ev.Nodes), ev.Duration)
+		if ev.QueueWait > 0 {
+			fmt.Fprintf(w, " (queued for %s)", ev.QueueWait)
+		}
+		fmt.Fprintln(w)
+
+		if chain := ev.SlowestChain(); len(chain) > 0 {
+			var (
+				links []string
+				total time.Duration
+			)
+			for _, n := range chain {
+				links = append(links, fmt.Sprintf("%s (%s)", n.ID, n.Duration))
+				total += n.Duration
+			}
+			fmt.Fprintf(w, "    slowest chain (%s): %s\n", total, strings.Join(links, " -> "))
+		}
+		for _, n := range ev.Nodes {
+			if n.Error != "" {
+				fmt.Fprintf(w, "    error in %s: %s\n", n.ID, n.Error)
+			}
+		}
+	}
+}
+
+// attributeValue returns the value of key in set. An empty value is returned
+// if key isn't in set.
+func attributeValue(set attribute.Set, key attribute.Key) attribute.Value {
+	v, _ := set.Value(key)
+	return v
+}
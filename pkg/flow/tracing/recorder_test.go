@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder(2)
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer(t.Name())
+
+	start := time.Now()
+	ctx, update := tracer.Start(context.Background(), SpanComponentUpdate, trace.WithTimestamp(start))
+	_, wait := tracer.Start(ctx, SpanQueueWait, trace.WithTimestamp(start))
+	wait.End(trace.WithTimestamp(start.Add(time.Second)))
+
+	ctx, graph := tracer.Start(ctx, SpanGraphEvaluation, trace.WithAttributes(
+		KeyTrigger.String(TriggerExportsChange),
+		KeyOriginator.String("a"),
+	))
+	evaluateNode(ctx, tracer, "b", []string{"a"}, start.Add(time.Second), 2*time.Second, nil)
+	evaluateNode(ctx, tracer, "c", []string{"b"}, start.Add(3*time.Second), time.Second, errors.New("bad"))
+	graph.End()
+	update.End()
+
+	// Spans are only recorded after the root span ends.
+	evaluations := rec.Evaluations()
+	require.Len(t, evaluations, 1)
+
+	ev := evaluations[0]
+	require.Equal(t, TriggerExportsChange, ev.Trigger)
+	require.Equal(t, "a", ev.Originator)
+	require.Equal(t, time.Second, ev.QueueWait)
+	require.Len(t, ev.Nodes, 2)
+	require.Equal(t, NodeEvaluation{
+		ID:               "b",
+		Start:            start.Add(time.Second),
+		Duration:         2 * time.Second,
+		Dependencies:     []string{"a"},
+		ArgumentsChanged: true,
+	}, ev.Nodes[0])
+	require.Equal(t, "bad", ev.Nodes[1].Error)
+
+	stats := rec.ComponentStats()
+	require.Len(t, stats, 2)
+	require.Equal(t, "b", stats[0].ID)
+	require.Equal(t, 1, stats[1].Errors)
+	require.Equal(t, "bad", stats[1].LastError)
+
+	// Traces without a graph evaluation aren't recorded.
+	_, sync := tracer.Start(context.Background(), SpanSynchronize)
+	sync.End()
+	require.Len(t, rec.Evaluations(), 1)
+
+	// Only the most recent evaluations are kept.
+	for i := 0; i < 3; i++ {
+		_, graph := tracer.Start(context.Background(), SpanGraphEvaluation, trace.WithAttributes(
+			KeyTrigger.String(TriggerLoad),
+		))
+		graph.End()
+	}
+	evaluations = rec.Evaluations()
+	require.Len(t, evaluations, 2)
+	require.Equal(t, TriggerLoad, evaluations[0].Trigger)
+	require.Equal(t, TriggerLoad, evaluations[1].Trigger)
+}
+
+func evaluateNode(ctx context.Context, tracer trace.Tracer, id string, deps []string, start time.Time, dur time.Duration, err error) {
+	_, span := tracer.Start(ctx, SpanNodeEvaluation, trace.WithTimestamp(start), trace.WithAttributes(
+		KeyNodeID.String(id),
+		KeyDependencies.StringSlice(deps),
+		KeyArgsChanged.Bool(true),
+	))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(start.Add(dur)))
+}
+
+func TestEvaluation_SlowestChain(t *testing.T) {
+	// a -> b -> d is slower than a -> c -> d, even though c is the slowest
+	// node after a.
+	ev := Evaluation{
+		Nodes: []NodeEvaluation{
+			{ID: "a", Duration: time.Second},
+			{ID: "c", Duration: 3 * time.Second, Dependencies: []string{"a"}},
+			{ID: "b", Duration: 2 * time.Second, Dependencies: []string{"a"}},
+			{ID: "e", Duration: 4 * time.Second, Dependencies: []string{"b"}},
+			{ID: "d", Duration: time.Second, Dependencies: []string{"b", "c", "unevaluated"}},
+			{ID: "f", Duration: time.Second},
+		},
+	}
+
+	var ids []string
+	for _, n := range ev.SlowestChain() {
+		ids = append(ids, n.ID)
+	}
+	require.Equal(t, []string{"a", "b", "e"}, ids)
+
+	require.Empty(t, Evaluation{}.SlowestChain())
+}
+
+func TestRecorder_ServeHTTP(t *testing.T) {
+	rec := NewRecorder(10)
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer(t.Name())
+
+	start := time.Now()
+	ctx, graph := tracer.Start(context.Background(), SpanGraphEvaluation, trace.WithAttributes(
+		KeyTrigger.String(TriggerLoad),
+	))
+	evaluateNode(ctx, tracer, "a", nil, start, time.Second, nil)
+	evaluateNode(ctx, tracer, "b", []string{"a"}, start.Add(time.Second), time.Second, errors.New("bad"))
+	graph.End()
+
+	rr := httptest.NewRecorder()
+	rec.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/flow/evaluations", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "slowest chain (2s): a (1s) -> b (1s)")
+	require.Contains(t, rr.Body.String(), "error in b: bad")
+
+	rr = httptest.NewRecorder()
+	rec.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/flow/evaluations?format=json", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	require.Contains(t, rr.Body.String(), `"trigger":"load"`)
+}
@@ -0,0 +1,139 @@
+// Package tracing instruments the Flow controller with OpenTelemetry spans.
+//
+// The controller emits a span for every graph evaluation, with a child span
+// for every node evaluated as part of it. Graph evaluations caused by a
+// component updating its exports are wrapped in a span which also covers the
+// time the component spent in the update queue. Spans may be exported to an
+// OTLP endpoint, and recent graph evaluations are kept in memory so they can
+// be inspected through the HTTP handler of a Recorder.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer used by the Flow controller.
+const InstrumentationName = "github.com/grafana/agent/pkg/flow"
+
+// Names of spans emitted by the Flow controller.
+const (
+	// SpanGraphEvaluation covers the evaluation of a set of nodes in a
+	// controller's graph.
+	SpanGraphEvaluation = "GraphEvaluation"
+
+	// SpanNodeEvaluation covers the evaluation of a single node. It is a child
+	// of a SpanGraphEvaluation span.
+	SpanNodeEvaluation = "NodeEvaluation"
+
+	// SpanComponentUpdate covers handling a component which updated its
+	// exports, from the time it was queued until its dependants were
+	// evaluated.
+	SpanComponentUpdate = "ComponentUpdate"
+
+	// SpanQueueWait covers the time a component which updated its exports
+	// spent in the update queue. It is a child of a SpanComponentUpdate span.
+	SpanQueueWait = "QueueWait"
+
+	// SpanSynchronize covers synchronizing the set of running components.
+	SpanSynchronize = "Synchronize"
+)
+
+// Values of the KeyTrigger attribute.
+const (
+	TriggerLoad          = "load"           // A config file was loaded.
+	TriggerExportsChange = "exports_change" // A component updated its exports.
+)
+
+// Attributes set on spans emitted by the Flow controller.
+const (
+	KeyControllerID = attribute.Key("flow.controller_id") // ID of the module which owns the controller.
+	KeyTrigger      = attribute.Key("flow.trigger")       // Cause of a graph evaluation.
+	KeyOriginator   = attribute.Key("flow.originator")    // Component whose exports changed.
+	KeyEvaluated    = attribute.Key("flow.evaluated")     // Number of nodes evaluated.
+	KeySkipped      = attribute.Key("flow.skipped")       // Number of nodes skipped.
+	KeyNodeID       = attribute.Key("flow.node_id")       // Global ID of a node.
+	KeyDependencies = attribute.Key("flow.dependencies")  // Global IDs of the direct dependencies of a node.
+	KeyArgsChanged  = attribute.Key("flow.args_changed")  // Whether a component's arguments changed.
+	KeyRunnables    = attribute.Key("flow.runnables")     // Number of nodes which should be running.
+	KeyStarted      = attribute.Key("flow.started")       // Number of nodes started by the scheduler.
+	KeyStopped      = attribute.Key("flow.stopped")       // Number of nodes stopped by the scheduler.
+)
+
+// Options configures a Provider.
+type Options struct {
+	// OTLPEndpoint is the host:port of an OTLP gRPC endpoint to export spans
+	// to. Spans aren't exported when empty.
+	OTLPEndpoint string
+
+	// OTLPInsecure disables TLS when connecting to OTLPEndpoint.
+	OTLPInsecure bool
+
+	// SamplingFraction is the fraction of graph evaluations to trace, between
+	// 0 and 1. Only sampled graph evaluations are exported or recorded.
+	SamplingFraction float64
+
+	// RecordedEvaluations is the number of recent graph evaluations kept in
+	// memory.
+	RecordedEvaluations int
+}
+
+// DefaultOptions holds defaults for creating a Provider.
+var DefaultOptions = Options{
+	SamplingFraction:    1,
+	RecordedEvaluations: 100,
+}
+
+// Provider is a trace.TracerProvider which records recent graph evaluations
+// and optionally exports spans to an OTLP endpoint.
+type Provider struct {
+	*sdktrace.TracerProvider
+	rec *Recorder
+}
+
+var _ trace.TracerProvider = (*Provider)(nil)
+
+// New creates a new Provider. Call Shutdown to flush spans to the OTLP
+// endpoint and release resources.
+func New(o Options) (*Provider, error) {
+	if o.SamplingFraction < 0 || o.SamplingFraction > 1 {
+		return nil, fmt.Errorf("sampling fraction must be between 0 and 1, got %v", o.SamplingFraction)
+	}
+
+	rec := NewRecorder(o.RecordedEvaluations)
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.SamplingFraction))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String("grafana-agent"),
+		)),
+		sdktrace.WithSpanProcessor(rec),
+	}
+
+	if o.OTLPEndpoint != "" {
+		clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(o.OTLPEndpoint)}
+		if o.OTLPInsecure {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+		exp, err := otlptracegrpc.New(context.Background(), clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+
+	return &Provider{
+		TracerProvider: sdktrace.NewTracerProvider(opts...),
+		rec:            rec,
+	}, nil
+}
+
+// Recorder returns the Recorder holding recent graph evaluations.
+func (p *Provider) Recorder() *Recorder { return p.rec }
@@ -0,0 +1,31 @@
+package blackbox_exporter
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeConfigs_Module(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.BlackboxTargets = []BlackboxTarget{
+		{Name: "default", Target: "example.com"},
+		{Name: "icmp", Target: "example.com", Module: "icmp"},
+	}
+
+	i, err := New(log.NewNopLogger(), &cfg)
+	require.NoError(t, err)
+
+	scrapeConfigs := i.ScrapeConfigs()
+	require.Len(t, scrapeConfigs, 2)
+
+	// Targets without a module are probed with the default module of
+	// blackbox_exporter.
+	require.Equal(t, "blackbox/default", scrapeConfigs[0].JobName)
+	require.Equal(t, url.Values{"target": {"example.com"}}, scrapeConfigs[0].QueryParams)
+
+	require.Equal(t, "blackbox/icmp", scrapeConfigs[1].JobName)
+	require.Equal(t, url.Values{"target": {"example.com"}, "module": {"icmp"}}, scrapeConfigs[1].QueryParams)
+}
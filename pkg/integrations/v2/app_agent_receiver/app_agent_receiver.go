@@ -22,6 +22,7 @@ import (
 type appAgentReceiverIntegration struct {
 	integrations.MetricsIntegration
 	appAgentReceiverHandler AppAgentReceiverHandler
+	sourceMapStore          *RealSourceMapStore
 	logger                  log.Logger
 	conf                    *Config
 	reg                     prometheus.Registerer
@@ -47,12 +48,12 @@ func (c *Config) NewIntegration(l log.Logger, globals integrations.Globals) (int
 
 	receiverMetricsExporter := NewReceiverMetricsExporter(reg)
 
-	var exp = []appAgentReceiverExporter{
+	var exp = []AppAgentReceiverExporter{
 		receiverMetricsExporter,
 	}
 
 	if len(c.LogsInstance) > 0 {
-		getLogsInstance := func() (logsInstance, error) {
+		getLogsInstance := func() (LogsInstance, error) {
 			instance := globals.Logs.Instance(c.LogsInstance)
 			if instance == nil {
 				return nil, fmt.Errorf("logs instance \"%s\" not found", c.LogsInstance)
@@ -99,7 +100,10 @@ func (c *Config) NewIntegration(l log.Logger, globals integrations.Globals) (int
 		exp = append(exp, tracesExporter)
 	}
 
-	handler := NewAppAgentReceiverHandler(c, exp, reg)
+	handler, err := NewAppAgentReceiverHandler(c, exp, reg)
+	if err != nil {
+		return nil, err
+	}
 
 	metricsIntegration, err := metricsutils.NewMetricsHandlerIntegration(l, c, c.Common, globals, promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
 	if err != nil {
@@ -136,6 +140,7 @@ func (c *Config) NewIntegration(l log.Logger, globals integrations.Globals) (int
 	return &appAgentReceiverIntegration{
 		MetricsIntegration:      metricsIntegration,
 		appAgentReceiverHandler: handler,
+		sourceMapStore:          sourcemapStore,
 		logger:                  l,
 		conf:                    c,
 		reg:                     reg,
@@ -151,6 +156,7 @@ func (c *Config) NewIntegration(l log.Logger, globals integrations.Globals) (int
 func (i *appAgentReceiverIntegration) RunIntegration(ctx context.Context) error {
 	r := mux.NewRouter()
 	r.Handle("/collect", i.appAgentReceiverHandler.HTTPHandler(i.logger)).Methods("POST", "OPTIONS")
+	r.PathPrefix("/sourcemaps/").Handler(NewSourceMapHandler(i.logger, i.sourceMapStore, i.conf.Server.UploadAPIKey))
 
 	mw := middleware.Instrument{
 		RouteMatcher:     r,
@@ -1,6 +1,8 @@
 package app_agent_receiver
 
 import (
+	"path/filepath"
+	"regexp"
 	"time"
 
 	"github.com/grafana/agent/pkg/integrations/v2"
@@ -16,6 +18,9 @@ const (
 	DefaultRateLimitingBurstiness = 50
 	// DefaultMaxPayloadSize is the max paylad size in bytes
 	DefaultMaxPayloadSize = 5e6
+	// DefaultSourceMapCacheMaxSize is the default max size in bytes of
+	// sourcemaps cached in memory
+	DefaultSourceMapCacheMaxSize = 100 << 20
 )
 
 // DefaultConfig holds the default configuration of the receiver
@@ -37,6 +42,11 @@ var DefaultConfig = Config{
 	SourceMaps: SourceMapConfig{
 		DownloadFromOrigins: []string{"*"},
 		DownloadTimeout:     time.Second,
+		Cache: SourceMapCacheConfig{
+			MaxSize:     DefaultSourceMapCacheMaxSize,
+			TTL:         24 * time.Hour,
+			NotFoundTTL: 5 * time.Minute,
+		},
 	},
 }
 
@@ -47,6 +57,7 @@ type ServerConfig struct {
 	CORSAllowedOrigins    []string           `yaml:"cors_allowed_origins,omitempty"`
 	RateLimiting          RateLimitingConfig `yaml:"rate_limiting,omitempty"`
 	APIKey                string             `yaml:"api_key,omitempty"`
+	UploadAPIKey          string             `yaml:"upload_api_key,omitempty"`
 	MaxAllowedPayloadSize int64              `yaml:"max_allowed_payload_size,omitempty"`
 }
 
@@ -63,12 +74,64 @@ type SourceMapFileLocation struct {
 	MinifiedPathPrefix string `yaml:"minified_path_prefix,omitempty"`
 }
 
+// SourceMapCacheConfig configures caching of source maps
+type SourceMapCacheConfig struct {
+	MaxSize     int64         `yaml:"max_size,omitempty"`
+	TTL         time.Duration `yaml:"ttl,omitempty"`
+	NotFoundTTL time.Duration `yaml:"not_found_ttl,omitempty"`
+	Directory   string        `yaml:"directory,omitempty"`
+}
+
 // SourceMapConfig configure source map locations
 type SourceMapConfig struct {
 	Download            bool                    `yaml:"download"`
 	DownloadFromOrigins []string                `yaml:"download_origins,omitempty"`
 	DownloadTimeout     time.Duration           `yaml:"download_timeout,omitempty"`
 	FileSystem          []SourceMapFileLocation `yaml:"filesystem,omitempty"`
+	Cache               SourceMapCacheConfig    `yaml:"cache,omitempty"`
+}
+
+// ValidationConfig configures validation of payloads
+type ValidationConfig struct {
+	Schema string `yaml:"schema,omitempty"`
+}
+
+// SamplingRule configures the ratio of items of an app and kind to keep
+type SamplingRule struct {
+	App   string  `yaml:"app,omitempty"`
+	Kind  string  `yaml:"kind,omitempty"`
+	Ratio float64 `yaml:"ratio"`
+}
+
+// SamplingConfig configures sampling of payload items
+type SamplingConfig struct {
+	Rules []SamplingRule `yaml:"rules,omitempty"`
+}
+
+// ScrubbingRule configures how to scrub values of payload fields
+type ScrubbingRule struct {
+	Fields      []string `yaml:"fields"`
+	Keys        string   `yaml:"keys,omitempty"`
+	Pattern     string   `yaml:"pattern,omitempty"`
+	Action      string   `yaml:"action"`
+	Replacement string   `yaml:"replacement,omitempty"`
+}
+
+// ScrubbingConfig configures scrubbing of personal data from payloads
+type ScrubbingConfig struct {
+	AllowedUserAttributes []string        `yaml:"allowed_user_attributes,omitempty"`
+	AllowedQueryParams    []string        `yaml:"allowed_query_params,omitempty"`
+	AllowedLogContext     []string        `yaml:"allowed_log_context,omitempty"`
+	HashSalt              string          `yaml:"hash_salt,omitempty"`
+	Rules                 []ScrubbingRule `yaml:"rules,omitempty"`
+}
+
+// ProcessingConfig configures how payloads are processed before they are
+// exported
+type ProcessingConfig struct {
+	Validation ValidationConfig `yaml:"validation,omitempty"`
+	Sampling   SamplingConfig   `yaml:"sampling,omitempty"`
+	Scrubbing  ScrubbingConfig  `yaml:"scrubbing,omitempty"`
 }
 
 // Config is the configuration struct of the
@@ -81,6 +144,7 @@ type Config struct {
 	LogsLabels      map[string]string    `yaml:"logs_labels,omitempty"`
 	LogsSendTimeout time.Duration        `yaml:"logs_send_timeout,omitempty"`
 	SourceMaps      SourceMapConfig      `yaml:"sourcemaps,omitempty"`
+	Processing      ProcessingConfig     `yaml:"processing,omitempty"`
 }
 
 // UnmarshalYAML implements the Unmarshaler interface
@@ -88,7 +152,10 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	*c = DefaultConfig
 	c.LogsLabels = make(map[string]string)
 	type plain Config
-	return unmarshal((*plain)(c))
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	return c.Processing.Validate()
 }
 
 // IntegrationName is the name of this integration
@@ -97,12 +164,23 @@ var IntegrationName = "app_agent_receiver"
 // ApplyDefaults applies runtime-specific defaults to c.
 func (c *Config) ApplyDefaults(globals integrations.Globals) error {
 	c.Common.ApplyDefaults(globals.SubsystemOpts.Metrics.Autoscrape)
-	if id, err := c.Identifier(globals); err == nil {
+	id, err := c.Identifier(globals)
+	if err == nil {
 		c.Common.InstanceKey = &id
 	}
+
+	// Persist sourcemaps under the data directory of the agent, with a
+	// directory per instance of the integration.
+	if c.SourceMaps.Cache.Directory == "" && err == nil && globals.Metrics != nil && globals.Metrics.Config().WALDir != "" {
+		c.SourceMaps.Cache.Directory = filepath.Join(globals.Metrics.Config().WALDir, IntegrationName, unsafePathChars.ReplaceAllString(id, "_"), "sourcemaps")
+	}
 	return nil
 }
 
+// unsafePathChars matches characters of integration identifiers which aren't
+// safe to use in directory names, like the colon of hostname:port.
+var unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
 // Name returns the name of the integration that this config represents
 func (c *Config) Name() string { return IntegrationName }
 
@@ -1,8 +1,13 @@
 package app_agent_receiver
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/go-kit/log"
+	"github.com/grafana/agent/pkg/integrations/v2"
+	"github.com/grafana/agent/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
 )
@@ -77,3 +82,24 @@ logs_labels:
 	}, cfg2.LogsLabels)
 	require.Equal(t, []string{"*"}, cfg2.SourceMaps.DownloadFromOrigins)
 }
+
+func TestConfig_ApplyDefaultsSourceMapCacheDirectory(t *testing.T) {
+	walDir := t.TempDir()
+	metricsCfg := metrics.DefaultConfig
+	metricsCfg.WALDir = walDir
+	agent, err := metrics.New(prometheus.NewRegistry(), metricsCfg, log.NewNopLogger())
+	require.NoError(t, err)
+	defer agent.Stop()
+
+	globals := integrations.Globals{AgentIdentifier: "localhost:12345", Metrics: agent}
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`{}`), &cfg))
+	require.NoError(t, cfg.ApplyDefaults(globals))
+	require.Equal(t, filepath.Join(walDir, "app_agent_receiver", "localhost_12345", "sourcemaps"), cfg.SourceMaps.Cache.Directory)
+
+	// A configured directory is kept.
+	require.NoError(t, yaml.Unmarshal([]byte(`{sourcemaps: {cache: {directory: /var/lib/sourcemaps}}}`), &cfg))
+	require.NoError(t, cfg.ApplyDefaults(globals))
+	require.Equal(t, "/var/lib/sourcemaps", cfg.SourceMaps.Cache.Directory)
+}
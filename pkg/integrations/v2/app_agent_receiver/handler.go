@@ -6,6 +6,7 @@ import (
 
 	"crypto/subtle"
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/go-kit/log"
@@ -17,21 +18,30 @@ import (
 
 const apiKeyHeader = "x-api-key"
 
-type appAgentReceiverExporter interface {
+// AppAgentReceiverExporter is implemented by exporters which receive the
+// payloads accepted by AppAgentReceiverHandler.
+type AppAgentReceiverExporter interface {
 	Name() string
 	Export(ctx context.Context, payload Payload) error
 }
 
 // AppAgentReceiverHandler struct controls the data ingestion http handler of the receiver
 type AppAgentReceiverHandler struct {
-	exporters               []appAgentReceiverExporter
+	exporters               []AppAgentReceiverExporter
 	config                  *Config
 	rateLimiter             *rate.Limiter
+	processor               *payloadProcessor
 	exporterErrorsCollector *prometheus.CounterVec
 }
 
-// NewAppAgentReceiverHandler creates a new AppReceiver instance based on the given configuration
-func NewAppAgentReceiverHandler(conf *Config, exporters []appAgentReceiverExporter, reg prometheus.Registerer) AppAgentReceiverHandler {
+// NewAppAgentReceiverHandler creates a new AppReceiver instance based on the given configuration.
+// An error is returned if the processing configuration is invalid.
+func NewAppAgentReceiverHandler(conf *Config, exporters []AppAgentReceiverExporter, reg prometheus.Registerer) (AppAgentReceiverHandler, error) {
+	processor, err := newPayloadProcessor(&conf.Processing, reg)
+	if err != nil {
+		return AppAgentReceiverHandler{}, err
+	}
+
 	var rateLimiter *rate.Limiter
 	if conf.Server.RateLimiting.Enabled {
 		var rps float64
@@ -57,16 +67,19 @@ func NewAppAgentReceiverHandler(conf *Config, exporters []appAgentReceiverExport
 		exporters:               exporters,
 		config:                  conf,
 		rateLimiter:             rateLimiter,
+		processor:               processor,
 		exporterErrorsCollector: exporterErrorsCollector,
-	}
+	}, nil
 }
 
 // HTTPHandler is the http.Handler for the receiver. It will do the following
 // 0. Enable CORS for the configured hosts
 // 1. Check if the request should be rate limited
 // 2. Verify that the payload size is within limits
-// 3. Start two go routines for exporters processing and exporting data respectively
-// 4. Respond with 202 once all the work is done
+// 3. Validate the payload against the configured schema
+// 4. Sample and scrub the payload
+// 5. Start two go routines for exporters processing and exporting data respectively
+// 6. Respond with 202 once all the work is done
 func (ar *AppAgentReceiverHandler) HTTPHandler(logger log.Logger) http.Handler {
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check rate limiting state
@@ -83,24 +96,44 @@ func (ar *AppAgentReceiverHandler) HTTPHandler(logger log.Logger) http.Handler {
 			return
 		}
 
-		// Verify content length. We trust net/http to give us the correct number
-		if ar.config.Server.MaxAllowedPayloadSize > 0 && r.ContentLength > ar.config.Server.MaxAllowedPayloadSize {
+		// Verify content length. Requests without a content length, such as
+		// chunked requests, are limited while reading the body.
+		maxSize := ar.config.Server.MaxAllowedPayloadSize
+		if maxSize > 0 && r.ContentLength > maxSize {
 			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
 			return
 		}
 
-		var p Payload
-		err := json.NewDecoder(r.Body).Decode(&p)
+		reader := r.Body
+		if maxSize > 0 {
+			reader = http.MaxBytesReader(w, r.Body, maxSize)
+		}
+		body, err := io.ReadAll(reader)
 		if err != nil {
+			if maxSize > 0 {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			} else {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+			return
+		}
+		if err := ar.processor.validate(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var p Payload
+		if err := json.Unmarshal(body, &p); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		ar.processor.process(&p)
 
 		var wg sync.WaitGroup
 
 		for _, exporter := range ar.exporters {
 			wg.Add(1)
-			go func(exp appAgentReceiverExporter) {
+			go func(exp AppAgentReceiverExporter) {
 				defer wg.Done()
 				if err := exp.Export(r.Context(), p); err != nil {
 					level.Error(logger).Log("msg", "exporter error", "exporter", exp.Name(), "error", err)
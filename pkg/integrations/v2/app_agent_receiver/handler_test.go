@@ -64,7 +64,8 @@ func TestMultipleExportersAllSucceed(t *testing.T) {
 
 	conf := &Config{}
 
-	fr := NewAppAgentReceiverHandler(conf, []appAgentReceiverExporter{&exporter1, &exporter2}, reg)
+	fr, err := NewAppAgentReceiverHandler(conf, []AppAgentReceiverExporter{&exporter1, &exporter2}, reg)
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(log.NewNopLogger())
 
 	rr := httptest.NewRecorder()
@@ -97,7 +98,8 @@ func TestMultipleExportersOneFails(t *testing.T) {
 
 	conf := &Config{}
 
-	fr := NewAppAgentReceiverHandler(conf, []appAgentReceiverExporter{&exporter1, &exporter2}, reg)
+	fr, err := NewAppAgentReceiverHandler(conf, []AppAgentReceiverExporter{&exporter1, &exporter2}, reg)
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(log.NewNopLogger())
 
 	rr := httptest.NewRecorder()
@@ -139,7 +141,8 @@ func TestMultipleExportersAllFail(t *testing.T) {
 
 	conf := &Config{}
 
-	fr := NewAppAgentReceiverHandler(conf, []appAgentReceiverExporter{&exporter1, &exporter2}, reg)
+	fr, err := NewAppAgentReceiverHandler(conf, []AppAgentReceiverExporter{&exporter1, &exporter2}, reg)
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(log.NewNopLogger())
 
 	rr := httptest.NewRecorder()
@@ -174,7 +177,8 @@ func TestNoContentLengthLimitSet(t *testing.T) {
 
 	req.ContentLength = 89348593894
 
-	fr := NewAppAgentReceiverHandler(conf, []appAgentReceiverExporter{}, reg)
+	fr, err := NewAppAgentReceiverHandler(conf, []AppAgentReceiverExporter{}, reg)
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(nil)
 
 	rr := httptest.NewRecorder()
@@ -195,7 +199,33 @@ func TestLargePayload(t *testing.T) {
 		},
 	}
 
-	fr := NewAppAgentReceiverHandler(conf, []appAgentReceiverExporter{}, reg)
+	fr, err := NewAppAgentReceiverHandler(conf, []AppAgentReceiverExporter{}, reg)
+	require.NoError(t, err)
+	handler := fr.HTTPHandler(nil)
+
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Result().StatusCode)
+}
+
+func TestLargePayloadWithoutContentLength(t *testing.T) {
+	req, err := http.NewRequest("POST", "/collect", bytes.NewBuffer([]byte(PAYLOAD)))
+	require.NoError(t, err)
+	reg := prometheus.NewRegistry()
+
+	conf := &Config{
+		Server: ServerConfig{
+			MaxAllowedPayloadSize: 10,
+		},
+	}
+
+	// Chunked requests don't have a content length, so the body must be
+	// limited while it's read.
+	req.ContentLength = -1
+
+	fr, err := NewAppAgentReceiverHandler(conf, []AppAgentReceiverExporter{}, reg)
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(nil)
 
 	rr := httptest.NewRecorder()
@@ -217,7 +247,8 @@ func TestAPIKeyRequiredButNotProvided(t *testing.T) {
 		},
 	}
 
-	fr := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	fr, err := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(nil)
 
 	rr := httptest.NewRecorder()
@@ -240,7 +271,8 @@ func TestAPIKeyWrong(t *testing.T) {
 		},
 	}
 
-	fr := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	fr, err := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(nil)
 
 	rr := httptest.NewRecorder()
@@ -263,7 +295,8 @@ func TestAPIKeyCorrect(t *testing.T) {
 		},
 	}
 
-	fr := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	fr, err := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(nil)
 
 	rr := httptest.NewRecorder()
@@ -289,7 +322,8 @@ func TestRateLimiterNoReject(t *testing.T) {
 		},
 	}
 
-	fr := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	fr, err := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(nil)
 
 	rr := httptest.NewRecorder()
@@ -309,7 +343,8 @@ func TestRateLimiterReject(t *testing.T) {
 		},
 	}
 
-	fr := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	fr, err := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(nil)
 
 	makeRequest := func() *httptest.ResponseRecorder {
@@ -346,7 +381,8 @@ func TestRateLimiterDisabled(t *testing.T) {
 		},
 	}
 
-	fr := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	fr, err := NewAppAgentReceiverHandler(conf, nil, prometheus.NewRegistry())
+	require.NoError(t, err)
 	handler := fr.HTTPHandler(nil)
 
 	rr := httptest.NewRecorder()
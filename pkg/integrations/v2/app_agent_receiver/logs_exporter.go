@@ -14,13 +14,13 @@ import (
 	prommodel "github.com/prometheus/common/model"
 )
 
-// logsInstance is an interface with capability to send log entries
-type logsInstance interface {
+// LogsInstance is an interface with capability to send log entries
+type LogsInstance interface {
 	SendEntry(entry api.Entry, dur time.Duration) bool
 }
 
 // logsInstanceGetter is a function that returns a LogsInstance to send log entries to
-type logsInstanceGetter func() (logsInstance, error)
+type logsInstanceGetter func() (LogsInstance, error)
 
 // LogsExporterConfig holds the configuration of the logs exporter
 type LogsExporterConfig struct {
@@ -40,7 +40,7 @@ type LogsExporter struct {
 
 // NewLogsExporter creates a new logs exporter with the given
 // configuration
-func NewLogsExporter(logger kitlog.Logger, conf LogsExporterConfig, sourceMapStore SourceMapStore) appAgentReceiverExporter {
+func NewLogsExporter(logger kitlog.Logger, conf LogsExporterConfig, sourceMapStore SourceMapStore) AppAgentReceiverExporter {
 	return &LogsExporter{
 		logger:           logger,
 		getLogsInstance:  conf.GetLogsInstance,
@@ -135,6 +135,6 @@ func (le *LogsExporter) labelSet(kv *KeyVal) prommodel.LabelSet {
 
 // Static typecheck tests
 var (
-	_ appAgentReceiverExporter = (*LogsExporter)(nil)
-	_ logsInstance             = (*logs.Instance)(nil)
+	_ AppAgentReceiverExporter = (*LogsExporter)(nil)
+	_ LogsInstance             = (*logs.Instance)(nil)
 )
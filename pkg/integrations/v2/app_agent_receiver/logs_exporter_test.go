@@ -53,7 +53,7 @@ func TestExportLogs(t *testing.T) {
 	logsExporter := NewLogsExporter(
 		logger,
 		LogsExporterConfig{
-			GetLogsInstance: func() (logsInstance, error) { return inst, nil },
+			GetLogsInstance: func() (LogsInstance, error) { return inst, nil },
 			Labels: map[string]string{
 				"app":  "frontend",
 				"kind": "",
@@ -0,0 +1,487 @@
+package app_agent_receiver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/minio/pkg/wildcard"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xeipuuv/gojsonschema"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Kinds of payload items which can be sampled
+const (
+	KindLog         = "log"
+	KindException   = "exception"
+	KindMeasurement = "measurement"
+	KindEvent       = "event"
+	KindTrace       = "trace"
+)
+
+// Fields of payloads which can be scrubbed
+const (
+	FieldUserEmail      = "user_email"
+	FieldUserID         = "user_id"
+	FieldUserUsername   = "user_username"
+	FieldUserAttributes = "user_attributes"
+	FieldPageURL        = "page_url"
+	FieldLogContext     = "log_context"
+	FieldExceptionValue = "exception_value"
+	FieldSpanAttributes = "span_attributes"
+)
+
+// Actions of scrubbing rules
+const (
+	ScrubActionReplace = "replace"
+	ScrubActionHash    = "hash"
+	ScrubActionDrop    = "drop"
+)
+
+// DefaultScrubReplacement is the default replacement of the replace action
+const DefaultScrubReplacement = "[REDACTED]"
+
+// maxValidationErrors is the max number of validation errors returned to
+// clients.
+const maxValidationErrors = 5
+
+var (
+	validKinds  = []string{KindLog, KindException, KindMeasurement, KindEvent, KindTrace}
+	validFields = []string{
+		FieldUserEmail, FieldUserID, FieldUserUsername, FieldUserAttributes,
+		FieldPageURL, FieldLogContext, FieldExceptionValue, FieldSpanAttributes,
+	}
+	validActions = []string{ScrubActionReplace, ScrubActionHash, ScrubActionDrop}
+)
+
+// Validate returns an error if c can't be used to process payloads.
+func (c *ProcessingConfig) Validate() error {
+	_, err := compileProcessing(c)
+	return err
+}
+
+type compiledProcessing struct {
+	schema        *gojsonschema.Schema
+	samplingRules []SamplingRule
+	scrubbing     *ScrubbingConfig
+	scrubRules    []compiledScrubRule
+}
+
+type compiledScrubRule struct {
+	ScrubbingRule
+	fields      map[string]struct{}
+	keys        *regexp.Regexp
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func compileProcessing(c *ProcessingConfig) (*compiledProcessing, error) {
+	res := &compiledProcessing{
+		samplingRules: c.Sampling.Rules,
+		scrubbing:     &c.Scrubbing,
+	}
+
+	if c.Validation.Schema != "" {
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(c.Validation.Schema))
+		if err != nil {
+			return nil, fmt.Errorf("invalid payload schema: %w", err)
+		}
+		res.schema = schema
+	}
+
+	for _, rule := range c.Sampling.Rules {
+		if rule.Kind != "" && !containsString(validKinds, rule.Kind) {
+			return nil, fmt.Errorf("invalid sampling kind %q, must be one of %s", rule.Kind, strings.Join(validKinds, ", "))
+		}
+		if rule.Ratio < 0 || rule.Ratio > 1 {
+			return nil, fmt.Errorf("sampling ratio must be between 0 and 1, got %v", rule.Ratio)
+		}
+	}
+
+	for _, rule := range c.Scrubbing.Rules {
+		compiled := compiledScrubRule{
+			ScrubbingRule: rule,
+			fields:        make(map[string]struct{}, len(rule.Fields)),
+			replacement:   rule.Replacement,
+		}
+		if len(rule.Fields) == 0 {
+			return nil, fmt.Errorf("scrubbing rule must have at least one field")
+		}
+		for _, f := range rule.Fields {
+			if !containsString(validFields, f) {
+				return nil, fmt.Errorf("invalid scrubbing field %q, must be one of %s", f, strings.Join(validFields, ", "))
+			}
+			compiled.fields[f] = struct{}{}
+		}
+		if !containsString(validActions, rule.Action) {
+			return nil, fmt.Errorf("invalid scrubbing action %q, must be one of %s", rule.Action, strings.Join(validActions, ", "))
+		}
+		if compiled.replacement == "" {
+			compiled.replacement = DefaultScrubReplacement
+		}
+
+		var err error
+		if rule.Keys != "" {
+			if compiled.keys, err = regexp.Compile("^(?:" + rule.Keys + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid scrubbing keys regex %q: %w", rule.Keys, err)
+			}
+		}
+		if rule.Pattern != "" {
+			if compiled.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+				return nil, fmt.Errorf("invalid scrubbing pattern regex %q: %w", rule.Pattern, err)
+			}
+		}
+		res.scrubRules = append(res.scrubRules, compiled)
+	}
+
+	return res, nil
+}
+
+// payloadProcessor validates, samples and scrubs payloads before they are
+// passed to exporters.
+type payloadProcessor struct {
+	compiledProcessing
+	random func() float64
+
+	invalidPayloads *prometheus.CounterVec
+	sampledOut      *prometheus.CounterVec
+	scrubbed        *prometheus.CounterVec
+}
+
+// newPayloadProcessor creates a payloadProcessor. An error is returned if
+// conf is invalid.
+func newPayloadProcessor(conf *ProcessingConfig, reg prometheus.Registerer) (*payloadProcessor, error) {
+	compiled, err := compileProcessing(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &payloadProcessor{
+		compiledProcessing: *compiled,
+		random:             rand.Float64,
+
+		invalidPayloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "app_agent_receiver_invalid_payloads_total",
+			Help: "Total number of payloads rejected by validation, by reason",
+		}, []string{"reason"}),
+		sampledOut: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "app_agent_receiver_sampled_out_total",
+			Help: "Total number of items dropped by sampling, by kind",
+		}, []string{"kind"}),
+		scrubbed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "app_agent_receiver_scrubbed_values_total",
+			Help: "Total number of values scrubbed from payloads, by field and action",
+		}, []string{"field", "action"}),
+	}
+	reg.MustRegister(p.invalidPayloads, p.sampledOut, p.scrubbed)
+	return p, nil
+}
+
+// validate checks that the request body matches the configured schema.
+func (p *payloadProcessor) validate(body []byte) error {
+	if p.schema == nil {
+		return nil
+	}
+
+	res, err := p.schema.Validate(gojsonschema.NewBytesLoader(body))
+	if err != nil {
+		p.invalidPayloads.WithLabelValues("malformed").Inc()
+		return err
+	}
+	if res.Valid() {
+		return nil
+	}
+
+	p.invalidPayloads.WithLabelValues("schema").Inc()
+	errs := make([]string, 0, maxValidationErrors)
+	for i, e := range res.Errors() {
+		if i == maxValidationErrors {
+			errs = append(errs, fmt.Sprintf("and %d more errors", len(res.Errors())-maxValidationErrors))
+			break
+		}
+		errs = append(errs, e.String())
+	}
+	return fmt.Errorf("payload does not match schema: %s", strings.Join(errs, "; "))
+}
+
+// process samples and scrubs the payload in place.
+func (p *payloadProcessor) process(payload *Payload) {
+	p.sample(payload)
+	p.scrub(payload)
+}
+
+func (p *payloadProcessor) sample(payload *Payload) {
+	if len(p.samplingRules) == 0 {
+		return
+	}
+	app := payload.Meta.App.Name
+
+	logs := payload.Logs[:0]
+	for _, l := range payload.Logs {
+		if p.keepItem(app, KindLog) {
+			logs = append(logs, l)
+		}
+	}
+	payload.Logs = logs
+
+	exceptions := payload.Exceptions[:0]
+	for _, e := range payload.Exceptions {
+		if p.keepItem(app, KindException) {
+			exceptions = append(exceptions, e)
+		}
+	}
+	payload.Exceptions = exceptions
+
+	measurements := payload.Measurements[:0]
+	for _, m := range payload.Measurements {
+		if p.keepItem(app, KindMeasurement) {
+			measurements = append(measurements, m)
+		}
+	}
+	payload.Measurements = measurements
+
+	events := payload.Events[:0]
+	for _, e := range payload.Events {
+		if p.keepItem(app, KindEvent) {
+			events = append(events, e)
+		}
+	}
+	payload.Events = events
+
+	// Traces of a payload are sampled together, so that spans of a trace
+	// aren't partially dropped.
+	if payload.Traces != nil && !p.keep(app, KindTrace) {
+		p.sampledOut.WithLabelValues(KindTrace).Add(float64(payload.Traces.SpanCount()))
+		payload.Traces = nil
+	}
+}
+
+// keepItem returns whether an item is kept by sampling, counting items
+// which aren't.
+func (p *payloadProcessor) keepItem(app string, kind string) bool {
+	if p.keep(app, kind) {
+		return true
+	}
+	p.sampledOut.WithLabelValues(kind).Inc()
+	return false
+}
+
+// keep returns whether an item of the given app and kind is kept by
+// sampling. The ratio of the first matching rule is used, and items which
+// don't match any rule are always kept.
+func (p *payloadProcessor) keep(app string, kind string) bool {
+	for _, rule := range p.samplingRules {
+		if rule.Kind != "" && rule.Kind != kind {
+			continue
+		}
+		if rule.App != "" && !wildcard.Match(rule.App, app) {
+			continue
+		}
+		return rule.Ratio >= 1 || p.random() < rule.Ratio
+	}
+	return true
+}
+
+func (p *payloadProcessor) scrub(payload *Payload) {
+	if len(p.scrubRules) == 0 && !p.scrubbing.hasAllowlists() {
+		return
+	}
+
+	user := &payload.Meta.User
+	user.Email = p.scrubValue(FieldUserEmail, user.Email)
+	user.ID = p.scrubValue(FieldUserID, user.ID)
+	user.Username = p.scrubValue(FieldUserUsername, user.Username)
+	user.Attributes = p.scrubMap(FieldUserAttributes, p.scrubbing.AllowedUserAttributes, user.Attributes)
+
+	payload.Meta.Page.URL = p.scrubURL(payload.Meta.Page.URL)
+
+	for i := range payload.Logs {
+		payload.Logs[i].Context = p.scrubMap(FieldLogContext, p.scrubbing.AllowedLogContext, payload.Logs[i].Context)
+	}
+	for i := range payload.Exceptions {
+		payload.Exceptions[i].Value = p.scrubValue(FieldExceptionValue, payload.Exceptions[i].Value)
+	}
+	if payload.Traces != nil {
+		p.scrubTraces(payload.Traces.Traces)
+	}
+}
+
+// scrubTraces applies the rules for span attributes to the attributes of
+// spans and span events. Only string attributes are scrubbed.
+func (p *payloadProcessor) scrubTraces(traces ptrace.Traces) {
+	rss := traces.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				p.scrubAttributes(span.Attributes())
+
+				events := span.Events()
+				for l := 0; l < events.Len(); l++ {
+					p.scrubAttributes(events.At(l).Attributes())
+				}
+			}
+		}
+	}
+}
+
+// scrubAttributes applies the rules for span attributes to the string values
+// of attrs.
+func (p *payloadProcessor) scrubAttributes(attrs pcommon.Map) {
+	attrs.RemoveIf(func(k string, v pcommon.Value) bool {
+		if v.Type() != pcommon.ValueTypeStr || v.Str() == "" {
+			return false
+		}
+		scrubbed := p.scrubKeyValue(FieldSpanAttributes, k, v.Str())
+		if scrubbed == "" {
+			return true
+		}
+		v.SetStr(scrubbed)
+		return false
+	})
+}
+
+// scrubValue applies the rules for field to a value which doesn't have a
+// key.
+func (p *payloadProcessor) scrubValue(field string, value string) string {
+	for _, rule := range p.scrubRules {
+		if value == "" {
+			break
+		}
+		if _, ok := rule.fields[field]; !ok {
+			continue
+		}
+		value = p.applyRule(field, &rule, value)
+	}
+	return value
+}
+
+// scrubMap applies the allowlist and rules for field to the values of m. A
+// nil allowlist allows all keys.
+func (p *payloadProcessor) scrubMap(field string, allowlist []string, m map[string]string) map[string]string {
+	for k, v := range m {
+		if allowlist != nil && !containsString(allowlist, k) {
+			delete(m, k)
+			p.scrubbed.WithLabelValues(field, "allowlist").Inc()
+			continue
+		}
+		if v = p.scrubKeyValue(field, k, v); v == "" {
+			delete(m, k)
+		} else {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// scrubURL applies the allowlist and rules for page URLs to the query
+// parameters of rawURL.
+func (p *payloadProcessor) scrubURL(rawURL string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+
+	query := u.Query()
+	changed := false
+	for k, values := range query {
+		if p.scrubbing.AllowedQueryParams != nil && !containsString(p.scrubbing.AllowedQueryParams, k) {
+			query.Del(k)
+			p.scrubbed.WithLabelValues(FieldPageURL, "allowlist").Inc()
+			changed = true
+			continue
+		}
+
+		kept := values[:0]
+		for _, v := range values {
+			scrubbed := p.scrubKeyValue(FieldPageURL, k, v)
+			changed = changed || scrubbed != v
+			if scrubbed != "" {
+				kept = append(kept, scrubbed)
+			}
+		}
+		if len(kept) == 0 {
+			query.Del(k)
+		} else {
+			query[k] = kept
+		}
+	}
+
+	// Only re-encode the query if needed, as encoding may reorder it.
+	if !changed {
+		return rawURL
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// scrubKeyValue applies the rules for field which match key to value.
+func (p *payloadProcessor) scrubKeyValue(field string, key string, value string) string {
+	for _, rule := range p.scrubRules {
+		if value == "" {
+			break
+		}
+		if _, ok := rule.fields[field]; !ok {
+			continue
+		}
+		if rule.keys != nil && !rule.keys.MatchString(key) {
+			continue
+		}
+		value = p.applyRule(field, &rule, value)
+	}
+	return value
+}
+
+// applyRule applies rule to value. If the rule has a pattern, only parts of
+// value matching the pattern are replaced or hashed, and value is only
+// dropped if it matches the pattern.
+func (p *payloadProcessor) applyRule(field string, rule *compiledScrubRule, value string) string {
+	if rule.pattern != nil && !rule.pattern.MatchString(value) {
+		return value
+	}
+	p.scrubbed.WithLabelValues(field, rule.Action).Inc()
+
+	switch rule.Action {
+	case ScrubActionDrop:
+		return ""
+	case ScrubActionHash:
+		if rule.pattern == nil {
+			return p.hash(value)
+		}
+		return rule.pattern.ReplaceAllStringFunc(value, p.hash)
+	default:
+		if rule.pattern == nil {
+			return rule.replacement
+		}
+		return rule.pattern.ReplaceAllString(value, rule.replacement)
+	}
+}
+
+func (p *payloadProcessor) hash(value string) string {
+	sum := sha256.Sum256([]byte(p.scrubbing.HashSalt + value))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (c *ScrubbingConfig) hasAllowlists() bool {
+	return c.AllowedUserAttributes != nil || c.AllowedQueryParams != nil || c.AllowedLogContext != nil
+}
+
+func containsString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
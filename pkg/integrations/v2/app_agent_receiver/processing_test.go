@@ -0,0 +1,259 @@
+package app_agent_receiver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"gopkg.in/yaml.v2"
+)
+
+func TestProcessing_Validation(t *testing.T) {
+	conf := &Config{
+		Processing: ProcessingConfig{
+			Validation: ValidationConfig{
+				Schema: `{
+					"type": "object",
+					"required": ["meta"],
+					"properties": {
+						"meta": {
+							"type": "object",
+							"required": ["app"],
+							"properties": {
+								"app": {"type": "object", "required": ["name"]}
+							}
+						}
+					}
+				}`,
+			},
+		},
+	}
+
+	exporter := &TestExporter{name: "exporter"}
+	fr, err := NewAppAgentReceiverHandler(conf, []AppAgentReceiverExporter{exporter}, prometheus.NewRegistry())
+	require.NoError(t, err)
+	handler := fr.HTTPHandler(log.NewNopLogger())
+
+	for _, tc := range []struct {
+		body   string
+		status int
+	}{
+		{`{"meta": {"app": {"name": "frontend"}}}`, http.StatusAccepted},
+		{`{"meta": {"app": {}}}`, http.StatusBadRequest},
+		{`{"logs": []}`, http.StatusBadRequest},
+		{`{"meta": `, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/collect", bytes.NewBufferString(tc.body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, tc.status, rr.Code, tc.body)
+	}
+
+	require.Len(t, exporter.payloads, 1)
+	require.Equal(t, 2.0, testutil.ToFloat64(fr.processor.invalidPayloads.WithLabelValues("schema")))
+	require.Equal(t, 1.0, testutil.ToFloat64(fr.processor.invalidPayloads.WithLabelValues("malformed")))
+}
+
+func TestProcessing_Sampling(t *testing.T) {
+	p, err := newPayloadProcessor(&ProcessingConfig{
+		Sampling: SamplingConfig{
+			Rules: []SamplingRule{
+				{App: "frontend", Kind: KindLog, Ratio: 0.5},
+				{App: "front*", Kind: KindTrace, Ratio: 0},
+				{Kind: KindEvent, Ratio: 0},
+			},
+		},
+	}, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	// Alternate between sampling in and out.
+	var calls int
+	p.random = func() float64 {
+		calls++
+		if calls%2 == 0 {
+			return 0.9
+		}
+		return 0.1
+	}
+
+	payload := loadTestPayload(t)
+	payload.Meta.App.Name = "frontend"
+	require.Len(t, payload.Logs, 2)
+	require.Len(t, payload.Events, 2)
+	require.NotNil(t, payload.Traces)
+
+	p.process(&payload)
+
+	require.Len(t, payload.Logs, 1)
+	require.Len(t, payload.Exceptions, 1)
+	require.Len(t, payload.Measurements, 1)
+	require.Len(t, payload.Events, 0)
+	require.Nil(t, payload.Traces)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(p.sampledOut.WithLabelValues(KindLog)))
+	require.Equal(t, 2.0, testutil.ToFloat64(p.sampledOut.WithLabelValues(KindEvent)))
+
+	// Rules only apply to matching apps.
+	payload = loadTestPayload(t)
+	payload.Meta.App.Name = "backend"
+	p.process(&payload)
+	require.Len(t, payload.Logs, 2)
+	require.NotNil(t, payload.Traces)
+	require.Len(t, payload.Events, 0)
+}
+
+func TestProcessing_Scrubbing(t *testing.T) {
+	p, err := newPayloadProcessor(&ProcessingConfig{
+		Scrubbing: ScrubbingConfig{
+			AllowedUserAttributes: []string{"plan"},
+			AllowedQueryParams:    []string{"page", "token", "email"},
+			HashSalt:              "salt",
+			Rules: []ScrubbingRule{
+				{Fields: []string{FieldUserEmail, FieldUserUsername}, Action: ScrubActionHash},
+				{Fields: []string{FieldPageURL}, Keys: "token", Action: ScrubActionDrop},
+				{Fields: []string{FieldPageURL, FieldLogContext, FieldExceptionValue, FieldSpanAttributes}, Pattern: `[\w.]+@[\w.]+`, Action: ScrubActionReplace},
+				{Fields: []string{FieldLogContext, FieldSpanAttributes}, Keys: "password|secret|enduser.id", Action: ScrubActionDrop},
+			},
+		},
+	}, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	traces := ptrace.NewTraces()
+	span := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().PutString("http.url", "https://api.example.com/users/jane@example.com")
+	span.Attributes().PutString("enduser.id", "123")
+	span.Attributes().PutInt("http.status_code", 200)
+	span.Events().AppendEmpty().Attributes().PutString("message", "signed in as jane@example.com")
+
+	payload := Payload{
+		Meta: Meta{
+			User: User{
+				Email:      "jane@example.com",
+				ID:         "123",
+				Username:   "jane",
+				Attributes: map[string]string{"plan": "pro", "address": "1 Main St"},
+			},
+			Page: Page{URL: "https://app.example.com/settings?page=2&token=abc&email=jane@example.com"},
+		},
+		Logs: []Log{
+			{Message: "login", Context: map[string]string{"password": "hunter2", "user": "jane@example.com", "step": "1"}},
+		},
+		Exceptions: []Exception{
+			{Type: "Error", Value: "no account for jane@example.com"},
+		},
+		Traces: &Traces{traces},
+	}
+
+	p.process(&payload)
+
+	require.Equal(t, User{
+		Email:      p.hash("jane@example.com"),
+		ID:         "123",
+		Username:   p.hash("jane"),
+		Attributes: map[string]string{"plan": "pro"},
+	}, payload.Meta.User)
+	require.NotEqual(t, "jane", payload.Meta.User.Username)
+	require.Equal(t, "https://app.example.com/settings?email=%5BREDACTED%5D&page=2", payload.Meta.Page.URL)
+	require.Equal(t, LogContext{"user": "[REDACTED]", "step": "1"}, payload.Logs[0].Context)
+	require.Equal(t, "no account for [REDACTED]", payload.Exceptions[0].Value)
+	require.Equal(t, map[string]interface{}{
+		"http.url":         "https://api.example.com/users/[REDACTED]",
+		"http.status_code": int64(200),
+	}, span.Attributes().AsRaw())
+	require.Equal(t, map[string]interface{}{"message": "signed in as [REDACTED]"}, span.Events().At(0).Attributes().AsRaw())
+
+	require.Equal(t, 1.0, testutil.ToFloat64(p.scrubbed.WithLabelValues(FieldUserEmail, ScrubActionHash)))
+	require.Equal(t, 1.0, testutil.ToFloat64(p.scrubbed.WithLabelValues(FieldUserAttributes, "allowlist")))
+	require.Equal(t, 1.0, testutil.ToFloat64(p.scrubbed.WithLabelValues(FieldPageURL, ScrubActionDrop)))
+	require.Equal(t, 1.0, testutil.ToFloat64(p.scrubbed.WithLabelValues(FieldLogContext, ScrubActionDrop)))
+	require.Equal(t, 1.0, testutil.ToFloat64(p.scrubbed.WithLabelValues(FieldExceptionValue, ScrubActionReplace)))
+	require.Equal(t, 2.0, testutil.ToFloat64(p.scrubbed.WithLabelValues(FieldSpanAttributes, ScrubActionReplace)))
+	require.Equal(t, 1.0, testutil.ToFloat64(p.scrubbed.WithLabelValues(FieldSpanAttributes, ScrubActionDrop)))
+
+	// URLs which don't need to be scrubbed are left untouched.
+	payload = Payload{Meta: Meta{Page: Page{URL: "https://app.example.com/?page=2"}}}
+	p.process(&payload)
+	require.Equal(t, "https://app.example.com/?page=2", payload.Meta.Page.URL)
+}
+
+func TestProcessing_InvalidConfig(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name: "invalid schema",
+			cfg: `
+processing:
+  validation:
+    schema: '{"type": 1}'`,
+			expect: "invalid payload schema",
+		},
+		{
+			name: "invalid sampling kind",
+			cfg: `
+processing:
+  sampling:
+    rules:
+      - kind: span
+        ratio: 0.5`,
+			expect: `invalid sampling kind "span"`,
+		},
+		{
+			name: "invalid sampling ratio",
+			cfg: `
+processing:
+  sampling:
+    rules:
+      - ratio: 2`,
+			expect: "sampling ratio must be between 0 and 1, got 2",
+		},
+		{
+			name: "invalid scrubbing field",
+			cfg: `
+processing:
+  scrubbing:
+    rules:
+      - fields: [user_password]
+        action: drop`,
+			expect: `invalid scrubbing field "user_password"`,
+		},
+		{
+			name: "invalid scrubbing action",
+			cfg: `
+processing:
+  scrubbing:
+    rules:
+      - fields: [user_email]
+        action: encrypt`,
+			expect: `invalid scrubbing action "encrypt"`,
+		},
+		{
+			name: "invalid scrubbing pattern",
+			cfg: `
+processing:
+  scrubbing:
+    rules:
+      - fields: [user_email]
+        pattern: "("
+        action: drop`,
+			expect: `invalid scrubbing pattern regex "("`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var cfg Config
+			err := yaml.Unmarshal([]byte(tc.cfg), &cfg)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expect)
+		})
+	}
+}
@@ -56,9 +56,13 @@ type SourceMap struct {
 }
 
 type sourceMapMetrics struct {
-	cacheSize *prometheus.CounterVec
-	downloads *prometheus.CounterVec
-	fileReads *prometheus.CounterVec
+	cacheSize      *prometheus.GaugeVec
+	cacheHits      *prometheus.CounterVec
+	cacheMisses    prometheus.Counter
+	cacheEvictions *prometheus.CounterVec
+	cacheBytes     *prometheus.GaugeVec
+	downloads      *prometheus.CounterVec
+	fileReads      *prometheus.CounterVec
 }
 
 type sourcemapFileLocation struct {
@@ -74,14 +78,14 @@ type RealSourceMapStore struct {
 	httpClient    httpClient
 	fileService   fileService
 	config        SourceMapConfig
-	cache         map[string]*SourceMap
+	cache         *sourceMapCache
 	fileLocations []*sourcemapFileLocation
 	metrics       *sourceMapMetrics
 }
 
 // NewSourceMapStore creates an instance of SourceMapStore.
 // httpClient and fileService will be instantiated to defaults if nil is provided
-func NewSourceMapStore(l log.Logger, config SourceMapConfig, reg prometheus.Registerer, httpClient httpClient, fileService fileService) SourceMapStore {
+func NewSourceMapStore(l log.Logger, config SourceMapConfig, reg prometheus.Registerer, httpClient httpClient, fileService fileService) *RealSourceMapStore {
 	if httpClient == nil {
 		httpClient = &http.Client{
 			Timeout: config.DownloadTimeout,
@@ -93,10 +97,26 @@ func NewSourceMapStore(l log.Logger, config SourceMapConfig, reg prometheus.Regi
 	}
 
 	metrics := &sourceMapMetrics{
-		cacheSize: prometheus.NewCounterVec(prometheus.CounterOpts{
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "app_agent_receiver_sourcemap_cache_size",
 			Help: "number of items in sourcemap cache, per origin",
 		}, []string{"origin"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "app_agent_receiver_sourcemap_cache_hits_total",
+			Help: "sourcemap cache hits, by cache",
+		}, []string{"cache"}),
+		cacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "app_agent_receiver_sourcemap_cache_misses_total",
+			Help: "sourcemap cache misses",
+		}),
+		cacheEvictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "app_agent_receiver_sourcemap_cache_evictions_total",
+			Help: "sourcemaps evicted from the sourcemap cache, by cache and reason",
+		}, []string{"cache", "reason"}),
+		cacheBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "app_agent_receiver_sourcemap_cache_bytes",
+			Help: "size of sourcemaps in the sourcemap cache, by cache",
+		}, []string{"cache"}),
 		downloads: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "app_agent_receiver_sourcemap_downloads_total",
 			Help: "downloads by the sourcemap service",
@@ -106,7 +126,10 @@ func NewSourceMapStore(l log.Logger, config SourceMapConfig, reg prometheus.Regi
 			Help: "sourcemap file reads from file system, by origin and status",
 		}, []string{"origin", "status"}),
 	}
-	reg.MustRegister(metrics.cacheSize, metrics.downloads, metrics.fileReads)
+	reg.MustRegister(
+		metrics.cacheSize, metrics.cacheHits, metrics.cacheMisses, metrics.cacheEvictions, metrics.cacheBytes,
+		metrics.downloads, metrics.fileReads,
+	)
 
 	fileLocations := []*sourcemapFileLocation{}
 
@@ -127,7 +150,7 @@ func NewSourceMapStore(l log.Logger, config SourceMapConfig, reg prometheus.Regi
 		httpClient:    httpClient,
 		fileService:   fileService,
 		config:        config,
-		cache:         make(map[string]*SourceMap),
+		cache:         newSourceMapCache(l, config.Cache, metrics),
 		metrics:       metrics,
 		fileLocations: fileLocations,
 	}
@@ -251,7 +274,7 @@ func (store *RealSourceMapStore) getSourceMapContent(sourceURL string, release s
 	}
 
 	//attempt to download
-	if strings.HasPrefix(sourceURL, "http") && urlMatchesOrigins(sourceURL, store.config.DownloadFromOrigins) {
+	if store.config.Download && strings.HasPrefix(sourceURL, "http") && urlMatchesOrigins(sourceURL, store.config.DownloadFromOrigins) {
 		return store.downloadSourceMapContent(sourceURL)
 	}
 	return nil, "", nil
@@ -262,32 +285,67 @@ func (store *RealSourceMapStore) GetSourceMap(sourceURL string, release string)
 	store.Lock()
 	defer store.Unlock()
 
-	cacheKey := fmt.Sprintf("%s__%s", sourceURL, release)
+	return store.getSourceMap(sourceURL, release)
+}
+
+// PreloadSourceMap retrieves the sourcemap for a given source url and adds
+// it to the cache. Unlike GetSourceMap, it retries source urls for which no
+// sourcemap was found before. It returns false if no sourcemap was found.
+func (store *RealSourceMapStore) PreloadSourceMap(sourceURL string, release string) (bool, error) {
+	store.Lock()
+	defer store.Unlock()
+
+	store.cache.forgetNotFound(sourceMapCacheKey{sourceURL: sourceURL, release: release})
+	smap, err := store.getSourceMap(sourceURL, release)
+	return smap != nil, err
+}
+
+// AddSourceMap adds the sourcemap content for a given source url to the
+// cache, replacing any sourcemap cached before.
+func (store *RealSourceMapStore) AddSourceMap(sourceURL string, release string, content []byte) error {
+	sourceMapURL := sourceURL + ".map"
+	smap, err := parseSourceMap(sourceMapURL, content)
+	if err != nil {
+		return err
+	}
+
+	store.Lock()
+	defer store.Unlock()
+
+	level.Info(store.l).Log("msg", "adding uploaded sourcemap", "url", sourceURL, "release", release)
+	return store.cache.add(sourceMapCacheKey{sourceURL: sourceURL, release: release}, sourceMapURL, content, smap)
+}
 
-	if smap, ok := store.cache[cacheKey]; ok {
+func (store *RealSourceMapStore) getSourceMap(sourceURL string, release string) (*SourceMap, error) {
+	cacheKey := sourceMapCacheKey{sourceURL: sourceURL, release: release}
+
+	if smap, ok := store.cache.get(cacheKey); ok {
 		return smap, nil
 	}
 	content, sourceMapURL, err := store.getSourceMapContent(sourceURL, release)
 	if err != nil || content == nil {
-		store.cache[cacheKey] = nil
+		store.cache.addNotFound(cacheKey)
 		return nil, err
 	}
-	if content != nil {
-		consumer, err := sourcemap.Parse(sourceMapURL, content)
-		if err != nil {
-			store.cache[cacheKey] = nil
-			level.Debug(store.l).Log("msg", "failed to parse sourcemap", "url", sourceMapURL, "release", release, "err", err)
-			return nil, err
-		}
-		level.Info(store.l).Log("msg", "successfully parsed sourcemap", "url", sourceMapURL, "release", release)
-		smap := &SourceMap{
-			consumer: consumer,
-		}
-		store.cache[cacheKey] = smap
-		store.metrics.cacheSize.WithLabelValues(getOrigin(sourceURL)).Inc()
-		return smap, nil
+	smap, err := parseSourceMap(sourceMapURL, content)
+	if err != nil {
+		store.cache.addNotFound(cacheKey)
+		level.Debug(store.l).Log("msg", "failed to parse sourcemap", "url", sourceMapURL, "release", release, "err", err)
+		return nil, err
+	}
+	level.Info(store.l).Log("msg", "successfully parsed sourcemap", "url", sourceMapURL, "release", release)
+	if err := store.cache.add(cacheKey, sourceMapURL, content, smap); err != nil {
+		level.Warn(store.l).Log("msg", "failed to write sourcemap to disk", "url", sourceMapURL, "release", release, "err", err)
+	}
+	return smap, nil
+}
+
+func parseSourceMap(sourceMapURL string, content []byte) (*SourceMap, error) {
+	consumer, err := sourcemap.Parse(sourceMapURL, content)
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return &SourceMap{consumer: consumer}, nil
 }
 
 // ResolveSourceLocation resolves minified source location to original source location
@@ -0,0 +1,434 @@
+package app_agent_receiver
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// maxSweepInterval is the maximum time between two checks for expired
+// releases.
+const maxSweepInterval = time.Minute
+
+// sourceMapCacheKey identifies a cached source map.
+type sourceMapCacheKey struct {
+	sourceURL string
+	release   string
+}
+
+type sourceMapCacheEntry struct {
+	key  sourceMapCacheKey
+	smap *SourceMap // nil if no source map was found.
+	size int64
+
+	// expires is set for entries without a source map, which are only cached
+	// for the configured not found TTL.
+	expires time.Time
+}
+
+// sourceMapCache is an LRU cache of source maps, bounded by the total size
+// of the cached source maps. Source maps of a release are evicted once the
+// release hasn't been used for the configured TTL.
+//
+// If a directory is configured, source maps are also stored on disk so that
+// they don't have to be retrieved again after a restart or after being
+// evicted from memory. The size of source maps on disk is bounded by the
+// same max size, evicting the files which were least recently used.
+//
+// sourceMapCache is not safe for concurrent use.
+type sourceMapCache struct {
+	l       log.Logger
+	config  SourceMapCacheConfig
+	metrics *sourceMapMetrics
+	now     func() time.Time
+
+	lru       *list.List // Most recently used entries are at the front.
+	entries   map[sourceMapCacheKey]*list.Element
+	size      int64
+	diskSize  int64
+	releases  map[string]time.Time // Last use of each release.
+	lastSweep time.Time
+}
+
+func newSourceMapCache(l log.Logger, config SourceMapCacheConfig, metrics *sourceMapMetrics) *sourceMapCache {
+	c := &sourceMapCache{
+		l:        l,
+		config:   config,
+		metrics:  metrics,
+		now:      time.Now,
+		lru:      list.New(),
+		entries:  make(map[sourceMapCacheKey]*list.Element),
+		releases: make(map[string]time.Time),
+	}
+
+	if config.Directory != "" {
+		if err := c.loadDisk(); err != nil {
+			level.Warn(l).Log("msg", "disabling on-disk sourcemap cache", "dir", config.Directory, "err", err)
+			c.config.Directory = ""
+		}
+	}
+	return c
+}
+
+// loadDisk creates the cache directory if it doesn't exist yet, and computes
+// the size of source maps cached by earlier runs.
+func (c *sourceMapCache) loadDisk() error {
+	if err := os.MkdirAll(c.config.Directory, 0750); err != nil {
+		return err
+	}
+	err := filepath.WalkDir(c.config.Directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		c.diskSize += info.Size()
+		return nil
+	})
+	c.metrics.cacheBytes.WithLabelValues("disk").Set(float64(c.diskSize))
+	if err != nil {
+		return err
+	}
+
+	// The max size may have been lowered since the source maps were cached.
+	c.evictDisk()
+	return nil
+}
+
+// get returns the cached source map for key. ok is false if the cache
+// doesn't know about key. A nil source map with ok set to true means that
+// no source map was found for key recently.
+func (c *sourceMapCache) get(key sourceMapCacheKey) (smap *SourceMap, ok bool) {
+	c.sweep()
+	c.releases[key.release] = c.now()
+
+	if el, found := c.entries[key]; found {
+		entry := el.Value.(*sourceMapCacheEntry)
+		if entry.smap != nil || entry.expires.IsZero() || c.now().Before(entry.expires) {
+			c.lru.MoveToFront(el)
+			if entry.smap != nil {
+				c.metrics.cacheHits.WithLabelValues("memory").Inc()
+			} else {
+				c.metrics.cacheHits.WithLabelValues("not_found").Inc()
+			}
+			return entry.smap, true
+		}
+		c.remove(el)
+	}
+
+	if smap := c.getFromDisk(key); smap != nil {
+		c.metrics.cacheHits.WithLabelValues("disk").Inc()
+		return smap, true
+	}
+
+	c.metrics.cacheMisses.Inc()
+	return nil, false
+}
+
+// add caches the source map smap parsed from content for key. The source
+// map is also written to disk if a directory is configured.
+func (c *sourceMapCache) add(key sourceMapCacheKey, sourceMapURL string, content []byte, smap *SourceMap) error {
+	c.put(&sourceMapCacheEntry{key: key, smap: smap, size: int64(len(content))})
+	if c.config.Directory == "" {
+		return nil
+	}
+	return c.writeDisk(key, sourceMapURL, content)
+}
+
+// addNotFound caches that no source map exists for key.
+func (c *sourceMapCache) addNotFound(key sourceMapCacheKey) {
+	entry := &sourceMapCacheEntry{
+		key:  key,
+		size: int64(len(key.sourceURL) + len(key.release)),
+	}
+	if c.config.NotFoundTTL > 0 {
+		entry.expires = c.now().Add(c.config.NotFoundTTL)
+	}
+	c.put(entry)
+}
+
+// forgetNotFound removes key from the cache if no source map was found for
+// it.
+func (c *sourceMapCache) forgetNotFound(key sourceMapCacheKey) {
+	if el, ok := c.entries[key]; ok && el.Value.(*sourceMapCacheEntry).smap == nil {
+		c.remove(el)
+	}
+}
+
+func (c *sourceMapCache) put(entry *sourceMapCacheEntry) {
+	if el, ok := c.entries[entry.key]; ok {
+		c.remove(el)
+	}
+	c.releases[entry.key.release] = c.now()
+
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	c.size += entry.size
+	if entry.smap != nil {
+		c.metrics.cacheSize.WithLabelValues(getOrigin(entry.key.sourceURL)).Inc()
+	}
+	c.metrics.cacheBytes.WithLabelValues("memory").Set(float64(c.size))
+
+	for c.config.MaxSize > 0 && c.size > c.config.MaxSize {
+		c.remove(c.lru.Back())
+		c.metrics.cacheEvictions.WithLabelValues("memory", "size").Inc()
+	}
+}
+
+func (c *sourceMapCache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*sourceMapCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+	if entry.smap != nil {
+		c.metrics.cacheSize.WithLabelValues(getOrigin(entry.key.sourceURL)).Dec()
+	}
+	c.metrics.cacheBytes.WithLabelValues("memory").Set(float64(c.size))
+}
+
+// sweep evicts the source maps of releases which haven't been used for the
+// configured TTL, both from memory and from disk.
+func (c *sourceMapCache) sweep() {
+	if c.config.TTL <= 0 {
+		return
+	}
+	interval := c.config.TTL
+	if interval > maxSweepInterval {
+		interval = maxSweepInterval
+	}
+	now := c.now()
+	if now.Sub(c.lastSweep) < interval {
+		return
+	}
+	c.lastSweep = now
+
+	expired := make(map[string]struct{})
+	for release, lastUse := range c.releases {
+		if now.Sub(lastUse) >= c.config.TTL {
+			expired[release] = struct{}{}
+			delete(c.releases, release)
+		}
+	}
+
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		if _, ok := expired[el.Value.(*sourceMapCacheEntry).key.release]; ok {
+			c.remove(el)
+			c.metrics.cacheEvictions.WithLabelValues("memory", "ttl").Inc()
+		}
+		el = next
+	}
+
+	if c.config.Directory != "" {
+		c.sweepDisk(now, expired)
+	}
+}
+
+// sweepDisk removes the directories of expired releases from disk. The
+// directories of releases which haven't been used since the agent started
+// are removed once they haven't been modified for the configured TTL.
+func (c *sourceMapCache) sweepDisk(now time.Time, expired map[string]struct{}) {
+	known := make(map[string]struct{}, len(c.releases))
+	for release := range c.releases {
+		known[hashCacheKey(release)] = struct{}{}
+	}
+	expiredDirs := make(map[string]struct{}, len(expired))
+	for release := range expired {
+		expiredDirs[hashCacheKey(release)] = struct{}{}
+	}
+
+	dirs, err := os.ReadDir(c.config.Directory)
+	if err != nil {
+		level.Warn(c.l).Log("msg", "failed to read sourcemap cache directory", "dir", c.config.Directory, "err", err)
+		return
+	}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		if _, ok := known[d.Name()]; ok {
+			continue
+		}
+		if _, ok := expiredDirs[d.Name()]; !ok {
+			info, err := d.Info()
+			if err != nil || now.Sub(info.ModTime()) < c.config.TTL {
+				continue
+			}
+		}
+		c.removeDiskDir(filepath.Join(c.config.Directory, d.Name()))
+	}
+}
+
+func (c *sourceMapCache) removeDiskDir(dir string) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		level.Warn(c.l).Log("msg", "failed to read sourcemap cache directory", "dir", dir, "err", err)
+		return
+	}
+	for _, f := range files {
+		if info, err := f.Info(); err == nil {
+			c.diskSize -= info.Size()
+		}
+		c.metrics.cacheEvictions.WithLabelValues("disk", "ttl").Inc()
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		level.Warn(c.l).Log("msg", "failed to remove expired sourcemaps from disk", "dir", dir, "err", err)
+	}
+	c.metrics.cacheBytes.WithLabelValues("disk").Set(float64(c.diskSize))
+}
+
+// diskPath returns the path of the file which caches the source map for
+// key. Releases and URLs are hashed so that they can't escape the cache
+// directory.
+func (c *sourceMapCache) diskPath(key sourceMapCacheKey) (dir string, file string) {
+	dir = filepath.Join(c.config.Directory, hashCacheKey(key.release))
+	return dir, filepath.Join(dir, hashCacheKey(key.sourceURL)+".map")
+}
+
+// writeDisk stores a source map on disk. The first line of the file is the
+// URL of the source map, which is needed to parse it again.
+func (c *sourceMapCache) writeDisk(key sourceMapCacheKey, sourceMapURL string, content []byte) error {
+	dir, path := c.diskPath(key)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	var oldSize int64
+	if info, err := os.Stat(path); err == nil {
+		oldSize = info.Size()
+	}
+
+	data := make([]byte, 0, len(sourceMapURL)+1+len(content))
+	data = append(data, sourceMapURL...)
+	data = append(data, '\n')
+	data = append(data, content...)
+
+	// Write to a temporary file first so that a partially written file is
+	// never read.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0640); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	// The modification time of files is their last use, used to evict the
+	// least recently used files from disk.
+	now := c.now()
+	_ = os.Chtimes(path, now, now)
+
+	c.diskSize += int64(len(data)) - oldSize
+	c.metrics.cacheBytes.WithLabelValues("disk").Set(float64(c.diskSize))
+	c.evictDisk()
+	return nil
+}
+
+// evictDisk removes the least recently used source maps from disk until
+// their total size is within the configured max size.
+func (c *sourceMapCache) evictDisk() {
+	if c.config.MaxSize <= 0 || c.diskSize <= c.config.MaxSize {
+		return
+	}
+
+	type diskFile struct {
+		path    string
+		size    int64
+		lastUse time.Time
+	}
+	var files []diskFile
+	err := filepath.WalkDir(c.config.Directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, diskFile{path: path, size: info.Size(), lastUse: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		level.Warn(c.l).Log("msg", "failed to read sourcemap cache directory", "dir", c.config.Directory, "err", err)
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].lastUse.Before(files[j].lastUse) })
+
+	for _, f := range files {
+		if c.diskSize <= c.config.MaxSize {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			level.Warn(c.l).Log("msg", "failed to remove sourcemap from disk", "path", f.path, "err", err)
+			continue
+		}
+		c.diskSize -= f.size
+		c.metrics.cacheEvictions.WithLabelValues("disk", "size").Inc()
+	}
+	c.metrics.cacheBytes.WithLabelValues("disk").Set(float64(c.diskSize))
+}
+
+// getFromDisk reads the source map for key from disk and adds it to the
+// in-memory cache. It returns nil if the source map isn't cached on disk.
+func (c *sourceMapCache) getFromDisk(key sourceMapCacheKey) *SourceMap {
+	if c.config.Directory == "" {
+		return nil
+	}
+
+	dir, path := c.diskPath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			level.Warn(c.l).Log("msg", "failed to read sourcemap from disk", "path", path, "err", err)
+		}
+		return nil
+	}
+
+	smap, sourceMapURL, content, err := parseCachedSourceMap(data)
+	if err != nil {
+		level.Warn(c.l).Log("msg", "removing invalid sourcemap from disk", "path", path, "err", err)
+		if err := os.Remove(path); err == nil {
+			c.diskSize -= int64(len(data))
+			c.metrics.cacheBytes.WithLabelValues("disk").Set(float64(c.diskSize))
+		}
+		return nil
+	}
+	level.Debug(c.l).Log("msg", "loaded sourcemap from disk", "url", sourceMapURL, "release", key.release)
+
+	// Keep the modification time of the release directory and the file up to
+	// date so that they aren't removed as unused, after a restart or when the
+	// disk cache is full.
+	now := c.now()
+	_ = os.Chtimes(dir, now, now)
+	_ = os.Chtimes(path, now, now)
+
+	c.put(&sourceMapCacheEntry{key: key, smap: smap, size: int64(len(content))})
+	return smap
+}
+
+func parseCachedSourceMap(data []byte) (smap *SourceMap, sourceMapURL string, content []byte, err error) {
+	idx := bytes.IndexByte(data, '\n')
+	if idx < 0 {
+		return nil, "", nil, fmt.Errorf("missing sourcemap url")
+	}
+	sourceMapURL, content = string(data[:idx]), data[idx+1:]
+	smap, err = parseSourceMap(sourceMapURL, content)
+	return smap, sourceMapURL, content, err
+}
+
+func hashCacheKey(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,273 @@
+package app_agent_receiver
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RealSourceMapStore_CacheEvictsLeastRecentlyUsed(t *testing.T) {
+	mapFile := loadTestData(t, "foo.js.map")
+
+	conf := SourceMapConfig{
+		FileSystem: []SourceMapFileLocation{
+			{
+				MinifiedPathPrefix: "http://foo.com/",
+				Path:               filepath.FromSlash("/var/build/"),
+			},
+		},
+		Cache: SourceMapCacheConfig{MaxSize: int64(len(mapFile)) + 1},
+	}
+
+	fileService := &mockFileService{
+		files: map[string][]byte{
+			filepath.FromSlash("/var/build/foo.js.map"): mapFile,
+			filepath.FromSlash("/var/build/bar.js.map"): mapFile,
+		},
+	}
+
+	sourceMapStore := NewSourceMapStore(log.NewNopLogger(), conf, prometheus.NewRegistry(), &mockHTTPClient{}, fileService)
+
+	for _, url := range []string{"http://foo.com/foo.js", "http://foo.com/foo.js", "http://foo.com/bar.js", "http://foo.com/foo.js"} {
+		smap, err := sourceMapStore.GetSourceMap(url, "123")
+		require.NoError(t, err)
+		require.NotNil(t, smap)
+	}
+
+	// Only one source map fits in the cache, so foo.js.map is read again after
+	// bar.js.map was read.
+	require.Equal(t, []string{
+		filepath.FromSlash("/var/build/foo.js.map"),
+		filepath.FromSlash("/var/build/bar.js.map"),
+		filepath.FromSlash("/var/build/foo.js.map"),
+	}, fileService.reads)
+
+	metrics := sourceMapStore.metrics
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.cacheHits.WithLabelValues("memory")))
+	require.Equal(t, 3.0, testutil.ToFloat64(metrics.cacheMisses))
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.cacheEvictions.WithLabelValues("memory", "size")))
+	require.Equal(t, float64(len(mapFile)), testutil.ToFloat64(metrics.cacheBytes.WithLabelValues("memory")))
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.cacheSize.WithLabelValues("http://foo.com")))
+}
+
+func Test_RealSourceMapStore_CacheNotFound(t *testing.T) {
+	conf := SourceMapConfig{
+		Download:            true,
+		DownloadFromOrigins: []string{"*"},
+		Cache:               SourceMapCacheConfig{NotFoundTTL: time.Minute},
+	}
+
+	httpClient := &mockHTTPClient{
+		responses: []struct {
+			*http.Response
+			error
+		}{
+			{&http.Response{StatusCode: 404, Body: io.NopCloser(bytes.NewReader(nil))}, nil},
+			{newResponseFromTestData(t, "foo.js"), nil},
+			{newResponseFromTestData(t, "foo.js.map"), nil},
+		},
+	}
+
+	sourceMapStore := NewSourceMapStore(log.NewNopLogger(), conf, prometheus.NewRegistry(), httpClient, &mockFileService{})
+
+	now := time.Now()
+	sourceMapStore.cache.now = func() time.Time { return now }
+
+	_, err := sourceMapStore.GetSourceMap("http://localhost:1234/foo.js", "123")
+	require.Error(t, err)
+
+	// The failed download isn't retried until the not found TTL expires.
+	smap, err := sourceMapStore.GetSourceMap("http://localhost:1234/foo.js", "123")
+	require.NoError(t, err)
+	require.Nil(t, smap)
+	require.Len(t, httpClient.requests, 1)
+	require.Equal(t, 1.0, testutil.ToFloat64(sourceMapStore.metrics.cacheHits.WithLabelValues("not_found")))
+
+	now = now.Add(time.Minute)
+	smap, err = sourceMapStore.GetSourceMap("http://localhost:1234/foo.js", "123")
+	require.NoError(t, err)
+	require.NotNil(t, smap)
+	require.Len(t, httpClient.requests, 3)
+}
+
+func Test_RealSourceMapStore_CacheOnDisk(t *testing.T) {
+	mapFile := loadTestData(t, "foo.js.map")
+
+	conf := SourceMapConfig{
+		FileSystem: []SourceMapFileLocation{
+			{
+				MinifiedPathPrefix: "http://foo.com/",
+				Path:               filepath.FromSlash("/var/build/"),
+			},
+		},
+		Cache: SourceMapCacheConfig{Directory: t.TempDir()},
+	}
+
+	fileService := &mockFileService{
+		files: map[string][]byte{
+			filepath.FromSlash("/var/build/foo.js.map"): mapFile,
+		},
+	}
+
+	sourceMapStore := NewSourceMapStore(log.NewNopLogger(), conf, prometheus.NewRegistry(), &mockHTTPClient{}, fileService)
+	smap, err := sourceMapStore.GetSourceMap("http://foo.com/foo.js", "123")
+	require.NoError(t, err)
+	require.NotNil(t, smap)
+
+	// A new store, e.g. after a restart, reads the source map from disk
+	// instead of the file system location.
+	fileService = &mockFileService{}
+	sourceMapStore = NewSourceMapStore(log.NewNopLogger(), conf, prometheus.NewRegistry(), &mockHTTPClient{}, fileService)
+	require.Less(t, float64(len(mapFile)), testutil.ToFloat64(sourceMapStore.metrics.cacheBytes.WithLabelValues("disk")))
+
+	smap, err = sourceMapStore.GetSourceMap("http://foo.com/foo.js", "123")
+	require.NoError(t, err)
+	require.NotNil(t, smap)
+	require.Empty(t, fileService.reads)
+	require.Equal(t, 1.0, testutil.ToFloat64(sourceMapStore.metrics.cacheHits.WithLabelValues("disk")))
+
+	exception := &Exception{
+		Stacktrace: &Stacktrace{
+			Frames: []Frame{{Colno: 6, Filename: "http://foo.com/foo.js", Function: "eval", Lineno: 5}},
+		},
+	}
+	transformed := TransformException(sourceMapStore, log.NewNopLogger(), exception, "123")
+	require.Equal(t, []Frame{
+		{Colno: 37, Filename: "/__parcel_source_root/demo/src/actions.ts", Function: "?", Lineno: 6},
+	}, transformed.Stacktrace.Frames)
+}
+
+func Test_RealSourceMapStore_CacheReleaseTTL(t *testing.T) {
+	mapFile := loadTestData(t, "foo.js.map")
+	dir := t.TempDir()
+
+	conf := SourceMapConfig{
+		FileSystem: []SourceMapFileLocation{
+			{
+				MinifiedPathPrefix: "http://foo.com/",
+				Path:               filepath.FromSlash("/var/build/{{ .Release }}/"),
+			},
+		},
+		Cache: SourceMapCacheConfig{TTL: time.Hour, Directory: dir},
+	}
+
+	fileService := &mockFileService{
+		files: map[string][]byte{
+			filepath.FromSlash("/var/build/1/foo.js.map"): mapFile,
+			filepath.FromSlash("/var/build/2/foo.js.map"): mapFile,
+		},
+	}
+
+	sourceMapStore := NewSourceMapStore(log.NewNopLogger(), conf, prometheus.NewRegistry(), &mockHTTPClient{}, fileService)
+
+	now := time.Now()
+	sourceMapStore.cache.now = func() time.Time { return now }
+
+	smap, err := sourceMapStore.GetSourceMap("http://foo.com/foo.js", "1")
+	require.NoError(t, err)
+	require.NotNil(t, smap)
+
+	now = now.Add(30 * time.Minute)
+	smap, err = sourceMapStore.GetSourceMap("http://foo.com/foo.js", "2")
+	require.NoError(t, err)
+	require.NotNil(t, smap)
+
+	// Release 1 hasn't been used for an hour, but release 2 has been used 30
+	// minutes ago.
+	now = now.Add(30 * time.Minute)
+	smap, err = sourceMapStore.GetSourceMap("http://foo.com/foo.js", "2")
+	require.NoError(t, err)
+	require.NotNil(t, smap)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(sourceMapStore.metrics.cacheEvictions.WithLabelValues("memory", "ttl")))
+	require.Equal(t, 1.0, testutil.ToFloat64(sourceMapStore.metrics.cacheEvictions.WithLabelValues("disk", "ttl")))
+	require.NoDirExists(t, filepath.Join(dir, hashCacheKey("1")))
+	require.DirExists(t, filepath.Join(dir, hashCacheKey("2")))
+
+	smap, err = sourceMapStore.GetSourceMap("http://foo.com/foo.js", "1")
+	require.NoError(t, err)
+	require.NotNil(t, smap)
+	require.Equal(t, []string{
+		filepath.FromSlash("/var/build/1/foo.js.map"),
+		filepath.FromSlash("/var/build/2/foo.js.map"),
+		filepath.FromSlash("/var/build/1/foo.js.map"),
+	}, fileService.reads)
+}
+
+func Test_RealSourceMapStore_CacheRemovesUnusedReleasesFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	releaseDir := filepath.Join(dir, hashCacheKey("old"))
+	require.NoError(t, os.MkdirAll(releaseDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(releaseDir, "foo.map"), []byte("foo.js.map\n{}"), 0640))
+
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(releaseDir, old, old))
+
+	conf := SourceMapConfig{
+		Cache: SourceMapCacheConfig{TTL: time.Hour, Directory: dir},
+	}
+	sourceMapStore := NewSourceMapStore(log.NewNopLogger(), conf, prometheus.NewRegistry(), &mockHTTPClient{}, &mockFileService{})
+	require.Equal(t, 13.0, testutil.ToFloat64(sourceMapStore.metrics.cacheBytes.WithLabelValues("disk")))
+
+	_, err := sourceMapStore.GetSourceMap("http://foo.com/foo.js", "new")
+	require.NoError(t, err)
+
+	require.NoDirExists(t, releaseDir)
+	require.Equal(t, 0.0, testutil.ToFloat64(sourceMapStore.metrics.cacheBytes.WithLabelValues("disk")))
+}
+
+func Test_RealSourceMapStore_CacheEvictsFromDisk(t *testing.T) {
+	mapFile := loadTestData(t, "foo.js.map")
+	dir := t.TempDir()
+
+	conf := SourceMapConfig{
+		FileSystem: []SourceMapFileLocation{
+			{
+				MinifiedPathPrefix: "http://foo.com/",
+				Path:               filepath.FromSlash("/var/build/"),
+			},
+		},
+		// Cached files also hold the URL of the source map, so only one of them
+		// fits on disk.
+		Cache: SourceMapCacheConfig{MaxSize: int64(len(mapFile)) + 100, Directory: dir},
+	}
+
+	fileService := &mockFileService{
+		files: map[string][]byte{
+			filepath.FromSlash("/var/build/foo.js.map"): mapFile,
+			filepath.FromSlash("/var/build/bar.js.map"): mapFile,
+		},
+	}
+
+	sourceMapStore := NewSourceMapStore(log.NewNopLogger(), conf, prometheus.NewRegistry(), &mockHTTPClient{}, fileService)
+
+	now := time.Now()
+	sourceMapStore.cache.now = func() time.Time { return now }
+
+	for _, url := range []string{"http://foo.com/foo.js", "http://foo.com/bar.js"} {
+		now = now.Add(time.Minute)
+		smap, err := sourceMapStore.GetSourceMap(url, "123")
+		require.NoError(t, err)
+		require.NotNil(t, smap)
+	}
+
+	metrics := sourceMapStore.metrics
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.cacheEvictions.WithLabelValues("disk", "size")))
+	require.LessOrEqual(t, testutil.ToFloat64(metrics.cacheBytes.WithLabelValues("disk")), float64(conf.Cache.MaxSize))
+
+	// foo.js.map was least recently used, so only bar.js.map is left on disk.
+	cache := sourceMapStore.cache
+	_, fooPath := cache.diskPath(sourceMapCacheKey{sourceURL: "http://foo.com/foo.js", release: "123"})
+	_, barPath := cache.diskPath(sourceMapCacheKey{sourceURL: "http://foo.com/bar.js", release: "123"})
+	require.NoFileExists(t, fooPath)
+	require.FileExists(t, barPath)
+}
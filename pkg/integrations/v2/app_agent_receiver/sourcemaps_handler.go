@@ -0,0 +1,114 @@
+package app_agent_receiver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// maxSourceMapUploadSize is the max size in bytes of an uploaded sourcemap.
+const maxSourceMapUploadSize = 100 << 20
+
+// SourceMapPreloadRequest is the body of a request to preload sourcemaps.
+type SourceMapPreloadRequest struct {
+	URLs []string `json:"urls"`
+}
+
+// SourceMapPreloadResponse is the body of the response to a request to
+// preload sourcemaps.
+type SourceMapPreloadResponse struct {
+	Loaded   []string          `json:"loaded"`
+	NotFound []string          `json:"not_found"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// NewSourceMapHandler returns the http.Handler for the endpoints which add
+// sourcemaps of a release to store ahead of time:
+//
+//   - POST /sourcemaps/upload?release=<release>&url=<source url> adds the
+//     sourcemap in the request body for the given source url.
+//   - POST /sourcemaps/preload?release=<release> retrieves the sourcemaps of
+//     the source urls in the request body, a JSON SourceMapPreloadRequest.
+//
+// Requests must specify uploadAPIKey in the "x-api-key" header. All requests
+// are rejected if uploadAPIKey is empty. uploadAPIKey must differ from the API
+// key of the receiver, which is public since browsers send it.
+func NewSourceMapHandler(logger log.Logger, store *RealSourceMapStore, uploadAPIKey string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/sourcemaps/upload", func(w http.ResponseWriter, r *http.Request) {
+		release, sourceURL := r.URL.Query().Get("release"), r.URL.Query().Get("url")
+		if sourceURL == "" {
+			http.Error(w, "url parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSourceMapUploadSize))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if err := store.AddSourceMap(sourceURL, release, content); err != nil {
+			level.Error(logger).Log("msg", "failed to add uploaded sourcemap", "url", sourceURL, "release", release, "err", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	mux.HandleFunc("/sourcemaps/preload", func(w http.ResponseWriter, r *http.Request) {
+		release := r.URL.Query().Get("release")
+
+		var req SourceMapPreloadRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := SourceMapPreloadResponse{
+			Loaded:   []string{},
+			NotFound: []string{},
+		}
+		for _, sourceURL := range req.URLs {
+			found, err := store.PreloadSourceMap(sourceURL, release)
+			switch {
+			case err != nil:
+				if resp.Errors == nil {
+					resp.Errors = make(map[string]string)
+				}
+				resp.Errors[sourceURL] = err.Error()
+			case found:
+				resp.Loaded = append(resp.Loaded, sourceURL)
+			default:
+				resp.NotFound = append(resp.NotFound, sourceURL)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			level.Error(logger).Log("msg", "failed to write preload response", "err", err)
+		}
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(uploadAPIKey) == 0 {
+			http.Error(w, "sourcemap endpoints require an upload api key to be configured", http.StatusForbidden)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(apiKeyHeader)), []byte(uploadAPIKey)) == 0 {
+			http.Error(w, "api key not provided or incorrect", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,93 @@
+package app_agent_receiver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceMapHandler(t *testing.T) {
+	mapFile := loadTestData(t, "foo.js.map")
+
+	conf := SourceMapConfig{
+		FileSystem: []SourceMapFileLocation{
+			{
+				MinifiedPathPrefix: "http://foo.com/",
+				Path:               filepath.FromSlash("/var/build/"),
+			},
+		},
+	}
+	fileService := &mockFileService{
+		files: map[string][]byte{
+			filepath.FromSlash("/var/build/foo.js.map"): mapFile,
+		},
+	}
+	store := NewSourceMapStore(log.NewNopLogger(), conf, prometheus.NewRegistry(), &mockHTTPClient{}, fileService)
+
+	post := func(handler http.Handler, url, apiKey string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if apiKey != "" {
+			req.Header.Set(apiKeyHeader, apiKey)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("no upload api key configured", func(t *testing.T) {
+		handler := NewSourceMapHandler(log.NewNopLogger(), store, "")
+		rr := post(handler, "/sourcemaps/upload?release=1&url=http://bar.com/bar.js", "", mapFile)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	handler := NewSourceMapHandler(log.NewNopLogger(), store, "secret")
+
+	t.Run("wrong api key", func(t *testing.T) {
+		rr := post(handler, "/sourcemaps/upload?release=1&url=http://bar.com/bar.js", "wrong", mapFile)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		rr := post(handler, "/sourcemaps/upload?release=1&url=http://bar.com/bar.js", "secret", mapFile)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		smap, err := store.GetSourceMap("http://bar.com/bar.js", "1")
+		require.NoError(t, err)
+		require.NotNil(t, smap)
+	})
+
+	t.Run("upload invalid sourcemap", func(t *testing.T) {
+		rr := post(handler, "/sourcemaps/upload?release=1&url=http://bar.com/baz.js", "secret", []byte("not a sourcemap"))
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("preload", func(t *testing.T) {
+		body, err := json.Marshal(SourceMapPreloadRequest{URLs: []string{"http://foo.com/foo.js", "http://foo.com/missing.js"}})
+		require.NoError(t, err)
+
+		rr := post(handler, "/sourcemaps/preload?release=1", "secret", body)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp SourceMapPreloadResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		require.Equal(t, SourceMapPreloadResponse{
+			Loaded:   []string{"http://foo.com/foo.js"},
+			NotFound: []string{"http://foo.com/missing.js"},
+		}, resp)
+
+		// Preloading retries source maps which weren't found before.
+		fileService.files[filepath.FromSlash("/var/build/missing.js.map")] = mapFile
+		rr = post(handler, "/sourcemaps/preload?release=1", "secret", body)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		require.Equal(t, []string{"http://foo.com/foo.js", "http://foo.com/missing.js"}, resp.Loaded)
+		require.Empty(t, resp.NotFound)
+	})
+}
@@ -83,6 +83,34 @@ func mockException() *Exception {
 	}
 }
 
+func Test_RealSourceMapStore_DownloadDisabled(t *testing.T) {
+	conf := SourceMapConfig{
+		Download:            false,
+		DownloadFromOrigins: []string{"*"},
+	}
+
+	httpClient := &mockHTTPClient{
+		responses: []struct {
+			*http.Response
+			error
+		}{
+			{newResponseFromTestData(t, "foo.js"), nil},
+			{newResponseFromTestData(t, "foo.js.map"), nil},
+		},
+	}
+
+	logger := log.NewNopLogger()
+
+	sourceMapStore := NewSourceMapStore(logger, conf, prometheus.NewRegistry(), httpClient, &mockFileService{})
+
+	exception := mockException()
+
+	transformed := TransformException(sourceMapStore, logger, exception, "123")
+
+	require.Empty(t, httpClient.requests)
+	require.Equal(t, exception, transformed)
+}
+
 func Test_RealSourceMapStore_DownloadSuccess(t *testing.T) {
 	conf := SourceMapConfig{
 		Download:            true,
@@ -14,7 +14,7 @@ type TracesExporter struct {
 }
 
 // NewTracesExporter creates a trace exporter for the app agent receiver.
-func NewTracesExporter(getTracesConsumer tracesConsumerGetter) appAgentReceiverExporter {
+func NewTracesExporter(getTracesConsumer tracesConsumerGetter) AppAgentReceiverExporter {
 	return &TracesExporter{getTracesConsumer}
 }
 
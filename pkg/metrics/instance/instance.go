@@ -234,6 +234,7 @@ type Instance struct {
 	discovery          *discoveryService
 	readyScrapeManager *readyScrapeManager
 	remoteStore        *remote.Storage
+	metadataSender     *wal.MetadataSender
 	storage            storage.Storage
 
 	// ready is set to true after the initialization process finishes
@@ -369,6 +370,8 @@ func (i *Instance) Run(ctx context.Context) error {
 					}
 				}
 
+				i.metadataSender.Stop()
+
 				// Closing the storage closes both the WAL storage and remote wrte
 				// storage.
 				level.Info(i.logger).Log("msg", "closing storage...")
@@ -417,14 +420,26 @@ func (i *Instance) initialize(ctx context.Context, reg prometheus.Registerer, cf
 	// Setup the remote storage
 	remoteLogger := log.With(i.logger, "component", "remote")
 	i.remoteStore = remote.NewStorage(remoteLogger, reg, i.wal.StartTime, i.wal.Directory(), cfg.RemoteFlushDeadline, i.readyScrapeManager)
+	remoteWrite, err := wal.WithoutMetadataSending(cfg.RemoteWrite)
+	if err != nil {
+		return fmt.Errorf("failed applying config to remote storage: %w", err)
+	}
 	err = i.remoteStore.ApplyConfig(&config.Config{
 		GlobalConfig:       cfg.global.Prometheus,
-		RemoteWriteConfigs: cfg.RemoteWrite,
+		RemoteWriteConfigs: remoteWrite,
 	})
 	if err != nil {
 		return fmt.Errorf("failed applying config to remote storage: %w", err)
 	}
 
+	// The remote storage can only send metadata of scraped series, so it's
+	// sent by the metadata sender instead, along with metadata recorded in
+	// the WAL, such as metadata of pushed series.
+	i.metadataSender = wal.NewMetadataSender(remoteLogger, i.wal, i.readyScrapeManager)
+	if err := i.metadataSender.ApplyConfig(cfg.RemoteWrite); err != nil {
+		return fmt.Errorf("failed applying config to metadata sender: %w", err)
+	}
+
 	i.storage = storage.NewFanout(i.logger, i.wal, i.remoteStore)
 
 	opts := &scrape.Options{
@@ -517,13 +532,20 @@ func (i *Instance) Update(c Config) (err error) {
 		i.hostFilter.PatchSD(c.ScrapeConfigs)
 	}
 
+	remoteWrite, err := wal.WithoutMetadataSending(c.RemoteWrite)
+	if err != nil {
+		return fmt.Errorf("error applying new remote_write configs: %w", err)
+	}
 	err = i.remoteStore.ApplyConfig(&config.Config{
 		GlobalConfig:       c.global.Prometheus,
-		RemoteWriteConfigs: c.RemoteWrite,
+		RemoteWriteConfigs: remoteWrite,
 	})
 	if err != nil {
 		return fmt.Errorf("error applying new remote_write configs: %w", err)
 	}
+	if err = i.metadataSender.ApplyConfig(c.RemoteWrite); err != nil {
+		return fmt.Errorf("error applying new remote_write configs to metadata sender: %w", err)
+	}
 
 	sm, err := i.readyScrapeManager.Get()
 	if err != nil {
@@ -730,6 +752,7 @@ type walStorage interface {
 	WriteStalenessMarkers(remoteTsFunc func() int64) error
 	Appender(context.Context) storage.Appender
 	Truncate(mint int64) error
+	ListMetadata() []scrape.MetricMetadata
 
 	Close() error
 }
@@ -828,3 +851,30 @@ func (rm *readyScrapeManager) Get() (*scrape.Manager, error) {
 
 	return nil, ErrNotReady
 }
+
+// ListMetadata returns the metadata of the active targets of the scrape
+// manager, or nothing if it's not ready yet. It implements
+// wal.MetadataLister.
+func (rm *readyScrapeManager) ListMetadata() []scrape.MetricMetadata {
+	sm, err := rm.Get()
+	if err != nil {
+		return nil
+	}
+
+	var (
+		res = []scrape.MetricMetadata{}
+		set = map[scrape.MetricMetadata]struct{}{}
+	)
+	for _, targets := range sm.TargetsActive() {
+		for _, target := range targets {
+			for _, md := range target.MetadataList() {
+				if _, ok := set[md]; ok {
+					continue
+				}
+				set[md] = struct{}{}
+				res = append(res, md)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,177 @@
+// Package push implements receiving Prometheus remote_write requests and
+// appending them to storage.
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/textparse"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/storage/remote"
+)
+
+// metricFamilySuffixes are suffixes which may be appended to a metric family
+// name to form the name of an individual series.
+var metricFamilySuffixes = []string{"_bucket", "_sum", "_count", "_gsum", "_gcount", "_total", "_created", "_info"}
+
+// handler is an http.Handler which accepts remote_write requests and
+// appends them to a storage.Appendable.
+type handler struct {
+	log        log.Logger
+	appendable storage.Appendable
+}
+
+// NewHandler returns an http.Handler which accepts remote_write requests and
+// appends them to appendable.
+//
+// Unlike the handler from the Prometheus remote package, the returned handler
+// also stores the metadata sent with requests.
+func NewHandler(l log.Logger, appendable storage.Appendable) http.Handler {
+	return &handler{log: l, appendable: appendable}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := remote.DecodeWriteRequest(r.Body)
+	if err != nil {
+		level.Error(h.log).Log("msg", "error decoding remote write request", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := Write(r.Context(), h.log, h.appendable, req); err != nil {
+		code := ErrorStatus(err)
+		if code == http.StatusInternalServerError {
+			level.Error(h.log).Log("msg", "error appending remote write", "err", err)
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ErrorStatus returns the HTTP status code to respond with for an error
+// returned by Write. Samples which can never be appended are reported as a
+// bad request to prevent clients from retrying them.
+func ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrOutOfOrderSample), errors.Is(err, storage.ErrOutOfBounds), errors.Is(err, storage.ErrDuplicateSampleForTimestamp):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Write appends the series in req to appendable. Metadata in req is stored
+// for every series of the metric family it describes. Either all samples in
+// req are committed or none are.
+//
+// Exemplars and metadata are best-effort; failing to append them doesn't
+// cause Write to fail.
+func Write(ctx context.Context, l log.Logger, appendable storage.Appendable, req *prompb.WriteRequest) (err error) {
+	app := appendable.Appender(ctx)
+	defer func() {
+		if err != nil {
+			_ = app.Rollback()
+			return
+		}
+		err = app.Commit()
+	}()
+
+	families := metadataByFamily(req.Metadata)
+
+	for _, ts := range req.Timeseries {
+		lbls := LabelProtosToLabels(ts.Labels)
+
+		var ref storage.SeriesRef
+		for _, s := range ts.Samples {
+			ref, err = app.Append(ref, lbls, s.Timestamp, s.Value)
+			if err != nil {
+				return fmt.Errorf("appending sample for series %s: %w", lbls, err)
+			}
+		}
+		if len(ts.Samples) == 0 {
+			continue
+		}
+
+		for _, ep := range ts.Exemplars {
+			e := exemplar.Exemplar{
+				Labels: LabelProtosToLabels(ep.Labels),
+				Value:  ep.Value,
+				Ts:     ep.Timestamp,
+				HasTs:  ep.Timestamp != 0,
+			}
+			if _, err := app.AppendExemplar(ref, lbls, e); err != nil {
+				level.Debug(l).Log("msg", "failed to append exemplar", "series", lbls, "err", err)
+			}
+		}
+		if md, ok := lookupMetadata(families, lbls.Get(labels.MetricName)); ok {
+			if _, err := app.UpdateMetadata(ref, lbls, md); err != nil {
+				level.Debug(l).Log("msg", "failed to update metadata", "series", lbls, "err", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// metadataByFamily indexes metadata from a remote_write request by metric
+// family name.
+func metadataByFamily(mm []prompb.MetricMetadata) map[string]metadata.Metadata {
+	if len(mm) == 0 {
+		return nil
+	}
+
+	res := make(map[string]metadata.Metadata, len(mm))
+	for _, m := range mm {
+		res[m.MetricFamilyName] = metadata.Metadata{
+			Type: textparse.MetricType(strings.ToLower(m.Type.String())),
+			Unit: m.Unit,
+			Help: m.Help,
+		}
+	}
+	return res
+}
+
+// lookupMetadata finds the metadata for the series with the given metric
+// name. The metric name is first checked as a family name, and then checked
+// with known suffixes removed.
+func lookupMetadata(families map[string]metadata.Metadata, name string) (metadata.Metadata, bool) {
+	if len(families) == 0 || name == "" {
+		return metadata.Metadata{}, false
+	}
+	if md, ok := families[name]; ok {
+		return md, true
+	}
+	for _, suffix := range metricFamilySuffixes {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		if md, ok := families[strings.TrimSuffix(name, suffix)]; ok {
+			return md, true
+		}
+	}
+	return metadata.Metadata{}, false
+}
+
+// LabelProtosToLabels converts remote_write labels into labels.Labels. The
+// resulting labels are sorted by name.
+func LabelProtosToLabels(lbls []prompb.Label) labels.Labels {
+	res := make(labels.Labels, 0, len(lbls))
+	for _, l := range lbls {
+		res = append(res, labels.Label{Name: l.Name, Value: l.Value})
+	}
+	sort.Sort(res)
+	return res
+}
@@ -0,0 +1,68 @@
+package push
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/golang/snappy"
+	"github.com/grafana/agent/pkg/metrics/wal"
+	"github.com/prometheus/prometheus/model/textparse"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Metadata(t *testing.T) {
+	s, err := wal.NewStorage(log.NewNopLogger(), nil, t.TempDir())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "requests_total"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "latency_seconds_count"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "no_metadata"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: 1}},
+			},
+		},
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "requests_total", Type: prompb.MetricMetadata_COUNTER, Help: "Total requests."},
+			{MetricFamilyName: "latency_seconds", Type: prompb.MetricMetadata_SUMMARY, Help: "Request latency.", Unit: "seconds"},
+		},
+	}
+	bb, err := req.Marshal()
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	NewHandler(log.NewNopLogger(), s).ServeHTTP(rr, httptest.NewRequest("POST", "/write", bytes.NewReader(snappy.Encode(nil, bb))))
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	require.ElementsMatch(t, []scrape.MetricMetadata{
+		{Metric: "requests_total", Type: textparse.MetricTypeCounter, Help: "Total requests."},
+		{Metric: "latency_seconds", Type: textparse.MetricTypeSummary, Help: "Request latency.", Unit: "seconds"},
+	}, s.ListMetadata())
+}
+
+func TestHandler_BadRequest(t *testing.T) {
+	s, err := wal.NewStorage(log.NewNopLogger(), nil, t.TempDir())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	rr := httptest.NewRecorder()
+	NewHandler(log.NewNopLogger(), s).ServeHTTP(rr, httptest.NewRequest("POST", "/write", bytes.NewReader([]byte("not snappy"))))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,255 @@
+package wal
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/textparse"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/prometheus/prometheus/storage/remote"
+	"gopkg.in/yaml.v2"
+)
+
+// MetadataLister lists the metadata of stored series.
+type MetadataLister interface {
+	ListMetadata() []scrape.MetricMetadata
+}
+
+// MetadataSender periodically sends the metadata listed by a set of
+// MetadataListers, such as a Storage, to every remote_write endpoint which
+// has metadata sending enabled.
+//
+// The remote_write queues only read samples, exemplars and series from the
+// WAL, and their metadata watcher can only read metadata from a scrape
+// manager. MetadataSender replaces the metadata watcher of the queues, so
+// the remote storage must be given configs from WithoutMetadataSending to
+// avoid sending metadata twice. Because metadata is replayed along with
+// series, it is available again after a restart without needing to be
+// re-appended.
+type MetadataSender struct {
+	log     log.Logger
+	listers []MetadataLister
+
+	mut     sync.Mutex
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewMetadataSender creates a new MetadataSender which sends the metadata of
+// listers. Call ApplyConfig to start sending metadata.
+func NewMetadataSender(l log.Logger, listers ...MetadataLister) *MetadataSender {
+	if l == nil {
+		l = log.NewNopLogger()
+	}
+	return &MetadataSender{log: l, listers: listers}
+}
+
+// WithoutMetadataSending returns copies of cfgs with metadata sending
+// disabled, to be applied to a remote storage whose metadata is sent by a
+// MetadataSender instead. Unnamed configs are named after the hash of the
+// original config, which is the name the remote storage would give them, so
+// that the names of queues don't change.
+func WithoutMetadataSending(cfgs []*config.RemoteWriteConfig) ([]*config.RemoteWriteConfig, error) {
+	res := make([]*config.RemoteWriteConfig, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		name, err := remoteWriteName(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		copied := *cfg
+		copied.Name = name
+		copied.MetadataConfig.Send = false
+		res = append(res, &copied)
+	}
+	return res, nil
+}
+
+// remoteWriteName returns the name of the queue of cfg in a remote storage.
+func remoteWriteName(cfg *config.RemoteWriteConfig) (string, error) {
+	if cfg.Name != "" {
+		return cfg.Name, nil
+	}
+	bb, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	hash := md5.Sum(bb)
+	return hex.EncodeToString(hash[:])[:6], nil
+}
+
+// ApplyConfig updates the set of endpoints metadata is sent to. Endpoints
+// where metadata_config.send is false are ignored. If ApplyConfig fails, the
+// previous set of endpoints is kept.
+func (ms *MetadataSender) ApplyConfig(cfgs []*config.RemoteWriteConfig) error {
+	var senders []*endpointMetadataSender
+	for _, cfg := range cfgs {
+		if !cfg.MetadataConfig.Send {
+			continue
+		}
+
+		name, err := remoteWriteName(cfg)
+		if err != nil {
+			return err
+		}
+		client, err := remote.NewWriteClient(name, &remote.ClientConfig{
+			URL:              cfg.URL,
+			Timeout:          cfg.RemoteTimeout,
+			HTTPClientConfig: cfg.HTTPClientConfig,
+			SigV4Config:      cfg.SigV4Config,
+			Headers:          cfg.Headers,
+			RetryOnRateLimit: cfg.QueueConfig.RetryOnRateLimit,
+		})
+		if err != nil {
+			return fmt.Errorf("creating metadata client for %s: %w", name, err)
+		}
+
+		senders = append(senders, &endpointMetadataSender{
+			log:    log.With(ms.log, "remote_name", name),
+			list:   ms.listMetadata,
+			client: client,
+			cfg:    cfg.MetadataConfig,
+		})
+	}
+
+	ms.mut.Lock()
+	defer ms.mut.Unlock()
+
+	ms.stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ms.cancel = cancel
+	for _, s := range senders {
+		ms.running.Add(1)
+		go func(s *endpointMetadataSender) {
+			defer ms.running.Done()
+			s.run(ctx)
+		}(s)
+	}
+	return nil
+}
+
+// Stop stops sending metadata to all endpoints.
+func (ms *MetadataSender) Stop() {
+	ms.mut.Lock()
+	defer ms.mut.Unlock()
+	ms.stop()
+}
+
+// stop stops all running senders. ms.mut must be held when calling stop.
+func (ms *MetadataSender) stop() {
+	if ms.cancel != nil {
+		ms.cancel()
+		ms.cancel = nil
+	}
+	ms.running.Wait()
+}
+
+// listMetadata returns the metadata of all listers, without duplicates.
+func (ms *MetadataSender) listMetadata() []scrape.MetricMetadata {
+	if len(ms.listers) == 1 {
+		return ms.listers[0].ListMetadata()
+	}
+
+	var (
+		res = []scrape.MetricMetadata{}
+		set = map[scrape.MetricMetadata]struct{}{}
+	)
+	for _, l := range ms.listers {
+		for _, md := range l.ListMetadata() {
+			if _, ok := set[md]; ok {
+				continue
+			}
+			set[md] = struct{}{}
+			res = append(res, md)
+		}
+	}
+	return res
+}
+
+// endpointMetadataSender sends metadata to a single remote_write endpoint.
+type endpointMetadataSender struct {
+	log    log.Logger
+	list   func() []scrape.MetricMetadata
+	client remote.WriteClient
+	cfg    config.MetadataConfig
+}
+
+func (s *endpointMetadataSender) run(ctx context.Context) {
+	interval := time.Duration(s.cfg.SendInterval)
+	if interval <= 0 {
+		interval = time.Duration(config.DefaultMetadataConfig.SendInterval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.send(ctx, s.list()); err != nil {
+				// Metadata is sent again on the next interval, so failures don't
+				// need to be retried.
+				level.Warn(s.log).Log("msg", "failed to send metadata", "err", err)
+			}
+		}
+	}
+}
+
+// send sends metadata to the endpoint in batches of at most
+// MaxSamplesPerSend entries.
+func (s *endpointMetadataSender) send(ctx context.Context, metadata []scrape.MetricMetadata) error {
+	batchSize := s.cfg.MaxSamplesPerSend
+	if batchSize <= 0 {
+		batchSize = config.DefaultMetadataConfig.MaxSamplesPerSend
+	}
+
+	for len(metadata) > 0 {
+		n := batchSize
+		if n > len(metadata) {
+			n = len(metadata)
+		}
+
+		batch := make([]prompb.MetricMetadata, 0, n)
+		for _, md := range metadata[:n] {
+			batch = append(batch, prompb.MetricMetadata{
+				MetricFamilyName: md.Metric,
+				Type:             metricTypeToProto(md.Type),
+				Help:             md.Help,
+				Unit:             md.Unit,
+			})
+		}
+		metadata = metadata[n:]
+
+		req := &prompb.WriteRequest{Metadata: batch}
+		bb, err := req.Marshal()
+		if err != nil {
+			return fmt.Errorf("encoding metadata: %w", err)
+		}
+		if err := s.client.Store(ctx, snappy.Encode(nil, bb)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func metricTypeToProto(t textparse.MetricType) prompb.MetricMetadata_MetricType {
+	v, ok := prompb.MetricMetadata_MetricType_value[strings.ToUpper(string(t))]
+	if !ok {
+		return prompb.MetricMetadata_UNKNOWN
+	}
+	return prompb.MetricMetadata_MetricType(v)
+}
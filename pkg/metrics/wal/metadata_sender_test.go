@@ -0,0 +1,130 @@
+package wal
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	config_util "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/textparse"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/prometheus/prometheus/storage/remote"
+	"github.com/stretchr/testify/require"
+)
+
+type staticMetadata []scrape.MetricMetadata
+
+func (s staticMetadata) ListMetadata() []scrape.MetricMetadata { return s }
+
+func TestMetadataSender(t *testing.T) {
+	received := make(chan *prompb.WriteRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		bb, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+
+		var req prompb.WriteRequest
+		require.NoError(t, req.Unmarshal(bb))
+		select {
+		case received <- &req:
+		default:
+		}
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	sender := NewMetadataSender(log.NewNopLogger(), staticMetadata{
+		{Metric: "requests_total", Type: textparse.MetricTypeCounter, Help: "Total requests."},
+		{Metric: "latency_seconds", Type: textparse.MetricTypeHistogram, Help: "Request latency."},
+	})
+	defer sender.Stop()
+
+	rwConfig := config.DefaultRemoteWriteConfig
+	rwConfig.URL = &config_util.URL{URL: u}
+	rwConfig.MetadataConfig = config.MetadataConfig{
+		Send:              true,
+		SendInterval:      model.Duration(10 * time.Millisecond),
+		MaxSamplesPerSend: 1,
+	}
+	require.NoError(t, sender.ApplyConfig([]*config.RemoteWriteConfig{&rwConfig}))
+
+	// Metadata should be sent in batches of MaxSamplesPerSend.
+	var got []prompb.MetricMetadata
+	for len(got) < 2 {
+		select {
+		case req := <-received:
+			require.Len(t, req.Metadata, 1)
+			got = append(got, req.Metadata...)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for metadata")
+		}
+	}
+	require.Equal(t, []prompb.MetricMetadata{
+		{MetricFamilyName: "requests_total", Type: prompb.MetricMetadata_COUNTER, Help: "Total requests."},
+		{MetricFamilyName: "latency_seconds", Type: prompb.MetricMetadata_HISTOGRAM, Help: "Request latency."},
+	}, got)
+}
+
+func TestMetadataSender_ListMetadata(t *testing.T) {
+	requests := scrape.MetricMetadata{Metric: "requests_total", Type: textparse.MetricTypeCounter, Help: "Total requests."}
+	latency := scrape.MetricMetadata{Metric: "latency_seconds", Type: textparse.MetricTypeHistogram, Help: "Request latency."}
+
+	// Metadata listed by several listers is only sent once.
+	sender := NewMetadataSender(log.NewNopLogger(), staticMetadata{requests}, staticMetadata{requests, latency})
+	require.Equal(t, []scrape.MetricMetadata{requests, latency}, sender.listMetadata())
+}
+
+func TestWithoutMetadataSending(t *testing.T) {
+	u, err := url.Parse("http://localhost:9009/api/prom/push")
+	require.NoError(t, err)
+
+	unnamed := config.DefaultRemoteWriteConfig
+	unnamed.URL = &config_util.URL{URL: u}
+	named := unnamed
+	named.Name = "named"
+
+	cfgs, err := WithoutMetadataSending([]*config.RemoteWriteConfig{&unnamed, &named})
+	require.NoError(t, err)
+	require.Len(t, cfgs, 2)
+
+	for _, cfg := range cfgs {
+		require.False(t, cfg.MetadataConfig.Send)
+	}
+	require.True(t, unnamed.MetadataConfig.Send, "original configs should be unchanged")
+
+	// Unnamed configs keep the name the remote storage would have given them.
+	reg := prometheus.NewRegistry()
+	remoteStore := remote.NewStorage(nil, reg, func() (int64, error) { return 0, nil }, t.TempDir(), time.Second, nil)
+	defer remoteStore.Close()
+	require.NoError(t, remoteStore.ApplyConfig(&config.Config{RemoteWriteConfigs: []*config.RemoteWriteConfig{&unnamed}}))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	var queueNames []string
+	for _, family := range families {
+		if family.GetName() != "prometheus_remote_storage_shards_max" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "remote_name" {
+					queueNames = append(queueNames, l.GetValue())
+				}
+			}
+		}
+	}
+	require.Equal(t, []string{cfgs[0].Name}, queueNames)
+	require.Equal(t, "named", cfgs[1].Name)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/intern"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
 	"github.com/prometheus/prometheus/tsdb/chunks"
 )
 
@@ -16,6 +17,10 @@ type memSeries struct {
 	lset   labels.Labels
 	lastTs int64
 
+	// meta is the latest metadata committed for the series. nil if the series
+	// never received metadata.
+	meta *metadata.Metadata
+
 	// TODO(rfratto): this solution below isn't perfect, and there's still
 	// the possibility for a series to be deleted before it's
 	// completely gone from the WAL. Rather, we should have gc return
@@ -135,10 +140,10 @@ func newStripeSeries() *stripeSeries {
 }
 
 // gc garbage collects old chunks that are strictly before mint and removes
-// series entirely that have no chunks left.
-func (s *stripeSeries) gc(mint int64) map[chunks.HeadSeriesRef]struct{} {
+// series entirely that have no chunks left. The deleted series are returned.
+func (s *stripeSeries) gc(mint int64) map[chunks.HeadSeriesRef]*memSeries {
 	var (
-		deleted = map[chunks.HeadSeriesRef]struct{}{}
+		deleted = map[chunks.HeadSeriesRef]*memSeries{}
 	)
 
 	// Run through all series and find series that haven't been written to
@@ -174,7 +179,7 @@ func (s *stripeSeries) gc(mint int64) map[chunks.HeadSeriesRef]struct{} {
 				s.locks[j].Lock()
 			}
 
-			deleted[series.ref] = struct{}{}
+			deleted[series.ref] = series
 			delete(s.series[i], series.ref)
 			s.hashes[j].del(seriesHash, series.ref)
 
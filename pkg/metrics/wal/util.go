@@ -8,6 +8,12 @@ import (
 	"github.com/prometheus/prometheus/tsdb/wal"
 )
 
+// metadataWriteTo is implemented by a wal.WriteTo which also accepts
+// metadata records.
+type metadataWriteTo interface {
+	StoreMetadata([]record.RefMetadata)
+}
+
 type walReplayer struct {
 	w wal.WriteTo
 }
@@ -88,6 +94,16 @@ func (r walReplayer) replayWAL(reader *wal.Reader) error {
 				return err
 			}
 			r.w.AppendExemplars(exemplars)
+		case record.Metadata:
+			mw, ok := r.w.(metadataWriteTo)
+			if !ok {
+				continue
+			}
+			metadata, err := dec.Metadata(rec, nil)
+			if err != nil {
+				return err
+			}
+			mw.StoreMetadata(metadata)
 		}
 	}
 
@@ -99,6 +115,14 @@ type walDataCollector struct {
 	samples   []record.RefSample
 	series    []record.RefSeries
 	exemplars []record.RefExemplar
+	metadata  []record.RefMetadata
+}
+
+func (c *walDataCollector) StoreMetadata(metadata []record.RefMetadata) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.metadata = append(c.metadata, metadata...)
 }
 
 func (c *walDataCollector) AppendExemplars(exemplars []record.RefExemplar) bool {
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 	"unicode/utf8"
@@ -15,8 +17,10 @@ import (
 	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/textparse"
 	"github.com/prometheus/prometheus/model/timestamp"
 	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/scrape"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/prometheus/prometheus/tsdb/chunks"
@@ -38,6 +42,7 @@ type storageMetrics struct {
 	totalRemovedSeries     prometheus.Counter
 	totalAppendedSamples   prometheus.Counter
 	totalAppendedExemplars prometheus.Counter
+	totalAppendedMetadata  prometheus.Counter
 }
 
 func newStorageMetrics(r prometheus.Registerer) *storageMetrics {
@@ -72,6 +77,11 @@ func newStorageMetrics(r prometheus.Registerer) *storageMetrics {
 		Help: "Total number of exemplars appended to the WAL",
 	})
 
+	m.totalAppendedMetadata = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "agent_wal_metadata_appended_total",
+		Help: "Total number of metadata updates appended to the WAL",
+	})
+
 	if r != nil {
 		r.MustRegister(
 			m.numActiveSeries,
@@ -80,6 +90,7 @@ func newStorageMetrics(r prometheus.Registerer) *storageMetrics {
 			m.totalRemovedSeries,
 			m.totalAppendedSamples,
 			m.totalAppendedExemplars,
+			m.totalAppendedMetadata,
 		)
 	}
 
@@ -97,6 +108,7 @@ func (m *storageMetrics) Unregister() {
 		m.totalRemovedSeries,
 		m.totalAppendedSamples,
 		m.totalAppendedExemplars,
+		m.totalAppendedMetadata,
 	}
 	for _, c := range cs {
 		m.r.Unregister(c)
@@ -129,6 +141,13 @@ type Storage struct {
 	deletedMtx sync.Mutex
 	deleted    map[chunks.HeadSeriesRef]int // Deleted series, and what WAL segment they must be kept until.
 
+	// familyMetadata holds the metadata of every metric family with active
+	// series, and how many series currently have it. It's kept up to date
+	// with the metadata of series so that metadata can be listed without
+	// iterating over every series.
+	familyMetadataMtx sync.Mutex
+	familyMetadata    map[scrape.MetricMetadata]int
+
 	metrics *storageMetrics
 }
 
@@ -147,6 +166,8 @@ func NewStorage(logger log.Logger, registerer prometheus.Registerer, path string
 		series:  newStripeSeries(),
 		metrics: newStorageMetrics(registerer),
 		ref:     atomic.NewUint64(0),
+
+		familyMetadata: map[scrape.MetricMetadata]int{},
 	}
 
 	storage.bufPool.New = func() interface{} {
@@ -160,6 +181,7 @@ func NewStorage(logger log.Logger, registerer prometheus.Registerer, path string
 			series:    make([]record.RefSeries, 0, 100),
 			samples:   make([]record.RefSample, 0, 100),
 			exemplars: make([]record.RefExemplar, 0, 10),
+			metadata:  make([]record.RefMetadata, 0, 10),
 		}
 	}
 
@@ -296,6 +318,17 @@ func (w *Storage) loadWAL(r *wal.Reader) (err error) {
 					}
 				}
 				decoded <- samples
+			case record.Metadata:
+				meta, err := dec.Metadata(rec, nil)
+				if err != nil {
+					errCh <- &wal.CorruptionErr{
+						Err:     fmt.Errorf("decode metadata: %w", err),
+						Segment: r.Segment(),
+						Offset:  r.Offset(),
+					}
+					return
+				}
+				decoded <- meta
 			case record.Tombstones, record.Exemplars:
 				// We don't care about decoding tombstones or exemplars
 				// TODO: If decide to decode exemplars, we should make sure to prepopulate
@@ -355,6 +388,17 @@ func (w *Storage) loadWAL(r *wal.Reader) (err error) {
 
 			//nolint:staticcheck
 			samplesPool.Put(v)
+		case []record.RefMetadata:
+			for _, m := range v {
+				// Metadata records are kept in checkpoints only for series which
+				// are kept, so missing series can be ignored.
+				series := w.series.getByID(m.Ref)
+				if series == nil {
+					continue
+				}
+
+				w.setSeriesMetadata(series, refMetadataToMetadata(m))
+			}
 		default:
 			panic(fmt.Errorf("unexpected decoded type: %T", d))
 		}
@@ -480,6 +524,14 @@ func (w *Storage) gc(mint int64) {
 	deleted := w.series.gc(mint)
 	w.metrics.numActiveSeries.Sub(float64(len(deleted)))
 
+	for _, series := range deleted {
+		series.Lock()
+		if series.meta != nil {
+			w.updateFamilyMetadata(series.lset, series.meta, nil)
+		}
+		series.Unlock()
+	}
+
 	_, last, _ := wal.Segments(w.wal.Dir())
 	w.deletedMtx.Lock()
 	defer w.deletedMtx.Unlock()
@@ -575,11 +627,110 @@ func (w *Storage) Close() error {
 	return w.wal.Close()
 }
 
+// ListMetadata returns the latest metadata of every metric family which has
+// an active series in the WAL. Metadata is recorded per series, so metadata
+// for the series of histograms and summaries is returned under the name of
+// their metric family.
+func (w *Storage) ListMetadata() []scrape.MetricMetadata {
+	w.familyMetadataMtx.Lock()
+	defer w.familyMetadataMtx.Unlock()
+
+	res := make([]scrape.MetricMetadata, 0, len(w.familyMetadata))
+	for md := range w.familyMetadata {
+		res = append(res, md)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		switch {
+		case a.Metric != b.Metric:
+			return a.Metric < b.Metric
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		case a.Help != b.Help:
+			return a.Help < b.Help
+		default:
+			return a.Unit < b.Unit
+		}
+	})
+	return res
+}
+
+// setSeriesMetadata sets the latest metadata of series.
+func (w *Storage) setSeriesMetadata(series *memSeries, meta metadata.Metadata) {
+	series.Lock()
+	defer series.Unlock()
+	if series.meta != nil && *series.meta == meta {
+		return
+	}
+	w.updateFamilyMetadata(series.lset, series.meta, &meta)
+	series.meta = &meta
+}
+
+// updateFamilyMetadata updates the metadata of metric families when the
+// metadata of the series with labels lset changes from oldMeta to newMeta.
+// Either may be nil.
+func (w *Storage) updateFamilyMetadata(lset labels.Labels, oldMeta, newMeta *metadata.Metadata) {
+	w.familyMetadataMtx.Lock()
+	defer w.familyMetadataMtx.Unlock()
+
+	name := lset.Get(labels.MetricName)
+	if oldMeta != nil {
+		md := familyMetadata(name, *oldMeta)
+		if w.familyMetadata[md]--; w.familyMetadata[md] <= 0 {
+			delete(w.familyMetadata, md)
+		}
+	}
+	if newMeta != nil {
+		w.familyMetadata[familyMetadata(name, *newMeta)]++
+	}
+}
+
+// familyMetadata returns the metadata of the metric family which the series
+// named seriesName belongs to.
+func familyMetadata(seriesName string, meta metadata.Metadata) scrape.MetricMetadata {
+	return scrape.MetricMetadata{
+		Metric: metricFamilyName(seriesName, meta.Type),
+		Type:   meta.Type,
+		Help:   meta.Help,
+		Unit:   meta.Unit,
+	}
+}
+
+// metricFamilyName returns the name of the metric family the series named
+// seriesName belongs to.
+func metricFamilyName(seriesName string, t textparse.MetricType) string {
+	var suffixes []string
+	switch t {
+	case textparse.MetricTypeHistogram:
+		suffixes = []string{"_bucket", "_sum", "_count"}
+	case textparse.MetricTypeGaugeHistogram:
+		suffixes = []string{"_bucket", "_gsum", "_gcount"}
+	case textparse.MetricTypeSummary:
+		suffixes = []string{"_sum", "_count"}
+	}
+
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(seriesName, suffix) {
+			return strings.TrimSuffix(seriesName, suffix)
+		}
+	}
+	return seriesName
+}
+
+func refMetadataToMetadata(m record.RefMetadata) metadata.Metadata {
+	return metadata.Metadata{
+		Type: record.ToTextparseMetricType(m.Type),
+		Unit: m.Unit,
+		Help: m.Help,
+	}
+}
+
 type appender struct {
 	w         *Storage
 	series    []record.RefSeries
 	samples   []record.RefSample
 	exemplars []record.RefExemplar
+	metadata  []record.RefMetadata
 }
 
 func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
@@ -687,9 +838,34 @@ func (a *appender) AppendExemplar(ref storage.SeriesRef, _ labels.Labels, e exem
 	return storage.SeriesRef(s.ref), nil
 }
 
-func (a *appender) UpdateMetadata(ref storage.SeriesRef, _ labels.Labels, m metadata.Metadata) (storage.SeriesRef, error) {
-	// TODO(rfratto): implement pushing metadata to WAL
-	return 0, nil
+func (a *appender) UpdateMetadata(ref storage.SeriesRef, l labels.Labels, m metadata.Metadata) (storage.SeriesRef, error) {
+	cref := chunks.HeadSeriesRef(ref)
+	s := a.w.series.getByID(cref)
+	if s == nil {
+		s = a.w.series.getByHash(l.Hash(), l)
+	}
+	if s == nil {
+		return 0, fmt.Errorf("unknown series when trying to add metadata with ref %d and labels %s", cref, l)
+	}
+
+	// Only record metadata which differs from the latest committed metadata
+	// for the series so metadata isn't written to the WAL on every scrape.
+	s.Lock()
+	changed := s.meta == nil || *s.meta != m
+	s.Unlock()
+	if !changed {
+		return storage.SeriesRef(s.ref), nil
+	}
+
+	a.metadata = append(a.metadata, record.RefMetadata{
+		Ref:  s.ref,
+		Type: record.GetMetricType(m.Type),
+		Unit: m.Unit,
+		Help: m.Help,
+	})
+
+	a.w.metrics.totalAppendedMetadata.Inc()
+	return storage.SeriesRef(s.ref), nil
 }
 
 // Commit submits the collected samples and purges the batch.
@@ -728,6 +904,16 @@ func (a *appender) Commit() error {
 		buf = buf[:0]
 	}
 
+	// The remote_write WAL watcher doesn't read metadata records; metadata is
+	// sent from memory by a MetadataSender instead.
+	if len(a.metadata) > 0 {
+		buf = encoder.Metadata(a.metadata, buf)
+		if err := a.w.wal.Log(buf); err != nil {
+			return err
+		}
+		buf = buf[:0]
+	}
+
 	//nolint:staticcheck
 	a.w.bufPool.Put(buf)
 
@@ -740,6 +926,13 @@ func (a *appender) Commit() error {
 		}
 	}
 
+	for _, m := range a.metadata {
+		series := a.w.series.getByID(m.Ref)
+		if series != nil {
+			a.w.setSeriesMetadata(series, refMetadataToMetadata(m))
+		}
+	}
+
 	return a.Rollback()
 }
 
@@ -747,6 +940,7 @@ func (a *appender) Rollback() error {
 	a.series = a.series[:0]
 	a.samples = a.samples[:0]
 	a.exemplars = a.exemplars[:0]
+	a.metadata = a.metadata[:0]
 	a.w.appenderPool.Put(a)
 	return nil
 }
@@ -12,7 +12,10 @@ import (
 	"github.com/grafana/agent/pkg/util"
 	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/textparse"
 	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/scrape"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/prometheus/prometheus/tsdb/chunks"
@@ -372,6 +375,182 @@ func TestStorage_TruncateAfterClose(t *testing.T) {
 	require.Error(t, ErrWALClosed, s.Truncate(0))
 }
 
+func TestStorage_Metadata(t *testing.T) {
+	walDir, err := os.MkdirTemp(os.TempDir(), "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(walDir)
+
+	s, err := NewStorage(log.NewNopLogger(), nil, walDir)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	var (
+		lbls = labels.FromStrings("__name__", "http_requests_total", "job", "test")
+		md   = metadata.Metadata{Type: textparse.MetricTypeCounter, Help: "Total HTTP requests.", Unit: "requests"}
+	)
+
+	app := s.Appender(context.Background())
+	ref, err := app.Append(0, lbls, 1, 1)
+	require.NoError(t, err)
+	_, err = app.UpdateMetadata(ref, lbls, md)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	// Updating the series with unchanged metadata shouldn't write a new record.
+	app = s.Appender(context.Background())
+	_, err = app.Append(ref, lbls, 2, 2)
+	require.NoError(t, err)
+	_, err = app.UpdateMetadata(ref, lbls, md)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	// Metadata can't be added for unknown series.
+	app = s.Appender(context.Background())
+	_, err = app.UpdateMetadata(0, labels.FromStrings("__name__", "unknown"), md)
+	require.Error(t, err)
+	require.NoError(t, app.Rollback())
+
+	collector := walDataCollector{}
+	replayer := walReplayer{w: &collector}
+	require.NoError(t, replayer.Replay(s.wal.Dir()))
+
+	require.Equal(t, []record.RefMetadata{{
+		Ref:  chunks.HeadSeriesRef(ref),
+		Type: record.GetMetricType(md.Type),
+		Unit: md.Unit,
+		Help: md.Help,
+	}}, collector.metadata)
+
+	require.Equal(t, []scrape.MetricMetadata{{
+		Metric: "http_requests_total",
+		Type:   md.Type,
+		Help:   md.Help,
+		Unit:   md.Unit,
+	}}, s.ListMetadata())
+}
+
+func TestStorage_Metadata_Changed(t *testing.T) {
+	s, err := NewStorage(log.NewNopLogger(), nil, t.TempDir())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	update := func(lbls labels.Labels, md metadata.Metadata) {
+		app := s.Appender(context.Background())
+		ref, err := app.Append(0, lbls, 1, 1)
+		require.NoError(t, err)
+		_, err = app.UpdateMetadata(ref, lbls, md)
+		require.NoError(t, err)
+		require.NoError(t, app.Commit())
+	}
+
+	var (
+		a = labels.FromStrings("__name__", "queue_length", "queue", "a")
+		b = labels.FromStrings("__name__", "queue_length", "queue", "b")
+	)
+	update(a, metadata.Metadata{Type: textparse.MetricTypeGauge, Help: "Old help."})
+	update(b, metadata.Metadata{Type: textparse.MetricTypeGauge, Help: "Old help."})
+
+	// The old metadata is listed until no series has it anymore.
+	update(a, metadata.Metadata{Type: textparse.MetricTypeGauge, Help: "New help."})
+	require.Equal(t, []scrape.MetricMetadata{
+		{Metric: "queue_length", Type: textparse.MetricTypeGauge, Help: "New help."},
+		{Metric: "queue_length", Type: textparse.MetricTypeGauge, Help: "Old help."},
+	}, s.ListMetadata())
+
+	update(b, metadata.Metadata{Type: textparse.MetricTypeGauge, Help: "New help."})
+	require.Equal(t, []scrape.MetricMetadata{
+		{Metric: "queue_length", Type: textparse.MetricTypeGauge, Help: "New help."},
+	}, s.ListMetadata())
+}
+
+func TestStorage_Metadata_ExistingWAL(t *testing.T) {
+	walDir, err := os.MkdirTemp(os.TempDir(), "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(walDir)
+
+	s, err := NewStorage(log.NewNopLogger(), nil, walDir)
+	require.NoError(t, err)
+
+	app := s.Appender(context.Background())
+	for _, name := range []string{"request_duration_seconds_bucket", "request_duration_seconds_sum", "request_duration_seconds_count"} {
+		lbls := labels.FromStrings("__name__", name)
+		ref, err := app.Append(0, lbls, 1, 1)
+		require.NoError(t, err)
+		_, err = app.UpdateMetadata(ref, lbls, metadata.Metadata{Type: textparse.MetricTypeHistogram, Help: "Request duration."})
+		require.NoError(t, err)
+	}
+	require.NoError(t, app.Commit())
+	require.NoError(t, s.Close())
+
+	// Metadata should be restored when the WAL is replayed.
+	s, err = NewStorage(log.NewNopLogger(), nil, walDir)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	require.Equal(t, []scrape.MetricMetadata{{
+		Metric: "request_duration_seconds",
+		Type:   textparse.MetricTypeHistogram,
+		Help:   "Request duration.",
+	}}, s.ListMetadata())
+}
+
+func TestStorage_Metadata_Truncate(t *testing.T) {
+	walDir, err := os.MkdirTemp(os.TempDir(), "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(walDir)
+
+	s, err := NewStorage(log.NewNopLogger(), nil, walDir)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	app := s.Appender(context.Background())
+	for i, name := range []string{"old", "new"} {
+		lbls := labels.FromStrings("__name__", name)
+		ref, err := app.Append(0, lbls, int64(i*100), 1)
+		require.NoError(t, err)
+		_, err = app.UpdateMetadata(ref, lbls, metadata.Metadata{Type: textparse.MetricTypeGauge, Help: name})
+		require.NoError(t, err)
+	}
+	require.NoError(t, app.Commit())
+
+	// Forcefully create new segments so there's enough segments to checkpoint.
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.wal.NextSegment())
+	}
+
+	// Truncating should garbage collect the old series along with its
+	// metadata, while keeping the metadata of the new series in the
+	// checkpoint. Series are only deleted after two truncations.
+	require.NoError(t, s.Truncate(50))
+	require.NoError(t, s.Truncate(50))
+
+	require.Equal(t, []scrape.MetricMetadata{{
+		Metric: "new",
+		Type:   textparse.MetricTypeGauge,
+		Help:   "new",
+	}}, s.ListMetadata())
+
+	// The segment with the metadata record has been truncated, so the
+	// metadata must have been kept in the checkpoint.
+	collector := walDataCollector{}
+	replayer := walReplayer{w: &collector}
+	require.NoError(t, replayer.Replay(s.wal.Dir()))
+
+	var help []string
+	for _, md := range collector.metadata {
+		help = append(help, md.Help)
+	}
+	require.Contains(t, help, "new")
+}
+
 func TestGlobalReferenceID_Normal(t *testing.T) {
 	walDir, _ := os.MkdirTemp(os.TempDir(), "wal")
 	defer os.RemoveAll(walDir)
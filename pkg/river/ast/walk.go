@@ -65,6 +65,10 @@ func Walk(v Visitor, node Node) {
 		Walk(v, n.Right)
 	case *ParenExpr:
 		Walk(v, n.Inner)
+	case *ConditionalExpr:
+		Walk(v, n.Condition)
+		Walk(v, n.TrueValue)
+		Walk(v, n.FalseValue)
 	default:
 		panic(fmt.Sprintf("river/ast: unexpected node type %T", n))
 	}
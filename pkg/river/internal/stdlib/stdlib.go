@@ -2,15 +2,24 @@
 package stdlib
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/agent/pkg/river/internal/value"
+	"gopkg.in/yaml.v3"
 )
 
 var goAny = reflect.TypeOf((*interface{})(nil)).Elem()
 
+var errSecretArgument = errors.New("secrets may not be converted into strings; use nonsensitive to reveal them")
+
 // Functions returns the list of stdlib functions by name. The interface{}
 // value is always a River-compatible function value, where functions have at
 // least one non-error return value, with an optionally supported error return
@@ -18,6 +27,153 @@ var goAny = reflect.TypeOf((*interface{})(nil)).Elem()
 var Functions = map[string]interface{}{
 	"env": os.Getenv,
 
+	"env_or": func(name, fallback string) string {
+		if val, ok := os.LookupEnv(name); ok {
+			return val
+		}
+		return fallback
+	},
+
+	"file": func(path string) (string, error) {
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return string(bb), nil
+	},
+
+	// format is implemented as a raw function so that secrets can't be
+	// formatted into plain strings.
+	"format": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if len(args) == 0 {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("expected at least 1 args, got 0"),
+			}
+		}
+		if args[0].Type() != value.TypeString {
+			return value.Null, argError(funcValue, args, 0, value.TypeError{Value: args[0], Expected: value.TypeString})
+		}
+
+		formatArgs := make([]interface{}, 0, len(args)-1)
+		for i := 1; i < len(args); i++ {
+			if containsSecret(args[i]) {
+				return value.Null, argError(funcValue, args, i, errSecretArgument)
+			}
+			var arg interface{}
+			if err := value.Decode(args[i], &arg); err != nil {
+				return value.Null, argError(funcValue, args, i, err)
+			}
+			formatArgs = append(formatArgs, arg)
+		}
+		return value.String(fmt.Sprintf(args[0].Text(), formatArgs...)), nil
+	}),
+
+	"join":        strings.Join,
+	"split":       strings.Split,
+	"replace":     strings.ReplaceAll,
+	"trim":        strings.Trim,
+	"trim_prefix": strings.TrimPrefix,
+	"trim_suffix": strings.TrimSuffix,
+	"trim_space":  strings.TrimSpace,
+	"to_lower":    strings.ToLower,
+	"to_upper":    strings.ToUpper,
+
+	"regex_match": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, value.TypeString, value.TypeString); err != nil {
+			return value.Null, err
+		}
+		re, err := regexp.Compile(args[1].Text())
+		if err != nil {
+			return value.Null, argError(funcValue, args, 1, err)
+		}
+		return value.Bool(re.MatchString(args[0].Text())), nil
+	}),
+
+	"regex_replace": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, value.TypeString, value.TypeString, value.TypeString); err != nil {
+			return value.Null, err
+		}
+		re, err := regexp.Compile(args[1].Text())
+		if err != nil {
+			return value.Null, argError(funcValue, args, 1, err)
+		}
+		return value.String(re.ReplaceAllString(args[0].Text(), args[2].Text())), nil
+	}),
+
+	// coalesce returns the first argument which is not null or an empty value.
+	// null is returned if all arguments are empty.
+	"coalesce": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		for _, arg := range args {
+			if !isEmpty(arg) {
+				return arg, nil
+			}
+		}
+		return value.Null, nil
+	}),
+
+	// nonsensitive converts a secret into a plain string. Strings are returned
+	// unmodified.
+	"nonsensitive": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if len(args) != 1 {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("expected 1 args, got %d", len(args)),
+			}
+		}
+
+		switch arg := args[0]; arg.Type() {
+		case value.TypeString:
+			return arg, nil
+		case value.TypeCapsule:
+			if secret, ok := arg.Interface().(value.SecretCapsule); ok {
+				return value.String(secret.RevealSecret()), nil
+			}
+		}
+		return value.Null, argError(funcValue, args, 0, fmt.Errorf("expected secret or string, got %s", args[0].Type()))
+	}),
+
+	"base64_encode": func(in string) string {
+		return base64.StdEncoding.EncodeToString([]byte(in))
+	},
+
+	"base64_decode": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, value.TypeString); err != nil {
+			return value.Null, err
+		}
+		bb, err := base64.StdEncoding.DecodeString(args[0].Text())
+		if err != nil {
+			return value.Null, argError(funcValue, args, 0, err)
+		}
+		return value.String(string(bb)), nil
+	}),
+
+	"parse_int": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, value.TypeString, value.TypeNumber); err != nil {
+			return value.Null, err
+		}
+		base := args[1].Int()
+		if base != 0 && (base < 2 || base > 36) {
+			return value.Null, argError(funcValue, args, 1, fmt.Errorf("invalid base %d", base))
+		}
+		res, err := strconv.ParseInt(args[0].Text(), int(base), 64)
+		if err != nil {
+			return value.Null, argError(funcValue, args, 0, err)
+		}
+		return value.Int(res), nil
+	}),
+
+	"parse_float": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if err := checkArgs(funcValue, args, value.TypeString); err != nil {
+			return value.Null, err
+		}
+		res, err := strconv.ParseFloat(args[0].Text(), 64)
+		if err != nil {
+			return value.Null, argError(funcValue, args, 0, err)
+		}
+		return value.Float(res), nil
+	}),
+
 	// concat is implemented as a raw function so it can bypass allocations
 	// converting arguments into []interface{}. concat is optimized to allow it
 	// to perform well when it is in the hot path for combining targets from many
@@ -96,4 +252,109 @@ var Functions = map[string]interface{}{
 		}
 		return res, nil
 	},
+
+	// json_encode is implemented as a raw function so that secrets can't be
+	// encoded into plain strings.
+	"json_encode": value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+		if len(args) != 1 {
+			return value.Null, value.Error{
+				Value: funcValue,
+				Inner: fmt.Errorf("expected 1 args, got %d", len(args)),
+			}
+		}
+		if containsSecret(args[0]) {
+			return value.Null, argError(funcValue, args, 0, errSecretArgument)
+		}
+
+		var in interface{}
+		if err := value.Decode(args[0], &in); err != nil {
+			return value.Null, argError(funcValue, args, 0, err)
+		}
+		bb, err := json.Marshal(in)
+		if err != nil {
+			return value.Null, argError(funcValue, args, 0, err)
+		}
+		return value.String(string(bb)), nil
+	}),
+
+	"yaml_decode": func(in string) (interface{}, error) {
+		var res interface{}
+		err := yaml.Unmarshal([]byte(in), &res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	},
+}
+
+// checkArgs validates that args matches the expected types. A value.Error is
+// returned if the number of arguments is wrong, and a value.ArgError is
+// returned if an argument has the wrong type.
+func checkArgs(funcValue value.Value, args []value.Value, types ...value.Type) error {
+	if len(args) != len(types) {
+		return value.Error{
+			Value: funcValue,
+			Inner: fmt.Errorf("expected %d args, got %d", len(types), len(args)),
+		}
+	}
+	for i, ty := range types {
+		if args[i].Type() != ty {
+			return argError(funcValue, args, i, value.TypeError{Value: args[i], Expected: ty})
+		}
+	}
+	return nil
+}
+
+// argError returns a value.ArgError for the argument at index i.
+func argError(funcValue value.Value, args []value.Value, i int, err error) error {
+	return value.ArgError{
+		Function: funcValue,
+		Argument: args[i],
+		Index:    i,
+		Inner:    err,
+	}
+}
+
+// containsSecret returns true if v is, or holds in an array or object, a
+// secret which can't be converted into a plain string.
+func containsSecret(v value.Value) bool {
+	switch v.Type() {
+	case value.TypeCapsule:
+		secret, ok := v.Interface().(value.SecretCapsule)
+		if !ok {
+			return false
+		}
+		if conv, ok := secret.(value.ConvertibleIntoCapsule); ok {
+			var s string
+			return conv.ConvertInto(&s) != nil
+		}
+		return true
+	case value.TypeArray:
+		for i := 0; i < v.Len(); i++ {
+			if containsSecret(v.Index(i)) {
+				return true
+			}
+		}
+	case value.TypeObject:
+		for _, key := range v.Keys() {
+			if field, ok := v.Key(key); ok && containsSecret(field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isEmpty returns true if v is null or an empty string, array, or object.
+func isEmpty(v value.Value) bool {
+	switch v.Type() {
+	case value.TypeNull:
+		return true
+	case value.TypeString:
+		return v.Text() == ""
+	case value.TypeArray, value.TypeObject:
+		return v.Len() == 0
+	default:
+		return false
+	}
 }
@@ -51,3 +51,13 @@ type ConvertibleIntoCapsule interface {
 	// available.
 	ConvertInto(dst interface{}) error
 }
+
+// SecretCapsule is a Capsule which holds a sensitive string. SecretCapsules
+// may only be converted into a plain string by explicitly revealing their
+// value.
+type SecretCapsule interface {
+	Capsule
+
+	// RevealSecret returns the sensitive string held by the capsule.
+	RevealSecret() string
+}
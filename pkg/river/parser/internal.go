@@ -374,9 +374,28 @@ func (n blockName) ValidAttribute() bool {
 
 // ParseExpression parses a single expression.
 //
-//	Expression = BinOpExpr
+//	Expression = BinOpExpr [ "?" Expression ":" Expression ]
+//
+// Conditional expressions are right-associative, so a ? b : c ? d : e is
+// parsed as a ? b : (c ? d : e).
 func (p *parser) ParseExpression() ast.Expr {
-	return p.parseBinOp(1)
+	cond := p.parseBinOp(1)
+	if p.tok != token.QUESTION {
+		return cond
+	}
+
+	questionPos, _, _ := p.expect(token.QUESTION)
+	trueValue := p.ParseExpression()
+	colonPos, _, _ := p.expect(token.COLON)
+	falseValue := p.ParseExpression()
+
+	return &ast.ConditionalExpr{
+		Condition:   cond,
+		QuestionPos: questionPos,
+		TrueValue:   trueValue,
+		ColonPos:    colonPos,
+		FalseValue:  falseValue,
+	}
 }
 
 // parseBinOp is the entrypoint for binary expressions. If there is no binary
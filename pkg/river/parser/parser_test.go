@@ -108,6 +108,9 @@ func TestParseExpressions(t *testing.T) {
 
 		"parens": `(1 + 5) * 100`,
 
+		"conditional":        `a == 1 ? "one" : "other"`,
+		"nested conditional": `a ? b : c ? d : e`,
+
 		"mixed exprsssion": `(a.b.c)(1, 3 * some_list[magic_index * 2]).resulting_field`,
 	}
 
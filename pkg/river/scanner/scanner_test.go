@@ -68,6 +68,8 @@ var tokens = []tokenExample{
 	{token.LCURLY, "{"},
 	{token.COMMA, ","},
 	{token.DOT, "."},
+	{token.QUESTION, "?"},
+	{token.COLON, ":"},
 
 	{token.RPAREN, ")"},
 	{token.RBRACK, "]"},
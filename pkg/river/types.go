@@ -14,6 +14,7 @@ var (
 	_ value.Capsule                = (Capsule)(nil)
 	_ value.ConvertibleFromCapsule = (ConvertibleFromCapsule)(nil)
 	_ value.ConvertibleIntoCapsule = (ConvertibleIntoCapsule)(nil)
+	_ value.SecretCapsule          = (SecretCapsule)(nil)
 )
 
 // The Unmarshaler interface allows a type to hook into River decoding and
@@ -73,3 +74,13 @@ type ConvertibleIntoCapsule interface {
 	// available. Other errors are treated as a River decoding error.
 	ConvertInto(dst interface{}) error
 }
+
+// SecretCapsule is a Capsule which holds a sensitive string value. The
+// nonsensitive standard library function uses SecretCapsule to convert a
+// sensitive value into a plain string.
+type SecretCapsule interface {
+	Capsule
+
+	// RevealSecret returns the sensitive string held by the capsule.
+	RevealSecret() string
+}
@@ -44,6 +44,10 @@ func makeDiagnostic(err error, assoc map[value.Value]ast.Node) error {
 		case value.FieldError:
 			fmt.Fprintf(&expr, ".%s", ne.Field)
 			val = ne.Value
+		case value.ArgError:
+			// The message may be replaced if the inner error is also a value error.
+			message = ne.Error()
+			val = ne.Argument
 		}
 
 		cause = val
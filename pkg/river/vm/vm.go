@@ -496,6 +496,22 @@ func (vm *Evaluator) evaluateExpr(scope *Scope, assoc map[value.Value]ast.Node,
 	case *ast.ParenExpr:
 		return vm.evaluateExpr(scope, assoc, expr.Inner)
 
+	case *ast.ConditionalExpr:
+		cond, err := vm.evaluateExpr(scope, assoc, expr.Condition)
+		if err != nil {
+			return value.Null, err
+		}
+		if cond.Type() != value.TypeBool {
+			return value.Null, value.TypeError{Value: cond, Expected: value.TypeBool}
+		}
+
+		// Only the selected branch is evaluated so that the other branch may
+		// reference values which are invalid when it isn't chosen.
+		if cond.Bool() {
+			return vm.evaluateExpr(scope, assoc, expr.TrueValue)
+		}
+		return vm.evaluateExpr(scope, assoc, expr.FalseValue)
+
 	case *ast.UnaryExpr:
 		val, err := vm.evaluateExpr(scope, assoc, expr.Value)
 		if err != nil {
@@ -65,6 +65,14 @@ func TestVM_ExprErrors(t *testing.T) {
 			}{},
 			expect: `test:1:7: [0, 1, 2] should be string, got array`,
 		},
+		{
+			name:  "non-bool condition",
+			input: `key = 5 ? 1 : 2`,
+			into: &struct {
+				Key int `river:"key,attr"`
+			}{},
+			expect: `test:1:7: 5 should be bool, got number`,
+		},
 	}
 
 	for _, tc := range tt {
@@ -23,6 +23,38 @@ func TestVM_Stdlib(t *testing.T) {
 		{"json_decode array", `json_decode("[0, 1, 2]")`, []interface{}{float64(0), float64(1), float64(2)}},
 		{"json_decode nil field", `json_decode("{\"foo\": null}")`, map[string]interface{}{"foo": nil}},
 		{"json_decode nil array element", `json_decode("[0, null]")`, []interface{}{float64(0), nil}},
+		{"json_encode", `json_encode({ foo = [1, 2] })`, string(`{"foo":[1,2]}`)},
+		{"yaml_decode object", `yaml_decode("foo: bar\nlist: [1, 2]")`, map[string]interface{}{"foo": "bar", "list": []interface{}{1, 2}}},
+
+		{"env_or set", `env_or("TEST_VAR", "fallback")`, string("Hello!")},
+		{"env_or unset", `env_or("DOES_NOT_EXIST", "fallback")`, string("fallback")},
+
+		{"format", `format("%s-%d", "foo", 5)`, string("foo-5")},
+		{"join", `join(["a", "b", "c"], ",")`, string("a,b,c")},
+		{"split", `split("a,b,c", ",")`, []interface{}{"a", "b", "c"}},
+		{"replace", `replace("foo-bar-baz", "-", "_")`, string("foo_bar_baz")},
+		{"trim", `trim("--foo--", "-")`, string("foo")},
+		{"trim_prefix", `trim_prefix("foobar", "foo")`, string("bar")},
+		{"trim_suffix", `trim_suffix("foobar", "bar")`, string("foo")},
+		{"trim_space", `trim_space("  foo  ")`, string("foo")},
+		{"to_lower", `to_lower("FOO")`, string("foo")},
+		{"to_upper", `to_upper("foo")`, string("FOO")},
+
+		{"regex_match", `regex_match("foo-123", "^foo-[0-9]+$")`, bool(true)},
+		{"regex_match no match", `regex_match("bar", "^foo")`, bool(false)},
+		{"regex_replace", `regex_replace("foo-123", "[0-9]+", "N")`, string("foo-N")},
+
+		{"coalesce", `coalesce(null, "", [], "foo", "bar")`, string("foo")},
+		{"coalesce all empty", `coalesce(null, "")`, nil},
+
+		{"base64_encode", `base64_encode("Hello!")`, string("SGVsbG8h")},
+		{"base64_decode", `base64_decode("SGVsbG8h")`, string("Hello!")},
+
+		{"nonsensitive string", `nonsensitive("foo")`, string("foo")},
+
+		{"parse_int", `parse_int("-42", 10)`, int(-42)},
+		{"parse_int hex", `parse_int("ff", 16)`, int(255)},
+		{"parse_float", `parse_float("1.5")`, float64(1.5)},
 	}
 
 	for _, tc := range tt {
@@ -39,6 +71,97 @@ func TestVM_Stdlib(t *testing.T) {
 	}
 }
 
+func TestVM_Stdlib_Errors(t *testing.T) {
+	tt := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{"join wrong type", `join("a", ",")`, `"a" should be array, got string`},
+		{"regex_match invalid pattern", `regex_match("foo", "(")`, "error parsing regexp: missing closing ): `(`"},
+		{"regex_replace wrong type", `regex_replace(5, "a", "b")`, `5 should be string, got number`},
+		{"base64_decode invalid", `base64_decode("!!")`, `illegal base64 data at input byte 0`},
+		{"parse_int invalid", `parse_int("foo", 10)`, `strconv.ParseInt: parsing "foo": invalid syntax`},
+		{"parse_int invalid base", `parse_int("10", 1)`, `invalid base 1`},
+		{"parse_float invalid", `parse_float("foo")`, `strconv.ParseFloat: parsing "foo": invalid syntax`},
+		{"nonsensitive wrong type", `nonsensitive(5)`, `expected secret or string, got number`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			var v interface{}
+			err = eval.Evaluate(nil, &v)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expect)
+		})
+	}
+}
+
+func TestVM_Stdlib_Nonsensitive(t *testing.T) {
+	expr, err := parser.ParseExpression(`nonsensitive(secret)`)
+	require.NoError(t, err)
+
+	eval := vm.New(expr)
+	scope := &vm.Scope{
+		Variables: map[string]interface{}{"secret": testSecret("Hello!")},
+	}
+
+	var v string
+	require.NoError(t, eval.Evaluate(scope, &v))
+	require.Equal(t, "Hello!", v)
+}
+
+func TestVM_Stdlib_Secrets(t *testing.T) {
+	tt := []struct {
+		name  string
+		input string
+	}{
+		{"format", `format("%s", secret)`},
+		{"format nested", `format("%v", [1, secret])`},
+		{"json_encode", `json_encode(secret)`},
+		{"json_encode nested", `json_encode({ a = secret })`},
+		{"json_encode array", `json_encode([{ a = [secret] }])`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+			scope := &vm.Scope{
+				Variables: map[string]interface{}{"secret": testSecret("hunter2")},
+			}
+
+			var v interface{}
+			err = eval.Evaluate(scope, &v)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "secrets may not be converted into strings")
+			require.NotContains(t, err.Error(), "hunter2")
+		})
+	}
+
+	// Revealed secrets can be used.
+	expr, err := parser.ParseExpression(`json_encode({ a = nonsensitive(secret) })`)
+	require.NoError(t, err)
+	scope := &vm.Scope{
+		Variables: map[string]interface{}{"secret": testSecret("hunter2")},
+	}
+	var v string
+	require.NoError(t, vm.New(expr).Evaluate(scope, &v))
+	require.Equal(t, `{"a":"hunter2"}`, v)
+}
+
+type testSecret string
+
+func (s testSecret) RiverCapsule()        {}
+func (s testSecret) RevealSecret() string { return string(s) }
+
 func BenchmarkConcat(b *testing.B) {
 	// There's a bit of setup work to do here: we want to create a scope
 	// holding a slice of the Data type, which has a fair amount of data in
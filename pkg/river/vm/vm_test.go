@@ -130,6 +130,13 @@ func TestVM_Evaluate(t *testing.T) {
 		{`!true`, bool(false)},
 		{`!false`, bool(true)},
 		{`-15`, int(-15)},
+
+		// Conditional
+		{`true ? 1 : 2`, int(1)},
+		{`false ? 1 : 2`, int(2)},
+		{`foobar > 40 ? "large" : "small"`, string("large")},
+		{`false ? 1 : true ? 2 : 3`, int(2)},
+		{`true ? 1 : [0][5]`, int(1)}, // Unselected branch is not evaluated
 	}
 
 	for _, tc := range tt {
@@ -335,6 +335,10 @@ type serviceGraphsConfig struct {
 	Enabled  bool          `yaml:"enabled,omitempty"`
 	Wait     time.Duration `yaml:"wait,omitempty"`
 	MaxItems int           `yaml:"max_items,omitempty"`
+
+	Dimensions                    []string        `yaml:"dimensions,omitempty"`
+	ClientLatencyHistogramBuckets []time.Duration `yaml:"client_latency_histogram_buckets,omitempty"`
+	ServerLatencyHistogramBuckets []time.Duration `yaml:"server_latency_histogram_buckets,omitempty"`
 }
 
 // exporter builds an OTel exporter from RemoteWriteConfig
@@ -708,8 +712,11 @@ func (c *InstanceConfig) otelConfig() (*config.Config, error) {
 
 	if c.ServiceGraphs != nil && c.ServiceGraphs.Enabled {
 		processors[servicegraphprocessor.TypeStr] = map[string]interface{}{
-			"wait":      c.ServiceGraphs.Wait,
-			"max_items": c.ServiceGraphs.MaxItems,
+			"wait":                             c.ServiceGraphs.Wait,
+			"max_items":                        c.ServiceGraphs.MaxItems,
+			"dimensions":                       c.ServiceGraphs.Dimensions,
+			"client_latency_histogram_buckets": c.ServiceGraphs.ClientLatencyHistogramBuckets,
+			"server_latency_histogram_buckets": c.ServiceGraphs.ServerLatencyHistogramBuckets,
 		}
 		processorNames = append(processorNames, servicegraphprocessor.TypeStr)
 	}
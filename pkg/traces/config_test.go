@@ -974,6 +974,46 @@ service:
       exporters: ["otlp/0"]
       processors: ["service_graphs"]
       receivers: ["push_receiver", "jaeger"]
+`,
+		},
+		{
+			name: "service graphs with dimensions and buckets",
+			cfg: `
+receivers:
+  jaeger:
+    protocols:
+      grpc:
+remote_write:
+  - endpoint: example.com:12345
+service_graphs:
+  enabled: true
+  dimensions: [http.method]
+  client_latency_histogram_buckets: [100ms, 1s]
+  server_latency_histogram_buckets: [10ms, 100ms]
+`,
+			expectedConfig: `
+receivers:
+  push_receiver: {}
+  jaeger:
+    protocols:
+      grpc:
+exporters:
+  otlp/0:
+    endpoint: example.com:12345
+    compression: gzip
+    retry_on_failure:
+      max_elapsed_time: 60s
+processors:
+  service_graphs:
+    dimensions: [http.method]
+    client_latency_histogram_buckets: [100ms, 1s]
+    server_latency_histogram_buckets: [10ms, 100ms]
+service:
+  pipelines:
+    traces:
+      exporters: ["otlp/0"]
+      processors: ["service_graphs"]
+      receivers: ["push_receiver", "jaeger"]
 `,
 		},
 		{
@@ -2,8 +2,10 @@ package servicegraphprocessor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/prometheus/prometheus/util/strutil"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
@@ -30,12 +32,60 @@ type Config struct {
 
 	Workers int `mapstructure:"workers"`
 
-	SuccessCodes *successCodes `mapstructure:"success_codes"`
+	SuccessCodes *SuccessCodes `mapstructure:"success_codes"`
+
+	// Dimensions are span or resource attributes which are added as labels
+	// to the metrics of edges.
+	Dimensions []string `mapstructure:"dimensions"`
+
+	// Buckets of the latency histograms as seen from the client and from the
+	// server.
+	ClientLatencyHistogramBuckets []time.Duration `mapstructure:"client_latency_histogram_buckets"`
+	ServerLatencyHistogramBuckets []time.Duration `mapstructure:"server_latency_histogram_buckets"`
 }
 
-type successCodes struct {
-	http []int64 `mapstructure:"http"`
-	grpc []int64 `mapstructure:"grpc"`
+// Validate checks that the dimensions don't conflict with the labels of the
+// processor metrics and that the latency buckets are valid.
+func (c *Config) Validate() error {
+	if err := validateBuckets(c.ClientLatencyHistogramBuckets); err != nil {
+		return fmt.Errorf("invalid client_latency_histogram_buckets: %w", err)
+	}
+	if err := validateBuckets(c.ServerLatencyHistogramBuckets); err != nil {
+		return fmt.Errorf("invalid server_latency_histogram_buckets: %w", err)
+	}
+
+	seen := make(map[string]string, len(c.Dimensions))
+	for _, l := range edgeLabelNames {
+		seen[l] = l
+	}
+	for _, d := range c.Dimensions {
+		name := strutil.SanitizeLabelName(d)
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("dimension %q conflicts with %q", d, other)
+		}
+		seen[name] = d
+	}
+	return nil
+}
+
+// validateBuckets checks that buckets are positive and strictly increasing,
+// as required by Prometheus histograms.
+func validateBuckets(buckets []time.Duration) error {
+	for i, b := range buckets {
+		if b <= 0 {
+			return fmt.Errorf("bucket %s must be greater than zero", b)
+		}
+		if i > 0 && b <= buckets[i-1] {
+			return fmt.Errorf("buckets must be in increasing order, got %s after %s", b, buckets[i-1])
+		}
+	}
+	return nil
+}
+
+// SuccessCodes holds the status codes which mark a request as successful.
+type SuccessCodes struct {
+	HTTP []int64 `mapstructure:"http"`
+	GRPC []int64 `mapstructure:"grpc"`
 }
 
 // NewFactory returns a new factory for the Prometheus service graph processor.
@@ -61,5 +111,8 @@ func createTracesProcessor(
 ) (component.TracesProcessor, error) {
 
 	eCfg := cfg.(*Config)
+	if err := eCfg.Validate(); err != nil {
+		return nil, err
+	}
 	return newProcessor(nextConsumer, eCfg), nil
 }
@@ -11,8 +11,10 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/pkg/traces/contextkeys"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/util/strutil"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
 	"google.golang.org/grpc/codes"
@@ -26,6 +28,30 @@ func (t tooManySpansError) Error() string {
 	return fmt.Sprintf("dropped %d spans", t.droppedSpans)
 }
 
+// Connection types of edges. Edges between two instrumented services don't
+// have a connection type.
+const (
+	connectionTypeMessagingSystem = "messaging_system"
+	connectionTypeDatabase        = "database"
+	connectionTypeVirtualNode     = "virtual_node"
+)
+
+// edgeLabelNames are the labels of the edge metrics, before dimensions.
+var edgeLabelNames = []string{"client", "server", "connection_type"}
+
+// peerAttributes are the span attributes used to name the peer of a span
+// when the peer isn't instrumented, in order of precedence.
+var peerAttributes = []string{
+	semconv.AttributePeerService,
+	semconv.AttributeDBSystem,
+	semconv.AttributeMessagingSystem,
+	semconv.AttributeNetPeerName,
+}
+
+// defaultLatencyHistogramBuckets are the default buckets of the latency
+// histograms, in seconds.
+var defaultLatencyHistogramBuckets = prometheus.ExponentialBuckets(0.01, 2, 12)
+
 // edge is an edge between two nodes in the graph
 type edge struct {
 	key string
@@ -37,6 +63,17 @@ type edge struct {
 	// the edge will be considered as failed.
 	failed bool
 
+	connectionType string
+
+	// peerNode is the name of the node inferred from the attributes of the
+	// client or consumer span. It's used as the other end of the edge if it
+	// expires without being paired.
+	peerNode string
+
+	// dimensions holds the values of the configured dimensions. Values of the
+	// client span take precedence over values of the server span.
+	dimensions map[string]string
+
 	// expiration is the time at which the edge expires, expressed as Unix time
 	expiration int64
 }
@@ -70,6 +107,10 @@ type processor struct {
 	wait     time.Duration
 	maxItems int
 
+	dimensions           []string
+	clientLatencyBuckets []float64
+	serverLatencyBuckets []float64
+
 	// completed edges are pushed through this channel to be processed.
 	collectCh chan string
 
@@ -105,10 +146,10 @@ func newProcessor(nextConsumer consumer.Traces, cfg *Config) *processor {
 		grpcSuccessCodeMap = make(map[int]struct{})
 	)
 	if cfg.SuccessCodes != nil {
-		for _, sc := range cfg.SuccessCodes.http {
+		for _, sc := range cfg.SuccessCodes.HTTP {
 			httpSuccessCodeMap[int(sc)] = struct{}{}
 		}
-		for _, sc := range cfg.SuccessCodes.grpc {
+		for _, sc := range cfg.SuccessCodes.GRPC {
 			grpcSuccessCodeMap[int(sc)] = struct{}{}
 		}
 	}
@@ -117,10 +158,13 @@ func newProcessor(nextConsumer consumer.Traces, cfg *Config) *processor {
 		nextConsumer: nextConsumer,
 		logger:       logger,
 
-		wait:               cfg.Wait,
-		maxItems:           cfg.MaxItems,
-		httpSuccessCodeMap: httpSuccessCodeMap,
-		grpcSuccessCodeMap: grpcSuccessCodeMap,
+		wait:                 cfg.Wait,
+		maxItems:             cfg.MaxItems,
+		dimensions:           cfg.Dimensions,
+		clientLatencyBuckets: latencyBuckets(cfg.ClientLatencyHistogramBuckets),
+		serverLatencyBuckets: latencyBuckets(cfg.ServerLatencyHistogramBuckets),
+		httpSuccessCodeMap:   httpSuccessCodeMap,
+		grpcSuccessCodeMap:   grpcSuccessCodeMap,
 
 		collectCh: make(chan string, cfg.Workers),
 
@@ -157,33 +201,38 @@ func (p *processor) Start(ctx context.Context, _ component.Host) error {
 }
 
 func (p *processor) registerMetrics() error {
+	labels := append([]string{}, edgeLabelNames...)
+	for _, d := range p.dimensions {
+		labels = append(labels, strutil.SanitizeLabelName(d))
+	}
+
 	p.serviceGraphRequestTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "traces",
 		Name:      "service_graph_request_total",
 		Help:      "Total count of requests between two nodes",
-	}, []string{"client", "server"})
+	}, labels)
 	p.serviceGraphRequestFailedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "traces",
 		Name:      "service_graph_request_failed_total",
 		Help:      "Total count of failed requests between two nodes",
-	}, []string{"client", "server"})
+	}, labels)
 	p.serviceGraphRequestServerHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "traces",
 		Name:      "service_graph_request_server_seconds",
 		Help:      "Time for a request between two nodes as seen from the server",
-		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
-	}, []string{"client", "server"})
+		Buckets:   p.serverLatencyBuckets,
+	}, labels)
 	p.serviceGraphRequestClientHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "traces",
 		Name:      "service_graph_request_client_seconds",
 		Help:      "Time for a request between two nodes as seen from the client",
-		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
-	}, []string{"client", "server"})
+		Buckets:   p.clientLatencyBuckets,
+	}, labels)
 	p.serviceGraphUnpairedSpansTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "traces",
 		Name:      "service_graph_unpaired_spans_total",
 		Help:      "Total count of unpaired spans",
-	}, []string{"client", "server"})
+	}, edgeLabelNames)
 	p.serviceGraphDroppedSpansTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "traces",
 		Name:      "service_graph_dropped_spans_total",
@@ -221,6 +270,7 @@ func (p *processor) unregisterMetrics() {
 		p.serviceGraphRequestServerHistogram,
 		p.serviceGraphRequestClientHistogram,
 		p.serviceGraphUnpairedSpansTotal,
+		p.serviceGraphDroppedSpansTotal,
 	}
 
 	for _, c := range cs {
@@ -249,17 +299,50 @@ func (p *processor) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
 }
 
 // collectEdge records the metrics for the given edge.
-// Returns true if the edge is completed or expired and should be deleted.
+//
+// Expired edges which weren't paired are recorded as an edge to a virtual
+// node if a peer could be inferred from the span attributes, and as unpaired
+// otherwise.
 func (p *processor) collectEdge(e *edge) {
-	if e.isCompleted() {
-		p.serviceGraphRequestTotal.WithLabelValues(e.clientService, e.serverService).Inc()
-		if e.failed {
-			p.serviceGraphRequestFailedTotal.WithLabelValues(e.clientService, e.serverService).Inc()
+	switch {
+	case e.isCompleted():
+		p.recordEdge(e, true, true)
+
+	case e.isExpired() && e.peerNode != "":
+		if e.connectionType == "" {
+			e.connectionType = connectionTypeVirtualNode
 		}
-		p.serviceGraphRequestServerHistogram.WithLabelValues(e.clientService, e.serverService).Observe(e.serverLatency.Seconds())
-		p.serviceGraphRequestClientHistogram.WithLabelValues(e.clientService, e.serverService).Observe(e.clientLatency.Seconds())
-	} else if e.isExpired() {
-		p.serviceGraphUnpairedSpansTotal.WithLabelValues(e.clientService, e.serverService).Inc()
+		if e.clientService == "" {
+			e.clientService = e.peerNode
+			p.recordEdge(e, false, true)
+		} else {
+			e.serverService = e.peerNode
+			p.recordEdge(e, true, false)
+		}
+
+	case e.isExpired():
+		p.serviceGraphUnpairedSpansTotal.WithLabelValues(e.clientService, e.serverService, e.connectionType).Inc()
+	}
+}
+
+// recordEdge records the request metrics of e. Latencies are only observed
+// for the sides of the edge which have a span.
+func (p *processor) recordEdge(e *edge, client, server bool) {
+	labels := make([]string, 0, len(edgeLabelNames)+len(p.dimensions))
+	labels = append(labels, e.clientService, e.serverService, e.connectionType)
+	for _, d := range p.dimensions {
+		labels = append(labels, e.dimensions[d])
+	}
+
+	p.serviceGraphRequestTotal.WithLabelValues(labels...).Inc()
+	if e.failed {
+		p.serviceGraphRequestFailedTotal.WithLabelValues(labels...).Inc()
+	}
+	if server {
+		p.serviceGraphRequestServerHistogram.WithLabelValues(labels...).Observe(e.serverLatency.Seconds())
+	}
+	if client {
+		p.serviceGraphRequestClientHistogram.WithLabelValues(labels...).Observe(e.clientLatency.Seconds())
 	}
 }
 
@@ -269,7 +352,8 @@ func (p *processor) consume(trace ptrace.Traces) error {
 	for i := 0; i < rSpansSlice.Len(); i++ {
 		rSpan := rSpansSlice.At(i)
 
-		svc, ok := rSpan.Resource().Attributes().Get(semconv.AttributeServiceName)
+		resource := rSpan.Resource()
+		svc, ok := resource.Attributes().Get(semconv.AttributeServiceName)
 		if !ok || svc.Str() == "" {
 			continue
 		}
@@ -281,14 +365,21 @@ func (p *processor) consume(trace ptrace.Traces) error {
 			for k := 0; k < ils.Spans().Len(); k++ {
 				span := ils.Spans().At(k)
 
+				// Producer and consumer spans are paired like client and
+				// server spans, with the producer as the client.
 				switch span.Kind() {
-				case ptrace.SpanKindClient:
+				case ptrace.SpanKindClient, ptrace.SpanKindProducer:
 					k := key(span.TraceID().HexString(), span.SpanID().HexString())
 
 					edge, err := p.store.upsertEdge(k, func(e *edge) {
 						e.clientService = svc.Str()
 						e.clientLatency = spanDuration(span)
 						e.failed = e.failed || p.spanFailed(span) // keep request as failed if any span is failed
+						e.peerNode = peerNode(span)
+						if ct := connectionType(span); ct != "" {
+							e.connectionType = ct
+						}
+						p.setDimensions(e, span, resource, true)
 					})
 
 					if errors.Is(err, errTooManyItems) {
@@ -305,13 +396,20 @@ func (p *processor) consume(trace ptrace.Traces) error {
 						p.collectCh <- k
 					}
 
-				case ptrace.SpanKindServer:
+				case ptrace.SpanKindServer, ptrace.SpanKindConsumer:
 					k := key(span.TraceID().HexString(), span.ParentSpanID().HexString())
 
 					edge, err := p.store.upsertEdge(k, func(e *edge) {
 						e.serverService = svc.Str()
 						e.serverLatency = spanDuration(span)
 						e.failed = e.failed || p.spanFailed(span) // keep request as failed if any span is failed
+						if span.Kind() == ptrace.SpanKindConsumer {
+							// Consumers are usually called by the messaging
+							// system, which may not be instrumented.
+							e.connectionType = connectionTypeMessagingSystem
+							e.peerNode = peerNode(span)
+						}
+						p.setDimensions(e, span, resource, false)
 					})
 
 					if errors.Is(err, errTooManyItems) {
@@ -362,6 +460,66 @@ func (p *processor) spanFailed(span ptrace.Span) bool {
 	return span.Status().Code() == ptrace.StatusCodeError
 }
 
+// setDimensions copies the configured dimensions from the span or resource
+// attributes to e. Values of client spans override values of server spans.
+func (p *processor) setDimensions(e *edge, span ptrace.Span, resource pcommon.Resource, client bool) {
+	for _, d := range p.dimensions {
+		if _, ok := e.dimensions[d]; ok && !client {
+			continue
+		}
+		v, ok := span.Attributes().Get(d)
+		if !ok {
+			v, ok = resource.Attributes().Get(d)
+		}
+		if !ok {
+			continue
+		}
+		if e.dimensions == nil {
+			e.dimensions = make(map[string]string, len(p.dimensions))
+		}
+		e.dimensions[d] = v.AsString()
+	}
+}
+
+// connectionType returns the connection type of the edge of a client or
+// producer span.
+func connectionType(span ptrace.Span) string {
+	if span.Kind() == ptrace.SpanKindProducer {
+		return connectionTypeMessagingSystem
+	}
+	if _, ok := span.Attributes().Get(semconv.AttributeDBSystem); ok {
+		return connectionTypeDatabase
+	}
+	if _, ok := span.Attributes().Get(semconv.AttributeMessagingSystem); ok {
+		return connectionTypeMessagingSystem
+	}
+	return ""
+}
+
+// peerNode returns the name of the peer of span inferred from its attributes,
+// or an empty string if it can't be inferred.
+func peerNode(span ptrace.Span) string {
+	for _, attr := range peerAttributes {
+		if v, ok := span.Attributes().Get(attr); ok && v.AsString() != "" {
+			return v.AsString()
+		}
+	}
+	return ""
+}
+
+// latencyBuckets converts buckets to seconds, returning the default buckets
+// if none are configured.
+func latencyBuckets(buckets []time.Duration) []float64 {
+	if len(buckets) == 0 {
+		return defaultLatencyHistogramBuckets
+	}
+	res := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		res = append(res, b.Seconds())
+	}
+	return res
+}
+
 func spanDuration(span ptrace.Span) time.Duration {
 	return span.EndTimestamp().AsTime().Sub(span.StartTimestamp().AsTime())
 }
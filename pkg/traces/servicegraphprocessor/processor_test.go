@@ -13,7 +13,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
 )
 
 const (
@@ -45,9 +47,9 @@ func TestConsumeMetrics(t *testing.T) {
 			expectedMetrics: `
 				# HELP traces_service_graph_unpaired_spans_total Total count of unpaired spans
 				# TYPE traces_service_graph_unpaired_spans_total counter
-				traces_service_graph_unpaired_spans_total{client="",server="db"} 2
-				traces_service_graph_unpaired_spans_total{client="app",server=""} 3
-				traces_service_graph_unpaired_spans_total{client="lb",server=""} 3
+				traces_service_graph_unpaired_spans_total{client="",connection_type="",server="db"} 2
+				traces_service_graph_unpaired_spans_total{client="app",connection_type="",server=""} 3
+				traces_service_graph_unpaired_spans_total{client="lb",connection_type="",server=""} 3
 `,
 		},
 		{
@@ -64,8 +66,8 @@ func TestConsumeMetrics(t *testing.T) {
 			sampleDataPath: traceSamplePath,
 			cfg: &Config{
 				Wait: -time.Millisecond,
-				SuccessCodes: &successCodes{
-					http: []int64{404},
+				SuccessCodes: &SuccessCodes{
+					HTTP: []int64{404},
 				},
 			},
 			expectedMetrics: successCodesCaseMetrics,
@@ -96,6 +98,112 @@ func TestConsumeMetrics(t *testing.T) {
 	}
 }
 
+func TestConsumeMetrics_ConnectionTypes(t *testing.T) {
+	p := newProcessor(&mockConsumer{}, &Config{
+		Wait:                          -time.Millisecond,
+		Dimensions:                    []string{"cluster", "http.method"},
+		ClientLatencyHistogramBuckets: []time.Duration{time.Second, 2 * time.Second},
+		ServerLatencyHistogramBuckets: []time.Duration{500 * time.Millisecond},
+	})
+	close(p.closeCh) // Don't collect any edges, leave that to the test.
+
+	reg := prometheus.NewRegistry()
+	ctx := context.WithValue(context.Background(), contextkeys.PrometheusRegisterer, reg)
+	require.NoError(t, p.Start(ctx, nil))
+
+	traces := ptrace.NewTraces()
+	// Producer and consumer spans are paired.
+	addSpan(traces, "app", ptrace.SpanKindProducer, 1, 0, map[string]string{semconv.AttributeMessagingSystem: "kafka", "http.method": "POST"})
+	addSpan(traces, "worker", ptrace.SpanKindConsumer, 2, 1, map[string]string{"http.method": "GET"})
+	// Unpaired client spans are recorded as edges to virtual nodes.
+	addSpan(traces, "app", ptrace.SpanKindClient, 3, 0, map[string]string{semconv.AttributeDBSystem: "postgresql", semconv.AttributeNetPeerName: "db.local"})
+	addSpan(traces, "app", ptrace.SpanKindClient, 4, 0, map[string]string{semconv.AttributePeerService: "payments", semconv.AttributeNetPeerName: "payments.local"})
+	// Unpaired consumer spans are recorded as edges from the messaging system.
+	addSpan(traces, "worker", ptrace.SpanKindConsumer, 5, 6, map[string]string{semconv.AttributeMessagingSystem: "rabbitmq"})
+	// Spans without peer attributes are recorded as unpaired.
+	addSpan(traces, "worker", ptrace.SpanKindProducer, 7, 0, nil)
+
+	require.NoError(t, p.ConsumeTraces(context.Background(), traces))
+	collectMetrics(p)
+
+	expectedMetrics := `
+		# HELP traces_service_graph_request_client_seconds Time for a request between two nodes as seen from the client
+		# TYPE traces_service_graph_request_client_seconds histogram
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="database",http_method="",server="postgresql",le="1"} 1
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="database",http_method="",server="postgresql",le="2"} 1
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="database",http_method="",server="postgresql",le="+Inf"} 1
+		traces_service_graph_request_client_seconds_sum{client="app",cluster="test",connection_type="database",http_method="",server="postgresql"} 1
+		traces_service_graph_request_client_seconds_count{client="app",cluster="test",connection_type="database",http_method="",server="postgresql"} 1
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker",le="1"} 1
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker",le="2"} 1
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker",le="+Inf"} 1
+		traces_service_graph_request_client_seconds_sum{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker"} 1
+		traces_service_graph_request_client_seconds_count{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker"} 1
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="virtual_node",http_method="",server="payments",le="1"} 1
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="virtual_node",http_method="",server="payments",le="2"} 1
+		traces_service_graph_request_client_seconds_bucket{client="app",cluster="test",connection_type="virtual_node",http_method="",server="payments",le="+Inf"} 1
+		traces_service_graph_request_client_seconds_sum{client="app",cluster="test",connection_type="virtual_node",http_method="",server="payments"} 1
+		traces_service_graph_request_client_seconds_count{client="app",cluster="test",connection_type="virtual_node",http_method="",server="payments"} 1
+		# HELP traces_service_graph_request_server_seconds Time for a request between two nodes as seen from the server
+		# TYPE traces_service_graph_request_server_seconds histogram
+		traces_service_graph_request_server_seconds_bucket{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker",le="0.5"} 0
+		traces_service_graph_request_server_seconds_bucket{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker",le="+Inf"} 1
+		traces_service_graph_request_server_seconds_sum{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker"} 1
+		traces_service_graph_request_server_seconds_count{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker"} 1
+		traces_service_graph_request_server_seconds_bucket{client="rabbitmq",cluster="test",connection_type="messaging_system",http_method="",server="worker",le="0.5"} 0
+		traces_service_graph_request_server_seconds_bucket{client="rabbitmq",cluster="test",connection_type="messaging_system",http_method="",server="worker",le="+Inf"} 1
+		traces_service_graph_request_server_seconds_sum{client="rabbitmq",cluster="test",connection_type="messaging_system",http_method="",server="worker"} 1
+		traces_service_graph_request_server_seconds_count{client="rabbitmq",cluster="test",connection_type="messaging_system",http_method="",server="worker"} 1
+		# HELP traces_service_graph_request_total Total count of requests between two nodes
+		# TYPE traces_service_graph_request_total counter
+		traces_service_graph_request_total{client="app",cluster="test",connection_type="database",http_method="",server="postgresql"} 1
+		traces_service_graph_request_total{client="app",cluster="test",connection_type="messaging_system",http_method="POST",server="worker"} 1
+		traces_service_graph_request_total{client="app",cluster="test",connection_type="virtual_node",http_method="",server="payments"} 1
+		traces_service_graph_request_total{client="rabbitmq",cluster="test",connection_type="messaging_system",http_method="",server="worker"} 1
+		# HELP traces_service_graph_unpaired_spans_total Total count of unpaired spans
+		# TYPE traces_service_graph_unpaired_spans_total counter
+		traces_service_graph_unpaired_spans_total{client="worker",connection_type="messaging_system",server=""} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(expectedMetrics)))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, (&Config{Dimensions: []string{"http.method", "cluster"}}).Validate())
+	require.EqualError(t, (&Config{Dimensions: []string{"server"}}).Validate(), `dimension "server" conflicts with "server"`)
+	require.EqualError(t, (&Config{Dimensions: []string{"http.method", "http_method"}}).Validate(), `dimension "http_method" conflicts with "http.method"`)
+
+	require.NoError(t, (&Config{ClientLatencyHistogramBuckets: []time.Duration{time.Millisecond, time.Second}}).Validate())
+	require.EqualError(t, (&Config{ClientLatencyHistogramBuckets: []time.Duration{time.Second, time.Millisecond}}).Validate(),
+		"invalid client_latency_histogram_buckets: buckets must be in increasing order, got 1ms after 1s")
+	require.EqualError(t, (&Config{ServerLatencyHistogramBuckets: []time.Duration{time.Second, time.Second}}).Validate(),
+		"invalid server_latency_histogram_buckets: buckets must be in increasing order, got 1s after 1s")
+	require.EqualError(t, (&Config{ServerLatencyHistogramBuckets: []time.Duration{0}}).Validate(),
+		"invalid server_latency_histogram_buckets: bucket 0s must be greater than zero")
+}
+
+// addSpan adds a span of one second to a new resource of traces. All spans
+// belong to the same trace.
+func addSpan(traces ptrace.Traces, service string, kind ptrace.SpanKind, id, parentID byte, attrs map[string]string) {
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutString(semconv.AttributeServiceName, service)
+	rs.Resource().Attributes().PutString("cluster", "test")
+
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetTraceID(pcommon.TraceID([16]byte{1}))
+	span.SetSpanID(pcommon.SpanID([8]byte{id}))
+	if parentID != 0 {
+		span.SetParentSpanID(pcommon.SpanID([8]byte{parentID}))
+	}
+	span.SetKind(kind)
+
+	start := time.Now()
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Second)))
+	for k, v := range attrs {
+		span.Attributes().PutString(k, v)
+	}
+}
+
 func traceSamples(t *testing.T, path string) ptrace.Traces {
 	b, err := os.ReadFile(path)
 	require.NoError(t, err)
@@ -129,75 +237,75 @@ const (
 	happyCaseExpectedMetrics = `
 		# HELP traces_service_graph_request_client_seconds Time for a request between two nodes as seen from the client
 		# TYPE traces_service_graph_request_client_seconds histogram
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.01"} 0
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.02"} 0
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.04"} 0
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.08"} 0
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.16"} 0
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.32"} 0
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.64"} 0
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="1.28"} 2
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="2.56"} 3
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="5.12"} 3
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="10.24"} 3
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="20.48"} 3
-		traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="+Inf"} 3
-		traces_service_graph_request_client_seconds_sum{client="app",server="db"} 4.4
-		traces_service_graph_request_client_seconds_count{client="app",server="db"} 3
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.01"} 0
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.02"} 0
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.04"} 0
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.08"} 0
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.16"} 0
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.32"} 0
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.64"} 0
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="1.28"} 0
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="2.56"} 2
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="5.12"} 3
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="10.24"} 3
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="20.48"} 3
-		traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="+Inf"} 3
-		traces_service_graph_request_client_seconds_sum{client="lb",server="app"} 7.8
-		traces_service_graph_request_client_seconds_count{client="lb",server="app"} 3
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.01"} 0
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.02"} 0
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.04"} 0
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.08"} 0
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.16"} 0
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.32"} 0
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.64"} 0
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="1.28"} 2
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="2.56"} 3
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="5.12"} 3
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="10.24"} 3
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="20.48"} 3
+		traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="+Inf"} 3
+		traces_service_graph_request_client_seconds_sum{client="app",connection_type="",server="db"} 4.4
+		traces_service_graph_request_client_seconds_count{client="app",connection_type="",server="db"} 3
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.01"} 0
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.02"} 0
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.04"} 0
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.08"} 0
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.16"} 0
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.32"} 0
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.64"} 0
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="1.28"} 0
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="2.56"} 2
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="5.12"} 3
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="10.24"} 3
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="20.48"} 3
+		traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="+Inf"} 3
+		traces_service_graph_request_client_seconds_sum{client="lb",connection_type="",server="app"} 7.8
+		traces_service_graph_request_client_seconds_count{client="lb",connection_type="",server="app"} 3
 		# HELP traces_service_graph_request_failed_total Total count of failed requests between two nodes
 		# TYPE traces_service_graph_request_failed_total counter
-		traces_service_graph_request_failed_total{client="lb",server="app"} 2
+		traces_service_graph_request_failed_total{client="lb",connection_type="",server="app"} 2
 		# HELP traces_service_graph_request_server_seconds Time for a request between two nodes as seen from the server
 		# TYPE traces_service_graph_request_server_seconds histogram
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.01"} 0
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.02"} 0
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.04"} 0
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.08"} 0
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.16"} 0
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.32"} 0
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.64"} 0
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="1.28"} 1
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="2.56"} 3
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="5.12"} 3
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="10.24"} 3
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="20.48"} 3
-		traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="+Inf"} 3
-		traces_service_graph_request_server_seconds_sum{client="app",server="db"} 5
-		traces_service_graph_request_server_seconds_count{client="app",server="db"} 3
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.01"} 0
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.02"} 0
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.04"} 0
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.08"} 0
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.16"} 0
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.32"} 0
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.64"} 0
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="1.28"} 1
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="2.56"} 2
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="5.12"} 3
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="10.24"} 3
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="20.48"} 3
-		traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="+Inf"} 3
-		traces_service_graph_request_server_seconds_sum{client="lb",server="app"} 6.2
-		traces_service_graph_request_server_seconds_count{client="lb",server="app"} 3
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.01"} 0
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.02"} 0
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.04"} 0
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.08"} 0
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.16"} 0
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.32"} 0
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.64"} 0
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="1.28"} 1
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="2.56"} 3
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="5.12"} 3
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="10.24"} 3
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="20.48"} 3
+		traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="+Inf"} 3
+		traces_service_graph_request_server_seconds_sum{client="app",connection_type="",server="db"} 5
+		traces_service_graph_request_server_seconds_count{client="app",connection_type="",server="db"} 3
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.01"} 0
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.02"} 0
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.04"} 0
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.08"} 0
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.16"} 0
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.32"} 0
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.64"} 0
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="1.28"} 1
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="2.56"} 2
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="5.12"} 3
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="10.24"} 3
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="20.48"} 3
+		traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="+Inf"} 3
+		traces_service_graph_request_server_seconds_sum{client="lb",connection_type="",server="app"} 6.2
+		traces_service_graph_request_server_seconds_count{client="lb",connection_type="",server="app"} 3
 		# HELP traces_service_graph_request_total Total count of requests between two nodes
 		# TYPE traces_service_graph_request_total counter
-		traces_service_graph_request_total{client="app",server="db"} 3
-		traces_service_graph_request_total{client="lb",server="app"} 3
+		traces_service_graph_request_total{client="app",connection_type="",server="db"} 3
+		traces_service_graph_request_total{client="lb",connection_type="",server="app"} 3
 `
 	droppedSpansCaseMetrics = `
         # HELP traces_service_graph_dropped_spans_total Total count of dropped spans
@@ -208,117 +316,117 @@ const (
         traces_service_graph_dropped_spans_total{client="lb",server=""} 2
         # HELP traces_service_graph_request_client_seconds Time for a request between two nodes as seen from the client
         # TYPE traces_service_graph_request_client_seconds histogram
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.01"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.02"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.04"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.08"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.16"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.32"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.64"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="1.28"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="2.56"} 1
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="5.12"} 1
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="10.24"} 1
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="20.48"} 1
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="+Inf"} 1
-        traces_service_graph_request_client_seconds_sum{client="lb",server="app"} 2.5
-        traces_service_graph_request_client_seconds_count{client="lb",server="app"} 1
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.01"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.02"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.04"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.08"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.16"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.32"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.64"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="1.28"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="2.56"} 1
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="5.12"} 1
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="10.24"} 1
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="20.48"} 1
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="+Inf"} 1
+        traces_service_graph_request_client_seconds_sum{client="lb",connection_type="",server="app"} 2.5
+        traces_service_graph_request_client_seconds_count{client="lb",connection_type="",server="app"} 1
         # HELP traces_service_graph_request_failed_total Total count of failed requests between two nodes
         # TYPE traces_service_graph_request_failed_total counter
-        traces_service_graph_request_failed_total{client="lb",server="app"} 1
+        traces_service_graph_request_failed_total{client="lb",connection_type="",server="app"} 1
         # HELP traces_service_graph_request_server_seconds Time for a request between two nodes as seen from the server
         # TYPE traces_service_graph_request_server_seconds histogram
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.01"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.02"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.04"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.08"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.16"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.32"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.64"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="1.28"} 1
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="2.56"} 1
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="5.12"} 1
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="10.24"} 1
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="20.48"} 1
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="+Inf"} 1
-        traces_service_graph_request_server_seconds_sum{client="lb",server="app"} 1
-        traces_service_graph_request_server_seconds_count{client="lb",server="app"} 1
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.01"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.02"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.04"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.08"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.16"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.32"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.64"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="1.28"} 1
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="2.56"} 1
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="5.12"} 1
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="10.24"} 1
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="20.48"} 1
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="+Inf"} 1
+        traces_service_graph_request_server_seconds_sum{client="lb",connection_type="",server="app"} 1
+        traces_service_graph_request_server_seconds_count{client="lb",connection_type="",server="app"} 1
         # HELP traces_service_graph_request_total Total count of requests between two nodes
         # TYPE traces_service_graph_request_total counter
-        traces_service_graph_request_total{client="lb",server="app"} 1
+        traces_service_graph_request_total{client="lb",connection_type="",server="app"} 1
 `
 	// has only one failed span instead of 2
 	successCodesCaseMetrics = `
         # HELP traces_service_graph_request_client_seconds Time for a request between two nodes as seen from the client
         # TYPE traces_service_graph_request_client_seconds histogram
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.01"} 0
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.02"} 0
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.04"} 0
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.08"} 0
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.16"} 0
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.32"} 0
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="0.64"} 0
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="1.28"} 2
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="2.56"} 3
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="5.12"} 3
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="10.24"} 3
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="20.48"} 3
-        traces_service_graph_request_client_seconds_bucket{client="app",server="db",le="+Inf"} 3
-        traces_service_graph_request_client_seconds_sum{client="app",server="db"} 4.4
-        traces_service_graph_request_client_seconds_count{client="app",server="db"} 3
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.01"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.02"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.04"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.08"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.16"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.32"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="0.64"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="1.28"} 0
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="2.56"} 2
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="5.12"} 3
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="10.24"} 3
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="20.48"} 3
-        traces_service_graph_request_client_seconds_bucket{client="lb",server="app",le="+Inf"} 3
-        traces_service_graph_request_client_seconds_sum{client="lb",server="app"} 7.8
-        traces_service_graph_request_client_seconds_count{client="lb",server="app"} 3
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.01"} 0
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.02"} 0
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.04"} 0
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.08"} 0
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.16"} 0
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.32"} 0
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="0.64"} 0
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="1.28"} 2
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="2.56"} 3
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="5.12"} 3
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="10.24"} 3
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="20.48"} 3
+        traces_service_graph_request_client_seconds_bucket{client="app",connection_type="",server="db",le="+Inf"} 3
+        traces_service_graph_request_client_seconds_sum{client="app",connection_type="",server="db"} 4.4
+        traces_service_graph_request_client_seconds_count{client="app",connection_type="",server="db"} 3
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.01"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.02"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.04"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.08"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.16"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.32"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="0.64"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="1.28"} 0
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="2.56"} 2
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="5.12"} 3
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="10.24"} 3
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="20.48"} 3
+        traces_service_graph_request_client_seconds_bucket{client="lb",connection_type="",server="app",le="+Inf"} 3
+        traces_service_graph_request_client_seconds_sum{client="lb",connection_type="",server="app"} 7.8
+        traces_service_graph_request_client_seconds_count{client="lb",connection_type="",server="app"} 3
         # HELP traces_service_graph_request_failed_total Total count of failed requests between two nodes
         # TYPE traces_service_graph_request_failed_total counter
-        traces_service_graph_request_failed_total{client="lb",server="app"} 1
+        traces_service_graph_request_failed_total{client="lb",connection_type="",server="app"} 1
         # HELP traces_service_graph_request_server_seconds Time for a request between two nodes as seen from the server
         # TYPE traces_service_graph_request_server_seconds histogram
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.01"} 0
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.02"} 0
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.04"} 0
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.08"} 0
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.16"} 0
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.32"} 0
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="0.64"} 0
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="1.28"} 1
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="2.56"} 3
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="5.12"} 3
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="10.24"} 3
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="20.48"} 3
-        traces_service_graph_request_server_seconds_bucket{client="app",server="db",le="+Inf"} 3
-        traces_service_graph_request_server_seconds_sum{client="app",server="db"} 5
-        traces_service_graph_request_server_seconds_count{client="app",server="db"} 3
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.01"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.02"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.04"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.08"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.16"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.32"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="0.64"} 0
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="1.28"} 1
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="2.56"} 2
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="5.12"} 3
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="10.24"} 3
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="20.48"} 3
-        traces_service_graph_request_server_seconds_bucket{client="lb",server="app",le="+Inf"} 3
-        traces_service_graph_request_server_seconds_sum{client="lb",server="app"} 6.2
-        traces_service_graph_request_server_seconds_count{client="lb",server="app"} 3
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.01"} 0
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.02"} 0
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.04"} 0
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.08"} 0
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.16"} 0
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.32"} 0
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="0.64"} 0
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="1.28"} 1
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="2.56"} 3
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="5.12"} 3
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="10.24"} 3
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="20.48"} 3
+        traces_service_graph_request_server_seconds_bucket{client="app",connection_type="",server="db",le="+Inf"} 3
+        traces_service_graph_request_server_seconds_sum{client="app",connection_type="",server="db"} 5
+        traces_service_graph_request_server_seconds_count{client="app",connection_type="",server="db"} 3
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.01"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.02"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.04"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.08"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.16"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.32"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="0.64"} 0
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="1.28"} 1
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="2.56"} 2
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="5.12"} 3
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="10.24"} 3
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="20.48"} 3
+        traces_service_graph_request_server_seconds_bucket{client="lb",connection_type="",server="app",le="+Inf"} 3
+        traces_service_graph_request_server_seconds_sum{client="lb",connection_type="",server="app"} 6.2
+        traces_service_graph_request_server_seconds_count{client="lb",connection_type="",server="app"} 3
         # HELP traces_service_graph_request_total Total count of requests between two nodes
         # TYPE traces_service_graph_request_total counter
-        traces_service_graph_request_total{client="app",server="db"} 3
-        traces_service_graph_request_total{client="lb",server="app"} 3
+        traces_service_graph_request_total{client="app",connection_type="",server="db"} 3
+        traces_service_graph_request_total{client="lb",connection_type="",server="app"} 3
 `
 )
@@ -9,27 +9,38 @@ import (
 	"encoding/json"
 	"net/http"
 	"path"
+	"sort"
 
 	"github.com/prometheus/prometheus/util/httputil"
 
 	"github.com/gorilla/mux"
+	"github.com/grafana/agent/pkg/cluster"
 	"github.com/grafana/agent/pkg/flow"
 )
 
 // FlowAPI is a wrapper around the component API.
 type FlowAPI struct {
 	flow *flow.Flow
+	node cluster.Node
 }
 
-// NewFlowAPI instantiates a new Flow API.
-func NewFlowAPI(flow *flow.Flow, r *mux.Router) *FlowAPI {
-	return &FlowAPI{flow: flow}
+// NewFlowAPI instantiates a new Flow API. node is the cluster node the agent
+// is a member of.
+func NewFlowAPI(flow *flow.Flow, node cluster.Node, r *mux.Router) *FlowAPI {
+	return &FlowAPI{flow: flow, node: node}
 }
 
 // RegisterRoutes registers all the API's routes.
 func (f *FlowAPI) RegisterRoutes(urlPrefix string, r *mux.Router) {
 	r.Handle(path.Join(urlPrefix, "/api/v0/web/components"), httputil.CompressionHandler{Handler: f.listComponentsHandler()})
-	r.Handle(path.Join(urlPrefix, "/api/v0/web/components/{id}"), httputil.CompressionHandler{Handler: f.listComponentHandler()})
+	// The live debugging stream is registered before the component details so
+	// that it isn't matched as part of a component ID. It isn't compressed so
+	// that events are flushed to clients immediately.
+	r.Handle(path.Join(urlPrefix, "/api/v0/web/components/{id:.+}/debug"), f.liveDebuggingHandler())
+	r.Handle(path.Join(urlPrefix, "/api/v0/web/components/{id:.+}"), httputil.CompressionHandler{Handler: f.listComponentHandler()})
+	r.Handle(path.Join(urlPrefix, "/api/v0/web/peers"), httputil.CompressionHandler{Handler: f.listPeersHandler()})
+	r.Handle(path.Join(urlPrefix, "/api/v0/web/reloads"), httputil.CompressionHandler{Handler: f.listReloadsHandler()})
+	r.Handle(path.Join(urlPrefix, "/api/v0/web/reloads/{hash}/rollback"), f.rollbackHandler()).Methods(http.MethodPost)
 }
 
 func (f *FlowAPI) listComponentsHandler() http.HandlerFunc {
@@ -66,6 +77,38 @@ func (f *FlowAPI) listComponentHandler() http.HandlerFunc {
 	}
 }
 
+// peerInfo describes a single member of the cluster.
+type peerInfo struct {
+	Name  string `json:"name"`
+	Addr  string `json:"address"`
+	Self  bool   `json:"isSelf"`
+	State string `json:"state"`
+}
+
+func (f *FlowAPI) listPeersHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		peers := f.node.Peers()
+
+		infos := make([]peerInfo, 0, len(peers))
+		for _, p := range peers {
+			infos = append(infos, peerInfo{
+				Name:  p.Name,
+				Addr:  p.Addr,
+				Self:  p.Self,
+				State: p.State.String(),
+			})
+		}
+		sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+		bb, err := json.Marshal(infos)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(bb)
+	}
+}
+
 // json returns the JSON representation of c.
 func (f *FlowAPI) json(c *flow.ComponentInfo) ([]byte, error) {
 	var buf bytes.Buffer
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/grafana/agent/pkg/flow"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// liveDebuggingBufferSize is the number of events which are buffered for
+	// a client before new events are dropped.
+	liveDebuggingBufferSize = 1000
+
+	defaultLiveDebuggingRate = 100
+	maxLiveDebuggingRate     = 1000
+)
+
+// liveDebuggingOptions controls how much data is streamed to a client.
+type liveDebuggingOptions struct {
+	// SampleRate is the fraction of events to stream, between 0 and 1.
+	SampleRate float64
+	// MaxPerSecond is the maximum number of events streamed each second.
+	MaxPerSecond int
+}
+
+func parseLiveDebuggingOptions(q url.Values) (liveDebuggingOptions, error) {
+	opts := liveDebuggingOptions{SampleRate: 1, MaxPerSecond: defaultLiveDebuggingRate}
+
+	if v := q.Get("sample_rate"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return opts, fmt.Errorf("sample_rate must be a number greater than 0 and at most 1")
+		}
+		opts.SampleRate = f
+	}
+	if v := q.Get("max_per_second"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxLiveDebuggingRate {
+			return opts, fmt.Errorf("max_per_second must be an integer between 1 and %d", maxLiveDebuggingRate)
+		}
+		opts.MaxPerSecond = n
+	}
+	return opts, nil
+}
+
+// liveDebuggingHandler streams the data passing through a component as
+// server-sent events. Events are sampled and rate limited according to the
+// sample_rate and max_per_second query parameters; events which exceed the
+// limits or which a slow client can't keep up with are dropped.
+func (f *FlowAPI) liveDebuggingHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts, err := parseLiveDebuggingOptions(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		var (
+			events  = make(chan string, liveDebuggingBufferSize)
+			limiter = rate.NewLimiter(rate.Limit(opts.MaxPerSecond), opts.MaxPerSecond)
+		)
+
+		unregister, err := f.flow.LiveDebugging(mux.Vars(r)["id"], func(data string) {
+			if opts.SampleRate < 1 && rand.Float64() >= opts.SampleRate {
+				return
+			}
+			if !limiter.Allow() {
+				return
+			}
+			select {
+			case events <- data:
+			default:
+			}
+		})
+		switch {
+		case errors.Is(err, flow.ErrComponentNotFound):
+			http.NotFound(w, r)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer unregister()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case data := <-events:
+				if err := writeEvent(w, data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// writeEvent writes data as a single server-sent event. Each line of data is
+// written as its own data field so that multi-line data is preserved.
+func writeEvent(w http.ResponseWriter, data string) error {
+	var sb strings.Builder
+	for _, line := range strings.Split(data, "\n") {
+		sb.WriteString("data: ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
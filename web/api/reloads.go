@@ -0,0 +1,38 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/grafana/agent/pkg/flow"
+)
+
+func (f *FlowAPI) listReloadsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		bb, err := json.Marshal(f.flow.ReloadHistory())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(bb)
+	}
+}
+
+// rollbackHandler loads the config from the reload history entry with the
+// requested hash.
+func (f *FlowAPI) rollbackHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := f.flow.Rollback(mux.Vars(r)["hash"])
+		switch {
+		case errors.Is(err, flow.ErrReloadNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -2,6 +2,8 @@ import { BrowserRouter, Routes, Route } from 'react-router-dom';
 import Navbar from './features/layout/Navbar';
 import PageComponentList from './pages/PageComponentList';
 import Graph from './pages/Graph';
+import Clustering from './pages/Clustering';
+import Reloads from './pages/Reloads';
 import styles from './App.module.css';
 import { ComponentDetailPage } from './pages/ComponentDetailPage';
 import { PathPrefixContext } from './contexts/PathPrefixContext';
@@ -34,8 +36,10 @@ function App() {
             <Routes>
               <Route path="/" element={<PageComponentList />} />
               <Route path="/components" element={<PageComponentList />} />
-              <Route path="/component/:id" element={<ComponentDetailPage />} />
+              <Route path="/component/*" element={<ComponentDetailPage />} />
               <Route path="/graph" element={<Graph />} />
+              <Route path="/clustering" element={<Clustering />} />
+              <Route path="/reloads" element={<Reloads />} />
             </Routes>
           </main>
         </BrowserRouter>
@@ -0,0 +1,43 @@
+import { FC } from 'react';
+import { PeerInfo, PeerState } from './types';
+import styles from './PeerList.module.css';
+
+interface PeerListProps {
+  peers: PeerInfo[];
+}
+
+const PeerList: FC<PeerListProps> = ({ peers }) => {
+  const stateMappings = {
+    [PeerState.PARTICIPANT]: `${styles.state} ${styles['state-ok']}`,
+    [PeerState.VIEWER]: `${styles.state} ${styles['state-warn']}`,
+    [PeerState.TERMINATING]: `${styles.state} ${styles['state-error']}`,
+  };
+
+  return (
+    <div className={styles.list}>
+      <header>
+        <ul>
+          <li>State</li>
+          <li>Name</li>
+          <li>Address</li>
+        </ul>
+      </header>
+      {peers.map((peer) => {
+        return (
+          <ul key={peer.name}>
+            <li>
+              <span className={stateMappings[peer.state]}>{peer.state}</span>
+            </li>
+            <li className={styles.text}>
+              {peer.name}
+              {peer.isSelf && <span className={styles.self}>self</span>}
+            </li>
+            <li className={styles.text}>{peer.address}</li>
+          </ul>
+        );
+      })}
+    </div>
+  );
+};
+
+export default PeerList;
@@ -0,0 +1,26 @@
+/**
+ * PeerInfo is information about a single member of the cluster.
+ */
+export interface PeerInfo {
+  /** Name of the peer. Unique across the cluster. */
+  name: string;
+
+  /** host:port address used to connect to the peer over gRPC. */
+  address: string;
+
+  /** True if the peer is the agent serving the UI. */
+  isSelf: boolean;
+
+  /** Current state of the peer within the cluster. */
+  state: PeerState;
+}
+
+/**
+ * PeerState is the state of a peer within the cluster. Only participants are
+ * assigned work.
+ */
+export enum PeerState {
+  VIEWER = 'viewer',
+  PARTICIPANT = 'participant',
+  TERMINATING = 'terminating',
+}
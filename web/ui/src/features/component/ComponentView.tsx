@@ -7,6 +7,7 @@ import { FontAwesomeIcon } from '@fortawesome/react-fontawesome';
 import { faCubes, faLink } from '@fortawesome/free-solid-svg-icons';
 import ComponentList from './ComponentList';
 import { HealthLabel } from './HealthLabel';
+import { LiveDebugging } from './LiveDebugging';
 import { Link } from 'react-router-dom';
 
 export interface ComponentViewProps {
@@ -55,6 +56,13 @@ export const ComponentView: FC<ComponentViewProps> = (props) => {
           {argsPartition && partitionTOC(argsPartition)}
           {exportsPartition && partitionTOC(exportsPartition)}
           {debugPartition && partitionTOC(debugPartition)}
+          {props.component.liveDebugging && (
+            <li>
+              <Link to="#live-debugging" target="_top">
+                Live debugging
+              </Link>
+            </li>
+          )}
           {props.component.referencesTo.length > 0 && (
             <li>
               <Link to="#dependencies" target="_top">
@@ -106,6 +114,15 @@ export const ComponentView: FC<ComponentViewProps> = (props) => {
         {exportsPartition && <ComponentBody partition={exportsPartition} />}
         {debugPartition && <ComponentBody partition={debugPartition} />}
 
+        {props.component.liveDebugging && (
+          <section id="live-debugging">
+            <h2>Live debugging</h2>
+            <div className={styles.sectionContent}>
+              <LiveDebugging componentID={props.component.id} />
+            </div>
+          </section>
+        )}
+
         {props.component.referencesTo.length > 0 && (
           <section id="dependencies">
             <h2>Dependencies</h2>
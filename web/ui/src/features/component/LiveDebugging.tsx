@@ -0,0 +1,100 @@
+import { FC, useEffect, useRef, useState } from 'react';
+import styles from './LiveDebugging.module.css';
+
+/** Maximum number of events kept on screen. */
+const MAX_EVENTS = 500;
+
+interface LiveDebuggingEvent {
+  key: number;
+  data: string;
+}
+
+interface LiveDebuggingProps {
+  /** ID of the component to stream data from. */
+  componentID: string;
+}
+
+/**
+ * LiveDebugging streams the data passing through a component. Streaming is
+ * only active while the user has started it, so components don't do any
+ * extra work when nobody is watching.
+ */
+export const LiveDebugging: FC<LiveDebuggingProps> = ({ componentID }) => {
+  const [running, setRunning] = useState(false);
+  const [sampleRate, setSampleRate] = useState(1);
+  const [maxPerSecond, setMaxPerSecond] = useState(100);
+  const [events, setEvents] = useState<LiveDebuggingEvent[]>([]);
+  const [error, setError] = useState<string | undefined>(undefined);
+  const nextKey = useRef(0);
+
+  useEffect(
+    function () {
+      if (!running) {
+        return;
+      }
+
+      const params = new URLSearchParams({
+        sample_rate: sampleRate.toString(),
+        max_per_second: maxPerSecond.toString(),
+      });
+
+      // Request is relative to the <base> tag inside of <head>.
+      const source = new EventSource(`./api/v0/web/components/${componentID}/debug?${params.toString()}`);
+      source.onopen = () => setError(undefined);
+      source.onmessage = (ev) => {
+        const event = { key: nextKey.current++, data: ev.data };
+        setEvents((prev) => [event, ...prev].slice(0, MAX_EVENTS));
+      };
+      source.onerror = () => {
+        setError('Lost connection to the live debugging stream.');
+        source.close();
+        setRunning(false);
+      };
+
+      return () => source.close();
+    },
+    [componentID, running, sampleRate, maxPerSecond]
+  );
+
+  return (
+    <div className={styles.liveDebugging}>
+      <div className={styles.controls}>
+        <button onClick={() => setRunning(!running)}>{running ? 'Stop' : 'Start'}</button>
+        <label>
+          Sample rate
+          <input
+            type="number"
+            min={0.01}
+            max={1}
+            step={0.01}
+            value={sampleRate}
+            disabled={running}
+            onChange={(e) => setSampleRate(Number(e.target.value))}
+          />
+        </label>
+        <label>
+          Max events per second
+          <input
+            type="number"
+            min={1}
+            max={1000}
+            value={maxPerSecond}
+            disabled={running}
+            onChange={(e) => setMaxPerSecond(Number(e.target.value))}
+          />
+        </label>
+        <button onClick={() => setEvents([])} disabled={events.length === 0}>
+          Clear
+        </button>
+      </div>
+      {error && <p className={styles.error}>{error}</p>}
+      {events.length === 0 ? (
+        <em className={styles.informative}>{running ? '(Waiting for data)' : '(Press start to stream data)'}</em>
+      ) : (
+        <ol className={styles.events}>
+          {events.map((ev) => <li key={ev.key}>{ev.data}</li>)}
+        </ol>
+      )}
+    </div>
+  );
+};
@@ -7,6 +7,12 @@ export interface ComponentInfo {
   /** The id of the component uniquely identifies the component. */
   id: string;
 
+  /**
+   * The ID of the module which the component is running in. Unset for
+   * components which are not running inside of a module.
+   */
+  moduleID?: string;
+
   /**
    * The name of the component is the name of the block used to instantiate
    * the component. For example, the component ID
@@ -39,6 +45,12 @@ export interface ComponentInfo {
    * IDs of components which this component is referencing.
    */
   referencesTo: string[];
+
+  /**
+   * Whether the component can stream the data passing through it for live
+   * debugging.
+   */
+  liveDebugging?: boolean;
 }
 
 /**
@@ -16,6 +16,16 @@ function Navbar() {
             Graph
           </NavLink>
         </li>
+        <li>
+          <NavLink to="/clustering" className="nav-link">
+            Clustering
+          </NavLink>
+        </li>
+        <li>
+          <NavLink to="/reloads" className="nav-link">
+            Reloads
+          </NavLink>
+        </li>
         <li>
           <a href="https://grafana.com/docs/agent/latest">Help</a>
         </li>
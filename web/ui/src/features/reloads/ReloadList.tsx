@@ -0,0 +1,64 @@
+import { FC, useState } from 'react';
+import { ReloadEntry } from './types';
+import styles from './ReloadList.module.css';
+
+interface ReloadListProps {
+  reloads: ReloadEntry[];
+  onRollback: (hash: string) => Promise<void>;
+}
+
+const ReloadList: FC<ReloadListProps> = ({ reloads, onRollback }) => {
+  const [rollbackError, setRollbackError] = useState<string | undefined>();
+
+  const rollback = (hash: string) => {
+    setRollbackError(undefined);
+    onRollback(hash).catch((err: Error) => setRollbackError(err.message));
+  };
+
+  return (
+    <>
+      {rollbackError && <p className={styles.rollbackError}>Rollback failed: {rollbackError}</p>}
+      <div className={styles.list}>
+        <header>
+          <ul>
+            <li>Status</li>
+            <li>Time</li>
+            <li>Hash</li>
+            <li></li>
+          </ul>
+        </header>
+        {reloads.map((entry) => {
+          const status = entry.error ? 'failed' : entry.active ? 'active' : 'loaded';
+          const stateClass =
+            status === 'failed'
+              ? `${styles.state} ${styles['state-error']}`
+              : status === 'active'
+              ? `${styles.state} ${styles['state-ok']}`
+              : styles.state;
+
+          return (
+            <ul key={`${entry.timestamp}-${entry.hash}`}>
+              <li>
+                <span className={stateClass}>{status}</span>
+              </li>
+              <li className={styles.text}>{new Date(entry.timestamp).toLocaleString()}</li>
+              <li className={styles.text}>
+                <span className={styles.hash}>{entry.hash.substring(0, 12)}</span>
+                {entry.error && <span className={styles.error}>{entry.error}</span>}
+              </li>
+              <li>
+                {!entry.error && !entry.active && (
+                  <button type="button" onClick={() => rollback(entry.hash)}>
+                    Roll back
+                  </button>
+                )}
+              </li>
+            </ul>
+          );
+        })}
+      </div>
+    </>
+  );
+};
+
+export default ReloadList;
@@ -0,0 +1,16 @@
+/**
+ * ReloadEntry describes an attempt to load a config file.
+ */
+export interface ReloadEntry {
+  /** Hex-encoded SHA256 hash of the config file. */
+  hash: string;
+
+  /** Time the config file was loaded. */
+  timestamp: string;
+
+  /** Error encountered while loading the config file, if any. */
+  error?: string;
+
+  /** True if the config file is the one currently in use. */
+  active: boolean;
+}
@@ -0,0 +1,24 @@
+import { useEffect, useState } from 'react';
+import { PeerInfo } from '../features/clustering/types';
+
+/**
+ * usePeerInfo retrieves the list of cluster peers from the API.
+ */
+export const usePeerInfo = (): PeerInfo[] => {
+  const [peers, setPeers] = useState<PeerInfo[]>([]);
+
+  useEffect(function () {
+    const worker = async () => {
+      // Request is relative to the <base> tag inside of <head>.
+      const resp = await fetch('./api/v0/web/peers', {
+        cache: 'no-cache',
+        credentials: 'same-origin',
+      });
+      setPeers(await resp.json());
+    };
+
+    worker().catch(console.error);
+  }, []);
+
+  return peers;
+};
@@ -0,0 +1,44 @@
+import { useCallback, useEffect, useState } from 'react';
+import { ReloadEntry } from '../features/reloads/types';
+
+/**
+ * useReloadHistory retrieves the history of config reloads from the API,
+ * newest first. The returned function rolls back to the config with the
+ * given hash and refreshes the history.
+ */
+export const useReloadHistory = (): [ReloadEntry[], (hash: string) => Promise<void>] => {
+  const [reloads, setReloads] = useState<ReloadEntry[]>([]);
+
+  const refresh = useCallback(async () => {
+    // Request is relative to the <base> tag inside of <head>.
+    const resp = await fetch('./api/v0/web/reloads', {
+      cache: 'no-cache',
+      credentials: 'same-origin',
+    });
+    const entries: ReloadEntry[] = await resp.json();
+    setReloads(entries.reverse());
+  }, []);
+
+  const rollback = useCallback(
+    async (hash: string) => {
+      const resp = await fetch(`./api/v0/web/reloads/${hash}/rollback`, {
+        method: 'POST',
+        credentials: 'same-origin',
+      });
+      await refresh();
+      if (!resp.ok) {
+        throw new Error(await resp.text());
+      }
+    },
+    [refresh]
+  );
+
+  useEffect(
+    function () {
+      refresh().catch(console.error);
+    },
+    [refresh]
+  );
+
+  return [reloads, rollback];
+};
@@ -0,0 +1,16 @@
+import { faNetworkWired } from '@fortawesome/free-solid-svg-icons';
+import Page from '../features/layout/Page';
+import PeerList from '../features/clustering/PeerList';
+import { usePeerInfo } from '../hooks/peerInfo';
+
+function Clustering() {
+  const peers = usePeerInfo();
+
+  return (
+    <Page name="Clustering" desc="List of cluster peers" icon={faNetworkWired}>
+      <PeerList peers={peers} />
+    </Page>
+  );
+}
+
+export default Clustering;
@@ -5,7 +5,9 @@ import { ComponentDetail, componentInfoByID } from '../features/component/types'
 import { useComponentInfo } from '../hooks/componentInfo';
 
 export const ComponentDetailPage: FC = () => {
-  const { id } = useParams();
+  // Components running inside of modules have IDs which contain slashes, so
+  // the ID is taken from the rest of the path.
+  const { '*': id } = useParams();
 
   const components = useComponentInfo();
   const infoByID = componentInfoByID(components);
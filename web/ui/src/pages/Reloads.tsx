@@ -0,0 +1,16 @@
+import { faClockRotateLeft } from '@fortawesome/free-solid-svg-icons';
+import Page from '../features/layout/Page';
+import ReloadList from '../features/reloads/ReloadList';
+import { useReloadHistory } from '../hooks/reloadHistory';
+
+function Reloads() {
+  const [reloads, rollback] = useReloadHistory();
+
+  return (
+    <Page name="Reloads" desc="History of config reloads" icon={faClockRotateLeft}>
+      <ReloadList reloads={reloads} onRollback={rollback} />
+    </Page>
+  );
+}
+
+export default Reloads;